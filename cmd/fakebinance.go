@@ -0,0 +1,28 @@
+package main
+
+import (
+	"errors"
+	"gexabyte/pkg/clients/binance/fake"
+	"log/slog"
+	"net/http"
+)
+
+const fakeBinanceAddr = "localhost:8090"
+
+var errFakeBinanceUsage = errors.New("expected fakebinance [ADDR]")
+
+// fakeBinance serves the fake exchange, so the service runs offline with BINANCE_BASE_URL pointed at it.
+func fakeBinance(logger *slog.Logger, args []string) error {
+	addr := fakeBinanceAddr
+	switch len(args) {
+	case 0:
+	case 1:
+		addr = args[0]
+	default:
+		return errFakeBinanceUsage
+	}
+
+	logger.Info("fake binance is listening", "addr", addr, "base_url", "http://"+addr)
+
+	return http.ListenAndServe(addr, fake.New(fake.Config{}))
+}
@@ -1,16 +1,27 @@
 package main
 
 import (
+	"flag"
+	"fmt"
 	"gexabyte/internal/config"
-	"gexabyte/internal/repository"
-	"gexabyte/internal/service"
-	"gexabyte/internal/transport/http"
-	"gexabyte/pkg/clients/binance"
 	"gexabyte/pkg/logger"
 	"log"
+	"os"
 	"time"
 )
 
+const usage = `usage: gexabyte [-config_path=path] [command]
+
+commands:
+  serve              run the service, the default command
+  migrate up         apply all new migrations
+  migrate down [N]   roll back the last N migrations, 1 by default
+  migrate goto V     migrate up or down to version V
+  migrate version    print the applied version
+  migrate force V    set version V without running migrations, clears the dirty flag
+  fakebinance [ADDR] serve a fake exchange with random walk prices, localhost:8090 by default
+`
+
 func init() {
 	time.Local = time.UTC
 }
@@ -21,22 +32,33 @@ func init() {
 
 // @BasePath	/api/v1
 func main() {
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+
 	cfg := config.MustLoad()
 	logger := logger.New(cfg.LogLevel)
 
-	repo, err := repository.NewRepository(cfg)
-	if err != nil {
-		panic(err)
+	command, args := "serve", flag.Args()
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
 	}
 
-	binanceClient := binance.New(&binance.Config{
-		ApiKey:    cfg.Binance.ApiKey,
-		SecretKey: cfg.Binance.SecretKey,
-	})
-
-	service := service.New(cfg, logger, binanceClient, repo)
-
-	server := http.New(cfg, logger, service)
+	var err error
+	switch command {
+	case "serve":
+		err = serve(cfg, logger)
+	case "migrate":
+		err = migrate(cfg, logger, args)
+	case "fakebinance":
+		err = fakeBinance(logger, args)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
 
-	log.Fatalln(server.Start())
+	if err != nil {
+		log.Fatalln(err)
+	}
 }
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"gexabyte/internal/config"
+	"gexabyte/internal/repository"
+	"gexabyte/pkg/clients/postgres"
+	"log/slog"
+	"strconv"
+)
+
+var errMigrateUsage = errors.New("expected migrate up, down [N], goto V, version or force V")
+
+// migrate manages the postgres schema, so it is rolled out once before replicas start with DB_AUTO_MIGRATE=false.
+func migrate(cfg *config.Config, logger *slog.Logger, args []string) error {
+	if cfg.DBDriver != repository.DriverPostgres {
+		return fmt.Errorf("migrations exist only for %s, db driver is %s", repository.DriverPostgres, cfg.DBDriver)
+	}
+
+	run, err := parseMigrate(args)
+	if err != nil {
+		return err
+	}
+
+	migration, err := postgres.NewMigration(cfg.Postgres.MigrationURL, cfg.Postgres.DSN)
+	if err != nil {
+		return err
+	}
+	defer migration.Close()
+
+	if err := run(migration); err != nil {
+		return err
+	}
+
+	version, dirty, err := migration.Version()
+	if err != nil {
+		return err
+	}
+
+	logger.Info("migrate: done", "args", args, "version", version, "dirty", dirty)
+	return nil
+}
+
+// parseMigrate checks arguments before connecting to the database.
+func parseMigrate(args []string) (func(m *postgres.Migration) error, error) {
+	if len(args) == 0 {
+		return nil, errMigrateUsage
+	}
+
+	command, args := args[0], args[1:]
+	switch {
+	case command == "up" && len(args) == 0:
+		return (*postgres.Migration).Up, nil
+
+	case command == "down" && len(args) <= 1:
+		steps := 1
+		if len(args) == 1 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid steps %q", args[0])
+			}
+			steps = n
+		}
+		return func(m *postgres.Migration) error { return m.Down(steps) }, nil
+
+	case command == "goto" && len(args) == 1:
+		version, err := strconv.ParseUint(args[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version %q", args[0])
+		}
+		return func(m *postgres.Migration) error { return m.Goto(uint(version)) }, nil
+
+	case command == "version" && len(args) == 0:
+		return func(m *postgres.Migration) error { return nil }, nil
+
+	case command == "force" && len(args) == 1:
+		version, err := strconv.Atoi(args[0])
+		if err != nil || version < -1 {
+			return nil, fmt.Errorf("invalid version %q", args[0])
+		}
+		return func(m *postgres.Migration) error { return m.Force(version) }, nil
+	}
+
+	return nil, errMigrateUsage
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gexabyte/internal/config"
+	"gexabyte/internal/model"
+	"gexabyte/internal/repository"
+	"gexabyte/internal/service"
+	"gexabyte/internal/service/currency"
+	"gexabyte/internal/transport/http"
+	"gexabyte/pkg/clients/binance"
+	"gexabyte/pkg/clients/bybit"
+	"gexabyte/pkg/clients/coinbase"
+	"gexabyte/pkg/clients/fxrate"
+	"gexabyte/pkg/clients/kraken"
+	"gexabyte/pkg/clients/marketdata"
+	"log/slog"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// serve runs the service until the http server stops.
+// Migrations are applied first unless DB_AUTO_MIGRATE is off.
+func serve(cfg *config.Config, logger *slog.Logger) error {
+	repo, err := repository.NewRepository(cfg)
+	if err != nil {
+		return err
+	}
+
+	binanceClient := binance.NewResilient(
+		binance.New(&binance.Config{
+			BaseURL:   cfg.Binance.BaseURL,
+			ApiKey:    cfg.Binance.ApiKey,
+			SecretKey: cfg.Binance.SecretKey,
+			Timeout:   cfg.Binance.Timeout,
+
+			WeightLimit: cfg.Binance.WeightLimit,
+		}),
+		binance.ResilienceConfig{
+			Retries:         cfg.Binance.Retries,
+			BackoffBase:     cfg.Binance.BackoffBase,
+			BackoffMax:      cfg.Binance.BackoffMax,
+			BreakerFailures: cfg.Binance.BreakerFailures,
+			BreakerCooldown: cfg.Binance.BreakerCooldown,
+		},
+	)
+
+	marketStream := binance.NewStream(binance.StreamConfig{
+		URL:  cfg.Binance.StreamURL,
+		Kind: cfg.Ingest.Stream,
+	})
+
+	providers, err := marketProviders(cfg, binanceClient)
+	if err != nil {
+		return err
+	}
+	if err := checkConsensus(cfg, len(providers)); err != nil {
+		return err
+	}
+	if err := checkRetention(cfg); err != nil {
+		return err
+	}
+	if err := checkIngest(cfg); err != nil {
+		return err
+	}
+	if err := checkPriceConflict(cfg); err != nil {
+		return err
+	}
+
+	fxProvider, err := newFxProvider(cfg)
+	if err != nil {
+		return err
+	}
+
+	service := service.New(cfg, logger, binanceClient, providers, marketStream, fxProvider, repo)
+	service.Currency.RunBackgroudProcesses(context.Background())
+	service.Fx.RunBackgroudProcesses(context.Background())
+
+	server := http.New(cfg, logger, service)
+
+	return server.Start()
+}
+
+// marketProviders returns configured venues of market data in order, the first one is primary.
+func marketProviders(cfg *config.Config, binanceClient binance.Client) ([]marketdata.Provider, error) {
+	if len(cfg.Market.Providers) == 0 {
+		return nil, errors.New("no market providers configured")
+	}
+
+	providers := make([]marketdata.Provider, 0, len(cfg.Market.Providers))
+	for _, name := range cfg.Market.Providers {
+		switch strings.TrimSpace(name) {
+		case marketdata.ProviderBinance:
+			providers = append(providers, binance.NewProvider(binanceClient))
+		case marketdata.ProviderKraken:
+			providers = append(providers, kraken.New(kraken.Config{BaseURL: cfg.Market.KrakenURL, Timeout: cfg.Market.Timeout}))
+		case marketdata.ProviderCoinbase:
+			providers = append(providers, coinbase.New(coinbase.Config{BaseURL: cfg.Market.CoinbaseURL, Timeout: cfg.Market.Timeout}))
+		case marketdata.ProviderBybit:
+			providers = append(providers, bybit.New(bybit.Config{BaseURL: cfg.Market.BybitURL, Timeout: cfg.Market.Timeout}))
+		default:
+			return nil, fmt.Errorf("unknown market provider: %s", name)
+		}
+	}
+
+	return providers, nil
+}
+
+// newFxProvider returns the configured provider of fiat rates.
+// A non-positive refresh or a currency the provider cannot quote stops the start.
+func newFxProvider(cfg *config.Config) (fxrate.Provider, error) {
+	if cfg.Fx.Refresh <= 0 {
+		return nil, fmt.Errorf("non-positive fx refresh: %s", cfg.Fx.Refresh)
+	}
+
+	provider, err := buildFxProvider(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkFxCurrencies(cfg, provider); err != nil {
+		return nil, err
+	}
+
+	return provider, nil
+}
+
+// buildFxProvider makes the provider named by FX_PROVIDER.
+func buildFxProvider(cfg *config.Config) (fxrate.Provider, error) {
+	switch cfg.Fx.Provider {
+	case fxrate.ProviderStatic:
+		rates := make(map[string]decimal.Decimal, len(cfg.Fx.Rates))
+		for currency, value := range cfg.Fx.Rates {
+			rate, err := decimal.NewFromString(strings.TrimSpace(value))
+			if err != nil || !rate.IsPositive() {
+				return nil, fmt.Errorf("incorrect fx rate of %s: %s", currency, value)
+			}
+			rates[currency] = rate
+		}
+		return fxrate.NewStatic(rates), nil
+	case fxrate.ProviderFile:
+		if cfg.Fx.File == "" {
+			return nil, errors.New("fx file provider needs FX_FILE")
+		}
+		return fxrate.NewFile(cfg.Fx.File), nil
+	case fxrate.ProviderERAPI:
+		return fxrate.NewERAPI(fxrate.ERAPIConfig{BaseURL: cfg.Fx.URL, Timeout: cfg.Fx.Timeout}), nil
+	}
+
+	return nil, fmt.Errorf("unknown fx provider: %s", cfg.Fx.Provider)
+}
+
+// checkFxCurrencies asks the provider once for FX_CURRENCIES and rejects ones it leaves out,
+// otherwise prices could never be converted to them.
+func checkFxCurrencies(cfg *config.Config, provider fxrate.Provider) error {
+	currencies := make([]string, 0, len(cfg.Fx.Currencies))
+	for _, currency := range cfg.Fx.Currencies {
+		if currency = strings.ToUpper(strings.TrimSpace(currency)); currency != "" {
+			currencies = append(currencies, currency)
+		}
+	}
+	if len(currencies) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Fx.Timeout)
+	defer cancel()
+
+	rates, err := provider.Rates(ctx, currencies...)
+	if err != nil {
+		return fmt.Errorf("fx provider %s failed to quote %s: %w", provider.Name(), strings.Join(currencies, ","), err)
+	}
+
+	quoted := make(map[string]bool, len(rates))
+	for _, rate := range rates {
+		quoted[rate.Currency] = true
+	}
+	for _, currency := range currencies {
+		if !quoted[currency] {
+			return fmt.Errorf("fx provider %s does not quote currency: %s", provider.Name(), currency)
+		}
+	}
+
+	return nil
+}
+
+// checkRetention rejects a retention which would remove prices other than configured.
+func checkRetention(cfg *config.Config) error {
+	switch cfg.Retention.Mode {
+	case currency.RetentionModeDrop, currency.RetentionModeDetach:
+	default:
+		return fmt.Errorf("unknown price retention mode: %s", cfg.Retention.Mode)
+	}
+
+	if cfg.Retention.Months < 0 {
+		return fmt.Errorf("negative price retention: %d months", cfg.Retention.Months)
+	}
+	if cfg.Retention.Interval <= 0 {
+		return fmt.Errorf("non-positive price maintenance interval: %s", cfg.Retention.Interval)
+	}
+
+	return nil
+}
+
+// checkIngest rejects a mode or a stream which would silently fall back to polling or push no prices.
+func checkIngest(cfg *config.Config) error {
+	switch cfg.Ingest.Mode {
+	case currency.IngestModePoll, currency.IngestModeStream:
+	default:
+		return fmt.Errorf("unknown ingest mode: %s", cfg.Ingest.Mode)
+	}
+
+	switch cfg.Ingest.Stream {
+	case binance.StreamMiniTicker, binance.StreamKline1m:
+	default:
+		return fmt.Errorf("unknown ingest stream: %s", cfg.Ingest.Stream)
+	}
+
+	if cfg.Ingest.SampleInterval <= 0 {
+		return fmt.Errorf("non-positive ingest sample interval: %s", cfg.Ingest.SampleInterval)
+	}
+
+	return nil
+}
+
+// checkPriceConflict rejects a policy the repositories don't know, they would silently keep the first price.
+func checkPriceConflict(cfg *config.Config) error {
+	switch cfg.PriceConflict {
+	case model.PriceConflictKeepFirst, model.PriceConflictKeepLast:
+		return nil
+	}
+
+	return fmt.Errorf("unknown price conflict policy: %s", cfg.PriceConflict)
+}
+
+// checkConsensus rejects a consensus which could never be reached by the configured providers.
+func checkConsensus(cfg *config.Config, providers int) error {
+	switch cfg.Consensus.Method {
+	case "":
+		return nil
+	case currency.ConsensusMedian, currency.ConsensusVWAP:
+	default:
+		return fmt.Errorf("unknown consensus method: %s", cfg.Consensus.Method)
+	}
+
+	if cfg.Consensus.Band < 0 {
+		return fmt.Errorf("negative consensus band: %v", cfg.Consensus.Band)
+	}
+	if cfg.Ingest.Mode == currency.IngestModeStream { // ticks of the stream are stored as binance quotes them
+		return errors.New("consensus prices need INGEST_MODE=poll")
+	}
+	if cfg.Consensus.MinSources > providers {
+		return fmt.Errorf("consensus needs %d sources of %d market providers", cfg.Consensus.MinSources, providers)
+	}
+
+	return nil
+}
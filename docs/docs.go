@@ -15,6 +15,114 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/binance/weight": {
+            "get": {
+                "description": "Request weight spent on Binance within the current minute and the limit of the client.\nCalls over the limit wait for the next minute or are rejected with 429.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "monitoring"
+                ],
+                "summary": "Binance request weight",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.BinanceWeightDTO"
+                        }
+                    }
+                }
+            }
+        },
+        "/convert": {
+            "get": {
+                "description": "Converts the amount by the latest price of a direct pair, or of two pairs through USDT or BTC when the assets make no pair.\nPrices are asked from the primary provider, a pair it fails to price takes its last stored price marked ` + "`" + `stored` + "`" + `, so tracked pairs convert while the provider is unreachable.\n` + "`" + `quote_time` + "`" + ` is the time of the oldest price of the path and ` + "`" + `age_ms` + "`" + ` is its age.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Convert an amount",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "ETH",
+                        "description": "Asset to convert from",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "BTC",
+                        "description": "Asset to convert to",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "1.5",
+                        "description": "Amount of the from asset",
+                        "name": "amount",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/model.ConvertDTORes"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "404": {
+                        "description": "No pair or path through USDT or BTC connects the assets",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "429": {
+                        "description": "Provider rate limit or ban, code rate_limited or ip_banned, Retry-After is set when known",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "502": {
+                        "description": "Provider failed, code upstream_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "503": {
+                        "description": "Provider keeps failing and is not called, code upstream_circuit_open",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "504": {
+                        "description": "Provider timed out, code upstream_timeout",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    }
+                }
+            }
+        },
         "/currencies": {
             "get": {
                 "description": "Retrieves a list of tracked currencies.",
@@ -45,6 +153,47 @@ const docTemplate = `{
                         }
                     }
                 }
+            },
+            "post": {
+                "description": "Creates tracked pairs of the list. Each symbol is validated and stored on its own,\nthe response tells per symbol whether it was created, already tracked, invalid or failed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "currency"
+                ],
+                "summary": "Create many",
+                "parameters": [
+                    {
+                        "description": "Symbols to create, up to 100",
+                        "name": "currencies",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.CreateCurrenciesReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Outcome per symbol",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.CreateCurrencyResDTO"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    }
+                }
             }
         },
         "/currency": {
@@ -81,50 +230,156 @@ const docTemplate = `{
                             "$ref": "#/definitions/http.ErrMsg"
                         }
                     },
+                    "409": {
+                        "description": "Currency is already tracked",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "422": {
+                        "description": "Symbol is not listed or not trading on the exchange",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "429": {
+                        "description": "Provider rate limit or ban, code rate_limited or ip_banned, Retry-After is set when known",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
                             "$ref": "#/definitions/http.ErrMsg"
                         }
+                    },
+                    "502": {
+                        "description": "Provider failed, code upstream_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "503": {
+                        "description": "Provider keeps failing and is not called, code upstream_circuit_open",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "504": {
+                        "description": "Provider timed out, code upstream_timeout",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
                     }
                 }
             }
         },
-        "/ping": {
-            "get": {
-                "description": "Returns a 200 OK status to indicate the service is up and running",
+        "/currency/{symbol}": {
+            "delete": {
+                "description": "Stops tracking a pair. A pair with stored prices is deleted only with purge=true:\nit is deactivated at once, prices are removed in background and the pair is deleted after them.",
+                "produces": [
+                    "application/json"
+                ],
                 "tags": [
-                    "ping"
+                    "currency"
+                ],
+                "summary": "Delete",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Remove stored prices too",
+                        "name": "purge",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Ping endpoint",
                 "responses": {
-                    "200": {
-                        "description": "OK"
+                    "202": {
+                        "description": "Purge is started"
+                    },
+                    "204": {
+                        "description": "Currency is deleted"
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "404": {
+                        "description": "Currency is not tracked",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "409": {
+                        "description": "Currency has stored prices, deactivate it or pass purge=true",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
                     }
                 }
-            }
-        },
-        "/prices": {
-            "get": {
-                "description": "Retrieves a list of current currency prices.",
+            },
+            "patch": {
+                "description": "Deactivates or reactivates a tracked pair. Inactive pairs are not polled for prices but keep their history.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "prices"
+                    "currency"
+                ],
+                "summary": "Update",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol",
+                        "name": "symbol",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update",
+                        "name": "currency",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/http.UpdateCurrencyReq"
+                        }
+                    }
                 ],
-                "summary": "List currency prices",
                 "responses": {
                     "200": {
-                        "description": "A list of current currency prices",
+                        "description": "Updated currency",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "type": "array",
-                                "items": {
-                                    "$ref": "#/definitions/model.CurrencyPrice"
-                                }
-                            }
+                            "$ref": "#/definitions/model.Currency"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "404": {
+                        "description": "Currency is not tracked",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
                         }
                     },
                     "500": {
@@ -136,38 +391,37 @@ const docTemplate = `{
                 }
             }
         },
-        "/prices/current": {
+        "/currency/{symbol}/backfill": {
             "get": {
-                "description": "Retrieves current prices fof symbols and save it in db.",
+                "description": "Retrieves progress of loading historical candles of a tracked pair, one item per interval.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "prices"
+                    "currency"
                 ],
-                "summary": "Get purrent prices of symbols",
+                "summary": "List backfills of currency",
                 "parameters": [
                     {
                         "type": "string",
-                        "example": "[\"BTCUSDT\", \"ETHUSDT\"]",
-                        "description": "symbols",
-                        "name": "symbols",
-                        "in": "query",
+                        "description": "Currency symbol",
+                        "name": "symbol",
+                        "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Backfill progress per interval",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/model.GetCurrencyPriceDTO"
+                                "$ref": "#/definitions/model.KlineBackfill"
                             }
                         }
                     },
-                    "400": {
-                        "description": "Invalid request parameters",
+                    "404": {
+                        "description": "Currency is not tracked",
                         "schema": {
                             "$ref": "#/definitions/http.ErrMsg"
                         }
@@ -181,23 +435,205 @@ const docTemplate = `{
                 }
             }
         },
-        "/prices/historical": {
+        "/ping": {
+            "get": {
+                "description": "Returns a 200 OK status to indicate the service is up and running",
+                "tags": [
+                    "ping"
+                ],
+                "summary": "Ping endpoint",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/prices": {
             "get": {
-                "description": "Retrieves historical prices for a currency based on the specified parameters. Requires ` + "`" + `symbol` + "`" + `, ` + "`" + `interval` + "`" + `, ` + "`" + `startTime` + "`" + `, ` + "`" + `endTime` + "`" + `, ` + "`" + `page` + "`" + `, and ` + "`" + `limit` + "`" + ` query parameters.",
+                "description": "Retrieves stored prices ordered by time. Pass ` + "`" + `next_cursor` + "`" + ` of the response as ` + "`" + `cursor` + "`" + ` to get the next page, the last page has no ` + "`" + `next_cursor` + "`" + `.\nWith ` + "`" + `convert` + "`" + `, like ` + "`" + `EUR` + "`" + `, prices of pairs quoted in USD or its stablecoins are converted by stored fx rates closest to their times.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "prices"
                 ],
-                "summary": "List historical currency prices",
+                "summary": "List currency prices",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Currency symbol",
-                        "name": "symbol",
-                        "in": "query",
-                        "required": true
+                        "example": "[\"BTCUSDT\", \"ETHUSDT\"]",
+                        "description": "symbols, all tracked by default",
+                        "name": "symbols",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Start time in Unix timestamp milliseconds, inclusive",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "End time in Unix timestamp milliseconds, inclusive",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "default": "asc",
+                        "description": "Sort order by time",
+                        "name": "order",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 1000,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 100,
+                        "description": "Page size",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the next page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "EUR",
+                        "description": "Fiat currency to convert prices to",
+                        "name": "convert",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "A page of stored prices",
+                        "schema": {
+                            "$ref": "#/definitions/model.ListCurrencyPricesDTORes"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    }
+                }
+            }
+        },
+        "/prices/current": {
+            "get": {
+                "description": "Retrieves current prices fof symbols and save it in db.\nWith CONSENSUS_METHOD set the price is agreed on by all market providers, ` + "`" + `Sources` + "`" + ` lists the used ones and ` + "`" + `Rejected` + "`" + ` the failed ones and outliers.\nPairs the exchange does not list, like ` + "`" + `SOLBTC` + "`" + `, are made of tracked pairs, ` + "`" + `Legs` + "`" + ` lists them with their prices and times.\nWith ` + "`" + `convert` + "`" + `, like ` + "`" + `EUR` + "`" + `, prices of pairs quoted in USD or its stablecoins are converted by stored fx rates closest to their times.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "Get purrent prices of symbols",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "example": "[\"BTCUSDT\", \"ETHUSDT\"]",
+                        "description": "symbols",
+                        "name": "symbols",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "EUR",
+                        "description": "Fiat currency to convert prices to",
+                        "name": "convert",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.GetCurrencyPriceDTO"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "404": {
+                        "description": "Symbol is not listed on the provider, code invalid_symbol",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "429": {
+                        "description": "Provider rate limit or ban, code rate_limited or ip_banned, Retry-After is set when known",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "502": {
+                        "description": "Provider failed, code upstream_unavailable, or too few providers agree, code no_consensus",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "503": {
+                        "description": "Provider keeps failing and is not called, code upstream_circuit_open",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "504": {
+                        "description": "Provider timed out, code upstream_timeout",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    }
+                }
+            }
+        },
+        "/prices/historical": {
+            "get": {
+                "description": "Retrieves historical prices for a currency based on the specified parameters. Requires ` + "`" + `symbol` + "`" + `, ` + "`" + `interval` + "`" + `, ` + "`" + `startTime` + "`" + `, ` + "`" + `endTime` + "`" + `, ` + "`" + `page` + "`" + `, and ` + "`" + `limit` + "`" + ` query parameters.\nCandles of pairs the exchange does not list are made of candles of tracked pairs listed in ` + "`" + `legs` + "`" + `, their high and low are bounds.\nWith ` + "`" + `convert` + "`" + `, like ` + "`" + `EUR` + "`" + `, prices of pairs quoted in USD or its stablecoins are converted by stored fx rates closest to their times.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "List historical currency prices",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol",
+                        "name": "symbol",
+                        "in": "query",
+                        "required": true
                     },
                     {
                         "enum": [
@@ -253,6 +689,13 @@ const docTemplate = `{
                         "name": "limit",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "EUR",
+                        "description": "Fiat currency to convert prices to",
+                        "name": "convert",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -271,6 +714,115 @@ const docTemplate = `{
                             "$ref": "#/definitions/http.ErrMsg"
                         }
                     },
+                    "404": {
+                        "description": "Symbol is not listed on the provider, code invalid_symbol",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "429": {
+                        "description": "Provider rate limit or ban, code rate_limited or ip_banned, Retry-After is set when known",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "502": {
+                        "description": "Provider failed, code upstream_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "503": {
+                        "description": "Provider keeps failing and is not called, code upstream_circuit_open",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "504": {
+                        "description": "Provider timed out, code upstream_timeout",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    }
+                }
+            }
+        },
+        "/prices/rollups": {
+            "get": {
+                "description": "Retrieves hourly or daily summaries of stored prices of a tracked symbol: open, close, high, low and average price and number of prices. Buckets which contain ` + "`" + `startTime` + "`" + ` and ` + "`" + `endTime` + "`" + ` are included.\nWith ` + "`" + `convert` + "`" + `, like ` + "`" + `EUR` + "`" + `, prices of pairs quoted in USD or its stablecoins are converted by stored fx rates closest to their times.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "prices"
+                ],
+                "summary": "List price rollups",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Currency symbol",
+                        "name": "symbol",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "1h",
+                            "1d"
+                        ],
+                        "type": "string",
+                        "description": "Interval",
+                        "name": "interval",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Start time in Unix timestamp milliseconds",
+                        "name": "startTime",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "End time in Unix timestamp milliseconds",
+                        "name": "endTime",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "EUR",
+                        "description": "Fiat currency to convert prices to",
+                        "name": "convert",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Summaries of stored prices",
+                        "schema": {
+                            "$ref": "#/definitions/model.GetCurrencyRollupsDTORes"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request parameters",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "404": {
+                        "description": "Currency is not tracked",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -282,7 +834,7 @@ const docTemplate = `{
         },
         "/stat/24h": {
             "get": {
-                "description": "Retrieves 24-hour statistics for the specified symbols.",
+                "description": "Retrieves 24-hour statistics for the specified symbols.\nWith ` + "`" + `source=local` + "`" + ` (default) the summary is aggregated from stored prices of tracked symbols, with a name of a configured provider it is taken from that venue.\nSymbols of any venue, like ` + "`" + `XBT/USDT` + "`" + ` or ` + "`" + `BTC-USDT` + "`" + `, are normalized to ` + "`" + `BTCUSDT` + "`" + `.\nSummaries of pairs the exchange does not list are made of tracked pairs listed in ` + "`" + `legs` + "`" + `.\nWith ` + "`" + `convert` + "`" + `, like ` + "`" + `EUR` + "`" + `, prices of pairs quoted in USD or its stablecoins are converted by stored fx rates closest to their times.",
                 "produces": [
                     "application/json"
                 ],
@@ -298,6 +850,27 @@ const docTemplate = `{
                         "name": "symbols",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "enum": [
+                            "local",
+                            "binance",
+                            "kraken",
+                            "coinbase",
+                            "bybit"
+                        ],
+                        "type": "string",
+                        "default": "local",
+                        "description": "Source of the summary",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "EUR",
+                        "description": "Fiat currency to convert prices to",
+                        "name": "convert",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -311,7 +884,19 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "Invalid request parameters",
+                        "description": "Invalid request parameters or the source is not configured",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "404": {
+                        "description": "Symbol is not listed on the provider, code invalid_symbol",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "429": {
+                        "description": "Provider rate limit or ban, code rate_limited or ip_banned, Retry-After is set when known",
                         "schema": {
                             "$ref": "#/definitions/http.ErrMsg"
                         }
@@ -321,16 +906,129 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/http.ErrMsg"
                         }
+                    },
+                    "502": {
+                        "description": "Provider failed, code upstream_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "503": {
+                        "description": "Provider keeps failing and is not called, code upstream_circuit_open",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "504": {
+                        "description": "Provider timed out, code upstream_timeout",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "http.CreateCurrencyReq": {
-            "type": "object",
-            "required": [
-                "symbol"
+        },
+        "/symbols": {
+            "get": {
+                "description": "Retrieves pairs listed on the primary provider from the cached exchangeInfo, marking the tracked ones.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "symbol"
+                ],
+                "summary": "List symbols",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Base asset, e.g. BTC",
+                        "name": "base_asset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Quote asset, e.g. USDT",
+                        "name": "quote_asset",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Exchange status, e.g. TRADING or BREAK",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Part of the symbol",
+                        "name": "q",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Listed pairs ordered by symbol",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/model.SymbolDTO"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Provider rate limit or ban, code rate_limited or ip_banned, Retry-After is set when known",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "502": {
+                        "description": "Provider failed, code upstream_unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "503": {
+                        "description": "Provider keeps failing and is not called, code upstream_circuit_open",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    },
+                    "504": {
+                        "description": "Provider timed out, code upstream_timeout",
+                        "schema": {
+                            "$ref": "#/definitions/http.ErrMsg"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "http.CreateCurrenciesReq": {
+            "type": "object",
+            "required": [
+                "symbols"
+            ],
+            "properties": {
+                "symbols": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "http.CreateCurrencyReq": {
+            "type": "object",
+            "required": [
+                "symbol"
             ],
             "properties": {
                 "symbol": {
@@ -341,33 +1039,155 @@ const docTemplate = `{
         "http.ErrMsg": {
             "type": "object",
             "properties": {
+                "code": {
+                    "description": "Code is a stable name of the error, set for failures of the exchange.",
+                    "type": "string"
+                },
                 "error": {
                     "type": "string"
                 }
             }
         },
-        "model.Currency": {
+        "http.UpdateCurrencyReq": {
             "type": "object",
+            "required": [
+                "active"
+            ],
             "properties": {
-                "id": {
+                "active": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "model.BinanceWeightDTO": {
+            "type": "object",
+            "properties": {
+                "blocked_until": {
+                    "description": "BlockedUntil is set while the exchange asked to retry later.",
+                    "type": "integer"
+                },
+                "limit": {
                     "type": "integer"
                 },
+                "reset_time": {
+                    "type": "integer"
+                },
+                "used": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ConvertDTORes": {
+            "type": "object",
+            "properties": {
+                "age_ms": {
+                    "type": "integer"
+                },
+                "amount": {
+                    "type": "string"
+                },
+                "from": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ConvertLegDTO"
+                    }
+                },
+                "quote_time": {
+                    "description": "QuoteTime is the time of the oldest price of the path, AgeMs is how many milliseconds ago it was.",
+                    "type": "integer"
+                },
+                "rate": {
+                    "type": "string"
+                },
+                "result": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.ConvertLegDTO": {
+            "type": "object",
+            "properties": {
+                "inverted": {
+                    "type": "boolean"
+                },
+                "price": {
+                    "type": "string"
+                },
+                "stored": {
+                    "description": "Stored is set when the provider failed and the last stored price is used.",
+                    "type": "boolean"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.CreateCurrencyResDTO": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
                 "symbol": {
                     "type": "string"
                 }
             }
         },
-        "model.CurrencyPrice": {
+        "model.Currency": {
             "type": "object",
             "properties": {
-                "currencyID": {
+                "active": {
+                    "type": "boolean"
+                },
+                "base_asset": {
+                    "type": "string"
+                },
+                "id": {
                     "type": "integer"
                 },
+                "quote_asset": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "step_size": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "tick_size": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.CurrencyPriceDTO": {
+            "type": "object",
+            "properties": {
                 "id": {
                     "type": "integer"
                 },
                 "price": {
-                    "type": "number"
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
                 },
                 "time": {
                     "type": "integer"
@@ -378,19 +1198,51 @@ const docTemplate = `{
             "type": "object",
             "properties": {
                 "close_price": {
-                    "type": "number"
+                    "type": "string"
                 },
                 "close_time": {
                     "type": "integer"
                 },
                 "high_price": {
-                    "type": "number"
+                    "type": "string"
                 },
                 "low_price": {
-                    "type": "number"
+                    "type": "string"
                 },
                 "open_price": {
-                    "type": "number"
+                    "type": "string"
+                },
+                "open_time": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.CurrencyRollup": {
+            "type": "object",
+            "properties": {
+                "avg_price": {
+                    "type": "string"
+                },
+                "bucket_time": {
+                    "type": "integer"
+                },
+                "close_price": {
+                    "type": "string"
+                },
+                "close_time": {
+                    "type": "integer"
+                },
+                "count": {
+                    "type": "integer"
+                },
+                "high_price": {
+                    "type": "string"
+                },
+                "low_price": {
+                    "type": "string"
+                },
+                "open_price": {
+                    "type": "string"
                 },
                 "open_time": {
                     "type": "integer"
@@ -400,8 +1252,33 @@ const docTemplate = `{
         "model.GetCurrencyPriceDTO": {
             "type": "object",
             "properties": {
+                "legs": {
+                    "description": "Legs are tracked pairs a synthetic price is made of, Time is then the time of the oldest leg.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.PriceLegDTO"
+                    }
+                },
                 "price": {
-                    "type": "number"
+                    "type": "string"
+                },
+                "rejected": {
+                    "description": "Rejected are providers left out of a consensus price because they failed or quoted outside of the band.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.RejectedQuoteDTO"
+                    }
+                },
+                "sources": {
+                    "description": "Sources are providers whose quotes make up a consensus price.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "stale": {
+                    "description": "Stale is set when the exchange is not called because it keeps failing\nand the price is the last stored one, Time is then the time it was stored with.",
+                    "type": "boolean"
                 },
                 "symbol": {
                     "type": "string"
@@ -414,6 +1291,13 @@ const docTemplate = `{
         "model.GetCurrencyPriceHistoricalDTORes": {
             "type": "object",
             "properties": {
+                "legs": {
+                    "description": "Legs are tracked pairs synthetic candles are made of.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "max_page": {
                     "type": "integer"
                 },
@@ -428,30 +1312,179 @@ const docTemplate = `{
                 }
             }
         },
+        "model.GetCurrencyRollupsDTORes": {
+            "type": "object",
+            "properties": {
+                "interval": {
+                    "type": "string"
+                },
+                "rollups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CurrencyRollup"
+                    }
+                },
+                "symbol": {
+                    "type": "string"
+                }
+            }
+        },
         "model.GetCurrencyStat24HDTO": {
             "type": "object",
             "properties": {
+                "avg_price": {
+                    "type": "string"
+                },
                 "close_time": {
                     "type": "integer"
                 },
+                "count": {
+                    "description": "FirstID   int64 ` + "`" + `json:\"firstId\"` + "`" + `\nLastID    int64 ` + "`" + `json:\"lastId\"` + "`" + `",
+                    "type": "integer"
+                },
                 "high_price": {
-                    "type": "number"
+                    "type": "string"
                 },
                 "last_price": {
-                    "type": "number"
+                    "type": "string"
+                },
+                "legs": {
+                    "description": "Legs are tracked pairs a synthetic summary is made of.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
                 "low_price": {
-                    "type": "number"
+                    "type": "string"
                 },
                 "open_price": {
-                    "type": "number"
+                    "type": "string"
                 },
                 "open_time": {
                     "description": "Volume      float64 ` + "`" + `json:\"volume,string\"` + "`" + `\nQuoteVolume float64 ` + "`" + `json:\"quoteVolume,string\"` + "`" + `",
                     "type": "integer"
                 },
+                "price_change_percent": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.KlineBackfill": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "description": "Attempts counts failures in a row, a failed backfill is retried with a delay growing with them.",
+                    "type": "integer"
+                },
+                "cursor": {
+                    "type": "integer"
+                },
+                "end_time": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "interval": {
+                    "type": "string"
+                },
+                "progress": {
+                    "type": "number"
+                },
+                "start_time": {
+                    "type": "integer"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.ListCurrencyPricesDTORes": {
+            "type": "object",
+            "properties": {
+                "next_cursor": {
+                    "description": "NextCursor is empty on the last page.",
+                    "type": "string"
+                },
+                "prices": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CurrencyPriceDTO"
+                    }
+                }
+            }
+        },
+        "model.PriceLegDTO": {
+            "type": "object",
+            "properties": {
+                "inverted": {
+                    "type": "boolean"
+                },
+                "price": {
+                    "type": "string"
+                },
+                "stale": {
+                    "type": "boolean"
+                },
+                "symbol": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "integer"
+                }
+            }
+        },
+        "model.RejectedQuoteDTO": {
+            "type": "object",
+            "properties": {
+                "price": {
+                    "description": "Price is zero when the provider failed.",
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                }
+            }
+        },
+        "model.SymbolDTO": {
+            "type": "object",
+            "properties": {
+                "base_asset": {
+                    "type": "string"
+                },
+                "quote_asset": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "step_size": {
+                    "type": "string"
+                },
                 "symbol": {
                     "type": "string"
+                },
+                "tick_size": {
+                    "type": "string"
+                },
+                "tracked": {
+                    "type": "boolean"
                 }
             }
         }
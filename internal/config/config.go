@@ -3,6 +3,7 @@ package config
 import (
 	"flag"
 	"os"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
@@ -14,17 +15,112 @@ type Config struct {
 		Port string `env:"APP_PORT"`
 	}
 
+	// DBDriver picks the storage backend: postgres, mongo or memory.
+	DBDriver string `env:"DB_DRIVER" env-default:"postgres"`
+
 	Postgres struct {
 		DSN          string `env:"DB_DSN"`
 		MigrationURL string `env:"MIGRATION_URL"`
+		// AutoMigrate applies migrations on serve start, turn it off to roll out schema by the migrate command.
+		AutoMigrate bool `env:"DB_AUTO_MIGRATE" env-default:"true"`
+	}
+
+	Mongo struct {
+		URI      string `env:"MONGO_URI"`
+		Database string `env:"MONGO_DATABASE" env-default:"gexabyte"`
 	}
 
 	LogLevel string `env:"LOG_LEVEL" env-default:"dev"`
 
+	Backfill struct {
+		Months       int           `env:"BACKFILL_MONTHS" env-default:"3"`
+		Intervals    []string      `env:"BACKFILL_INTERVALS" env-separator:"," env-default:"1h,1d"`
+		RequestDelay time.Duration `env:"BACKFILL_REQUEST_DELAY" env-default:"500ms"`
+	}
+
+	Retention struct {
+		// Months of prices kept besides the current one, zero keeps all of them.
+		Months      int `env:"PRICE_RETENTION_MONTHS" env-default:"0"`
+		AheadMonths int `env:"PRICE_PARTITIONS_AHEAD" env-default:"2"`
+		// Mode is detach to keep expired months as standalone tables or drop to remove them.
+		Mode     string        `env:"PRICE_RETENTION_MODE" env-default:"detach"`
+		Interval time.Duration `env:"PRICE_MAINTENANCE_INTERVAL" env-default:"24h"`
+	}
+
+	Ingest struct {
+		// Mode is poll for REST polling or stream for market streams, polling covers for a stream which is down.
+		Mode string `env:"INGEST_MODE" env-default:"poll"`
+		// Stream is the kind of market stream: miniTicker or kline_1m.
+		Stream         string        `env:"INGEST_STREAM" env-default:"miniTicker"`
+		SampleInterval time.Duration `env:"INGEST_SAMPLE_INTERVAL" env-default:"10s"`
+	}
+
+	Market struct {
+		// Providers are venues of market data: binance, kraken, coinbase or bybit.
+		// The first one is primary, it gives current prices, candles and symbols, others serve /stat/24h by source.
+		Providers []string `env:"MARKET_PROVIDERS" env-separator:"," env-default:"binance"`
+		// Timeout limits one http call of venues other than binance.
+		Timeout time.Duration `env:"MARKET_TIMEOUT" env-default:"10s"`
+		// Workers bounds calls of a provider one request makes at the same time, zero makes them all at once.
+		Workers int `env:"FETCH_WORKERS" env-default:"10"`
+		// TaskTimeout limits each of these calls on its own, so a symbol which hangs does not hold a worker.
+		TaskTimeout time.Duration `env:"FETCH_TASK_TIMEOUT" env-default:"2s"`
+		KrakenURL   string        `env:"KRAKEN_BASE_URL"`
+		CoinbaseURL string        `env:"COINBASE_BASE_URL"`
+		BybitURL    string        `env:"BYBIT_BASE_URL"`
+	}
+
+	Consensus struct {
+		// Method is median or vwap to price pairs by quotes of all market providers, the primary one alone prices them when empty.
+		Method string `env:"CONSENSUS_METHOD"`
+		// Band is the largest relative deviation of a quote from the median, quotes further away are rejected.
+		Band float64 `env:"CONSENSUS_BAND" env-default:"0.02"`
+		// MinSources is how many quotes have to stay within the band, the price fails otherwise.
+		MinSources int `env:"CONSENSUS_MIN_SOURCES" env-default:"2"`
+		// Timeout limits each venue on its own, a venue which hangs is rejected and the others still agree.
+		Timeout time.Duration `env:"CONSENSUS_TIMEOUT" env-default:"2s"`
+	}
+
+	Fx struct {
+		// Provider of rates of fiat currencies to USD: static, file or erapi.
+		Provider string `env:"FX_PROVIDER" env-default:"static"`
+		// Rates of the static provider, units of the currency for one USD, like EUR:0.92,KZT:470.
+		Rates map[string]string `env:"FX_RATES" env-separator:","`
+		// File of the file provider, a json of rates it reads on every refresh.
+		File string `env:"FX_FILE"`
+		// URL of the erapi provider, the open access api when empty.
+		URL     string        `env:"FX_URL"`
+		Timeout time.Duration `env:"FX_TIMEOUT" env-default:"10s"`
+		// Currencies are kept in the rate history and accepted by convert params.
+		Currencies []string `env:"FX_CURRENCIES" env-separator:"," env-default:"EUR,KZT"`
+		// Refresh is how often rates are taken from the provider and stored.
+		Refresh time.Duration `env:"FX_REFRESH" env-default:"1h"`
+	}
+
+	// PriceConflict decides which price stays for the same currency and time: keep_first or keep_last.
+	PriceConflict string `env:"PRICE_CONFLICT_POLICY" env-default:"keep_first"`
+
 	Binance struct {
-		BaseURL   string `env:"BINANCE_BASE_URL"`
+		// BaseURL points the client to the testnet or a local fake, the production api when empty.
+		BaseURL string `env:"BINANCE_BASE_URL"`
+		// StreamURL of market streams, the production one when empty.
+		StreamURL string `env:"BINANCE_STREAM_URL"`
 		ApiKey    string `env:"BINANCE_API_KEY"`
 		SecretKey string `env:"BINANCE_SECRET_KEY"`
+		// Timeout limits one http call of the exchange.
+		Timeout time.Duration `env:"BINANCE_TIMEOUT" env-default:"10s"`
+		// WeightLimit is request weight per minute the client spends at most, calls over it wait or are rejected.
+		WeightLimit int `env:"BINANCE_WEIGHT_LIMIT" env-default:"5000"`
+
+		// Retries of calls failed by the exchange, with exponential backoff between BackoffBase and BackoffMax.
+		Retries     int           `env:"BINANCE_RETRIES" env-default:"2"`
+		BackoffBase time.Duration `env:"BINANCE_BACKOFF_BASE" env-default:"200ms"`
+		BackoffMax  time.Duration `env:"BINANCE_BACKOFF_MAX" env-default:"2s"`
+		// BreakerFailures in a row stop calls of the endpoint for BreakerCooldown, zero turns breakers off.
+		BreakerFailures int           `env:"BINANCE_BREAKER_FAILURES" env-default:"5"`
+		BreakerCooldown time.Duration `env:"BINANCE_BREAKER_COOLDOWN" env-default:"30s"`
+		// ExchangeInfoRefresh is how often symbols of the exchange are reloaded to validate new pairs.
+		ExchangeInfoRefresh time.Duration `env:"EXCHANGE_INFO_REFRESH" env-default:"1h"`
 	}
 }
 
@@ -1,13 +1,161 @@
 package model
 
+import "github.com/shopspring/decimal"
+
+// SymbolStatusTrading is the exchange status of symbols open for trading, only they can be tracked.
+const SymbolStatusTrading = "TRADING"
+
+// Currency is a tracked pair. Inactive pairs are not polled for prices but keep their history.
+// Assets, status and sizes are taken from exchangeInfo of the exchange.
 type Currency struct {
 	ID     int    `json:"id"`
 	Symbol string `json:"symbol"`
+	Active bool   `json:"active"`
+
+	BaseAsset  string          `json:"base_asset"`
+	QuoteAsset string          `json:"quote_asset"`
+	Status     string          `json:"status"`
+	TickSize   decimal.Decimal `json:"tick_size" swaggertype:"string"`
+	StepSize   decimal.Decimal `json:"step_size" swaggertype:"string"`
+}
+
+// SameInfo tells whether exchange metadata of the currencies is equal.
+func (c Currency) SameInfo(other Currency) bool {
+	return c.BaseAsset == other.BaseAsset &&
+		c.QuoteAsset == other.QuoteAsset &&
+		c.Status == other.Status &&
+		c.TickSize.Equal(other.TickSize) &&
+		c.StepSize.Equal(other.StepSize)
 }
 
 type CurrencyPrice struct {
 	ID         int
 	CurrencyID int
-	Price      float64
+	Price      decimal.Decimal
 	Time       int64
+	// Source is the name of the provider the price came from, or consensus when several providers agreed on it.
+	Source string
+}
+
+// PriceSourceDefault is the source of prices stored before sources were recorded, they all came from binance.
+const PriceSourceDefault = "binance"
+
+// PriceSourceConsensus is the source of prices agreed on by several providers.
+const PriceSourceConsensus = "consensus"
+
+// Policies of storing a price whose currency and time are already stored.
+const (
+	PriceConflictKeepFirst = "keep_first"
+	PriceConflictKeepLast  = "keep_last"
+)
+
+// CreateCurrencyPricesRes is the outcome of storing a batch of prices.
+type CreateCurrencyPricesRes struct {
+	// Inserted are prices stored under a new currency and time.
+	Inserted []CurrencyPrice
+	// Replaced are stored prices overwritten with PriceConflictKeepLast, they carry the new price.
+	Replaced []CurrencyPrice
+	// Deduplicated counts prices which repeated a currency and time of the store or of the batch and changed nothing.
+	Deduplicated int
+}
+
+// DedupePrices collapses prices of the same currency and time within the batch.
+// The first price of a pair stays in place, with PriceConflictKeepLast it takes the price of the last one.
+func DedupePrices(onConflict string, prices ...CurrencyPrice) []CurrencyPrice {
+	type key struct {
+		currencyID int
+		time       int64
+	}
+
+	res := make([]CurrencyPrice, 0, len(prices))
+	seen := make(map[key]int, len(prices))
+	for _, p := range prices {
+		k := key{p.CurrencyID, p.Time}
+		if i, ok := seen[k]; ok {
+			if onConflict == PriceConflictKeepLast {
+				res[i].Price, res[i].Source = p.Price, p.Source
+			}
+			continue
+		}
+
+		seen[k] = len(res)
+		res = append(res, p)
+	}
+
+	return res
+}
+
+// CurrencyKline is a stored candle of a tracked currency.
+type CurrencyKline struct {
+	CurrencyID int
+	Interval   string
+
+	OpenPrice  decimal.Decimal
+	ClosePrice decimal.Decimal
+	HighPrice  decimal.Decimal
+	LowPrice   decimal.Decimal
+
+	OpenTime  int64
+	CloseTime int64
+}
+
+const (
+	BackfillStatusPending = "pending"
+	BackfillStatusRunning = "running"
+	BackfillStatusDone    = "done"
+	BackfillStatusFailed  = "failed"
+)
+
+// KlineBackfill is the progress of loading candles of one interval for a tracked currency.
+// Candles from StartTime up to Cursor are already stored.
+type KlineBackfill struct {
+	CurrencyID int    `json:"-"`
+	Symbol     string `json:"symbol"`
+	Interval   string `json:"interval"`
+
+	StartTime int64   `json:"start_time"`
+	EndTime   int64   `json:"end_time"`
+	Cursor    int64   `json:"cursor"`
+	Progress  float64 `json:"progress"`
+
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	// Attempts counts failures in a row, a failed backfill is retried with a delay growing with them.
+	Attempts  int   `json:"attempts"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// Intervals of price rollups.
+const (
+	RollupInterval1h = "1h"
+	RollupInterval1d = "1d"
+)
+
+var RollupIntervals = []string{RollupInterval1h, RollupInterval1d}
+
+// RollupRange returns the start of the bucket which contains startTime and the last millisecond of the bucket
+// which contains endTime. Buckets of the interval are aligned to unix time, so days start at 00:00 UTC.
+func RollupRange(interval string, startTime, endTime int64) (start, end int64) {
+	size := KlineInterval.GetDuration(interval).Milliseconds()
+	return startTime - startTime%size, endTime - endTime%size + size - 1
+}
+
+// CurrencyRollup summarises stored prices of a currency within one bucket of interval.
+// BucketTime is the start of bucket, OpenTime and CloseTime are times of the first and the last price in it.
+// Sum and count are kept instead of average, the average is counted when rollups are read.
+type CurrencyRollup struct {
+	CurrencyID int    `json:"-"`
+	Interval   string `json:"-"`
+	BucketTime int64  `json:"bucket_time"`
+
+	OpenPrice  decimal.Decimal `json:"open_price" swaggertype:"string"`
+	ClosePrice decimal.Decimal `json:"close_price" swaggertype:"string"`
+	HighPrice  decimal.Decimal `json:"high_price" swaggertype:"string"`
+	LowPrice   decimal.Decimal `json:"low_price" swaggertype:"string"`
+	AvgPrice   decimal.Decimal `json:"avg_price" swaggertype:"string"`
+	SumPrice   decimal.Decimal `json:"-"`
+	Count      int             `json:"count"`
+
+	OpenTime  int64 `json:"open_time"`
+	CloseTime int64 `json:"close_time"`
 }
@@ -1,24 +1,69 @@
 package model
 
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
+
 type GetCurrencyPriceDTO struct {
 	Symbol string
-	Price  float64
+	Price  decimal.Decimal `swaggertype:"string"`
 	Time   int64
+	// Stale is set when the exchange is not called because it keeps failing
+	// and the price is the last stored one, Time is then the time it was stored with.
+	Stale bool `json:",omitempty"`
+	// Sources are providers whose quotes make up a consensus price.
+	Sources []string `json:",omitempty"`
+	// Rejected are providers left out of a consensus price because they failed or quoted outside of the band.
+	Rejected []RejectedQuoteDTO `json:",omitempty"`
+	// Legs are tracked pairs a synthetic price is made of, Time is then the time of the oldest leg.
+	Legs []PriceLegDTO `json:",omitempty"`
+}
+
+// PriceLegDTO is a tracked pair of a synthetic price, the price of an Inverted leg divides instead of multiplying.
+type PriceLegDTO struct {
+	Symbol   string
+	Price    decimal.Decimal `swaggertype:"string"`
+	Time     int64
+	Inverted bool `json:",omitempty"`
+	Stale    bool `json:",omitempty"`
 }
 
+type RejectedQuoteDTO struct {
+	Source string
+	// Price is zero when the provider failed.
+	Price  decimal.Decimal `swaggertype:"string"`
+	Reason string
+}
+
+// Sources of the 24h summary.
+// Local is aggregated from currency_price, others are names of providers the summary is proxied from.
+const (
+	StatSourceLocal   = "local"
+	StatSourceBinance = "binance"
+)
+
 type GetCurrencyStat24HDTO struct {
-	Symbol    string  `json:"symbol"`
-	OpenPrice float64 `json:"open_price"`
-	LastPrice float64 `json:"last_price"`
-	HighPrice float64 `json:"high_price"`
-	LowPrice  float64 `json:"low_price"`
+	Symbol             string          `json:"symbol"`
+	Source             string          `json:"source"`
+	OpenPrice          decimal.Decimal `json:"open_price" swaggertype:"string"`
+	LastPrice          decimal.Decimal `json:"last_price" swaggertype:"string"`
+	HighPrice          decimal.Decimal `json:"high_price" swaggertype:"string"`
+	LowPrice           decimal.Decimal `json:"low_price" swaggertype:"string"`
+	AvgPrice           decimal.Decimal `json:"avg_price" swaggertype:"string"`
+	PriceChangePercent decimal.Decimal `json:"price_change_percent" swaggertype:"string"`
 	// Volume      float64 `json:"volume,string"`
 	// QuoteVolume float64 `json:"quoteVolume,string"`
 	OpenTime  int64 `json:"open_time"`
 	CloseTime int64 `json:"close_time"`
 	// FirstID   int64 `json:"firstId"`
 	// LastID    int64 `json:"lastId"`
-	// Count     int   `json:"count"`
+	Count int `json:"count"`
+	// Legs are tracked pairs a synthetic summary is made of.
+	Legs []string `json:"legs,omitempty"`
 }
 
 type GetCurrencyPriceHistoricalDTOReq struct {
@@ -31,10 +76,10 @@ type GetCurrencyPriceHistoricalDTOReq struct {
 }
 
 type CurrencyPriceInterval struct {
-	OpenPrice  float64 `json:"open_price"`
-	ClosePrice float64 `json:"close_price"`
-	HighPrice  float64 `json:"high_price"`
-	LowPrice   float64 `json:"low_price"`
+	OpenPrice  decimal.Decimal `json:"open_price" swaggertype:"string"`
+	ClosePrice decimal.Decimal `json:"close_price" swaggertype:"string"`
+	HighPrice  decimal.Decimal `json:"high_price" swaggertype:"string"`
+	LowPrice   decimal.Decimal `json:"low_price" swaggertype:"string"`
 
 	OpenTime  int64 `json:"open_time"`
 	CloseTime int64 `json:"close_time"`
@@ -45,4 +90,165 @@ type GetCurrencyPriceHistoricalDTORes struct {
 	MaxPage int `json:"max_page"`
 
 	Prices []CurrencyPriceInterval `json:"prices"`
+	// Legs are tracked pairs synthetic candles are made of.
+	Legs []string `json:"legs,omitempty"`
+}
+
+type GetCurrencyRollupsDTOReq struct {
+	Symbol    string
+	Interval  string
+	StartTime int64
+	EndTime   int64
+}
+
+type GetCurrencyRollupsDTORes struct {
+	Symbol   string           `json:"symbol"`
+	Interval string           `json:"interval"`
+	Rollups  []CurrencyRollup `json:"rollups"`
+}
+
+// Orders of listed prices, by time and id.
+const (
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// PriceCursor is the position of the last listed price, next page starts right after it.
+type PriceCursor struct {
+	Time int64 `json:"t"`
+	ID   int   `json:"i"`
+}
+
+// Encode returns the cursor as an opaque url safe string.
+func (c PriceCursor) Encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodePriceCursor parses a cursor made by Encode.
+func DecodePriceCursor(s string) (PriceCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return PriceCursor{}, ErrInvalidCursor
+	}
+
+	var c PriceCursor
+	if err := json.Unmarshal(b, &c); err != nil {
+		return PriceCursor{}, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// ListCurrencyPricesFilter selects stored prices.
+// Empty symbols match all currencies, zero times leave the range open.
+type ListCurrencyPricesFilter struct {
+	Symbols   []string
+	StartTime int64
+	EndTime   int64
+	Order     string
+	Limit     int
+	After     *PriceCursor
+}
+
+type CurrencyPriceDTO struct {
+	ID     int             `json:"id"`
+	Symbol string          `json:"symbol"`
+	Price  decimal.Decimal `json:"price" swaggertype:"string"`
+	Time   int64           `json:"time"`
+	Source string          `json:"source"`
+}
+
+// Cursor returns position of the price for the next page.
+func (p CurrencyPriceDTO) Cursor() PriceCursor {
+	return PriceCursor{Time: p.Time, ID: p.ID}
+}
+
+type ListCurrencyPricesDTORes struct {
+	Prices []CurrencyPriceDTO `json:"prices"`
+	// NextCursor is empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// PriceChangePercent returns change from open to last price in percents, zero open price gives zero change.
+func PriceChangePercent(openPrice, lastPrice decimal.Decimal) decimal.Decimal {
+	if openPrice.IsZero() {
+		return decimal.Zero
+	}
+
+	return lastPrice.Sub(openPrice).Div(openPrice).Mul(decimal.NewFromInt(100))
+}
+
+// ListSymbolsFilter selects symbols of the exchange, empty fields match all.
+// Search matches a part of the symbol.
+type ListSymbolsFilter struct {
+	BaseAsset  string
+	QuoteAsset string
+	Status     string
+	Search     string
+}
+
+// SymbolDTO is a pair listed on the exchange, Tracked tells whether it is added by /currency.
+type SymbolDTO struct {
+	Symbol     string          `json:"symbol"`
+	BaseAsset  string          `json:"base_asset"`
+	QuoteAsset string          `json:"quote_asset"`
+	Status     string          `json:"status"`
+	TickSize   decimal.Decimal `json:"tick_size" swaggertype:"string"`
+	StepSize   decimal.Decimal `json:"step_size" swaggertype:"string"`
+	Tracked    bool            `json:"tracked"`
+}
+
+// Outcomes of adding one symbol of a bulk request.
+const (
+	CreateCurrencyStatusCreated = "created"
+	CreateCurrencyStatusExists  = "exists"
+	CreateCurrencyStatusInvalid = "invalid"
+	CreateCurrencyStatusFailed  = "failed"
+)
+
+type CreateCurrencyResDTO struct {
+	Symbol string `json:"symbol"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BinanceWeightDTO is request weight of the exchange spent within the current minute, times are unix milliseconds.
+type BinanceWeightDTO struct {
+	Used      int   `json:"used"`
+	Limit     int   `json:"limit"`
+	ResetTime int64 `json:"reset_time"`
+	// BlockedUntil is set while the exchange asked to retry later.
+	BlockedUntil int64 `json:"blocked_until,omitempty"`
+}
+
+type ConvertDTOReq struct {
+	From   string
+	To     string
+	Amount decimal.Decimal
+}
+
+// ConvertDTORes is the amount converted by the rate of the path, the rate of two legs is the product of their prices.
+type ConvertDTORes struct {
+	From   string          `json:"from"`
+	To     string          `json:"to"`
+	Amount decimal.Decimal `json:"amount" swaggertype:"string"`
+	Result decimal.Decimal `json:"result" swaggertype:"string"`
+	Rate   decimal.Decimal `json:"rate" swaggertype:"string"`
+	Path   []ConvertLegDTO `json:"path"`
+	// QuoteTime is the time of the oldest price of the path, AgeMs is how many milliseconds ago it was.
+	QuoteTime int64 `json:"quote_time"`
+	AgeMs     int64 `json:"age_ms"`
+}
+
+// ConvertLegDTO is a pair of the conversion path, the price of an inverted leg divides instead of multiplying.
+type ConvertLegDTO struct {
+	Symbol   string          `json:"symbol"`
+	Price    decimal.Decimal `json:"price" swaggertype:"string"`
+	Time     int64           `json:"time"`
+	Inverted bool            `json:"inverted"`
+	// Stored is set when the provider failed and the last stored price is used.
+	Stored bool `json:"stored"`
 }
@@ -0,0 +1,14 @@
+package model
+
+import "errors"
+
+var (
+	ErrNotFound      = errors.New("not found")
+	ErrAlreadyExists = errors.New("already exists")
+	ErrHasPrices     = errors.New("currency has stored prices")
+	ErrInvalidSymbol = errors.New("invalid symbol")
+	ErrUnknownSource = errors.New("unknown source")
+	ErrNoConsensus   = errors.New("no consensus price")
+	ErrNoPath        = errors.New("no conversion path")
+	ErrNoFxRate      = errors.New("no fx rate")
+)
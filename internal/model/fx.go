@@ -0,0 +1,100 @@
+package model
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+// FxBase is the currency fx rates are quoted against.
+const FxBase = "USD"
+
+// FxBaseAssets are quote assets whose prices convert as USD, stablecoins are taken at par.
+var FxBaseAssets = []string{"USD", "USDT", "USDC", "FDUSD"}
+
+// fxScale is the number of decimal places of converted prices, the same as stored ones.
+const fxScale = 10
+
+// IsFxBaseAsset tells whether prices quoted in the asset can be converted by fx rates.
+func IsFxBaseAsset(asset string) bool {
+	return slices.Contains(FxBaseAssets, asset)
+}
+
+// FxRate is how many units of Currency one USD buys at Time, unix milliseconds.
+// Source is the fx provider the rate came from.
+type FxRate struct {
+	ID       int
+	Currency string
+	Rate     decimal.Decimal
+	Time     int64
+	Source   string
+}
+
+// FxRates are rates of one currency ordered by time.
+type FxRates []FxRate
+
+// At returns the rate closest to the time, the earlier one of two equally close, zero without rates.
+func (r FxRates) At(time int64) decimal.Decimal {
+	if len(r) == 0 {
+		return decimal.Zero
+	}
+
+	i := sort.Search(len(r), func(i int) bool { return r[i].Time >= time })
+	switch {
+	case i == 0:
+		return r[0].Rate
+	case i == len(r):
+		return r[i-1].Rate
+	case r[i].Time-time < time-r[i-1].Time:
+		return r[i].Rate
+	}
+
+	return r[i-1].Rate
+}
+
+func (r FxRates) convert(price decimal.Decimal, time int64) decimal.Decimal {
+	return price.Mul(r.At(time)).Round(fxScale)
+}
+
+// ConvertFx converts the price by the rate closest to its time.
+func (p *CurrencyPriceDTO) ConvertFx(rates FxRates) {
+	p.Price = rates.convert(p.Price, p.Time)
+}
+
+// ConvertFx converts the price and prices of rejected quotes by the rate closest to its time.
+// Legs stay in their own quote assets.
+func (p *GetCurrencyPriceDTO) ConvertFx(rates FxRates) {
+	p.Price = rates.convert(p.Price, p.Time)
+	for i := range p.Rejected {
+		p.Rejected[i].Price = rates.convert(p.Rejected[i].Price, p.Time)
+	}
+}
+
+// ConvertFx converts the open price by the rate closest to the open time and other prices by the one closest to the close time.
+func (s *GetCurrencyStat24HDTO) ConvertFx(rates FxRates) {
+	s.OpenPrice = rates.convert(s.OpenPrice, s.OpenTime)
+	s.LastPrice = rates.convert(s.LastPrice, s.CloseTime)
+	s.HighPrice = rates.convert(s.HighPrice, s.CloseTime)
+	s.LowPrice = rates.convert(s.LowPrice, s.CloseTime)
+	s.AvgPrice = rates.convert(s.AvgPrice, s.CloseTime)
+	s.PriceChangePercent = PriceChangePercent(s.OpenPrice, s.LastPrice)
+}
+
+// ConvertFx converts the open price by the rate closest to the open time and other prices by the one closest to the close time.
+func (p *CurrencyPriceInterval) ConvertFx(rates FxRates) {
+	p.OpenPrice = rates.convert(p.OpenPrice, p.OpenTime)
+	p.ClosePrice = rates.convert(p.ClosePrice, p.CloseTime)
+	p.HighPrice = rates.convert(p.HighPrice, p.CloseTime)
+	p.LowPrice = rates.convert(p.LowPrice, p.CloseTime)
+}
+
+// ConvertFx converts the open price by the rate closest to the open time and other prices by the one closest to the close time.
+func (r *CurrencyRollup) ConvertFx(rates FxRates) {
+	r.OpenPrice = rates.convert(r.OpenPrice, r.OpenTime)
+	r.ClosePrice = rates.convert(r.ClosePrice, r.CloseTime)
+	r.HighPrice = rates.convert(r.HighPrice, r.CloseTime)
+	r.LowPrice = rates.convert(r.LowPrice, r.CloseTime)
+	r.AvgPrice = rates.convert(r.AvgPrice, r.CloseTime)
+	r.SumPrice = rates.convert(r.SumPrice, r.CloseTime)
+}
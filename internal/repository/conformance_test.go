@@ -0,0 +1,644 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gexabyte/internal/config"
+	"gexabyte/internal/model"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConformance runs the same checks against every backend.
+// Memory always runs, postgres and mongo run when TEST_DB_DSN and TEST_MONGO_URI are set.
+// Checks create their own currencies, so they can run on a database with data.
+func TestConformance(t *testing.T) {
+	backends := map[string]func(t *testing.T) *Manager{
+		DriverMemory: func(t *testing.T) *Manager {
+			return newMemory()
+		},
+	}
+
+	if dsn := os.Getenv("TEST_DB_DSN"); dsn != "" {
+		backends[DriverPostgres] = func(t *testing.T) *Manager {
+			cfg := &config.Config{DBDriver: DriverPostgres}
+			cfg.Postgres.DSN = dsn
+			cfg.Postgres.MigrationURL = "file://postgres/migrations"
+
+			manager, err := NewRepository(cfg)
+			require.NoError(t, err)
+			return manager
+		}
+	}
+
+	if uri := os.Getenv("TEST_MONGO_URI"); uri != "" {
+		backends[DriverMongo] = func(t *testing.T) *Manager {
+			cfg := &config.Config{DBDriver: DriverMongo}
+			cfg.Mongo.URI = uri
+			cfg.Mongo.Database = "gexabyte_test"
+
+			manager, err := NewRepository(cfg)
+			require.NoError(t, err)
+			return manager
+		}
+	}
+
+	for name, newManager := range backends {
+		t.Run(name, func(t *testing.T) {
+			manager := newManager(t)
+
+			t.Run("currency", func(t *testing.T) { testCurrency(t, manager) })
+			t.Run("currency lifecycle", func(t *testing.T) { testCurrencyLifecycle(t, manager) })
+			t.Run("currency price", func(t *testing.T) { testCurrencyPrice(t, manager) })
+			t.Run("currency price conflict", func(t *testing.T) { testCurrencyPriceConflict(t, manager) })
+			t.Run("currency price partition", func(t *testing.T) { testCurrencyPricePartition(t, manager) })
+			t.Run("currency rollup", func(t *testing.T) { testCurrencyRollup(t, manager) })
+			t.Run("currency kline", func(t *testing.T) { testCurrencyKline(t, manager) })
+			t.Run("kline backfill", func(t *testing.T) { testKlineBackfill(t, manager) })
+			t.Run("fx rate", func(t *testing.T) { testFxRate(t, manager) })
+		})
+	}
+}
+
+// canonical rebuilds the decimal from its string, so decimals equal by value become equal by struct.
+// Backends return numbers with different scale, like 10 and 10.0000000000.
+func canonical(d decimal.Decimal) decimal.Decimal {
+	return decimal.RequireFromString(d.String())
+}
+
+// uniqueSymbol returns a symbol which is not stored yet.
+func uniqueSymbol(prefix string) string {
+	return fmt.Sprintf("%s%dUSDT", prefix, time.Now().UnixNano())
+}
+
+func testCurrency(t *testing.T, manager *Manager) {
+	ctx := context.Background()
+	symbol := uniqueSymbol("CUR")
+	info := model.Currency{
+		Symbol:     symbol,
+		BaseAsset:  "CUR",
+		QuoteAsset: "USDT",
+		Status:     model.SymbolStatusTrading,
+		TickSize:   decimal.RequireFromString("0.01"),
+		StepSize:   decimal.RequireFromString("0.00001"),
+	}
+
+	created, err := manager.Currency.Create(ctx, info)
+	require.NoError(t, err)
+	assert.NotZero(t, created.ID)
+	assert.Equal(t, symbol, created.Symbol)
+	assert.True(t, created.Active, "new currencies are active")
+	assert.True(t, created.SameInfo(info), "exchange metadata must be stored")
+
+	_, err = manager.Currency.Create(ctx, info)
+	assert.ErrorIs(t, err, model.ErrAlreadyExists)
+
+	item, err := manager.Currency.GetBySymbol(ctx, symbol)
+	assert.NoError(t, err)
+	assert.Equal(t, created, item)
+
+	_, err = manager.Currency.GetBySymbol(ctx, uniqueSymbol("UNKNOWN"))
+	assert.ErrorIs(t, err, model.ErrNotFound)
+
+	items, err := manager.Currency.List(ctx)
+	assert.NoError(t, err)
+	assert.Contains(t, items, created)
+	assert.True(t, slices.IsSortedFunc(items, func(a, b model.Currency) int {
+		return a.ID - b.ID
+	}), "currencies must be ordered by id")
+
+	info.Status = "BREAK"
+	info.TickSize = decimal.RequireFromString("0.1")
+	require.NoError(t, manager.Currency.UpdateInfo(ctx, info))
+
+	item, err = manager.Currency.GetBySymbol(ctx, symbol)
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, item.ID)
+	assert.True(t, item.SameInfo(info), "exchange metadata must be updated")
+
+	assert.ErrorIs(t, manager.Currency.UpdateInfo(ctx, model.Currency{Symbol: uniqueSymbol("UNKNOWN")}), model.ErrNotFound)
+}
+
+func testCurrencyLifecycle(t *testing.T, manager *Manager) {
+	ctx := context.Background()
+
+	currency, err := manager.Currency.Create(ctx, model.Currency{Symbol: uniqueSymbol("LIFE")})
+	require.NoError(t, err)
+
+	inactive, err := manager.Currency.SetActive(ctx, currency.Symbol, false)
+	require.NoError(t, err)
+	assert.Equal(t, currency.ID, inactive.ID)
+	assert.False(t, inactive.Active)
+
+	item, err := manager.Currency.GetBySymbol(ctx, currency.Symbol)
+	require.NoError(t, err)
+	assert.False(t, item.Active)
+
+	item, err = manager.Currency.SetActive(ctx, currency.Symbol, true)
+	require.NoError(t, err)
+	assert.True(t, item.Active)
+
+	_, err = manager.Currency.SetActive(ctx, uniqueSymbol("UNKNOWN"), false)
+	assert.ErrorIs(t, err, model.ErrNotFound)
+
+	_, err = manager.CurrencyPrice.Create(ctx, model.PriceConflictKeepFirst,
+		model.CurrencyPrice{CurrencyID: currency.ID, Price: decimal.RequireFromString("1"), Time: 1000},
+		model.CurrencyPrice{CurrencyID: currency.ID, Price: decimal.RequireFromString("2"), Time: 2000},
+		model.CurrencyPrice{CurrencyID: currency.ID, Price: decimal.RequireFromString("3"), Time: 3000},
+	)
+	require.NoError(t, err)
+	require.NoError(t, manager.CurrencyRollup.Rebuild(ctx, currency.ID, model.RollupInterval1h, 1000, 3000))
+	rollups, err := manager.CurrencyRollup.List(ctx, currency.ID, model.RollupInterval1h, 0, 3600000)
+	require.NoError(t, err)
+	require.Len(t, rollups, 1)
+
+	// history protects the currency until prices are purged
+	assert.ErrorIs(t, manager.Currency.Delete(ctx, currency.ID), model.ErrHasPrices)
+
+	deleted, err := manager.CurrencyPrice.DeleteByCurrency(ctx, currency.ID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+	deleted, err = manager.CurrencyPrice.DeleteByCurrency(ctx, currency.ID, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	deleted, err = manager.CurrencyPrice.DeleteByCurrency(ctx, currency.ID, 2)
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+
+	require.NoError(t, manager.Currency.Delete(ctx, currency.ID))
+
+	_, err = manager.Currency.GetBySymbol(ctx, currency.Symbol)
+	assert.ErrorIs(t, err, model.ErrNotFound)
+	assert.ErrorIs(t, manager.Currency.Delete(ctx, currency.ID), model.ErrNotFound)
+
+	rollups, err = manager.CurrencyRollup.List(ctx, currency.ID, model.RollupInterval1h, 0, 3600000)
+	require.NoError(t, err)
+	assert.Empty(t, rollups, "rollups are deleted with the currency")
+}
+
+func testCurrencyPrice(t *testing.T, manager *Manager) {
+	ctx := context.Background()
+
+	currency, err := manager.Currency.Create(ctx, model.Currency{Symbol: uniqueSymbol("PRICE")})
+	require.NoError(t, err)
+
+	// out of time order
+	_, err = manager.CurrencyPrice.Create(ctx, model.PriceConflictKeepFirst,
+		model.CurrencyPrice{CurrencyID: currency.ID, Price: decimal.RequireFromString("12"), Time: 2000},
+		model.CurrencyPrice{CurrencyID: currency.ID, Price: decimal.RequireFromString("11"), Time: 2500},
+		model.CurrencyPrice{CurrencyID: currency.ID, Price: decimal.RequireFromString("10"), Time: 1000},
+		model.CurrencyPrice{CurrencyID: currency.ID, Price: decimal.RequireFromString("20"), Time: 3000},
+		model.CurrencyPrice{CurrencyID: currency.ID, Price: decimal.RequireFromString("99"), Time: 9000},
+	)
+	require.NoError(t, err)
+
+	filter := model.ListCurrencyPricesFilter{Symbols: []string{currency.Symbol}, Limit: 10}
+	items, err := manager.CurrencyPrice.List(ctx, filter)
+	require.NoError(t, err)
+	require.Len(t, items, 5)
+
+	var prices []string
+	for _, item := range items {
+		assert.NotZero(t, item.ID)
+		assert.Equal(t, currency.Symbol, item.Symbol)
+		prices = append(prices, item.Price.String())
+	}
+	assert.Equal(t, []string{"10", "12", "11", "20", "99"}, prices)
+
+	// pages follow each other without gaps in both orders
+	for order, expected := range map[string][]string{
+		model.OrderAsc:  {"10", "12", "11", "20", "99"},
+		model.OrderDesc: {"99", "20", "11", "12", "10"},
+	} {
+		filter := model.ListCurrencyPricesFilter{Symbols: []string{currency.Symbol}, Order: order, Limit: 2}
+
+		var prices []string
+		for {
+			page, err := manager.CurrencyPrice.List(ctx, filter)
+			require.NoError(t, err)
+			if len(page) == 0 {
+				break
+			}
+			for _, item := range page {
+				prices = append(prices, item.Price.String())
+			}
+
+			after := page[len(page)-1].Cursor()
+			filter.After = &after
+		}
+		assert.Equal(t, expected, prices, order)
+	}
+
+	items, err = manager.CurrencyPrice.List(ctx, model.ListCurrencyPricesFilter{
+		Symbols:   []string{currency.Symbol},
+		StartTime: 2000,
+		EndTime:   3000,
+		Order:     model.OrderDesc,
+		Limit:     10,
+	})
+	require.NoError(t, err)
+	prices = nil
+	for _, item := range items {
+		prices = append(prices, item.Price.String())
+	}
+	assert.Equal(t, []string{"20", "11", "12"}, prices)
+
+	items, err = manager.CurrencyPrice.List(ctx, model.ListCurrencyPricesFilter{Symbols: []string{uniqueSymbol("UNKNOWN")}, Limit: 10})
+	assert.NoError(t, err)
+	assert.Empty(t, items)
+
+	// digits quoted by the exchange come back exactly, float64 would give 0.30000000000000004 for the sum
+	exact, err := manager.Currency.Create(ctx, model.Currency{Symbol: uniqueSymbol("EXACT")})
+	require.NoError(t, err)
+	_, err = manager.CurrencyPrice.Create(ctx, model.PriceConflictKeepFirst,
+		model.CurrencyPrice{CurrencyID: exact.ID, Price: decimal.RequireFromString("0.1"), Time: 1000},
+		model.CurrencyPrice{CurrencyID: exact.ID, Price: decimal.RequireFromString("0.2"), Time: 1500},
+		model.CurrencyPrice{CurrencyID: exact.ID, Price: decimal.RequireFromString("0.0000012345"), Time: 2000},
+	)
+	require.NoError(t, err)
+
+	items, err = manager.CurrencyPrice.List(ctx, model.ListCurrencyPricesFilter{Symbols: []string{exact.Symbol}, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	assert.Equal(t, "0.0000012345", items[2].Price.String())
+
+	exactStat, err := manager.CurrencyPrice.Stat(ctx, 1000, 1500, exact.Symbol)
+	require.NoError(t, err)
+	require.Len(t, exactStat, 1)
+	assert.Equal(t, "0.15", exactStat[0].AvgPrice.String())
+
+	stat, err := manager.CurrencyPrice.Stat(ctx, 1000, 3000, currency.Symbol, uniqueSymbol("UNKNOWN"))
+	require.NoError(t, err)
+	require.Len(t, stat, 1)
+	assert.Equal(t, currency.Symbol, stat[0].Symbol)
+	assert.Equal(t, model.StatSourceLocal, stat[0].Source)
+	assert.Equal(t, "10", stat[0].OpenPrice.String())
+	assert.Equal(t, "20", stat[0].LastPrice.String())
+	assert.Equal(t, "20", stat[0].HighPrice.String())
+	assert.Equal(t, "10", stat[0].LowPrice.String())
+	assert.Equal(t, "13.25", stat[0].AvgPrice.String())
+	assert.Equal(t, "100", stat[0].PriceChangePercent.String())
+	assert.Equal(t, 4, stat[0].Count)
+	assert.Equal(t, int64(1000), stat[0].OpenTime)
+	assert.Equal(t, int64(3000), stat[0].CloseTime)
+
+	stat, err = manager.CurrencyPrice.Stat(ctx, 4000, 5000, currency.Symbol)
+	assert.NoError(t, err)
+	assert.Empty(t, stat)
+}
+
+func testCurrencyPriceConflict(t *testing.T, manager *Manager) {
+	ctx := context.Background()
+
+	currency, err := manager.Currency.Create(ctx, model.Currency{Symbol: uniqueSymbol("CONFLICT")})
+	require.NoError(t, err)
+
+	price := func(time int64, p string) model.CurrencyPrice {
+		return model.CurrencyPrice{CurrencyID: currency.ID, Price: decimal.RequireFromString(p), Time: time}
+	}
+	stored := func() []string {
+		items, err := manager.CurrencyPrice.List(ctx, model.ListCurrencyPricesFilter{Symbols: []string{currency.Symbol}, Limit: 10})
+		require.NoError(t, err)
+
+		var prices []string
+		for _, item := range items {
+			prices = append(prices, item.Price.String())
+		}
+		return prices
+	}
+
+	// repeated time within the batch keeps the first sample
+	res, err := manager.CurrencyPrice.Create(ctx, model.PriceConflictKeepFirst, price(1000, "1"), price(1000, "2"), price(2000, "3"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Deduplicated)
+	require.Len(t, res.Inserted, 2)
+	assert.NotZero(t, res.Inserted[0].ID)
+	assert.Equal(t, int64(1000), res.Inserted[0].Time)
+	assert.Equal(t, "1", res.Inserted[0].Price.String())
+	assert.Equal(t, []string{"1", "3"}, stored())
+	firstID := res.Inserted[0].ID
+
+	// a retry of stored samples inserts nothing
+	res, err = manager.CurrencyPrice.Create(ctx, model.PriceConflictKeepFirst, price(1000, "5"), price(2000, "6"), price(3000, "7"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Deduplicated)
+	require.Len(t, res.Inserted, 1)
+	assert.Equal(t, int64(3000), res.Inserted[0].Time)
+	assert.Equal(t, []string{"1", "3", "7"}, stored())
+
+	// keep_last overwrites stored prices with the last sample of the batch
+	res, err = manager.CurrencyPrice.Create(ctx, model.PriceConflictKeepLast, price(1000, "8"), price(1000, "9"), price(4000, "10"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, res.Deduplicated)
+	require.Len(t, res.Inserted, 1)
+	assert.Equal(t, int64(4000), res.Inserted[0].Time)
+	require.Len(t, res.Replaced, 1)
+	assert.Equal(t, firstID, res.Replaced[0].ID)
+	assert.Equal(t, int64(1000), res.Replaced[0].Time)
+	assert.Equal(t, "9", res.Replaced[0].Price.String())
+	assert.Equal(t, []string{"9", "3", "7", "10"}, stored())
+
+	// overwriting with the same price replaces nothing
+	res, err = manager.CurrencyPrice.Create(ctx, model.PriceConflictKeepLast, price(1000, "9"), price(2000, "3"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, res.Deduplicated)
+	assert.Empty(t, res.Inserted)
+	assert.Empty(t, res.Replaced)
+
+	res, err = manager.CurrencyPrice.Create(ctx, model.PriceConflictKeepLast)
+	require.NoError(t, err)
+	assert.Empty(t, res.Inserted)
+	assert.Zero(t, res.Deduplicated)
+}
+
+func testCurrencyPricePartition(t *testing.T, manager *Manager) {
+	ctx := context.Background()
+
+	currency, err := manager.Currency.Create(ctx, model.Currency{Symbol: uniqueSymbol("PARTITION")})
+	require.NoError(t, err)
+
+	// months long ago, so other data of the database is not touched
+	january := time.Date(2001, time.January, 15, 0, 0, 0, 0, time.UTC).UnixMilli()
+	february := time.Date(2001, time.February, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	march := time.Date(2001, time.March, 15, 0, 0, 0, 0, time.UTC).UnixMilli()
+
+	require.NoError(t, manager.CurrencyPricePartition.Ensure(ctx, january, march))
+	require.NoError(t, manager.CurrencyPricePartition.Ensure(ctx, january, march), "ensure must be repeatable")
+
+	_, err = manager.CurrencyPrice.Create(ctx, model.PriceConflictKeepFirst,
+		model.CurrencyPrice{CurrencyID: currency.ID, Price: decimal.RequireFromString("1"), Time: january},
+		model.CurrencyPrice{CurrencyID: currency.ID, Price: decimal.RequireFromString("2"), Time: february},
+		model.CurrencyPrice{CurrencyID: currency.ID, Price: decimal.RequireFromString("3"), Time: march},
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, manager.CurrencyPricePartition.DropBefore(ctx, february, false))
+
+	items, err := manager.CurrencyPrice.List(ctx, model.ListCurrencyPricesFilter{Symbols: []string{currency.Symbol}, Limit: 10})
+	require.NoError(t, err)
+
+	var prices []string
+	for _, item := range items {
+		prices = append(prices, item.Price.String())
+	}
+	assert.Equal(t, []string{"2", "3"}, prices)
+}
+
+func testCurrencyRollup(t *testing.T, manager *Manager) {
+	ctx := context.Background()
+
+	currency, err := manager.Currency.Create(ctx, model.Currency{Symbol: uniqueSymbol("ROLLUP")})
+	require.NoError(t, err)
+
+	hour := int64(3600000)
+	price := func(time int64, p string) model.CurrencyPrice {
+		return model.CurrencyPrice{CurrencyID: currency.ID, Price: decimal.RequireFromString(p), Time: time}
+	}
+	rollup := func(interval string, bucketTime int64, openTime, closeTime int64, open, close, high, low, sum string, count int) model.CurrencyRollup {
+		return model.CurrencyRollup{
+			CurrencyID: currency.ID,
+			Interval:   interval,
+			BucketTime: bucketTime,
+			OpenPrice:  decimal.RequireFromString(open),
+			ClosePrice: decimal.RequireFromString(close),
+			HighPrice:  decimal.RequireFromString(high),
+			LowPrice:   decimal.RequireFromString(low),
+			SumPrice:   decimal.RequireFromString(sum),
+			Count:      count,
+			OpenTime:   openTime,
+			CloseTime:  closeTime,
+		}
+	}
+	list := func(interval string) []model.CurrencyRollup {
+		items, err := manager.CurrencyRollup.List(ctx, currency.ID, interval, 0, 24*hour)
+		require.NoError(t, err)
+		for i := range items {
+			items[i].OpenPrice = canonical(items[i].OpenPrice)
+			items[i].ClosePrice = canonical(items[i].ClosePrice)
+			items[i].HighPrice = canonical(items[i].HighPrice)
+			items[i].LowPrice = canonical(items[i].LowPrice)
+			items[i].SumPrice = canonical(items[i].SumPrice)
+		}
+		return items
+	}
+
+	_, err = manager.CurrencyPrice.Create(ctx, model.PriceConflictKeepFirst,
+		price(200, "12"), price(100, "10"), price(150, "13"), price(hour, "20"), price(2*hour+10, "30"),
+	)
+	require.NoError(t, err)
+
+	// buckets which contain the range are summarised whole, the third hour is out of the range
+	require.NoError(t, manager.CurrencyRollup.Rebuild(ctx, currency.ID, model.RollupInterval1h, 150, hour))
+	require.NoError(t, manager.CurrencyRollup.Rebuild(ctx, currency.ID, model.RollupInterval1d, 150, 150))
+	assert.Equal(t, []model.CurrencyRollup{
+		rollup(model.RollupInterval1h, 0, 100, 200, "10", "12", "13", "10", "35", 3),
+		rollup(model.RollupInterval1h, hour, hour, hour, "20", "20", "20", "20", "20", 1),
+	}, list(model.RollupInterval1h))
+	assert.Equal(t, []model.CurrencyRollup{
+		rollup(model.RollupInterval1d, 0, 100, 2*hour+10, "10", "30", "30", "10", "85", 5),
+	}, list(model.RollupInterval1d))
+
+	// rebuilding again changes nothing, new prices around the stored ones move open and close
+	require.NoError(t, manager.CurrencyRollup.Rebuild(ctx, currency.ID, model.RollupInterval1h, 0, hour))
+	_, err = manager.CurrencyPrice.Create(ctx, model.PriceConflictKeepFirst, price(50, "8"), price(300, "15"))
+	require.NoError(t, err)
+	require.NoError(t, manager.CurrencyRollup.Rebuild(ctx, currency.ID, model.RollupInterval1h, 50, 300))
+	assert.Equal(t, []model.CurrencyRollup{
+		rollup(model.RollupInterval1h, 0, 50, 300, "8", "15", "15", "8", "58", 5),
+		rollup(model.RollupInterval1h, hour, hour, hour, "20", "20", "20", "20", "20", 1),
+	}, list(model.RollupInterval1h))
+
+	// a keep_last overwrite reports the replaced price, rebuilding its bucket takes the new value
+	res, err := manager.CurrencyPrice.Create(ctx, model.PriceConflictKeepLast, price(hour, "25"))
+	require.NoError(t, err)
+	require.Len(t, res.Replaced, 1)
+	replaced := res.Replaced[0]
+	require.NoError(t, manager.CurrencyRollup.Rebuild(ctx, currency.ID, model.RollupInterval1h, replaced.Time, replaced.Time))
+	assert.Equal(t, []model.CurrencyRollup{
+		rollup(model.RollupInterval1h, 0, 50, 300, "8", "15", "15", "8", "58", 5),
+		rollup(model.RollupInterval1h, hour, hour, hour, "25", "25", "25", "25", "25", 1),
+	}, list(model.RollupInterval1h))
+
+	// other currencies are not summarised in
+	other, err := manager.Currency.Create(ctx, model.Currency{Symbol: uniqueSymbol("ROLLUP")})
+	require.NoError(t, err)
+	require.NoError(t, manager.CurrencyRollup.Rebuild(ctx, other.ID, model.RollupInterval1h, 0, hour))
+	items, err := manager.CurrencyRollup.List(ctx, other.ID, model.RollupInterval1h, 0, hour)
+	assert.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func testCurrencyKline(t *testing.T, manager *Manager) {
+	ctx := context.Background()
+
+	currency, err := manager.Currency.Create(ctx, model.Currency{Symbol: uniqueSymbol("KLINE")})
+	require.NoError(t, err)
+
+	kline := func(openTime int64, p string) model.CurrencyKline {
+		price := decimal.RequireFromString(p)
+		return model.CurrencyKline{
+			CurrencyID: currency.ID,
+			Interval:   "1m",
+			OpenPrice:  price,
+			ClosePrice: price,
+			HighPrice:  price,
+			LowPrice:   price,
+			OpenTime:   openTime,
+			CloseTime:  openTime + 59999,
+		}
+	}
+
+	require.NoError(t, manager.CurrencyKline.Create(ctx, kline(120000, "3"), kline(0, "1"), kline(60000, "2")))
+	// candles are unique by currency, interval and open time, the latest write wins
+	require.NoError(t, manager.CurrencyKline.Create(ctx, kline(60000, "5")))
+	// other interval does not mix in
+	other := kline(0, "7")
+	other.Interval = "1h"
+	require.NoError(t, manager.CurrencyKline.Create(ctx, other))
+
+	count, err := manager.CurrencyKline.Count(ctx, currency.ID, "1m", 0, 120000)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	count, err = manager.CurrencyKline.Count(ctx, currency.ID, "1m", 1, 120000)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	items, err := manager.CurrencyKline.List(ctx, currency.ID, "1m", 0, 120000, 2, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.CurrencyKline{kline(0, "1"), kline(60000, "5")}, items)
+
+	items, err = manager.CurrencyKline.List(ctx, currency.ID, "1m", 0, 120000, 2, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.CurrencyKline{kline(120000, "3")}, items)
+
+	items, err = manager.CurrencyKline.List(ctx, currency.ID, "1m", 0, 120000, 2, 4)
+	assert.NoError(t, err)
+	assert.Empty(t, items)
+
+	synced, err := manager.CurrencyKline.Synced(ctx, currency.ID, "1m", 0, 120000)
+	assert.NoError(t, err)
+	assert.False(t, synced)
+
+	require.NoError(t, manager.CurrencyKline.MarkSynced(ctx, currency.ID, "1m", 0, 180000))
+	// marking the same range again is not an error
+	require.NoError(t, manager.CurrencyKline.MarkSynced(ctx, currency.ID, "1m", 0, 180000))
+
+	tc := []struct {
+		interval           string
+		startTime, endTime int64
+		synced             bool
+	}{
+		{interval: "1m", startTime: 0, endTime: 180000, synced: true},
+		{interval: "1m", startTime: 60000, endTime: 120000, synced: true},
+		{interval: "1m", startTime: 60000, endTime: 240000, synced: false},
+		{interval: "1h", startTime: 0, endTime: 120000, synced: false},
+	}
+	for _, test := range tc {
+		synced, err := manager.CurrencyKline.Synced(ctx, currency.ID, test.interval, test.startTime, test.endTime)
+		assert.NoError(t, err)
+		assert.Equal(t, test.synced, synced, "%s %d-%d", test.interval, test.startTime, test.endTime)
+	}
+}
+
+func testKlineBackfill(t *testing.T, manager *Manager) {
+	ctx := context.Background()
+
+	currency, err := manager.Currency.Create(ctx, model.Currency{Symbol: uniqueSymbol("BACKFILL")})
+	require.NoError(t, err)
+
+	daily := model.KlineBackfill{
+		CurrencyID: currency.ID,
+		Interval:   "1d",
+		StartTime:  0,
+		EndTime:    100,
+		Status:     model.BackfillStatusPending,
+		UpdatedAt:  1,
+	}
+	hourly := daily
+	hourly.Interval = "1h"
+	hourly.UpdatedAt = 2
+
+	require.NoError(t, manager.KlineBackfill.Save(ctx, daily))
+	require.NoError(t, manager.KlineBackfill.Save(ctx, hourly))
+
+	// one record per currency and interval, save overwrites it
+	hourly.Cursor = 50
+	hourly.Status = model.BackfillStatusFailed
+	hourly.Error = "some error"
+	hourly.Attempts = 2
+	hourly.UpdatedAt = 3
+	require.NoError(t, manager.KlineBackfill.Save(ctx, hourly))
+
+	daily.Symbol = currency.Symbol
+	hourly.Symbol = currency.Symbol
+
+	items, err := manager.KlineBackfill.ListByCurrency(ctx, currency.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.KlineBackfill{daily, hourly}, items)
+
+	items, err = manager.KlineBackfill.ListByStatus(ctx, model.BackfillStatusPending, model.BackfillStatusFailed)
+	assert.NoError(t, err)
+	assert.True(t, slices.IsSortedFunc(items, func(a, b model.KlineBackfill) int {
+		return int(a.UpdatedAt - b.UpdatedAt)
+	}), "backfills must be ordered by update time")
+
+	var own []model.KlineBackfill
+	for _, item := range items {
+		if item.CurrencyID == currency.ID {
+			own = append(own, item)
+		}
+	}
+	assert.Equal(t, []model.KlineBackfill{daily, hourly}, own)
+
+	items, err = manager.KlineBackfill.ListByStatus(ctx, model.BackfillStatusDone)
+	assert.NoError(t, err)
+	for _, item := range items {
+		assert.NotEqual(t, currency.ID, item.CurrencyID)
+	}
+}
+
+func testFxRate(t *testing.T, manager *Manager) {
+	ctx := context.Background()
+	currency := fmt.Sprintf("FX%d", time.Now().UnixNano())
+
+	rate := func(value string, at int64) model.FxRate {
+		return model.FxRate{Currency: currency, Rate: decimal.RequireFromString(value), Time: at, Source: "static"}
+	}
+
+	require.NoError(t, manager.FxRate.Create(ctx, rate("1", 1000), rate("2", 2000), rate("3", 3000), rate("4", 4000), rate("5", 5000)))
+	// a rate of stored time is skipped
+	require.NoError(t, manager.FxRate.Create(ctx, rate("9", 3000)))
+
+	values := func(items []model.FxRate) []string {
+		res := make([]string, 0, len(items))
+		for _, item := range items {
+			assert.NotZero(t, item.ID)
+			assert.Equal(t, currency, item.Currency)
+			res = append(res, canonical(item.Rate).String())
+		}
+		return res
+	}
+
+	items, err := manager.FxRate.Around(ctx, currency, 2500, 3500)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "3", "4"}, values(items), "the range with the closest rates outside of it")
+
+	items, err = manager.FxRate.Around(ctx, currency, 2000, 4000)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3", "4", "5"}, values(items))
+
+	items, err = manager.FxRate.Around(ctx, currency, 6000, 7000)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"5"}, values(items))
+
+	items, err = manager.FxRate.Around(ctx, "FX"+currency, 0, 7000)
+	assert.NoError(t, err)
+	assert.Empty(t, items)
+}
@@ -2,44 +2,189 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"gexabyte/internal/config"
 	"gexabyte/internal/model"
-	repo "gexabyte/internal/repository/postgres"
+	"gexabyte/internal/repository/memory"
+	mongorepo "gexabyte/internal/repository/mongo"
+	pgrepo "gexabyte/internal/repository/postgres"
+	"gexabyte/pkg/clients/mongo"
 	"gexabyte/pkg/clients/postgres"
 )
 
+const setupTimeout = 30 * time.Second
+
 type Manager struct {
 	Currency      Currency
 	CurrencyPrice CurrencyPrice
+	CurrencyKline CurrencyKline
+	KlineBackfill KlineBackfill
+
+	CurrencyPricePartition CurrencyPricePartition
+	CurrencyRollup         CurrencyRollup
+
+	FxRate FxRate
 }
 
 type Currency interface {
-	Create(ctx context.Context, symbol string) error
+	// Create stores the pair with its exchange metadata, new pairs are active.
+	Create(ctx context.Context, currency model.Currency) (model.Currency, error)
 	GetBySymbol(ctx context.Context, symbol string) (model.Currency, error)
 	List(ctx context.Context) ([]model.Currency, error)
+	SetActive(ctx context.Context, symbol string, active bool) (model.Currency, error)
+	// UpdateInfo overwrites exchange metadata of the currency found by symbol.
+	UpdateInfo(ctx context.Context, currency model.Currency) error
+	// Delete removes the currency with its candles, backfills and rollups.
+	// It fails with model.ErrHasPrices while prices of the currency are stored.
+	Delete(ctx context.Context, id int) error
 }
 
+// CurrencyPrice stores price samples, unique by currency and time (unix milliseconds).
 type CurrencyPrice interface {
-	Create(ctx context.Context, rates ...model.CurrencyPrice) error
-	List(ctx context.Context) ([]model.CurrencyPrice, error)
+	// Create stores the batch at once. A sample of stored currency and time is skipped with model.PriceConflictKeepFirst
+	// or overwrites the stored price with model.PriceConflictKeepLast.
+	Create(ctx context.Context, onConflict string, rates ...model.CurrencyPrice) (model.CreateCurrencyPricesRes, error)
+	// List returns up to filter.Limit prices ordered by time and id, starting right after filter.After.
+	List(ctx context.Context, filter model.ListCurrencyPricesFilter) ([]model.CurrencyPriceDTO, error)
+	// Stat aggregates stored prices of symbols between startTime and endTime (unix milliseconds).
+	Stat(ctx context.Context, startTime, endTime int64, symbols ...string) ([]model.GetCurrencyStat24HDTO, error)
+	// DeleteByCurrency removes up to limit prices of the currency and returns how many were removed.
+	DeleteByCurrency(ctx context.Context, currencyID int, limit int) (int, error)
+}
+
+// CurrencyPricePartition keeps storage of prices split by months of time, times are unix milliseconds.
+// Backends without partitions have nothing to create and delete old prices instead.
+type CurrencyPricePartition interface {
+	// Ensure makes sure prices of months between startTime and endTime can be stored.
+	Ensure(ctx context.Context, startTime, endTime int64) error
+	// DropBefore removes prices older than before, which is the start of a month.
+	// With detach partitions are kept as standalone tables where supported.
+	DropBefore(ctx context.Context, before int64, detach bool) error
+}
+
+// CurrencyRollup stores summaries of prices per currency, interval and bucket start time (unix milliseconds).
+type CurrencyRollup interface {
+	// Rebuild summarises stored prices of the currency in every bucket of the interval
+	// between the buckets of startTime and endTime, and replaces what the buckets held.
+	// Buckets are made from prices alone, so rebuilding them again gives the same rollups.
+	Rebuild(ctx context.Context, currencyID int, interval string, startTime, endTime int64) error
+	List(ctx context.Context, currencyID int, interval string, startTime, endTime int64) ([]model.CurrencyRollup, error)
+}
+
+// FxRate stores history of rates of fiat currencies to USD, unique by currency and time (unix milliseconds).
+type FxRate interface {
+	// Create stores the batch at once, a rate of stored currency and time is skipped.
+	Create(ctx context.Context, rates ...model.FxRate) error
+	// Around returns rates of the currency between startTime and endTime ordered by time,
+	// with the last one before startTime and the first one after endTime, so every time of the range has its closest rate.
+	Around(ctx context.Context, currency string, startTime, endTime int64) ([]model.FxRate, error)
+}
+
+// CurrencyKline stores candles of tracked currencies.
+// Candles are unique by currency, interval and open time, times are unix milliseconds.
+type CurrencyKline interface {
+	Create(ctx context.Context, klines ...model.CurrencyKline) error
+	Count(ctx context.Context, currencyID int, interval string, startTime, endTime int64) (int, error)
+	List(ctx context.Context, currencyID int, interval string, startTime, endTime int64, limit, offset int) ([]model.CurrencyKline, error)
+	// MarkSynced records that every candle the provider has between startTime and endTime is stored,
+	// so ranges where the venue has gaps are not asked again.
+	MarkSynced(ctx context.Context, currencyID int, interval string, startTime, endTime int64) error
+	// Synced reports whether one marked range covers startTime to endTime.
+	Synced(ctx context.Context, currencyID int, interval string, startTime, endTime int64) (bool, error)
+}
+
+// KlineBackfill stores progress of loading historical candles, one record per currency and interval.
+type KlineBackfill interface {
+	Save(ctx context.Context, backfill model.KlineBackfill) error
+	ListByCurrency(ctx context.Context, currencyID int) ([]model.KlineBackfill, error)
+	ListByStatus(ctx context.Context, statuses ...string) ([]model.KlineBackfill, error)
 }
 
+const (
+	DriverPostgres = "postgres"
+	DriverMongo    = "mongo"
+	DriverMemory   = "memory"
+)
+
+// NewRepository builds repositories of the backend picked by cfg.DBDriver.
 func NewRepository(cfg *config.Config) (*Manager, error) {
+	switch cfg.DBDriver {
+	case DriverPostgres:
+		return newPostgres(cfg)
+	case DriverMongo:
+		return newMongo(cfg)
+	case DriverMemory:
+		return newMemory(), nil
+	}
+
+	return nil, fmt.Errorf("unknown db driver: %s", cfg.DBDriver)
+}
+
+func newPostgres(cfg *config.Config) (*Manager, error) {
 	dbClient, err := postgres.NewClient(postgres.Config{DSN: cfg.Postgres.DSN})
 	if err != nil {
 		return nil, err
 	}
 
-	if err := postgres.RunDBMigration(cfg.Postgres.MigrationURL, cfg.Postgres.DSN); err != nil {
+	if cfg.Postgres.AutoMigrate {
+		if err := postgres.RunDBMigration(cfg.Postgres.MigrationURL, cfg.Postgres.DSN); err != nil {
+			return nil, err
+		}
+	}
+
+	return &Manager{
+		Currency:      pgrepo.NewCurrency(dbClient.DB),
+		CurrencyPrice: pgrepo.NewCurrencyPrice(dbClient.DB),
+		CurrencyKline: pgrepo.NewCurrencyKline(dbClient.DB),
+		KlineBackfill: pgrepo.NewKlineBackfill(dbClient.DB),
+
+		CurrencyPricePartition: pgrepo.NewCurrencyPricePartition(dbClient.DB),
+		CurrencyRollup:         pgrepo.NewCurrencyRollup(dbClient.DB),
+
+		FxRate: pgrepo.NewFxRate(dbClient.DB),
+	}, nil
+}
+
+func newMongo(cfg *config.Config) (*Manager, error) {
+	dbClient, err := mongo.NewClient(mongo.Config{URI: cfg.Mongo.URI, Database: cfg.Mongo.Database})
+	if err != nil {
 		return nil, err
 	}
 
-	currencyPairs := repo.NewCurrency(dbClient.DB)
-	currencyPrice := repo.NewCurrencyPrice(dbClient.DB)
+	ctx, cancel := context.WithTimeout(context.Background(), setupTimeout)
+	defer cancel()
+
+	if err := mongorepo.Setup(ctx, dbClient.Database); err != nil {
+		return nil, fmt.Errorf("failed setup mongo: %w", err)
+	}
 
 	return &Manager{
-		Currency:      currencyPairs,
-		CurrencyPrice: currencyPrice,
+		Currency:      mongorepo.NewCurrency(dbClient.Database),
+		CurrencyPrice: mongorepo.NewCurrencyPrice(dbClient.Database),
+		CurrencyKline: mongorepo.NewCurrencyKline(dbClient.Database),
+		KlineBackfill: mongorepo.NewKlineBackfill(dbClient.Database),
+
+		CurrencyPricePartition: mongorepo.NewCurrencyPricePartition(dbClient.Database),
+		CurrencyRollup:         mongorepo.NewCurrencyRollup(dbClient.Database),
+
+		FxRate: mongorepo.NewFxRate(dbClient.Database),
 	}, nil
 }
+
+func newMemory() *Manager {
+	db := memory.NewDB()
+
+	return &Manager{
+		Currency:      memory.NewCurrency(db),
+		CurrencyPrice: memory.NewCurrencyPrice(db),
+		CurrencyKline: memory.NewCurrencyKline(db),
+		KlineBackfill: memory.NewKlineBackfill(db),
+
+		CurrencyPricePartition: memory.NewCurrencyPricePartition(db),
+		CurrencyRollup:         memory.NewCurrencyRollup(db),
+
+		FxRate: memory.NewFxRate(db),
+	}
+}
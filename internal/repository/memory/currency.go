@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"context"
+	"gexabyte/internal/model"
+	"slices"
+)
+
+type CurrencyRepo struct {
+	db *DB
+}
+
+func NewCurrency(db *DB) *CurrencyRepo {
+	return &CurrencyRepo{
+		db: db,
+	}
+}
+
+func (r *CurrencyRepo) Create(ctx context.Context, currency model.Currency) (model.Currency, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, c := range r.db.currencies {
+		if c.Symbol == currency.Symbol {
+			return model.Currency{}, model.ErrAlreadyExists
+		}
+	}
+
+	r.db.currencySeq++
+	res := currency
+	res.ID, res.Active = r.db.currencySeq, true
+	r.db.currencies = append(r.db.currencies, res)
+
+	return res, nil
+}
+
+func (r *CurrencyRepo) GetBySymbol(ctx context.Context, symbol string) (model.Currency, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, c := range r.db.currencies {
+		if c.Symbol == symbol {
+			return c, nil
+		}
+	}
+
+	return model.Currency{}, model.ErrNotFound
+}
+
+func (r *CurrencyRepo) List(ctx context.Context) ([]model.Currency, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	if len(r.db.currencies) == 0 {
+		return nil, nil
+	}
+
+	// currencies are appended with growing ids, so the slice is already ordered by id
+	items := make([]model.Currency, len(r.db.currencies))
+	copy(items, r.db.currencies)
+
+	return items, nil
+}
+
+func (r *CurrencyRepo) SetActive(ctx context.Context, symbol string, active bool) (model.Currency, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for i, c := range r.db.currencies {
+		if c.Symbol == symbol {
+			r.db.currencies[i].Active = active
+			return r.db.currencies[i], nil
+		}
+	}
+
+	return model.Currency{}, model.ErrNotFound
+}
+
+// UpdateInfo overwrites exchange metadata of the currency with the symbol.
+func (r *CurrencyRepo) UpdateInfo(ctx context.Context, currency model.Currency) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for i, c := range r.db.currencies {
+		if c.Symbol == currency.Symbol {
+			c.BaseAsset, c.QuoteAsset, c.Status = currency.BaseAsset, currency.QuoteAsset, currency.Status
+			c.TickSize, c.StepSize = currency.TickSize, currency.StepSize
+			r.db.currencies[i] = c
+			return nil
+		}
+	}
+
+	return model.ErrNotFound
+}
+
+// Delete keeps the same rules as postgres foreign keys: prices restrict the delete,
+// candles, backfills and rollups are removed with the currency.
+func (r *CurrencyRepo) Delete(ctx context.Context, id int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	i := slices.IndexFunc(r.db.currencies, func(c model.Currency) bool { return c.ID == id })
+	if i < 0 {
+		return model.ErrNotFound
+	}
+	if slices.ContainsFunc(r.db.prices, func(p model.CurrencyPrice) bool { return p.CurrencyID == id }) {
+		return model.ErrHasPrices
+	}
+
+	r.db.currencies = slices.Delete(r.db.currencies, i, i+1)
+	for k := range r.db.klines {
+		if k.currencyID == id {
+			delete(r.db.klines, k)
+		}
+	}
+	for k := range r.db.klineSyncs {
+		if k.currencyID == id {
+			delete(r.db.klineSyncs, k)
+		}
+	}
+	for k := range r.db.backfills {
+		if k.currencyID == id {
+			delete(r.db.backfills, k)
+		}
+	}
+	for k := range r.db.rollups {
+		if k.currencyID == id {
+			delete(r.db.rollups, k)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"gexabyte/internal/model"
+	"sort"
+)
+
+type CurrencyKlineRepo struct {
+	db *DB
+}
+
+func NewCurrencyKline(db *DB) *CurrencyKlineRepo {
+	return &CurrencyKlineRepo{
+		db: db,
+	}
+}
+
+func (r *CurrencyKlineRepo) Create(ctx context.Context, klines ...model.CurrencyKline) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, k := range klines {
+		r.db.klines[klineKey{currencyID: k.CurrencyID, interval: k.Interval, openTime: k.OpenTime}] = k
+	}
+
+	return nil
+}
+
+func (r *CurrencyKlineRepo) Count(ctx context.Context, currencyID int, interval string, startTime, endTime int64) (int, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	return len(r.filter(currencyID, interval, startTime, endTime)), nil
+}
+
+func (r *CurrencyKlineRepo) List(ctx context.Context, currencyID int, interval string, startTime, endTime int64, limit, offset int) ([]model.CurrencyKline, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	items := r.filter(currencyID, interval, startTime, endTime)
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].OpenTime < items[j].OpenTime
+	})
+
+	if offset >= len(items) {
+		return nil, nil
+	}
+	items = items[offset:]
+	if limit < len(items) {
+		items = items[:limit]
+	}
+
+	return items, nil
+}
+
+func (r *CurrencyKlineRepo) MarkSynced(ctx context.Context, currencyID int, interval string, startTime, endTime int64) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	r.db.klineSyncs[klineSyncKey{currencyID: currencyID, interval: interval, startTime: startTime, endTime: endTime}] = struct{}{}
+
+	return nil
+}
+
+func (r *CurrencyKlineRepo) Synced(ctx context.Context, currencyID int, interval string, startTime, endTime int64) (bool, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for key := range r.db.klineSyncs {
+		if key.currencyID == currencyID && key.interval == interval && key.startTime <= startTime && key.endTime >= endTime {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// filter returns candles opened between startTime and endTime, the caller must hold the lock.
+func (r *CurrencyKlineRepo) filter(currencyID int, interval string, startTime, endTime int64) []model.CurrencyKline {
+	var items []model.CurrencyKline
+	for key, k := range r.db.klines {
+		if key.currencyID != currencyID || key.interval != interval || key.openTime < startTime || key.openTime > endTime {
+			continue
+		}
+
+		items = append(items, k)
+	}
+
+	return items
+}
@@ -0,0 +1,179 @@
+package memory
+
+import (
+	"context"
+	"gexabyte/internal/model"
+	"slices"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+type CurrencyPriceRepo struct {
+	db *DB
+}
+
+func NewCurrencyPrice(db *DB) *CurrencyPriceRepo {
+	return &CurrencyPriceRepo{
+		db: db,
+	}
+}
+
+func (r *CurrencyPriceRepo) Create(ctx context.Context, onConflict string, rates ...model.CurrencyPrice) (model.CreateCurrencyPricesRes, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	total := len(rates)
+
+	var res model.CreateCurrencyPricesRes
+	for _, rate := range model.DedupePrices(onConflict, rates...) {
+		i := slices.IndexFunc(r.db.prices, func(p model.CurrencyPrice) bool {
+			return p.CurrencyID == rate.CurrencyID && p.Time == rate.Time
+		})
+		if i >= 0 {
+			stored := &r.db.prices[i]
+			if onConflict == model.PriceConflictKeepLast && (!stored.Price.Equal(rate.Price) || stored.Source != rate.Source) {
+				stored.Price, stored.Source = rate.Price, rate.Source
+				res.Replaced = append(res.Replaced, *stored)
+			}
+			continue
+		}
+
+		r.db.priceSeq++
+		rate.ID = r.db.priceSeq
+		r.db.prices = append(r.db.prices, rate)
+		res.Inserted = append(res.Inserted, rate)
+	}
+	res.Deduplicated = total - len(res.Inserted) - len(res.Replaced)
+
+	return res, nil
+}
+
+func (r *CurrencyPriceRepo) List(ctx context.Context, filter model.ListCurrencyPricesFilter) ([]model.CurrencyPriceDTO, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	desc := filter.Order == model.OrderDesc
+
+	var prices []model.CurrencyPrice
+	for _, p := range r.db.prices {
+		if len(filter.Symbols) > 0 && !slices.Contains(filter.Symbols, r.db.symbol(p.CurrencyID)) {
+			continue
+		}
+		if filter.StartTime > 0 && p.Time < filter.StartTime {
+			continue
+		}
+		if filter.EndTime > 0 && p.Time > filter.EndTime {
+			continue
+		}
+		if filter.After != nil {
+			after := p.Time > filter.After.Time || p.Time == filter.After.Time && p.ID > filter.After.ID
+			before := p.Time < filter.After.Time || p.Time == filter.After.Time && p.ID < filter.After.ID
+			if desc && !before || !desc && !after {
+				continue
+			}
+		}
+
+		prices = append(prices, p)
+	}
+
+	sortPrices(prices)
+	if desc {
+		slices.Reverse(prices)
+	}
+	if len(prices) > filter.Limit {
+		prices = prices[:filter.Limit]
+	}
+
+	var items []model.CurrencyPriceDTO
+	for _, p := range prices {
+		items = append(items, model.CurrencyPriceDTO{
+			ID:     p.ID,
+			Symbol: r.db.symbol(p.CurrencyID),
+			Price:  p.Price,
+			Time:   p.Time,
+			Source: p.Source,
+		})
+	}
+
+	return items, nil
+}
+
+func (r *CurrencyPriceRepo) Stat(ctx context.Context, startTime, endTime int64, symbols ...string) ([]model.GetCurrencyStat24HDTO, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	grouped := make(map[string][]model.CurrencyPrice)
+	for _, p := range r.db.prices {
+		if p.Time < startTime || p.Time > endTime {
+			continue
+		}
+
+		symbol := r.db.symbol(p.CurrencyID)
+		if !slices.Contains(symbols, symbol) {
+			continue
+		}
+
+		grouped[symbol] = append(grouped[symbol], p)
+	}
+
+	var items []model.GetCurrencyStat24HDTO
+	for symbol, prices := range grouped {
+		sortPrices(prices)
+
+		item := model.GetCurrencyStat24HDTO{
+			Symbol:    symbol,
+			Source:    model.StatSourceLocal,
+			OpenPrice: prices[0].Price,
+			LastPrice: prices[len(prices)-1].Price,
+			HighPrice: prices[0].Price,
+			LowPrice:  prices[0].Price,
+			Count:     len(prices),
+			OpenTime:  prices[0].Time,
+			CloseTime: prices[len(prices)-1].Time,
+		}
+
+		sum := decimal.Zero
+		for _, p := range prices {
+			item.HighPrice = decimal.Max(item.HighPrice, p.Price)
+			item.LowPrice = decimal.Min(item.LowPrice, p.Price)
+			sum = sum.Add(p.Price)
+		}
+		item.AvgPrice = sum.Div(decimal.NewFromInt(int64(len(prices))))
+		item.PriceChangePercent = model.PriceChangePercent(item.OpenPrice, item.LastPrice)
+
+		items = append(items, item)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Symbol < items[j].Symbol
+	})
+
+	return items, nil
+}
+
+// sortPrices orders prices by time, prices of the same time keep insertion order.
+func sortPrices(prices []model.CurrencyPrice) {
+	sort.Slice(prices, func(i, j int) bool {
+		if prices[i].Time != prices[j].Time {
+			return prices[i].Time < prices[j].Time
+		}
+		return prices[i].ID < prices[j].ID
+	})
+}
+
+func (r *CurrencyPriceRepo) DeleteByCurrency(ctx context.Context, currencyID int, limit int) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	deleted := 0
+	r.db.prices = slices.DeleteFunc(r.db.prices, func(p model.CurrencyPrice) bool {
+		if p.CurrencyID != currencyID || deleted >= limit {
+			return false
+		}
+		deleted++
+		return true
+	})
+
+	return deleted, nil
+}
@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"context"
+	"gexabyte/internal/model"
+)
+
+// CurrencyPricePartitionRepo keeps prices in one slice, old ones are deleted.
+type CurrencyPricePartitionRepo struct {
+	db *DB
+}
+
+func NewCurrencyPricePartition(db *DB) *CurrencyPricePartitionRepo {
+	return &CurrencyPricePartitionRepo{
+		db: db,
+	}
+}
+
+func (r *CurrencyPricePartitionRepo) Ensure(ctx context.Context, startTime, endTime int64) error {
+	return nil
+}
+
+func (r *CurrencyPricePartitionRepo) DropBefore(ctx context.Context, before int64, detach bool) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	// copy to a new slice, so memory of deleted prices is released
+	prices := make([]model.CurrencyPrice, 0, len(r.db.prices))
+	for _, p := range r.db.prices {
+		if p.Time >= before {
+			prices = append(prices, p)
+		}
+	}
+	r.db.prices = prices
+
+	return nil
+}
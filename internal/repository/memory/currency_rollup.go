@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"gexabyte/internal/model"
+	"sort"
+
+	"github.com/shopspring/decimal"
+)
+
+type CurrencyRollupRepo struct {
+	db *DB
+}
+
+func NewCurrencyRollup(db *DB) *CurrencyRollupRepo {
+	return &CurrencyRollupRepo{
+		db: db,
+	}
+}
+
+func (r *CurrencyRollupRepo) Rebuild(ctx context.Context, currencyID int, interval string, startTime, endTime int64) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	startTime, endTime = model.RollupRange(interval, startTime, endTime)
+	size := model.KlineInterval.GetDuration(interval).Milliseconds()
+
+	rollups := make(map[rollupKey]model.CurrencyRollup)
+	for _, p := range r.db.prices {
+		if p.CurrencyID != currencyID || p.Time < startTime || p.Time > endTime {
+			continue
+		}
+
+		key := rollupKey{currencyID: currencyID, interval: interval, bucketTime: p.Time - p.Time%size}
+		rollup, ok := rollups[key]
+		if !ok {
+			rollup = model.CurrencyRollup{
+				CurrencyID: currencyID,
+				Interval:   interval,
+				BucketTime: key.bucketTime,
+				OpenPrice:  p.Price,
+				OpenTime:   p.Time,
+				HighPrice:  p.Price,
+				LowPrice:   p.Price,
+			}
+		}
+
+		if p.Time < rollup.OpenTime {
+			rollup.OpenPrice, rollup.OpenTime = p.Price, p.Time
+		}
+		if p.Time >= rollup.CloseTime {
+			rollup.ClosePrice, rollup.CloseTime = p.Price, p.Time
+		}
+		rollup.HighPrice = decimal.Max(rollup.HighPrice, p.Price)
+		rollup.LowPrice = decimal.Min(rollup.LowPrice, p.Price)
+		rollup.SumPrice = rollup.SumPrice.Add(p.Price)
+		rollup.Count++
+		rollups[key] = rollup
+	}
+
+	for key, rollup := range rollups {
+		r.db.rollups[key] = rollup
+	}
+
+	return nil
+}
+
+func (r *CurrencyRollupRepo) List(ctx context.Context, currencyID int, interval string, startTime, endTime int64) ([]model.CurrencyRollup, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var items []model.CurrencyRollup
+	for key, rollup := range r.db.rollups {
+		if key.currencyID != currencyID || key.interval != interval || key.bucketTime < startTime || key.bucketTime > endTime {
+			continue
+		}
+
+		items = append(items, rollup)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].BucketTime < items[j].BucketTime
+	})
+
+	return items, nil
+}
@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"sync"
+
+	"gexabyte/internal/model"
+)
+
+// seedSymbols are tracked by default, the same as in postgres migrations.
+var seedSymbols = []string{"BTCUSDT", "ETHUSDT", "SOLUSDT", "TRXUSDT"}
+
+type klineKey struct {
+	currencyID int
+	interval   string
+	openTime   int64
+}
+
+type klineSyncKey struct {
+	currencyID int
+	interval   string
+	startTime  int64
+	endTime    int64
+}
+
+type rollupKey struct {
+	currencyID int
+	interval   string
+	bucketTime int64
+}
+
+type backfillKey struct {
+	currencyID int
+	interval   string
+}
+
+// DB keeps all tables in process memory, it is shared by repositories of the package.
+// Data is lost on restart, so it suits local runs and tests.
+type DB struct {
+	mu sync.RWMutex
+
+	currencies []model.Currency
+	prices     []model.CurrencyPrice
+	klines     map[klineKey]model.CurrencyKline
+	klineSyncs map[klineSyncKey]struct{}
+	backfills  map[backfillKey]model.KlineBackfill
+	rollups    map[rollupKey]model.CurrencyRollup
+	fxRates    []model.FxRate
+
+	currencySeq int
+	priceSeq    int
+	fxRateSeq   int
+}
+
+// NewDB returns a database seeded with the default currencies.
+func NewDB() *DB {
+	db := &DB{
+		klines:     make(map[klineKey]model.CurrencyKline),
+		klineSyncs: make(map[klineSyncKey]struct{}),
+		backfills:  make(map[backfillKey]model.KlineBackfill),
+		rollups:    make(map[rollupKey]model.CurrencyRollup),
+	}
+
+	for _, symbol := range seedSymbols {
+		db.currencySeq++
+		db.currencies = append(db.currencies, model.Currency{ID: db.currencySeq, Symbol: symbol, Active: true})
+	}
+
+	return db
+}
+
+// symbol returns symbol of the currency, the caller must hold the lock.
+func (db *DB) symbol(currencyID int) string {
+	for _, c := range db.currencies {
+		if c.ID == currencyID {
+			return c.Symbol
+		}
+	}
+
+	return ""
+}
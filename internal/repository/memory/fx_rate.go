@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"gexabyte/internal/model"
+	"sort"
+)
+
+type FxRateRepo struct {
+	db *DB
+}
+
+func NewFxRate(db *DB) *FxRateRepo {
+	return &FxRateRepo{
+		db: db,
+	}
+}
+
+func (r *FxRateRepo) Create(ctx context.Context, rates ...model.FxRate) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, rate := range rates {
+		if r.db.hasFxRate(rate.Currency, rate.Time) {
+			continue
+		}
+
+		r.db.fxRateSeq++
+		rate.ID = r.db.fxRateSeq
+		r.db.fxRates = append(r.db.fxRates, rate)
+	}
+
+	return nil
+}
+
+func (r *FxRateRepo) Around(ctx context.Context, currency string, startTime, endTime int64) ([]model.FxRate, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var (
+		items         []model.FxRate
+		before, after *model.FxRate
+	)
+	for i, rate := range r.db.fxRates {
+		switch {
+		case rate.Currency != currency:
+		case rate.Time < startTime:
+			if before == nil || rate.Time > before.Time {
+				before = &r.db.fxRates[i]
+			}
+		case rate.Time > endTime:
+			if after == nil || rate.Time < after.Time {
+				after = &r.db.fxRates[i]
+			}
+		default:
+			items = append(items, rate)
+		}
+	}
+	if before != nil {
+		items = append(items, *before)
+	}
+	if after != nil {
+		items = append(items, *after)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Time < items[j].Time
+	})
+
+	return items, nil
+}
+
+// hasFxRate tells whether a rate of the currency and time is stored, the caller must hold the lock.
+func (db *DB) hasFxRate(currency string, time int64) bool {
+	for _, rate := range db.fxRates {
+		if rate.Currency == currency && rate.Time == time {
+			return true
+		}
+	}
+
+	return false
+}
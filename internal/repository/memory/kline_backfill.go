@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"gexabyte/internal/model"
+	"slices"
+	"sort"
+)
+
+type KlineBackfillRepo struct {
+	db *DB
+}
+
+func NewKlineBackfill(db *DB) *KlineBackfillRepo {
+	return &KlineBackfillRepo{
+		db: db,
+	}
+}
+
+func (r *KlineBackfillRepo) Save(ctx context.Context, backfill model.KlineBackfill) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	// symbol and progress are not stored, as in other backends
+	backfill.Symbol = ""
+	backfill.Progress = 0
+	r.db.backfills[backfillKey{currencyID: backfill.CurrencyID, interval: backfill.Interval}] = backfill
+
+	return nil
+}
+
+func (r *KlineBackfillRepo) ListByCurrency(ctx context.Context, currencyID int) ([]model.KlineBackfill, error) {
+	items := r.list(func(b model.KlineBackfill) bool {
+		return b.CurrencyID == currencyID
+	})
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Interval < items[j].Interval
+	})
+
+	return items, nil
+}
+
+func (r *KlineBackfillRepo) ListByStatus(ctx context.Context, statuses ...string) ([]model.KlineBackfill, error) {
+	items := r.list(func(b model.KlineBackfill) bool {
+		return slices.Contains(statuses, b.Status)
+	})
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].UpdatedAt < items[j].UpdatedAt
+	})
+
+	return items, nil
+}
+
+func (r *KlineBackfillRepo) list(match func(b model.KlineBackfill) bool) []model.KlineBackfill {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var items []model.KlineBackfill
+	for _, b := range r.db.backfills {
+		if !match(b) {
+			continue
+		}
+
+		b.Symbol = r.db.symbol(b.CurrencyID)
+		items = append(items, b)
+	}
+
+	return items
+}
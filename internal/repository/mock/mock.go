@@ -36,17 +36,32 @@ func (m *MockCurrency) EXPECT() *MockCurrencyMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockCurrency) Create(ctx context.Context, symbol string) error {
+func (m *MockCurrency) Create(ctx context.Context, currency model.Currency) (model.Currency, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", ctx, symbol)
+	ret := m.ctrl.Call(m, "Create", ctx, currency)
+	ret0, _ := ret[0].(model.Currency)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockCurrencyMockRecorder) Create(ctx, currency interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCurrency)(nil).Create), ctx, currency)
+}
+
+// Delete mocks base method.
+func (m *MockCurrency) Delete(ctx context.Context, id int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Create indicates an expected call of Create.
-func (mr *MockCurrencyMockRecorder) Create(ctx, symbol interface{}) *gomock.Call {
+// Delete indicates an expected call of Delete.
+func (mr *MockCurrencyMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCurrency)(nil).Create), ctx, symbol)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCurrency)(nil).Delete), ctx, id)
 }
 
 // GetBySymbol mocks base method.
@@ -79,6 +94,35 @@ func (mr *MockCurrencyMockRecorder) List(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCurrency)(nil).List), ctx)
 }
 
+// SetActive mocks base method.
+func (m *MockCurrency) SetActive(ctx context.Context, symbol string, active bool) (model.Currency, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetActive", ctx, symbol, active)
+	ret0, _ := ret[0].(model.Currency)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetActive indicates an expected call of SetActive.
+func (mr *MockCurrencyMockRecorder) SetActive(ctx, symbol, active interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetActive", reflect.TypeOf((*MockCurrency)(nil).SetActive), ctx, symbol, active)
+}
+
+// UpdateInfo mocks base method.
+func (m *MockCurrency) UpdateInfo(ctx context.Context, currency model.Currency) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateInfo", ctx, currency)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateInfo indicates an expected call of UpdateInfo.
+func (mr *MockCurrencyMockRecorder) UpdateInfo(ctx, currency interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateInfo", reflect.TypeOf((*MockCurrency)(nil).UpdateInfo), ctx, currency)
+}
+
 // MockCurrencyPrice is a mock of CurrencyPrice interface.
 type MockCurrencyPrice struct {
 	ctrl     *gomock.Controller
@@ -103,7 +147,218 @@ func (m *MockCurrencyPrice) EXPECT() *MockCurrencyPriceMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockCurrencyPrice) Create(ctx context.Context, rates ...model.CurrencyPrice) error {
+func (m *MockCurrencyPrice) Create(ctx context.Context, onConflict string, rates ...model.CurrencyPrice) (model.CreateCurrencyPricesRes, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, onConflict}
+	for _, a := range rates {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Create", varargs...)
+	ret0, _ := ret[0].(model.CreateCurrencyPricesRes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockCurrencyPriceMockRecorder) Create(ctx, onConflict interface{}, rates ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, onConflict}, rates...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCurrencyPrice)(nil).Create), varargs...)
+}
+
+// DeleteByCurrency mocks base method.
+func (m *MockCurrencyPrice) DeleteByCurrency(ctx context.Context, currencyID, limit int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByCurrency", ctx, currencyID, limit)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteByCurrency indicates an expected call of DeleteByCurrency.
+func (mr *MockCurrencyPriceMockRecorder) DeleteByCurrency(ctx, currencyID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByCurrency", reflect.TypeOf((*MockCurrencyPrice)(nil).DeleteByCurrency), ctx, currencyID, limit)
+}
+
+// List mocks base method.
+func (m *MockCurrencyPrice) List(ctx context.Context, filter model.ListCurrencyPricesFilter) ([]model.CurrencyPriceDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter)
+	ret0, _ := ret[0].([]model.CurrencyPriceDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockCurrencyPriceMockRecorder) List(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCurrencyPrice)(nil).List), ctx, filter)
+}
+
+// Stat mocks base method.
+func (m *MockCurrencyPrice) Stat(ctx context.Context, startTime, endTime int64, symbols ...string) ([]model.GetCurrencyStat24HDTO, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, startTime, endTime}
+	for _, a := range symbols {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Stat", varargs...)
+	ret0, _ := ret[0].([]model.GetCurrencyStat24HDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stat indicates an expected call of Stat.
+func (mr *MockCurrencyPriceMockRecorder) Stat(ctx, startTime, endTime interface{}, symbols ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, startTime, endTime}, symbols...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stat", reflect.TypeOf((*MockCurrencyPrice)(nil).Stat), varargs...)
+}
+
+// MockCurrencyPricePartition is a mock of CurrencyPricePartition interface.
+type MockCurrencyPricePartition struct {
+	ctrl     *gomock.Controller
+	recorder *MockCurrencyPricePartitionMockRecorder
+}
+
+// MockCurrencyPricePartitionMockRecorder is the mock recorder for MockCurrencyPricePartition.
+type MockCurrencyPricePartitionMockRecorder struct {
+	mock *MockCurrencyPricePartition
+}
+
+// NewMockCurrencyPricePartition creates a new mock instance.
+func NewMockCurrencyPricePartition(ctrl *gomock.Controller) *MockCurrencyPricePartition {
+	mock := &MockCurrencyPricePartition{ctrl: ctrl}
+	mock.recorder = &MockCurrencyPricePartitionMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCurrencyPricePartition) EXPECT() *MockCurrencyPricePartitionMockRecorder {
+	return m.recorder
+}
+
+// DropBefore mocks base method.
+func (m *MockCurrencyPricePartition) DropBefore(ctx context.Context, before int64, detach bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropBefore", ctx, before, detach)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DropBefore indicates an expected call of DropBefore.
+func (mr *MockCurrencyPricePartitionMockRecorder) DropBefore(ctx, before, detach interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropBefore", reflect.TypeOf((*MockCurrencyPricePartition)(nil).DropBefore), ctx, before, detach)
+}
+
+// Ensure mocks base method.
+func (m *MockCurrencyPricePartition) Ensure(ctx context.Context, startTime, endTime int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Ensure", ctx, startTime, endTime)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Ensure indicates an expected call of Ensure.
+func (mr *MockCurrencyPricePartitionMockRecorder) Ensure(ctx, startTime, endTime interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ensure", reflect.TypeOf((*MockCurrencyPricePartition)(nil).Ensure), ctx, startTime, endTime)
+}
+
+// MockCurrencyRollup is a mock of CurrencyRollup interface.
+type MockCurrencyRollup struct {
+	ctrl     *gomock.Controller
+	recorder *MockCurrencyRollupMockRecorder
+}
+
+// MockCurrencyRollupMockRecorder is the mock recorder for MockCurrencyRollup.
+type MockCurrencyRollupMockRecorder struct {
+	mock *MockCurrencyRollup
+}
+
+// NewMockCurrencyRollup creates a new mock instance.
+func NewMockCurrencyRollup(ctrl *gomock.Controller) *MockCurrencyRollup {
+	mock := &MockCurrencyRollup{ctrl: ctrl}
+	mock.recorder = &MockCurrencyRollupMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCurrencyRollup) EXPECT() *MockCurrencyRollupMockRecorder {
+	return m.recorder
+}
+
+// List mocks base method.
+func (m *MockCurrencyRollup) List(ctx context.Context, currencyID int, interval string, startTime, endTime int64) ([]model.CurrencyRollup, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, currencyID, interval, startTime, endTime)
+	ret0, _ := ret[0].([]model.CurrencyRollup)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockCurrencyRollupMockRecorder) List(ctx, currencyID, interval, startTime, endTime interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCurrencyRollup)(nil).List), ctx, currencyID, interval, startTime, endTime)
+}
+
+// Rebuild mocks base method.
+func (m *MockCurrencyRollup) Rebuild(ctx context.Context, currencyID int, interval string, startTime, endTime int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rebuild", ctx, currencyID, interval, startTime, endTime)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Rebuild indicates an expected call of Rebuild.
+func (mr *MockCurrencyRollupMockRecorder) Rebuild(ctx, currencyID, interval, startTime, endTime interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rebuild", reflect.TypeOf((*MockCurrencyRollup)(nil).Rebuild), ctx, currencyID, interval, startTime, endTime)
+}
+
+// MockFxRate is a mock of FxRate interface.
+type MockFxRate struct {
+	ctrl     *gomock.Controller
+	recorder *MockFxRateMockRecorder
+}
+
+// MockFxRateMockRecorder is the mock recorder for MockFxRate.
+type MockFxRateMockRecorder struct {
+	mock *MockFxRate
+}
+
+// NewMockFxRate creates a new mock instance.
+func NewMockFxRate(ctrl *gomock.Controller) *MockFxRate {
+	mock := &MockFxRate{ctrl: ctrl}
+	mock.recorder = &MockFxRateMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFxRate) EXPECT() *MockFxRateMockRecorder {
+	return m.recorder
+}
+
+// Around mocks base method.
+func (m *MockFxRate) Around(ctx context.Context, currency string, startTime, endTime int64) ([]model.FxRate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Around", ctx, currency, startTime, endTime)
+	ret0, _ := ret[0].([]model.FxRate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Around indicates an expected call of Around.
+func (mr *MockFxRateMockRecorder) Around(ctx, currency, startTime, endTime interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Around", reflect.TypeOf((*MockFxRate)(nil).Around), ctx, currency, startTime, endTime)
+}
+
+// Create mocks base method.
+func (m *MockFxRate) Create(ctx context.Context, rates ...model.FxRate) error {
 	m.ctrl.T.Helper()
 	varargs := []interface{}{ctx}
 	for _, a := range rates {
@@ -115,23 +370,181 @@ func (m *MockCurrencyPrice) Create(ctx context.Context, rates ...model.CurrencyP
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockCurrencyPriceMockRecorder) Create(ctx interface{}, rates ...interface{}) *gomock.Call {
+func (mr *MockFxRateMockRecorder) Create(ctx interface{}, rates ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	varargs := append([]interface{}{ctx}, rates...)
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCurrencyPrice)(nil).Create), varargs...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFxRate)(nil).Create), varargs...)
+}
+
+// MockCurrencyKline is a mock of CurrencyKline interface.
+type MockCurrencyKline struct {
+	ctrl     *gomock.Controller
+	recorder *MockCurrencyKlineMockRecorder
+}
+
+// MockCurrencyKlineMockRecorder is the mock recorder for MockCurrencyKline.
+type MockCurrencyKlineMockRecorder struct {
+	mock *MockCurrencyKline
+}
+
+// NewMockCurrencyKline creates a new mock instance.
+func NewMockCurrencyKline(ctrl *gomock.Controller) *MockCurrencyKline {
+	mock := &MockCurrencyKline{ctrl: ctrl}
+	mock.recorder = &MockCurrencyKlineMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCurrencyKline) EXPECT() *MockCurrencyKlineMockRecorder {
+	return m.recorder
+}
+
+// Count mocks base method.
+func (m *MockCurrencyKline) Count(ctx context.Context, currencyID int, interval string, startTime, endTime int64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Count", ctx, currencyID, interval, startTime, endTime)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Count indicates an expected call of Count.
+func (mr *MockCurrencyKlineMockRecorder) Count(ctx, currencyID, interval, startTime, endTime interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Count", reflect.TypeOf((*MockCurrencyKline)(nil).Count), ctx, currencyID, interval, startTime, endTime)
+}
+
+// Create mocks base method.
+func (m *MockCurrencyKline) Create(ctx context.Context, klines ...model.CurrencyKline) error {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range klines {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Create", varargs...)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockCurrencyKlineMockRecorder) Create(ctx interface{}, klines ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, klines...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCurrencyKline)(nil).Create), varargs...)
 }
 
 // List mocks base method.
-func (m *MockCurrencyPrice) List(ctx context.Context) ([]model.CurrencyPrice, error) {
+func (m *MockCurrencyKline) List(ctx context.Context, currencyID int, interval string, startTime, endTime int64, limit, offset int) ([]model.CurrencyKline, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List", ctx)
-	ret0, _ := ret[0].([]model.CurrencyPrice)
+	ret := m.ctrl.Call(m, "List", ctx, currencyID, interval, startTime, endTime, limit, offset)
+	ret0, _ := ret[0].([]model.CurrencyKline)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // List indicates an expected call of List.
-func (mr *MockCurrencyPriceMockRecorder) List(ctx interface{}) *gomock.Call {
+func (mr *MockCurrencyKlineMockRecorder) List(ctx, currencyID, interval, startTime, endTime, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCurrencyKline)(nil).List), ctx, currencyID, interval, startTime, endTime, limit, offset)
+}
+
+// MarkSynced mocks base method.
+func (m *MockCurrencyKline) MarkSynced(ctx context.Context, currencyID int, interval string, startTime, endTime int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkSynced", ctx, currencyID, interval, startTime, endTime)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkSynced indicates an expected call of MarkSynced.
+func (mr *MockCurrencyKlineMockRecorder) MarkSynced(ctx, currencyID, interval, startTime, endTime interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkSynced", reflect.TypeOf((*MockCurrencyKline)(nil).MarkSynced), ctx, currencyID, interval, startTime, endTime)
+}
+
+// Synced mocks base method.
+func (m *MockCurrencyKline) Synced(ctx context.Context, currencyID int, interval string, startTime, endTime int64) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Synced", ctx, currencyID, interval, startTime, endTime)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Synced indicates an expected call of Synced.
+func (mr *MockCurrencyKlineMockRecorder) Synced(ctx, currencyID, interval, startTime, endTime interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Synced", reflect.TypeOf((*MockCurrencyKline)(nil).Synced), ctx, currencyID, interval, startTime, endTime)
+}
+
+// MockKlineBackfill is a mock of KlineBackfill interface.
+type MockKlineBackfill struct {
+	ctrl     *gomock.Controller
+	recorder *MockKlineBackfillMockRecorder
+}
+
+// MockKlineBackfillMockRecorder is the mock recorder for MockKlineBackfill.
+type MockKlineBackfillMockRecorder struct {
+	mock *MockKlineBackfill
+}
+
+// NewMockKlineBackfill creates a new mock instance.
+func NewMockKlineBackfill(ctrl *gomock.Controller) *MockKlineBackfill {
+	mock := &MockKlineBackfill{ctrl: ctrl}
+	mock.recorder = &MockKlineBackfillMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKlineBackfill) EXPECT() *MockKlineBackfillMockRecorder {
+	return m.recorder
+}
+
+// ListByCurrency mocks base method.
+func (m *MockKlineBackfill) ListByCurrency(ctx context.Context, currencyID int) ([]model.KlineBackfill, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListByCurrency", ctx, currencyID)
+	ret0, _ := ret[0].([]model.KlineBackfill)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByCurrency indicates an expected call of ListByCurrency.
+func (mr *MockKlineBackfillMockRecorder) ListByCurrency(ctx, currencyID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByCurrency", reflect.TypeOf((*MockKlineBackfill)(nil).ListByCurrency), ctx, currencyID)
+}
+
+// ListByStatus mocks base method.
+func (m *MockKlineBackfill) ListByStatus(ctx context.Context, statuses ...string) ([]model.KlineBackfill, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range statuses {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListByStatus", varargs...)
+	ret0, _ := ret[0].([]model.KlineBackfill)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListByStatus indicates an expected call of ListByStatus.
+func (mr *MockKlineBackfillMockRecorder) ListByStatus(ctx interface{}, statuses ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, statuses...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListByStatus", reflect.TypeOf((*MockKlineBackfill)(nil).ListByStatus), varargs...)
+}
+
+// Save mocks base method.
+func (m *MockKlineBackfill) Save(ctx context.Context, backfill model.KlineBackfill) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", ctx, backfill)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockKlineBackfillMockRecorder) Save(ctx, backfill interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCurrencyPrice)(nil).List), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockKlineBackfill)(nil).Save), ctx, backfill)
 }
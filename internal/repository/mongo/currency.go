@@ -0,0 +1,209 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"gexabyte/internal/model"
+
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// currencyDocument keeps the inverted flag, so documents stored before the flag appeared are active.
+type currencyDocument struct {
+	ID       int    `bson:"_id"`
+	Symbol   string `bson:"symbol"`
+	Inactive bool   `bson:"inactive,omitempty"`
+
+	BaseAsset  string       `bson:"base_asset"`
+	QuoteAsset string       `bson:"quote_asset"`
+	Status     string       `bson:"status"`
+	TickSize   decimalValue `bson:"tick_size"`
+	StepSize   decimalValue `bson:"step_size"`
+}
+
+func newCurrencyDocument(id int, c model.Currency) currencyDocument {
+	return currencyDocument{
+		ID:     id,
+		Symbol: c.Symbol,
+
+		BaseAsset:  c.BaseAsset,
+		QuoteAsset: c.QuoteAsset,
+		Status:     c.Status,
+		TickSize:   decimalValue(c.TickSize),
+		StepSize:   decimalValue(c.StepSize),
+	}
+}
+
+func (d currencyDocument) model() model.Currency {
+	return model.Currency{
+		ID:     d.ID,
+		Symbol: d.Symbol,
+		Active: !d.Inactive,
+
+		BaseAsset:  d.BaseAsset,
+		QuoteAsset: d.QuoteAsset,
+		Status:     d.Status,
+		TickSize:   decimal.Decimal(d.TickSize),
+		StepSize:   decimal.Decimal(d.StepSize),
+	}
+}
+
+type CurrencyRepo struct {
+	db *mongo.Database
+}
+
+func NewCurrency(db *mongo.Database) *CurrencyRepo {
+	return &CurrencyRepo{
+		db: db,
+	}
+}
+
+func (r *CurrencyRepo) Create(ctx context.Context, currency model.Currency) (model.Currency, error) {
+	id, err := nextID(ctx, r.db, currencyCollection, 1)
+	if err != nil {
+		return model.Currency{}, err
+	}
+
+	doc := newCurrencyDocument(id, currency)
+	if _, err := r.db.Collection(currencyCollection).InsertOne(ctx, doc); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return model.Currency{}, model.ErrAlreadyExists
+		}
+		return model.Currency{}, err
+	}
+
+	return doc.model(), nil
+}
+
+func (r *CurrencyRepo) GetBySymbol(ctx context.Context, symbol string) (model.Currency, error) {
+	var doc currencyDocument
+	if err := r.db.Collection(currencyCollection).FindOne(ctx, bson.M{"symbol": symbol}).Decode(&doc); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return model.Currency{}, model.ErrNotFound
+		}
+		return model.Currency{}, err
+	}
+
+	return doc.model(), nil
+}
+
+func (r *CurrencyRepo) List(ctx context.Context) ([]model.Currency, error) {
+	cursor, err := r.db.Collection(currencyCollection).Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var items []model.Currency
+	for cursor.Next(ctx) {
+		var doc currencyDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+
+		items = append(items, doc.model())
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *CurrencyRepo) SetActive(ctx context.Context, symbol string, active bool) (model.Currency, error) {
+	res := r.db.Collection(currencyCollection).FindOneAndUpdate(ctx,
+		bson.M{"symbol": symbol},
+		bson.M{"$set": bson.M{"inactive": !active}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	)
+
+	var doc currencyDocument
+	if err := res.Decode(&doc); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return model.Currency{}, model.ErrNotFound
+		}
+		return model.Currency{}, err
+	}
+
+	return doc.model(), nil
+}
+
+// UpdateInfo overwrites exchange metadata of the currency with the symbol.
+func (r *CurrencyRepo) UpdateInfo(ctx context.Context, currency model.Currency) error {
+	doc := newCurrencyDocument(0, currency)
+	res, err := r.db.Collection(currencyCollection).UpdateOne(ctx,
+		bson.M{"symbol": currency.Symbol},
+		bson.M{"$set": bson.M{
+			"base_asset":  doc.BaseAsset,
+			"quote_asset": doc.QuoteAsset,
+			"status":      doc.Status,
+			"tick_size":   doc.TickSize,
+			"step_size":   doc.StepSize,
+		}},
+	)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return model.ErrNotFound
+	}
+
+	return nil
+}
+
+// Delete keeps the same rules as postgres foreign keys: prices restrict the delete,
+// candles, backfills and rollups are removed with the currency.
+func (r *CurrencyRepo) Delete(ctx context.Context, id int) error {
+	prices, err := r.db.Collection(currencyPriceCollection).CountDocuments(ctx, bson.M{"currency_id": id}, options.Count().SetLimit(1))
+	if err != nil {
+		return err
+	}
+	if prices > 0 {
+		return model.ErrHasPrices
+	}
+
+	res, err := r.db.Collection(currencyCollection).DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return model.ErrNotFound
+	}
+
+	for _, collection := range []string{currencyKlineCollection, currencyKlineSyncCollection, klineBackfillCollection, currencyRollupCollection} {
+		if _, err := r.db.Collection(collection).DeleteMany(ctx, bson.M{"currency_id": id}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// symbolIDs maps currency ids to symbols, unknown symbols are skipped.
+func symbolIDs(ctx context.Context, db *mongo.Database, symbols ...string) (map[int]string, error) {
+	return currencySymbols(ctx, db, bson.M{"symbol": bson.M{"$in": symbols}})
+}
+
+// currencySymbols maps ids to symbols of currencies matching the filter.
+func currencySymbols(ctx context.Context, db *mongo.Database, filter any) (map[int]string, error) {
+	cursor, err := db.Collection(currencyCollection).Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	ids := make(map[int]string)
+	for cursor.Next(ctx) {
+		var doc currencyDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+
+		ids[doc.ID] = doc.Symbol
+	}
+
+	return ids, cursor.Err()
+}
@@ -0,0 +1,139 @@
+package mongo
+
+import (
+	"context"
+	"gexabyte/internal/model"
+
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type currencyKlineDocument struct {
+	CurrencyID int    `bson:"currency_id"`
+	Interval   string `bson:"interval"`
+	OpenTime   int64  `bson:"open_time"`
+	CloseTime  int64  `bson:"close_time"`
+
+	OpenPrice  decimalValue `bson:"open_price"`
+	ClosePrice decimalValue `bson:"close_price"`
+	HighPrice  decimalValue `bson:"high_price"`
+	LowPrice   decimalValue `bson:"low_price"`
+}
+
+func (d currencyKlineDocument) model() model.CurrencyKline {
+	return model.CurrencyKline{
+		CurrencyID: d.CurrencyID,
+		Interval:   d.Interval,
+		OpenPrice:  decimal.Decimal(d.OpenPrice),
+		ClosePrice: decimal.Decimal(d.ClosePrice),
+		HighPrice:  decimal.Decimal(d.HighPrice),
+		LowPrice:   decimal.Decimal(d.LowPrice),
+		OpenTime:   d.OpenTime,
+		CloseTime:  d.CloseTime,
+	}
+}
+
+type CurrencyKlineRepo struct {
+	db *mongo.Database
+}
+
+func NewCurrencyKline(db *mongo.Database) *CurrencyKlineRepo {
+	return &CurrencyKlineRepo{
+		db: db,
+	}
+}
+
+func (r *CurrencyKlineRepo) Create(ctx context.Context, klines ...model.CurrencyKline) error {
+	if len(klines) == 0 {
+		return nil
+	}
+
+	writes := make([]mongo.WriteModel, 0, len(klines))
+	for _, k := range klines {
+		writes = append(writes, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"currency_id": k.CurrencyID, "interval": k.Interval, "open_time": k.OpenTime}).
+			SetReplacement(currencyKlineDocument{
+				CurrencyID: k.CurrencyID,
+				Interval:   k.Interval,
+				OpenTime:   k.OpenTime,
+				CloseTime:  k.CloseTime,
+				OpenPrice:  decimalValue(k.OpenPrice),
+				ClosePrice: decimalValue(k.ClosePrice),
+				HighPrice:  decimalValue(k.HighPrice),
+				LowPrice:   decimalValue(k.LowPrice),
+			}).
+			SetUpsert(true),
+		)
+	}
+
+	_, err := r.db.Collection(currencyKlineCollection).BulkWrite(ctx, writes)
+	return err
+}
+
+func (r *CurrencyKlineRepo) Count(ctx context.Context, currencyID int, interval string, startTime, endTime int64) (int, error) {
+	count, err := r.db.Collection(currencyKlineCollection).CountDocuments(ctx, klineFilter(currencyID, interval, startTime, endTime))
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+func (r *CurrencyKlineRepo) List(ctx context.Context, currencyID int, interval string, startTime, endTime int64, limit, offset int) ([]model.CurrencyKline, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "open_time", Value: 1}}).
+		SetSkip(int64(offset)).
+		SetLimit(int64(limit))
+
+	cursor, err := r.db.Collection(currencyKlineCollection).Find(ctx, klineFilter(currencyID, interval, startTime, endTime), opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var items []model.CurrencyKline
+	for cursor.Next(ctx) {
+		var doc currencyKlineDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+
+		items = append(items, doc.model())
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *CurrencyKlineRepo) MarkSynced(ctx context.Context, currencyID int, interval string, startTime, endTime int64) error {
+	doc := bson.M{"currency_id": currencyID, "interval": interval, "start_time": startTime, "end_time": endTime}
+
+	_, err := r.db.Collection(currencyKlineSyncCollection).UpdateOne(ctx, doc, bson.M{"$setOnInsert": doc}, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *CurrencyKlineRepo) Synced(ctx context.Context, currencyID int, interval string, startTime, endTime int64) (bool, error) {
+	count, err := r.db.Collection(currencyKlineSyncCollection).CountDocuments(ctx, bson.M{
+		"currency_id": currencyID,
+		"interval":    interval,
+		"start_time":  bson.M{"$lte": startTime},
+		"end_time":    bson.M{"$gte": endTime},
+	}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func klineFilter(currencyID int, interval string, startTime, endTime int64) bson.M {
+	return bson.M{
+		"currency_id": currencyID,
+		"interval":    interval,
+		"open_time":   bson.M{"$gte": startTime, "$lte": endTime},
+	}
+}
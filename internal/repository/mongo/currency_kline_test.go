@@ -0,0 +1,81 @@
+package mongo
+
+import (
+	"context"
+	"gexabyte/internal/model"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestCurrencyKline(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	in := []model.CurrencyKline{
+		{CurrencyID: 1, Interval: "1m", OpenTime: 0, CloseTime: 59999, OpenPrice: decimal.RequireFromString("1"), ClosePrice: decimal.RequireFromString("2"), HighPrice: decimal.RequireFromString("3"), LowPrice: decimal.RequireFromString("0.5")},
+	}
+
+	mt.Run("create", func(mt *mtest.T) {
+		repo := NewCurrencyKline(mt.DB)
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 0}))
+		assert.NoError(mt, repo.Create(context.Background(), in...))
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "some error"}))
+		assert.Error(mt, repo.Create(context.Background(), in...))
+	})
+
+	mt.Run("count", func(mt *mtest.T) {
+		repo := NewCurrencyKline(mt.DB)
+		ns := mt.DB.Name() + "." + currencyKlineCollection
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "n", Value: 2}}))
+		count, err := repo.Count(context.Background(), 1, "1m", 0, 60000)
+		assert.NoError(mt, err)
+		assert.Equal(mt, 2, count)
+	})
+
+	mt.Run("list", func(mt *mtest.T) {
+		repo := NewCurrencyKline(mt.DB)
+		ns := mt.DB.Name() + "." + currencyKlineCollection
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{
+			{Key: "currency_id", Value: 1},
+			{Key: "interval", Value: "1m"},
+			{Key: "open_time", Value: int64(0)},
+			{Key: "close_time", Value: int64(59999)},
+			{Key: "open_price", Value: 1.0},
+			{Key: "close_price", Value: 2.0},
+			{Key: "high_price", Value: 3.0},
+			{Key: "low_price", Value: 0.5},
+		}))
+		res, err := repo.List(context.Background(), 1, "1m", 0, 60000, 1, 0)
+		assert.NoError(mt, err)
+		assert.Equal(mt, in, res)
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "some error"}))
+		res, err = repo.List(context.Background(), 1, "1m", 0, 60000, 1, 0)
+		assert.Error(mt, err)
+		assert.Nil(mt, res)
+	})
+
+	mt.Run("synced", func(mt *mtest.T) {
+		repo := NewCurrencyKline(mt.DB)
+		ns := mt.DB.Name() + "." + currencyKlineSyncCollection
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 0}))
+		assert.NoError(mt, repo.MarkSynced(context.Background(), 1, "1m", 0, 60000))
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "n", Value: 1}}))
+		synced, err := repo.Synced(context.Background(), 1, "1m", 0, 60000)
+		assert.NoError(mt, err)
+		assert.True(mt, synced)
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "some error"}))
+		_, err = repo.Synced(context.Background(), 1, "1m", 0, 60000)
+		assert.Error(mt, err)
+	})
+}
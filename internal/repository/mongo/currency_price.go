@@ -0,0 +1,299 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"gexabyte/internal/model"
+	"sort"
+
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type currencyPriceDocument struct {
+	ID         int          `bson:"_id"`
+	CurrencyID int          `bson:"currency_id"`
+	Price      decimalValue `bson:"price"`
+	Time       int64        `bson:"time"`
+	Source     string       `bson:"source,omitempty"`
+}
+
+func (d currencyPriceDocument) dto(symbol string) model.CurrencyPriceDTO {
+	source := d.Source
+	if source == "" { // stored before sources were recorded
+		source = model.PriceSourceDefault
+	}
+
+	return model.CurrencyPriceDTO{
+		ID:     d.ID,
+		Symbol: symbol,
+		Price:  decimal.Decimal(d.Price),
+		Time:   d.Time,
+		Source: source,
+	}
+}
+
+type CurrencyPriceRepo struct {
+	db *mongo.Database
+}
+
+func NewCurrencyPrice(db *mongo.Database) *CurrencyPriceRepo {
+	return &CurrencyPriceRepo{
+		db: db,
+	}
+}
+
+func (r *CurrencyPriceRepo) Create(ctx context.Context, onConflict string, rates ...model.CurrencyPrice) (model.CreateCurrencyPricesRes, error) {
+	total := len(rates)
+	rates = model.DedupePrices(onConflict, rates...)
+	if len(rates) == 0 {
+		return model.CreateCurrencyPricesRes{}, nil
+	}
+
+	id, err := nextID(ctx, r.db, currencyPriceCollection, len(rates))
+	if err != nil {
+		return model.CreateCurrencyPricesRes{}, err
+	}
+
+	// upserts by the unique (currency_id, time) index, ids of skipped samples are just not used
+	writes := make([]mongo.WriteModel, 0, len(rates))
+	for i, rate := range rates {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"currency_id": rate.CurrencyID, "time": rate.Time}).
+			SetUpdate(bson.M{"$setOnInsert": bson.M{"_id": id + i, "price": decimalValue(rate.Price), "source": rate.Source}}).
+			SetUpsert(true))
+	}
+
+	bulk, err := r.db.Collection(currencyPriceCollection).BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	if err != nil {
+		return model.CreateCurrencyPricesRes{}, err
+	}
+
+	var res model.CreateCurrencyPricesRes
+	for i, rate := range rates {
+		if _, ok := bulk.UpsertedIDs[int64(i)]; ok {
+			rate.ID = id + i
+			res.Inserted = append(res.Inserted, rate)
+			continue
+		}
+
+		if onConflict != model.PriceConflictKeepLast {
+			continue
+		}
+
+		// with keep_last the stored price is replaced one by one to learn which of them changed
+		var doc currencyPriceDocument
+		err := r.db.Collection(currencyPriceCollection).FindOneAndUpdate(ctx,
+			bson.M{
+				"currency_id": rate.CurrencyID,
+				"time":        rate.Time,
+				"$or": bson.A{
+					bson.M{"price": bson.M{"$ne": decimalValue(rate.Price)}},
+					bson.M{"source": bson.M{"$ne": rate.Source}},
+				},
+			},
+			bson.M{"$set": bson.M{"price": decimalValue(rate.Price), "source": rate.Source}},
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&doc)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		}
+		if err != nil {
+			return model.CreateCurrencyPricesRes{}, err
+		}
+
+		rate.ID = doc.ID
+		res.Replaced = append(res.Replaced, rate)
+	}
+	res.Deduplicated = total - len(res.Inserted) - len(res.Replaced)
+
+	return res, nil
+}
+
+func (r *CurrencyPriceRepo) List(ctx context.Context, filter model.ListCurrencyPricesFilter) ([]model.CurrencyPriceDTO, error) {
+	query := bson.D{}
+
+	var (
+		ids map[int]string
+		err error
+	)
+	if len(filter.Symbols) > 0 {
+		ids, err = symbolIDs(ctx, r.db, filter.Symbols...)
+	} else {
+		ids, err = currencySymbols(ctx, r.db, bson.D{})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	if len(filter.Symbols) > 0 {
+		currencyIDs := make([]int, 0, len(ids))
+		for id := range ids {
+			currencyIDs = append(currencyIDs, id)
+		}
+		query = append(query, bson.E{Key: "currency_id", Value: bson.M{"$in": currencyIDs}})
+	}
+
+	timeRange := bson.M{}
+	if filter.StartTime > 0 {
+		timeRange["$gte"] = filter.StartTime
+	}
+	if filter.EndTime > 0 {
+		timeRange["$lte"] = filter.EndTime
+	}
+	if len(timeRange) > 0 {
+		query = append(query, bson.E{Key: "time", Value: timeRange})
+	}
+
+	cmp, order := "$gt", 1
+	if filter.Order == model.OrderDesc {
+		cmp, order = "$lt", -1
+	}
+	if filter.After != nil {
+		query = append(query, bson.E{Key: "$or", Value: bson.A{
+			bson.M{"time": bson.M{cmp: filter.After.Time}},
+			bson.M{"time": filter.After.Time, "_id": bson.M{cmp: filter.After.ID}},
+		}})
+	}
+
+	opts := options.Find().
+		SetSort(bson.D{{Key: "time", Value: order}, {Key: "_id", Value: order}}).
+		SetLimit(int64(filter.Limit))
+
+	cursor, err := r.db.Collection(currencyPriceCollection).Find(ctx, query, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var items []model.CurrencyPriceDTO
+	for cursor.Next(ctx) {
+		var doc currencyPriceDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+
+		items = append(items, doc.dto(ids[doc.CurrencyID]))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *CurrencyPriceRepo) Stat(ctx context.Context, startTime, endTime int64, symbols ...string) ([]model.GetCurrencyStat24HDTO, error) {
+	ids, err := symbolIDs(ctx, r.db, symbols...)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	currencyIDs := make([]int, 0, len(ids))
+	for id := range ids {
+		currencyIDs = append(currencyIDs, id)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"currency_id": bson.M{"$in": currencyIDs},
+			"time":        bson.M{"$gte": startTime, "$lte": endTime},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "time", Value: 1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$currency_id"},
+			{Key: "open_price", Value: bson.M{"$first": "$price"}},
+			{Key: "last_price", Value: bson.M{"$last": "$price"}},
+			{Key: "high_price", Value: bson.M{"$max": "$price"}},
+			{Key: "low_price", Value: bson.M{"$min": "$price"}},
+			{Key: "avg_price", Value: bson.M{"$avg": "$price"}},
+			{Key: "count", Value: bson.M{"$sum": 1}},
+			{Key: "open_time", Value: bson.M{"$min": "$time"}},
+			{Key: "close_time", Value: bson.M{"$max": "$time"}},
+		}}},
+	}
+
+	cursor, err := r.db.Collection(currencyPriceCollection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var items []model.GetCurrencyStat24HDTO
+	for cursor.Next(ctx) {
+		var doc struct {
+			CurrencyID int          `bson:"_id"`
+			OpenPrice  decimalValue `bson:"open_price"`
+			LastPrice  decimalValue `bson:"last_price"`
+			HighPrice  decimalValue `bson:"high_price"`
+			LowPrice   decimalValue `bson:"low_price"`
+			AvgPrice   decimalValue `bson:"avg_price"`
+			Count      int          `bson:"count"`
+			OpenTime   int64        `bson:"open_time"`
+			CloseTime  int64        `bson:"close_time"`
+		}
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+
+		items = append(items, model.GetCurrencyStat24HDTO{
+			Symbol:             ids[doc.CurrencyID],
+			Source:             model.StatSourceLocal,
+			OpenPrice:          decimal.Decimal(doc.OpenPrice),
+			LastPrice:          decimal.Decimal(doc.LastPrice),
+			HighPrice:          decimal.Decimal(doc.HighPrice),
+			LowPrice:           decimal.Decimal(doc.LowPrice),
+			AvgPrice:           decimal.Decimal(doc.AvgPrice),
+			PriceChangePercent: model.PriceChangePercent(decimal.Decimal(doc.OpenPrice), decimal.Decimal(doc.LastPrice)),
+			OpenTime:           doc.OpenTime,
+			CloseTime:          doc.CloseTime,
+			Count:              doc.Count,
+		})
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(items, func(i, j int) bool { return items[i].Symbol < items[j].Symbol })
+
+	return items, nil
+}
+
+func (r *CurrencyPriceRepo) DeleteByCurrency(ctx context.Context, currencyID int, limit int) (int, error) {
+	cursor, err := r.db.Collection(currencyPriceCollection).Find(ctx,
+		bson.M{"currency_id": currencyID},
+		options.Find().SetProjection(bson.M{"_id": 1}).SetLimit(int64(limit)),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var docs []struct {
+		ID int `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return 0, err
+	}
+	if len(docs) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int, 0, len(docs))
+	for _, doc := range docs {
+		ids = append(ids, doc.ID)
+	}
+
+	res, err := r.db.Collection(currencyPriceCollection).DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(res.DeletedCount), nil
+}
@@ -0,0 +1,28 @@
+package mongo
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CurrencyPricePartitionRepo keeps prices in one collection, mongo reuses space of deleted documents.
+type CurrencyPricePartitionRepo struct {
+	db *mongo.Database
+}
+
+func NewCurrencyPricePartition(db *mongo.Database) *CurrencyPricePartitionRepo {
+	return &CurrencyPricePartitionRepo{
+		db: db,
+	}
+}
+
+func (r *CurrencyPricePartitionRepo) Ensure(ctx context.Context, startTime, endTime int64) error {
+	return nil
+}
+
+func (r *CurrencyPricePartitionRepo) DropBefore(ctx context.Context, before int64, detach bool) error {
+	_, err := r.db.Collection(currencyPriceCollection).DeleteMany(ctx, bson.M{"time": bson.M{"$lt": before}})
+	return err
+}
@@ -0,0 +1,27 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestCurrencyPricePartition(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("drop before", func(mt *mtest.T) {
+		repo := NewCurrencyPricePartition(mt.DB)
+
+		// nothing to create in mongo, so no requests
+		assert.NoError(mt, repo.Ensure(context.Background(), 1, 2))
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}))
+		assert.NoError(mt, repo.DropBefore(context.Background(), 1, false))
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "some error"}))
+		assert.Error(mt, repo.DropBefore(context.Background(), 1, false))
+	})
+}
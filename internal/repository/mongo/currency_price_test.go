@@ -0,0 +1,155 @@
+package mongo
+
+import (
+	"context"
+	"gexabyte/internal/model"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestCurrencyPrice(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("create", func(mt *mtest.T) {
+		repo := NewCurrencyPrice(mt.DB)
+
+		in := []model.CurrencyPrice{
+			{CurrencyID: 1, Price: decimal.RequireFromString("1"), Time: 1},
+			{CurrencyID: 2, Price: decimal.RequireFromString("2"), Time: 1},
+		}
+
+		// the second sample is already stored, only the first one is upserted
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{{Key: "_id", Value: currencyPriceCollection}, {Key: "seq", Value: 2}}}),
+			mtest.CreateSuccessResponse(
+				bson.E{Key: "n", Value: 2},
+				bson.E{Key: "nModified", Value: 0},
+				bson.E{Key: "upserted", Value: bson.A{bson.D{{Key: "index", Value: 0}, {Key: "_id", Value: 1}}}},
+			),
+		)
+		res, err := repo.Create(context.Background(), model.PriceConflictKeepFirst, append(in, in[0])...)
+		assert.NoError(mt, err)
+		assert.Equal(mt, model.CreateCurrencyPricesRes{
+			Inserted:     []model.CurrencyPrice{{ID: 1, CurrencyID: 1, Price: decimal.RequireFromString("1"), Time: 1}},
+			Deduplicated: 2,
+		}, res)
+
+		// keep_last replaces the stored second sample, the stored id is reported
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{{Key: "_id", Value: currencyPriceCollection}, {Key: "seq", Value: 4}}}),
+			mtest.CreateSuccessResponse(
+				bson.E{Key: "n", Value: 2},
+				bson.E{Key: "nModified", Value: 0},
+				bson.E{Key: "upserted", Value: bson.A{bson.D{{Key: "index", Value: 0}, {Key: "_id", Value: 3}}}},
+			),
+			mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{
+				{Key: "_id", Value: 2}, {Key: "currency_id", Value: 2}, {Key: "price", Value: 2.0}, {Key: "time", Value: int64(1)},
+			}}),
+		)
+		res, err = repo.Create(context.Background(), model.PriceConflictKeepLast, in...)
+		assert.NoError(mt, err)
+		assert.Equal(mt, model.CreateCurrencyPricesRes{
+			Inserted: []model.CurrencyPrice{{ID: 3, CurrencyID: 1, Price: decimal.RequireFromString("1"), Time: 1}},
+			Replaced: []model.CurrencyPrice{{ID: 2, CurrencyID: 2, Price: decimal.RequireFromString("2"), Time: 1}},
+		}, res)
+
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{{Key: "_id", Value: currencyPriceCollection}, {Key: "seq", Value: 6}}}),
+			mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 1, Code: 1, Message: "some error"}),
+		)
+		_, err = repo.Create(context.Background(), model.PriceConflictKeepLast, in...)
+		assert.Error(mt, err)
+
+		// nothing to insert, no requests
+		res, err = repo.Create(context.Background(), model.PriceConflictKeepFirst)
+		assert.NoError(mt, err)
+		assert.Empty(mt, res.Inserted)
+	})
+
+	mt.Run("list", func(mt *mtest.T) {
+		repo := NewCurrencyPrice(mt.DB)
+		currencyNS := mt.DB.Name() + "." + currencyCollection
+		priceNS := mt.DB.Name() + "." + currencyPriceCollection
+
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, currencyNS, mtest.FirstBatch, bson.D{{Key: "_id", Value: 1}, {Key: "symbol", Value: "BTCUSDT"}}),
+			mtest.CreateCursorResponse(0, priceNS, mtest.FirstBatch,
+				bson.D{{Key: "_id", Value: 2}, {Key: "currency_id", Value: 1}, {Key: "price", Value: 10.5}, {Key: "time", Value: int64(2)}, {Key: "source", Value: "kraken"}},
+				// stored before sources were recorded
+				bson.D{{Key: "_id", Value: 1}, {Key: "currency_id", Value: 1}, {Key: "price", Value: 10.4}, {Key: "time", Value: int64(1)}},
+			),
+		)
+		res, err := repo.List(context.Background(), model.ListCurrencyPricesFilter{
+			Symbols:   []string{"BTCUSDT"},
+			StartTime: 1,
+			EndTime:   5,
+			Order:     model.OrderDesc,
+			Limit:     10,
+			After:     &model.PriceCursor{Time: 4, ID: 2},
+		})
+		assert.NoError(mt, err)
+		assert.Equal(mt, []model.CurrencyPriceDTO{
+			{ID: 2, Symbol: "BTCUSDT", Price: decimal.RequireFromString("10.5"), Time: 2, Source: "kraken"},
+			{ID: 1, Symbol: "BTCUSDT", Price: decimal.RequireFromString("10.4"), Time: 1, Source: model.PriceSourceDefault},
+		}, res)
+
+		// untracked symbols give no prices
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, currencyNS, mtest.FirstBatch))
+		res, err = repo.List(context.Background(), model.ListCurrencyPricesFilter{Symbols: []string{"UNKNOWN"}, Limit: 10})
+		assert.NoError(mt, err)
+		assert.Empty(mt, res)
+
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, currencyNS, mtest.FirstBatch, bson.D{{Key: "_id", Value: 1}, {Key: "symbol", Value: "BTCUSDT"}}),
+			mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "some error"}),
+		)
+		res, err = repo.List(context.Background(), model.ListCurrencyPricesFilter{Limit: 10})
+		assert.Error(mt, err)
+		assert.Nil(mt, res)
+	})
+
+	mt.Run("stat", func(mt *mtest.T) {
+		repo := NewCurrencyPrice(mt.DB)
+		currencyNS := mt.DB.Name() + "." + currencyCollection
+		priceNS := mt.DB.Name() + "." + currencyPriceCollection
+
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, currencyNS, mtest.FirstBatch, bson.D{{Key: "_id", Value: 1}, {Key: "symbol", Value: "BTCUSDT"}}),
+			mtest.CreateCursorResponse(0, priceNS, mtest.FirstBatch, bson.D{
+				{Key: "_id", Value: 1},
+				{Key: "open_price", Value: 10.0},
+				{Key: "last_price", Value: 12.0},
+				{Key: "high_price", Value: 13.0},
+				{Key: "low_price", Value: 9.0},
+				{Key: "avg_price", Value: 11.0},
+				{Key: "count", Value: 3},
+				{Key: "open_time", Value: int64(1)},
+				{Key: "close_time", Value: int64(2)},
+			}),
+		)
+		res, err := repo.Stat(context.Background(), 1, 2, "BTCUSDT")
+		assert.NoError(mt, err)
+		assert.Len(mt, res, 1)
+		assert.Equal(mt, "BTCUSDT", res[0].Symbol)
+		assert.Equal(mt, model.StatSourceLocal, res[0].Source)
+		assert.Equal(mt, "10", res[0].OpenPrice.String())
+		assert.Equal(mt, "12", res[0].LastPrice.String())
+		assert.Equal(mt, "13", res[0].HighPrice.String())
+		assert.Equal(mt, "9", res[0].LowPrice.String())
+		assert.Equal(mt, "11", res[0].AvgPrice.String())
+		assert.Equal(mt, "20", res[0].PriceChangePercent.String())
+		assert.Equal(mt, 3, res[0].Count)
+		assert.Equal(mt, int64(1), res[0].OpenTime)
+		assert.Equal(mt, int64(2), res[0].CloseTime)
+
+		// untracked symbols give empty stat
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, currencyNS, mtest.FirstBatch))
+		res, err = repo.Stat(context.Background(), 1, 2, "UNKNOWN")
+		assert.NoError(mt, err)
+		assert.Empty(mt, res)
+	})
+}
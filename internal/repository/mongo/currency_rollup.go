@@ -0,0 +1,129 @@
+package mongo
+
+import (
+	"context"
+	"gexabyte/internal/model"
+
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type currencyRollupDocument struct {
+	CurrencyID int    `bson:"currency_id"`
+	Interval   string `bson:"interval"`
+	BucketTime int64  `bson:"bucket_time"`
+
+	OpenPrice  decimalValue `bson:"open_price"`
+	OpenTime   int64        `bson:"open_time"`
+	ClosePrice decimalValue `bson:"close_price"`
+	CloseTime  int64        `bson:"close_time"`
+	HighPrice  decimalValue `bson:"high_price"`
+	LowPrice   decimalValue `bson:"low_price"`
+	SumPrice   decimalValue `bson:"sum_price"`
+	Count      int          `bson:"count"`
+}
+
+func (d currencyRollupDocument) model() model.CurrencyRollup {
+	return model.CurrencyRollup{
+		CurrencyID: d.CurrencyID,
+		Interval:   d.Interval,
+		BucketTime: d.BucketTime,
+		OpenPrice:  decimal.Decimal(d.OpenPrice),
+		OpenTime:   d.OpenTime,
+		ClosePrice: decimal.Decimal(d.ClosePrice),
+		CloseTime:  d.CloseTime,
+		HighPrice:  decimal.Decimal(d.HighPrice),
+		LowPrice:   decimal.Decimal(d.LowPrice),
+		SumPrice:   decimal.Decimal(d.SumPrice),
+		Count:      d.Count,
+	}
+}
+
+type CurrencyRollupRepo struct {
+	db *mongo.Database
+}
+
+func NewCurrencyRollup(db *mongo.Database) *CurrencyRollupRepo {
+	return &CurrencyRollupRepo{
+		db: db,
+	}
+}
+
+// Rebuild groups prices on the server and replaces buckets by the summaries.
+func (r *CurrencyRollupRepo) Rebuild(ctx context.Context, currencyID int, interval string, startTime, endTime int64) error {
+	startTime, endTime = model.RollupRange(interval, startTime, endTime)
+	size := model.KlineInterval.GetDuration(interval).Milliseconds()
+
+	cursor, err := r.db.Collection(currencyPriceCollection).Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"currency_id": currencyID, "time": bson.M{"$gte": startTime, "$lte": endTime}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "time", Value: 1}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.M{"$subtract": bson.A{"$time", bson.M{"$mod": bson.A{"$time", size}}}}},
+			{Key: "open_price", Value: bson.M{"$first": "$price"}},
+			{Key: "open_time", Value: bson.M{"$min": "$time"}},
+			{Key: "close_price", Value: bson.M{"$last": "$price"}},
+			{Key: "close_time", Value: bson.M{"$max": "$time"}},
+			{Key: "high_price", Value: bson.M{"$max": "$price"}},
+			{Key: "low_price", Value: bson.M{"$min": "$price"}},
+			// documents written before prices became decimal hold doubles
+			{Key: "sum_price", Value: bson.M{"$sum": bson.M{"$toDecimal": "$price"}}},
+			{Key: "count", Value: bson.M{"$sum": 1}},
+		}}},
+		{{Key: "$set", Value: bson.M{"currency_id": currencyID, "interval": interval, "bucket_time": "$_id"}}},
+		{{Key: "$unset", Value: "_id"}},
+	})
+	if err != nil {
+		return err
+	}
+
+	var buckets []currencyRollupDocument
+	if err := cursor.All(ctx, &buckets); err != nil {
+		return err
+	}
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	writes := make([]mongo.WriteModel, 0, len(buckets))
+	for _, doc := range buckets {
+		writes = append(writes, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"currency_id": doc.CurrencyID, "interval": doc.Interval, "bucket_time": doc.BucketTime}).
+			SetReplacement(doc).
+			SetUpsert(true),
+		)
+	}
+
+	_, err = r.db.Collection(currencyRollupCollection).BulkWrite(ctx, writes)
+	return err
+}
+
+func (r *CurrencyRollupRepo) List(ctx context.Context, currencyID int, interval string, startTime, endTime int64) ([]model.CurrencyRollup, error) {
+	filter := bson.M{
+		"currency_id": currencyID,
+		"interval":    interval,
+		"bucket_time": bson.M{"$gte": startTime, "$lte": endTime},
+	}
+
+	cursor, err := r.db.Collection(currencyRollupCollection).Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "bucket_time", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var items []model.CurrencyRollup
+	for cursor.Next(ctx) {
+		var doc currencyRollupDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+
+		items = append(items, doc.model())
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
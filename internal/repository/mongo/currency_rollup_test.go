@@ -0,0 +1,88 @@
+package mongo
+
+import (
+	"context"
+	"gexabyte/internal/model"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestCurrencyRollup(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	in := model.CurrencyRollup{
+		CurrencyID: 1,
+		Interval:   "1h",
+		BucketTime: 0,
+		OpenPrice:  decimal.RequireFromString("1"),
+		ClosePrice: decimal.RequireFromString("2"),
+		HighPrice:  decimal.RequireFromString("3"),
+		LowPrice:   decimal.RequireFromString("0.5"),
+		SumPrice:   decimal.RequireFromString("6"),
+		Count:      4,
+		OpenTime:   10,
+		CloseTime:  20,
+	}
+
+	mt.Run("rebuild", func(mt *mtest.T) {
+		repo := NewCurrencyRollup(mt.DB)
+		ns := mt.DB.Name() + "." + currencyPriceCollection
+
+		// buckets grouped from prices, then replaced
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{
+				{Key: "currency_id", Value: 1},
+				{Key: "interval", Value: "1h"},
+				{Key: "bucket_time", Value: int64(0)},
+				{Key: "open_price", Value: 1.0},
+				{Key: "open_time", Value: int64(10)},
+				{Key: "close_price", Value: 2.0},
+				{Key: "close_time", Value: int64(20)},
+				{Key: "high_price", Value: 3.0},
+				{Key: "low_price", Value: 0.5},
+				{Key: "sum_price", Value: 6.0},
+				{Key: "count", Value: 4},
+			}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}),
+		)
+		assert.NoError(mt, repo.Rebuild(context.Background(), in.CurrencyID, in.Interval, 10, 20))
+
+		// no prices, nothing to replace
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch))
+		assert.NoError(mt, repo.Rebuild(context.Background(), 1, "1h", 10, 20))
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "some error"}))
+		assert.Error(mt, repo.Rebuild(context.Background(), 1, "1h", 10, 20))
+	})
+
+	mt.Run("list", func(mt *mtest.T) {
+		repo := NewCurrencyRollup(mt.DB)
+		ns := mt.DB.Name() + "." + currencyRollupCollection
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{
+			{Key: "currency_id", Value: 1},
+			{Key: "interval", Value: "1h"},
+			{Key: "bucket_time", Value: int64(0)},
+			{Key: "open_price", Value: 1.0},
+			{Key: "open_time", Value: int64(10)},
+			{Key: "close_price", Value: 2.0},
+			{Key: "close_time", Value: int64(20)},
+			{Key: "high_price", Value: 3.0},
+			{Key: "low_price", Value: 0.5},
+			{Key: "sum_price", Value: 6.0},
+			{Key: "count", Value: 4},
+		}))
+		res, err := repo.List(context.Background(), 1, "1h", 0, 3600000)
+		assert.NoError(mt, err)
+		assert.Equal(mt, []model.CurrencyRollup{in}, res)
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "some error"}))
+		res, err = repo.List(context.Background(), 1, "1h", 0, 3600000)
+		assert.Error(mt, err)
+		assert.Nil(mt, res)
+	})
+}
@@ -0,0 +1,129 @@
+package mongo
+
+import (
+	"context"
+	"gexabyte/internal/model"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestCurrency(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("create", func(mt *mtest.T) {
+		repo := NewCurrency(mt.DB)
+
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{{Key: "_id", Value: currencyCollection}, {Key: "seq", Value: 5}}}),
+			mtest.CreateSuccessResponse(),
+		)
+		info := model.Currency{
+			Symbol:     "BTCUSDT",
+			BaseAsset:  "BTC",
+			QuoteAsset: "USDT",
+			Status:     model.SymbolStatusTrading,
+			TickSize:   decimal.RequireFromString("0.01"),
+			StepSize:   decimal.RequireFromString("0.00001"),
+		}
+		res, err := repo.Create(context.Background(), info)
+		assert.NoError(mt, err)
+		info.ID, info.Active = 5, true
+		assert.Equal(mt, info, res)
+
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{{Key: "_id", Value: currencyCollection}, {Key: "seq", Value: 6}}}),
+			mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key error"}),
+		)
+		_, err = repo.Create(context.Background(), model.Currency{Symbol: "BTCUSDT"})
+		assert.ErrorIs(mt, err, model.ErrAlreadyExists)
+	})
+
+	mt.Run("get by symbol", func(mt *mtest.T) {
+		repo := NewCurrency(mt.DB)
+		ns := mt.DB.Name() + "." + currencyCollection
+
+		// documents stored before the flag are active
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "_id", Value: 1}, {Key: "symbol", Value: "BTCUSDT"}}))
+		res, err := repo.GetBySymbol(context.Background(), "BTCUSDT")
+		assert.NoError(mt, err)
+		assert.Equal(mt, model.Currency{ID: 1, Symbol: "BTCUSDT", Active: true}, res)
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch))
+		_, err = repo.GetBySymbol(context.Background(), "BTCUSDT")
+		assert.ErrorIs(mt, err, model.ErrNotFound)
+	})
+
+	mt.Run("list", func(mt *mtest.T) {
+		repo := NewCurrency(mt.DB)
+		ns := mt.DB.Name() + "." + currencyCollection
+
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(1, ns, mtest.FirstBatch, bson.D{{Key: "_id", Value: 1}, {Key: "symbol", Value: "BTCUSDT"}}),
+			mtest.CreateCursorResponse(0, ns, mtest.NextBatch, bson.D{{Key: "_id", Value: 2}, {Key: "symbol", Value: "ETHUSDT"}, {Key: "inactive", Value: true}}),
+		)
+		res, err := repo.List(context.Background())
+		assert.NoError(mt, err)
+		assert.Equal(mt, []model.Currency{{ID: 1, Symbol: "BTCUSDT", Active: true}, {ID: 2, Symbol: "ETHUSDT", Active: false}}, res)
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "some error"}))
+		res, err = repo.List(context.Background())
+		assert.Error(mt, err)
+		assert.Nil(mt, res)
+	})
+
+	mt.Run("set active", func(mt *mtest.T) {
+		repo := NewCurrency(mt.DB)
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{
+			{Key: "_id", Value: 1}, {Key: "symbol", Value: "BTCUSDT"}, {Key: "inactive", Value: true},
+		}}))
+		res, err := repo.SetActive(context.Background(), "BTCUSDT", false)
+		assert.NoError(mt, err)
+		assert.Equal(mt, model.Currency{ID: 1, Symbol: "BTCUSDT", Active: false}, res)
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "value", Value: nil}))
+		_, err = repo.SetActive(context.Background(), "BTCUSDT", true)
+		assert.ErrorIs(mt, err, model.ErrNotFound)
+	})
+
+	mt.Run("update info", func(mt *mtest.T) {
+		repo := NewCurrency(mt.DB)
+		info := model.Currency{Symbol: "BTCUSDT", BaseAsset: "BTC", QuoteAsset: "USDT", Status: "BREAK"}
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+		assert.NoError(mt, repo.UpdateInfo(context.Background(), info))
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+		assert.ErrorIs(mt, repo.UpdateInfo(context.Background(), info), model.ErrNotFound)
+	})
+
+	mt.Run("delete", func(mt *mtest.T) {
+		repo := NewCurrency(mt.DB)
+		priceNS := mt.DB.Name() + "." + currencyPriceCollection
+
+		count := func(n int32) bson.D {
+			return mtest.CreateCursorResponse(0, priceNS, mtest.FirstBatch, bson.D{{Key: "n", Value: n}})
+		}
+
+		mt.AddMockResponses(count(1))
+		assert.ErrorIs(mt, repo.Delete(context.Background(), 1), model.ErrHasPrices)
+
+		mt.AddMockResponses(count(0), mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}))
+		assert.ErrorIs(mt, repo.Delete(context.Background(), 1), model.ErrNotFound)
+
+		// the currency, then its candles, synced ranges, backfills and rollups
+		mt.AddMockResponses(
+			count(0),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 3}),
+		)
+		assert.NoError(mt, repo.Delete(context.Background(), 1))
+	})
+}
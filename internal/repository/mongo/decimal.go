@@ -0,0 +1,47 @@
+package mongo
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// decimalValue is stored as Decimal128, so prices keep exactly the digits quoted by the exchange.
+type decimalValue decimal.Decimal
+
+func (v decimalValue) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	d := decimal.Decimal(v)
+
+	res, ok := primitive.ParseDecimal128FromBigInt(d.Coefficient(), int(d.Exponent()))
+	if !ok {
+		return 0, nil, fmt.Errorf("decimal %s does not fit decimal128", d)
+	}
+
+	return bson.MarshalValue(res)
+}
+
+func (v *decimalValue) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	raw := bson.RawValue{Type: t, Value: data}
+
+	switch t {
+	case bsontype.Decimal128:
+		coefficient, exponent, err := raw.Decimal128().BigInt()
+		if err != nil {
+			return err
+		}
+		*v = decimalValue(decimal.NewFromBigInt(coefficient, int32(exponent)))
+	case bsontype.Double: // documents written before prices became decimal
+		*v = decimalValue(decimal.NewFromFloat(raw.Double()))
+	case bsontype.Int32:
+		*v = decimalValue(decimal.NewFromInt32(raw.Int32()))
+	case bsontype.Int64:
+		*v = decimalValue(decimal.NewFromInt(raw.Int64()))
+	default:
+		return fmt.Errorf("cannot decode %s into decimal", t)
+	}
+
+	return nil
+}
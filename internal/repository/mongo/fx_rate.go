@@ -0,0 +1,113 @@
+package mongo
+
+import (
+	"context"
+	"gexabyte/internal/model"
+
+	"github.com/shopspring/decimal"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type fxRateDocument struct {
+	ID       int          `bson:"_id"`
+	Currency string       `bson:"currency"`
+	Rate     decimalValue `bson:"rate"`
+	Time     int64        `bson:"time"`
+	Source   string       `bson:"source"`
+}
+
+func (d fxRateDocument) model() model.FxRate {
+	return model.FxRate{
+		ID:       d.ID,
+		Currency: d.Currency,
+		Rate:     decimal.Decimal(d.Rate),
+		Time:     d.Time,
+		Source:   d.Source,
+	}
+}
+
+type FxRateRepo struct {
+	db *mongo.Database
+}
+
+func NewFxRate(db *mongo.Database) *FxRateRepo {
+	return &FxRateRepo{
+		db: db,
+	}
+}
+
+// Create upserts by the unique (currency, time) index, ids of skipped rates are just not used.
+func (r *FxRateRepo) Create(ctx context.Context, rates ...model.FxRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+
+	id, err := nextID(ctx, r.db, fxRateCollection, len(rates))
+	if err != nil {
+		return err
+	}
+
+	writes := make([]mongo.WriteModel, 0, len(rates))
+	for i, rate := range rates {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"currency": rate.Currency, "time": rate.Time}).
+			SetUpdate(bson.M{"$setOnInsert": bson.M{"_id": id + i, "rate": decimalValue(rate.Rate), "source": rate.Source}}).
+			SetUpsert(true))
+	}
+
+	_, err = r.db.Collection(fxRateCollection).BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+func (r *FxRateRepo) Around(ctx context.Context, currency string, startTime, endTime int64) ([]model.FxRate, error) {
+	before, err := r.find(ctx,
+		bson.M{"currency": currency, "time": bson.M{"$lt": startTime}},
+		options.Find().SetSort(bson.D{{Key: "time", Value: -1}}).SetLimit(1),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	within, err := r.find(ctx,
+		bson.M{"currency": currency, "time": bson.M{"$gte": startTime, "$lte": endTime}},
+		options.Find().SetSort(bson.D{{Key: "time", Value: 1}}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := r.find(ctx,
+		bson.M{"currency": currency, "time": bson.M{"$gt": endTime}},
+		options.Find().SetSort(bson.D{{Key: "time", Value: 1}}).SetLimit(1),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(append(before, within...), after...), nil
+}
+
+func (r *FxRateRepo) find(ctx context.Context, filter bson.M, opts *options.FindOptions) ([]model.FxRate, error) {
+	cursor, err := r.db.Collection(fxRateCollection).Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var items []model.FxRate
+	for cursor.Next(ctx) {
+		var doc fxRateDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+
+		items = append(items, doc.model())
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
@@ -0,0 +1,63 @@
+package mongo
+
+import (
+	"context"
+	"gexabyte/internal/model"
+	"testing"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestFxRate(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("create", func(mt *mtest.T) {
+		repo := NewFxRate(mt.DB)
+
+		in := model.FxRate{Currency: "EUR", Rate: decimal.RequireFromString("0.92"), Time: 1000, Source: "static"}
+
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{{Key: "_id", Value: fxRateCollection}, {Key: "seq", Value: 1}}}),
+			mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 0}),
+		)
+		assert.NoError(mt, repo.Create(context.Background(), in))
+
+		mt.AddMockResponses(
+			mtest.CreateSuccessResponse(bson.E{Key: "value", Value: bson.D{{Key: "_id", Value: fxRateCollection}, {Key: "seq", Value: 2}}}),
+			mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 1, Message: "some error"}),
+		)
+		assert.Error(mt, repo.Create(context.Background(), in))
+
+		// nothing to store, no requests
+		assert.NoError(mt, repo.Create(context.Background()))
+	})
+
+	mt.Run("around", func(mt *mtest.T) {
+		repo := NewFxRate(mt.DB)
+		ns := mt.DB.Name() + "." + fxRateCollection
+
+		rate := func(id int, rate float64, time int64) bson.D {
+			return bson.D{{Key: "_id", Value: id}, {Key: "currency", Value: "EUR"}, {Key: "rate", Value: rate}, {Key: "time", Value: time}, {Key: "source", Value: "static"}}
+		}
+
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, rate(1, 0.9, 1000)),
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, rate(2, 0.91, 2500)),
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch),
+		)
+		res, err := repo.Around(context.Background(), "EUR", 2000, 3000)
+		assert.NoError(mt, err)
+		assert.Equal(mt, []model.FxRate{
+			{ID: 1, Currency: "EUR", Rate: decimal.RequireFromString("0.9"), Time: 1000, Source: "static"},
+			{ID: 2, Currency: "EUR", Rate: decimal.RequireFromString("0.91"), Time: 2500, Source: "static"},
+		}, res)
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "some error"}))
+		res, err = repo.Around(context.Background(), "EUR", 2000, 3000)
+		assert.Error(mt, err)
+		assert.Nil(mt, res)
+	})
+}
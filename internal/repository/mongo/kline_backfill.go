@@ -0,0 +1,113 @@
+package mongo
+
+import (
+	"context"
+	"gexabyte/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type klineBackfillDocument struct {
+	CurrencyID int    `bson:"currency_id"`
+	Symbol     string `bson:"symbol,omitempty"` // joined from currency, not stored
+	Interval   string `bson:"interval"`
+
+	StartTime int64 `bson:"start_time"`
+	EndTime   int64 `bson:"end_time"`
+	Cursor    int64 `bson:"cursor"`
+
+	Status    string `bson:"status"`
+	Error     string `bson:"error"`
+	Attempts  int    `bson:"attempts"`
+	UpdatedAt int64  `bson:"updated_at"`
+}
+
+func (d klineBackfillDocument) model() model.KlineBackfill {
+	return model.KlineBackfill{
+		CurrencyID: d.CurrencyID,
+		Symbol:     d.Symbol,
+		Interval:   d.Interval,
+		StartTime:  d.StartTime,
+		EndTime:    d.EndTime,
+		Cursor:     d.Cursor,
+		Status:     d.Status,
+		Error:      d.Error,
+		Attempts:   d.Attempts,
+		UpdatedAt:  d.UpdatedAt,
+	}
+}
+
+type KlineBackfillRepo struct {
+	db *mongo.Database
+}
+
+func NewKlineBackfill(db *mongo.Database) *KlineBackfillRepo {
+	return &KlineBackfillRepo{
+		db: db,
+	}
+}
+
+func (r *KlineBackfillRepo) Save(ctx context.Context, backfill model.KlineBackfill) error {
+	_, err := r.db.Collection(klineBackfillCollection).ReplaceOne(ctx,
+		bson.M{"currency_id": backfill.CurrencyID, "interval": backfill.Interval},
+		klineBackfillDocument{
+			CurrencyID: backfill.CurrencyID,
+			Interval:   backfill.Interval,
+			StartTime:  backfill.StartTime,
+			EndTime:    backfill.EndTime,
+			Cursor:     backfill.Cursor,
+			Status:     backfill.Status,
+			Error:      backfill.Error,
+			Attempts:   backfill.Attempts,
+			UpdatedAt:  backfill.UpdatedAt,
+		},
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+func (r *KlineBackfillRepo) ListByCurrency(ctx context.Context, currencyID int) ([]model.KlineBackfill, error) {
+	return r.list(ctx, bson.M{"currency_id": currencyID}, bson.D{{Key: "interval", Value: 1}})
+}
+
+func (r *KlineBackfillRepo) ListByStatus(ctx context.Context, statuses ...string) ([]model.KlineBackfill, error) {
+	return r.list(ctx, bson.M{"status": bson.M{"$in": statuses}}, bson.D{{Key: "updated_at", Value: 1}})
+}
+
+func (r *KlineBackfillRepo) list(ctx context.Context, filter bson.M, sort bson.D) ([]model.KlineBackfill, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
+		{{Key: "$sort", Value: sort}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         currencyCollection,
+			"localField":   "currency_id",
+			"foreignField": "_id",
+			"as":           "currency",
+		}}},
+		{{Key: "$unwind", Value: "$currency"}},
+		{{Key: "$set", Value: bson.M{"symbol": "$currency.symbol"}}},
+	}
+
+	cursor, err := r.db.Collection(klineBackfillCollection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var items []model.KlineBackfill
+	for cursor.Next(ctx) {
+		var doc klineBackfillDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, err
+		}
+
+		items = append(items, doc.model())
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
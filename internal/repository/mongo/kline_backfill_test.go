@@ -0,0 +1,68 @@
+package mongo
+
+import (
+	"context"
+	"gexabyte/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestKlineBackfill(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	in := model.KlineBackfill{
+		CurrencyID: 1,
+		Symbol:     "BTCUSDT",
+		Interval:   "1h",
+		StartTime:  1,
+		EndTime:    3,
+		Cursor:     2,
+		Status:     model.BackfillStatusRunning,
+		UpdatedAt:  4,
+	}
+
+	mt.Run("save", func(mt *mtest.T) {
+		repo := NewKlineBackfill(mt.DB)
+
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+		assert.NoError(mt, repo.Save(context.Background(), in))
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "some error"}))
+		assert.Error(mt, repo.Save(context.Background(), in))
+	})
+
+	mt.Run("list", func(mt *mtest.T) {
+		repo := NewKlineBackfill(mt.DB)
+		ns := mt.DB.Name() + "." + klineBackfillCollection
+
+		doc := bson.D{
+			{Key: "currency_id", Value: 1},
+			{Key: "symbol", Value: "BTCUSDT"},
+			{Key: "interval", Value: "1h"},
+			{Key: "start_time", Value: int64(1)},
+			{Key: "end_time", Value: int64(3)},
+			{Key: "cursor", Value: int64(2)},
+			{Key: "status", Value: model.BackfillStatusRunning},
+			{Key: "error", Value: ""},
+			{Key: "updated_at", Value: int64(4)},
+		}
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, doc))
+		res, err := repo.ListByCurrency(context.Background(), 1)
+		assert.NoError(mt, err)
+		assert.Equal(mt, []model.KlineBackfill{in}, res)
+
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, doc))
+		res, err = repo.ListByStatus(context.Background(), model.BackfillStatusRunning)
+		assert.NoError(mt, err)
+		assert.Equal(mt, []model.KlineBackfill{in}, res)
+
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 1, Message: "some error"}))
+		res, err = repo.ListByStatus(context.Background(), model.BackfillStatusRunning)
+		assert.Error(mt, err)
+		assert.Nil(mt, res)
+	})
+}
@@ -0,0 +1,145 @@
+package mongo
+
+import (
+	"context"
+	"gexabyte/internal/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	currencyCollection          = "currency"
+	currencyPriceCollection     = "currency_price"
+	currencyKlineCollection     = "currency_kline"
+	currencyKlineSyncCollection = "currency_kline_sync"
+	klineBackfillCollection     = "kline_backfill"
+	currencyRollupCollection    = "currency_rollup"
+	fxRateCollection            = "fx_rate"
+	counterCollection           = "counter"
+)
+
+// seedSymbols are tracked by default, the same as in postgres migrations.
+var seedSymbols = []string{"BTCUSDT", "ETHUSDT", "SOLUSDT", "TRXUSDT"}
+
+// Setup creates indexes and seeds currencies into an empty database.
+// It plays the role of migrations and is safe to run on every start.
+func Setup(ctx context.Context, db *mongo.Database) error {
+	indexes := map[string][]mongo.IndexModel{
+		currencyCollection: {
+			{Keys: bson.D{{Key: "symbol", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		currencyPriceCollection: {
+			{Keys: bson.D{{Key: "currency_id", Value: 1}, {Key: "time", Value: 1}, {Key: "_id", Value: 1}}},
+			{Keys: bson.D{{Key: "currency_id", Value: 1}, {Key: "time", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "time", Value: 1}, {Key: "_id", Value: 1}}},
+		},
+		currencyKlineCollection: {
+			{Keys: bson.D{{Key: "currency_id", Value: 1}, {Key: "interval", Value: 1}, {Key: "open_time", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		currencyKlineSyncCollection: {
+			{Keys: bson.D{{Key: "currency_id", Value: 1}, {Key: "interval", Value: 1}, {Key: "start_time", Value: 1}, {Key: "end_time", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		currencyRollupCollection: {
+			{Keys: bson.D{{Key: "currency_id", Value: 1}, {Key: "interval", Value: 1}, {Key: "bucket_time", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		fxRateCollection: {
+			{Keys: bson.D{{Key: "currency", Value: 1}, {Key: "time", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		klineBackfillCollection: {
+			{Keys: bson.D{{Key: "currency_id", Value: 1}, {Key: "interval", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "status", Value: 1}}},
+		},
+	}
+
+	if err := dedupePrices(ctx, db); err != nil {
+		return err
+	}
+
+	for collection, models := range indexes {
+		if _, err := db.Collection(collection).Indexes().CreateMany(ctx, models); err != nil {
+			return err
+		}
+	}
+
+	count, err := db.Collection(currencyCollection).CountDocuments(ctx, bson.D{})
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+
+	currency := NewCurrency(db)
+	for _, symbol := range seedSymbols {
+		if _, err := currency.Create(ctx, model.Currency{Symbol: symbol}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dedupePrices keeps the first stored price of each currency and time, so the unique index can be built.
+// It does nothing once the index exists.
+func dedupePrices(ctx context.Context, db *mongo.Database) error {
+	names, err := db.Collection(currencyPriceCollection).Indexes().ListSpecifications(ctx)
+	if err != nil {
+		return err
+	}
+	for _, index := range names {
+		if index.Name == "currency_id_1_time_1" {
+			return nil
+		}
+	}
+
+	cursor, err := db.Collection(currencyPriceCollection).Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "currency_id", Value: "$currency_id"}, {Key: "time", Value: "$time"}}},
+			{Key: "ids", Value: bson.D{{Key: "$push", Value: "$_id"}}},
+		}}},
+		{{Key: "$match", Value: bson.D{{Key: "ids.1", Value: bson.D{{Key: "$exists", Value: true}}}}}},
+	}, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return err
+	}
+
+	var duplicates []struct {
+		IDs []int `bson:"ids"`
+	}
+	if err := cursor.All(ctx, &duplicates); err != nil {
+		return err
+	}
+
+	var ids []int
+	for _, d := range duplicates {
+		ids = append(ids, d.IDs[1:]...)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err = db.Collection(currencyPriceCollection).DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	return err
+}
+
+// nextID reserves n sequential ids of the collection and returns the first one.
+// Mongo has no serial columns, and models use int ids as in postgres.
+func nextID(ctx context.Context, db *mongo.Database, collection string, n int) (int, error) {
+	res := db.Collection(counterCollection).FindOneAndUpdate(ctx,
+		bson.M{"_id": collection},
+		bson.M{"$inc": bson.M{"seq": n}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	)
+
+	var counter struct {
+		Seq int `bson:"seq"`
+	}
+	if err := res.Decode(&counter); err != nil {
+		return 0, err
+	}
+
+	return counter.Seq - n + 1, nil
+}
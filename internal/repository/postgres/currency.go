@@ -3,9 +3,21 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"gexabyte/internal/model"
+
+	"github.com/lib/pq"
+)
+
+// Postgres error codes of constraint violations.
+const (
+	uniqueViolation     = "23505"
+	foreignKeyViolation = "23503"
 )
 
+// currencyColumns are selected in the order of scanCurrency.
+const currencyColumns = `id, symbol, active, base_asset, quote_asset, status, tick_size, step_size`
+
 type CurrencyRepo struct {
 	db *sql.DB
 }
@@ -16,30 +28,42 @@ func NewCurrency(db *sql.DB) *CurrencyRepo {
 	}
 }
 
-func (r *CurrencyRepo) Create(ctx context.Context, symbol string) error {
-	query := `insert into currency_price(symbol) values($1)`
+func (r *CurrencyRepo) Create(ctx context.Context, currency model.Currency) (model.Currency, error) {
+	query := `insert into currency(symbol, base_asset, quote_asset, status, tick_size, step_size)
+		values($1, $2, $3, $4, $5, $6) returning ` + currencyColumns
 
-	_, err := r.db.ExecContext(ctx, query, symbol)
+	res, err := scanCurrency(r.db.QueryRowContext(ctx, query,
+		currency.Symbol,
+		currency.BaseAsset,
+		currency.QuoteAsset,
+		currency.Status,
+		currency.TickSize,
+		currency.StepSize,
+	))
 	if err != nil {
-		return err
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return model.Currency{}, model.ErrAlreadyExists
+		}
+		return model.Currency{}, err
 	}
 
-	return nil
+	return res, nil
 }
 
 func (r *CurrencyRepo) GetBySymbol(ctx context.Context, symbol string) (model.Currency, error) {
-	query := "select id, symbol from currency where symbol = $1"
+	query := "select " + currencyColumns + " from currency where symbol = $1"
 
 	row := r.db.QueryRowContext(ctx, query, &symbol)
 	if row.Err() != nil {
 		return model.Currency{}, row.Err()
 	}
 
-	var res model.Currency
-	if err := row.Scan(
-		&res.ID,
-		&res.Symbol,
-	); err != nil {
+	res, err := scanCurrency(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Currency{}, model.ErrNotFound
+		}
 		return model.Currency{}, err
 	}
 
@@ -47,7 +71,7 @@ func (r *CurrencyRepo) GetBySymbol(ctx context.Context, symbol string) (model.Cu
 }
 
 func (r *CurrencyRepo) List(ctx context.Context) ([]model.Currency, error) {
-	query := `select id, symbol from currency`
+	query := `select ` + currencyColumns + ` from currency order by id`
 
 	rows, err := r.db.QueryContext(ctx, query)
 	if err != nil {
@@ -57,11 +81,8 @@ func (r *CurrencyRepo) List(ctx context.Context) ([]model.Currency, error) {
 
 	var items []model.Currency
 	for rows.Next() {
-		var item model.Currency
-		if err := rows.Scan(
-			&item.ID,
-			&item.Symbol,
-		); err != nil {
+		item, err := scanCurrency(rows)
+		if err != nil {
 			return nil, err
 		}
 
@@ -73,3 +94,85 @@ func (r *CurrencyRepo) List(ctx context.Context) ([]model.Currency, error) {
 
 	return items, nil
 }
+
+func (r *CurrencyRepo) SetActive(ctx context.Context, symbol string, active bool) (model.Currency, error) {
+	query := `update currency set active = $2 where symbol = $1 returning ` + currencyColumns
+
+	res, err := scanCurrency(r.db.QueryRowContext(ctx, query, symbol, active))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return model.Currency{}, model.ErrNotFound
+		}
+		return model.Currency{}, err
+	}
+
+	return res, nil
+}
+
+// UpdateInfo overwrites exchange metadata of the currency with the symbol.
+func (r *CurrencyRepo) UpdateInfo(ctx context.Context, currency model.Currency) error {
+	query := `update currency set base_asset = $2, quote_asset = $3, status = $4, tick_size = $5, step_size = $6
+		where symbol = $1`
+
+	res, err := r.db.ExecContext(ctx, query,
+		currency.Symbol,
+		currency.BaseAsset,
+		currency.QuoteAsset,
+		currency.Status,
+		currency.TickSize,
+		currency.StepSize,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return model.ErrNotFound
+	}
+
+	return nil
+}
+
+// Delete relies on foreign keys: prices restrict the delete, candles, backfills and rollups cascade.
+func (r *CurrencyRepo) Delete(ctx context.Context, id int) error {
+	query := `delete from currency where id = $1`
+
+	res, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == foreignKeyViolation {
+			return model.ErrHasPrices
+		}
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return model.ErrNotFound
+	}
+
+	return nil
+}
+
+func scanCurrency(row interface{ Scan(dest ...any) error }) (model.Currency, error) {
+	var res model.Currency
+	err := row.Scan(
+		&res.ID,
+		&res.Symbol,
+		&res.Active,
+		&res.BaseAsset,
+		&res.QuoteAsset,
+		&res.Status,
+		&res.TickSize,
+		&res.StepSize,
+	)
+
+	return res, err
+}
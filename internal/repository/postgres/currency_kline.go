@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"gexabyte/internal/model"
+
+	"github.com/lib/pq"
+)
+
+type CurrencyKlineRepo struct {
+	db *sql.DB
+}
+
+func NewCurrencyKline(db *sql.DB) *CurrencyKlineRepo {
+	return &CurrencyKlineRepo{
+		db: db,
+	}
+}
+
+func (r *CurrencyKlineRepo) Create(ctx context.Context, klines ...model.CurrencyKline) error {
+	// one statement for the whole batch, a row can't be updated twice by it, so repeated candles keep the last one
+	query := `
+	insert into currency_kline(currency_id, "interval", open_time, close_time, open_price, close_price, high_price, low_price)
+	select * from unnest($1::bigint[], $2::varchar[], $3::bigint[], $4::bigint[], $5::numeric[], $6::numeric[], $7::numeric[], $8::numeric[])
+	on conflict (currency_id, "interval", open_time) do update set
+		close_time = excluded.close_time,
+		open_price = excluded.open_price,
+		close_price = excluded.close_price,
+		high_price = excluded.high_price,
+		low_price = excluded.low_price`
+
+	type key struct {
+		currencyID int
+		interval   string
+		openTime   int64
+	}
+
+	seen := make(map[key]int, len(klines))
+	batch := make([]model.CurrencyKline, 0, len(klines))
+	for _, k := range klines {
+		if i, ok := seen[key{k.CurrencyID, k.Interval, k.OpenTime}]; ok {
+			batch[i] = k
+			continue
+		}
+		seen[key{k.CurrencyID, k.Interval, k.OpenTime}] = len(batch)
+		batch = append(batch, k)
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+
+	var (
+		currencyIDs = make([]int64, 0, len(batch))
+		intervals   = make([]string, 0, len(batch))
+		openTimes   = make([]int64, 0, len(batch))
+		closeTimes  = make([]int64, 0, len(batch))
+		openPrices  = make([]string, 0, len(batch))
+		closePrices = make([]string, 0, len(batch))
+		highPrices  = make([]string, 0, len(batch))
+		lowPrices   = make([]string, 0, len(batch))
+	)
+	for _, k := range batch {
+		currencyIDs = append(currencyIDs, int64(k.CurrencyID))
+		intervals = append(intervals, k.Interval)
+		openTimes = append(openTimes, k.OpenTime)
+		closeTimes = append(closeTimes, k.CloseTime)
+		openPrices = append(openPrices, k.OpenPrice.String())
+		closePrices = append(closePrices, k.ClosePrice.String())
+		highPrices = append(highPrices, k.HighPrice.String())
+		lowPrices = append(lowPrices, k.LowPrice.String())
+	}
+
+	_, err := r.db.ExecContext(ctx, query,
+		pq.Array(currencyIDs), pq.Array(intervals), pq.Array(openTimes), pq.Array(closeTimes),
+		pq.Array(openPrices), pq.Array(closePrices), pq.Array(highPrices), pq.Array(lowPrices),
+	)
+	return err
+}
+
+func (r *CurrencyKlineRepo) Count(ctx context.Context, currencyID int, interval string, startTime, endTime int64) (int, error) {
+	query := `select count(*) from currency_kline where currency_id = $1 and "interval" = $2 and open_time >= $3 and open_time <= $4`
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, query, currencyID, interval, startTime, endTime).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *CurrencyKlineRepo) List(ctx context.Context, currencyID int, interval string, startTime, endTime int64, limit, offset int) ([]model.CurrencyKline, error) {
+	query := `
+	select currency_id, "interval", open_time, close_time, open_price, close_price, high_price, low_price
+	from currency_kline
+	where currency_id = $1 and "interval" = $2 and open_time >= $3 and open_time <= $4
+	order by open_time
+	limit $5 offset $6`
+
+	rows, err := r.db.QueryContext(ctx, query, currencyID, interval, startTime, endTime, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.CurrencyKline
+	for rows.Next() {
+		var item model.CurrencyKline
+		if err := rows.Scan(
+			&item.CurrencyID,
+			&item.Interval,
+			&item.OpenTime,
+			&item.CloseTime,
+			&item.OpenPrice,
+			&item.ClosePrice,
+			&item.HighPrice,
+			&item.LowPrice,
+		); err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *CurrencyKlineRepo) MarkSynced(ctx context.Context, currencyID int, interval string, startTime, endTime int64) error {
+	query := `
+	insert into currency_kline_sync(currency_id, "interval", start_time, end_time)
+	values($1, $2, $3, $4)
+	on conflict do nothing`
+
+	if _, err := r.db.ExecContext(ctx, query, currencyID, interval, startTime, endTime); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *CurrencyKlineRepo) Synced(ctx context.Context, currencyID int, interval string, startTime, endTime int64) (bool, error) {
+	query := `
+	select exists(
+		select 1 from currency_kline_sync
+		where currency_id = $1 and "interval" = $2 and start_time <= $3 and end_time >= $4
+	)`
+
+	var synced bool
+	if err := r.db.QueryRowContext(ctx, query, currencyID, interval, startTime, endTime).Scan(&synced); err != nil {
+		return false, err
+	}
+
+	return synced, nil
+}
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"gexabyte/internal/model"
+	"log"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurrencyKline(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer db.Close()
+
+	repo := NewCurrencyKline(db)
+
+	in := []model.CurrencyKline{
+		{CurrencyID: 1, Interval: "1m", OpenTime: 0, CloseTime: 59999, OpenPrice: decimal.RequireFromString("1"), ClosePrice: decimal.RequireFromString("2"), HighPrice: decimal.RequireFromString("3"), LowPrice: decimal.RequireFromString("0.5")},
+		{CurrencyID: 1, Interval: "1m", OpenTime: 60000, CloseTime: 119999, OpenPrice: decimal.RequireFromString("2"), ClosePrice: decimal.RequireFromString("3"), HighPrice: decimal.RequireFromString("4"), LowPrice: decimal.RequireFromString("1.5")},
+	}
+
+	// one statement for the batch, the repeated candle keeps the last one
+	repeated := in[1]
+	repeated.ClosePrice = decimal.RequireFromString("3.5")
+	mock.ExpectExec(`insert into currency_kline(.+) select \* from unnest`).
+		WithArgs(
+			pq.Array([]int64{1, 1}), pq.Array([]string{"1m", "1m"}), pq.Array([]int64{0, 60000}), pq.Array([]int64{59999, 119999}),
+			pq.Array([]string{"1", "2"}), pq.Array([]string{"2", "3.5"}), pq.Array([]string{"3", "4"}), pq.Array([]string{"0.5", "1.5"}),
+		).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	assert.NoError(t, repo.Create(context.Background(), append(in, repeated)...))
+
+	expectedErr := fmt.Errorf("some error")
+
+	mock.ExpectExec("insert into currency_kline").WillReturnError(expectedErr)
+	assert.ErrorIs(t, repo.Create(context.Background(), in...), expectedErr)
+
+	// nothing to insert, no queries
+	assert.NoError(t, repo.Create(context.Background()))
+
+	mock.ExpectQuery("select count").WithArgs(1, "1m", int64(0), int64(60000)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+	count, err := repo.Count(context.Background(), 1, "1m", 0, 60000)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	mock.ExpectQuery("select count").WillReturnError(expectedErr)
+	_, err = repo.Count(context.Background(), 1, "1m", 0, 60000)
+	assert.Error(t, err)
+
+	columns := []string{"currency_id", "interval", "open_time", "close_time", "open_price", "close_price", "high_price", "low_price"}
+	mock.ExpectQuery("select currency_id, \"interval\", open_time").WithArgs(1, "1m", int64(0), int64(60000), 1, 1).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "1m", 60000, 119999, 2, 3, 4, 1.5))
+	res, err := repo.List(context.Background(), 1, "1m", 0, 60000, 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, in[1:], res)
+
+	mock.ExpectQuery("select currency_id, \"interval\", open_time").WillReturnError(expectedErr)
+	res, err = repo.List(context.Background(), 1, "1m", 0, 60000, 1, 1)
+	assert.Error(t, err)
+	assert.Nil(t, res)
+
+	mock.ExpectExec("insert into currency_kline_sync").WithArgs(1, "1m", int64(0), int64(60000)).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.MarkSynced(context.Background(), 1, "1m", 0, 60000))
+
+	mock.ExpectExec("insert into currency_kline_sync").WillReturnError(expectedErr)
+	assert.Error(t, repo.MarkSynced(context.Background(), 1, "1m", 0, 60000))
+
+	mock.ExpectQuery("select exists").WithArgs(1, "1m", int64(0), int64(60000)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	synced, err := repo.Synced(context.Background(), 1, "1m", 0, 60000)
+	assert.NoError(t, err)
+	assert.True(t, synced)
+
+	mock.ExpectQuery("select exists").WillReturnError(expectedErr)
+	_, err = repo.Synced(context.Background(), 1, "1m", 0, 60000)
+	assert.Error(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -4,6 +4,10 @@ import (
 	"context"
 	"database/sql"
 	"gexabyte/internal/model"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
 )
 
 type CurrencyPriceRepo struct {
@@ -16,46 +20,212 @@ func NewCurrencyPrice(db *sql.DB) *CurrencyPriceRepo {
 	}
 }
 
-func (r *CurrencyPriceRepo) Create(ctx context.Context, rates ...model.CurrencyPrice) error {
-	query := `insert into currency_price(currency_id, price, time) values($1, $2, $3)`
+func (r *CurrencyPriceRepo) Create(ctx context.Context, onConflict string, rates ...model.CurrencyPrice) (model.CreateCurrencyPricesRes, error) {
+	total := len(rates)
+	rates = model.DedupePrices(onConflict, rates...)
+	if len(rates) == 0 {
+		return model.CreateCurrencyPricesRes{}, nil
+	}
+
+	currencyIDs := make([]int64, 0, len(rates))
+	prices := make([]string, 0, len(rates))
+	times := make([]int64, 0, len(rates))
+	sources := make([]string, 0, len(rates))
+	for _, rate := range rates {
+		currencyIDs = append(currencyIDs, int64(rate.CurrencyID))
+		prices = append(prices, rate.Price.String())
+		times = append(times, rate.Time)
+		sources = append(sources, rate.Source)
+	}
+
+	// one statement for the whole batch, conflicting samples are left for the update below
+	insertQuery := `
+	insert into currency_price(currency_id, price, time, source)
+	select * from unnest($1::bigint[], $2::numeric[], $3::bigint[], $4::varchar[])
+	on conflict (currency_id, time) do nothing
+	returning id, currency_id, price, time, source`
+
+	// with keep_last the stored price is replaced by the new one, just inserted samples are equal and untouched
+	updateQuery := `
+	update currency_price p set price = i.price, source = i.source
+	from unnest($1::bigint[], $2::numeric[], $3::bigint[], $4::varchar[]) as i(currency_id, price, time, source)
+	where p.currency_id = i.currency_id and p.time = i.time and (p.price <> i.price or p.source <> i.source)
+	returning p.id, p.currency_id, p.price, p.time, p.source`
 
 	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{
 		Isolation: sql.LevelReadCommitted,
 	})
 	if err != nil {
-		return err
+		return model.CreateCurrencyPricesRes{}, err
 	}
 
-	for _, rate := range rates {
-		_, err := tx.ExecContext(ctx, query, rate.CurrencyID, rate.Price, rate.Time)
+	rollback := func(err error) (model.CreateCurrencyPricesRes, error) {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return model.CreateCurrencyPricesRes{}, rbErr
+		}
+		return model.CreateCurrencyPricesRes{}, err
+	}
+
+	var res model.CreateCurrencyPricesRes
+	rows, err := tx.QueryContext(ctx, insertQuery, pq.Array(currencyIDs), pq.Array(prices), pq.Array(times), pq.Array(sources))
+	if err != nil {
+		return rollback(err)
+	}
+
+	for rows.Next() {
+		var p model.CurrencyPrice
+		if err := rows.Scan(&p.ID, &p.CurrencyID, &p.Price, &p.Time, &p.Source); err != nil {
+			rows.Close()
+			return rollback(err)
+		}
+		res.Inserted = append(res.Inserted, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return rollback(err)
+	}
+
+	if onConflict == model.PriceConflictKeepLast && len(res.Inserted) < len(rates) {
+		rows, err := tx.QueryContext(ctx, updateQuery, pq.Array(currencyIDs), pq.Array(prices), pq.Array(times), pq.Array(sources))
 		if err != nil {
-			if rbErr := tx.Rollback(); rbErr != nil {
-				return rbErr
+			return rollback(err)
+		}
+
+		for rows.Next() {
+			var p model.CurrencyPrice
+			if err := rows.Scan(&p.ID, &p.CurrencyID, &p.Price, &p.Time, &p.Source); err != nil {
+				rows.Close()
+				return rollback(err)
 			}
-			return err
+			res.Replaced = append(res.Replaced, p)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return rollback(err)
 		}
 	}
 
-	return tx.Commit()
+	if err := tx.Commit(); err != nil {
+		return model.CreateCurrencyPricesRes{}, err
+	}
+
+	res.Deduplicated = total - len(res.Inserted) - len(res.Replaced)
+	return res, nil
 }
 
-func (r *CurrencyPriceRepo) List(ctx context.Context) ([]model.CurrencyPrice, error) {
-	query := `select id, currency_id, price, time from currency_price order by time`
+func (r *CurrencyPriceRepo) List(ctx context.Context, filter model.ListCurrencyPricesFilter) ([]model.CurrencyPriceDTO, error) {
+	var (
+		where []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if len(filter.Symbols) > 0 {
+		where = append(where, "c.symbol = any("+arg(pq.Array(filter.Symbols))+")")
+	}
+	if filter.StartTime > 0 {
+		where = append(where, "p.time >= "+arg(filter.StartTime))
+	}
+	if filter.EndTime > 0 {
+		where = append(where, "p.time <= "+arg(filter.EndTime))
+	}
+
+	cmp, order := ">", "asc"
+	if filter.Order == model.OrderDesc {
+		cmp, order = "<", "desc"
+	}
+	if filter.After != nil {
+		where = append(where, "(p.time, p.id) "+cmp+" ("+arg(filter.After.Time)+", "+arg(filter.After.ID)+")")
+	}
 
-	rows, err := r.db.QueryContext(ctx, query)
+	query := `
+	select p.id, c.symbol, p.price, p.time, p.source
+	from currency_price p
+	join currency c on c.id = p.currency_id`
+	if len(where) > 0 {
+		query += `
+	where ` + strings.Join(where, " and ")
+	}
+	query += `
+	order by p.time ` + order + `, p.id ` + order + `
+	limit ` + arg(filter.Limit)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var items []model.CurrencyPrice
+	var items []model.CurrencyPriceDTO
 	for rows.Next() {
-		var item model.CurrencyPrice
+		var item model.CurrencyPriceDTO
 		if err := rows.Scan(
 			&item.ID,
-			&item.CurrencyID,
+			&item.Symbol,
 			&item.Price,
 			&item.Time,
+			&item.Source,
+		); err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *CurrencyPriceRepo) Stat(ctx context.Context, startTime, endTime int64, symbols ...string) ([]model.GetCurrencyStat24HDTO, error) {
+	query := `
+	with stat as (
+		select
+			c.symbol,
+			(array_agg(p.price order by p.time, p.id))[1] as open_price,
+			(array_agg(p.price order by p.time desc, p.id desc))[1] as last_price,
+			max(p.price) as high_price,
+			min(p.price) as low_price,
+			avg(p.price) as avg_price,
+			count(*) as count,
+			min(p.time) as open_time,
+			max(p.time) as close_time
+		from currency_price p
+		join currency c on c.id = p.currency_id
+		where p.time >= $1 and p.time <= $2 and c.symbol = any($3)
+		group by c.symbol
+	)
+	select
+		symbol, open_price, last_price, high_price, low_price, avg_price,
+		coalesce((last_price - open_price) / nullif(open_price, 0) * 100, 0) as price_change_percent,
+		count, open_time, close_time
+	from stat
+	order by symbol`
+
+	rows, err := r.db.QueryContext(ctx, query, startTime, endTime, pq.Array(symbols))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.GetCurrencyStat24HDTO
+	for rows.Next() {
+		item := model.GetCurrencyStat24HDTO{Source: model.StatSourceLocal}
+		if err := rows.Scan(
+			&item.Symbol,
+			&item.OpenPrice,
+			&item.LastPrice,
+			&item.HighPrice,
+			&item.LowPrice,
+			&item.AvgPrice,
+			&item.PriceChangePercent,
+			&item.Count,
+			&item.OpenTime,
+			&item.CloseTime,
 		); err != nil {
 			return nil, err
 		}
@@ -68,3 +238,23 @@ func (r *CurrencyPriceRepo) List(ctx context.Context) ([]model.CurrencyPrice, er
 
 	return items, nil
 }
+
+func (r *CurrencyPriceRepo) DeleteByCurrency(ctx context.Context, currencyID int, limit int) (int, error) {
+	query := `
+	delete from currency_price
+	where (id, time) in (
+		select id, time from currency_price where currency_id = $1 limit $2
+	)`
+
+	res, err := r.db.ExecContext(ctx, query, currencyID, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(affected), nil
+}
@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// pricePartitionPrefix is followed by the month of partition as YYYYMM, the same as in migrations.
+const pricePartitionPrefix = "currency_price_p"
+
+type CurrencyPricePartitionRepo struct {
+	db *sql.DB
+}
+
+func NewCurrencyPricePartition(db *sql.DB) *CurrencyPricePartitionRepo {
+	return &CurrencyPricePartitionRepo{
+		db: db,
+	}
+}
+
+func (r *CurrencyPricePartitionRepo) Ensure(ctx context.Context, startTime, endTime int64) error {
+	for month := monthStart(startTime); month.UnixMilli() <= endTime; month = month.AddDate(0, 1, 0) {
+		query := fmt.Sprintf(`create table if not exists %s partition of currency_price for values from (%d) to (%d)`,
+			pq.QuoteIdentifier(pricePartitionPrefix+month.Format("200601")),
+			month.UnixMilli(),
+			month.AddDate(0, 1, 0).UnixMilli(),
+		)
+
+		if _, err := r.db.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *CurrencyPricePartitionRepo) DropBefore(ctx context.Context, before int64, detach bool) error {
+	query := `
+	select c.relname
+	from pg_inherits i
+	join pg_class c on c.oid = i.inhrelid
+	where i.inhparent = 'currency_price'::regclass
+	order by c.relname`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var expired []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+
+		// default partition and tables attached by hand are left alone
+		if !strings.HasPrefix(name, pricePartitionPrefix) {
+			continue
+		}
+		month, err := time.Parse("200601", strings.TrimPrefix(name, pricePartitionPrefix))
+		if err != nil {
+			continue
+		}
+
+		if month.AddDate(0, 1, 0).UnixMilli() <= before {
+			expired = append(expired, name)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, name := range expired {
+		query := `drop table ` + pq.QuoteIdentifier(name)
+		if detach {
+			query = `alter table currency_price detach partition ` + pq.QuoteIdentifier(name)
+		}
+
+		if _, err := r.db.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// monthStart returns the first moment of month of the time in UTC.
+func monthStart(t int64) time.Time {
+	tm := time.UnixMilli(t).UTC()
+	return time.Date(tm.Year(), tm.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
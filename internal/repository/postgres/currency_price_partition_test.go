@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurrencyPricePartition(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer db.Close()
+
+	repo := NewCurrencyPricePartition(db)
+
+	january := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	february := time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	march := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+
+	mock.ExpectExec(`create table if not exists "currency_price_p202401" partition of currency_price for values from \(1704067200000\) to \(1706745600000\)`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`create table if not exists "currency_price_p202402" partition of currency_price for values from \(1706745600000\) to \(1709251200000\)`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.NoError(t, repo.Ensure(context.Background(), january+10, february))
+
+	partitions := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"relname"}).
+			AddRow("currency_price_default").
+			AddRow("currency_price_p202401").
+			AddRow("currency_price_p202402")
+	}
+
+	mock.ExpectQuery("select c.relname from pg_inherits").WillReturnRows(partitions())
+	mock.ExpectExec(`drop table "currency_price_p202401"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.NoError(t, repo.DropBefore(context.Background(), february, false))
+
+	mock.ExpectQuery("select c.relname from pg_inherits").WillReturnRows(partitions())
+	mock.ExpectExec(`alter table currency_price detach partition "currency_price_p202401"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`alter table currency_price detach partition "currency_price_p202402"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.NoError(t, repo.DropBefore(context.Background(), march, true))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -26,45 +27,159 @@ func TestCurrencyPrice(t *testing.T) {
 	in = append(in,
 		model.CurrencyPrice{
 			CurrencyID: 1,
-			Price:      1,
+			Price:      decimal.RequireFromString("1"),
 			Time:       now,
+			Source:     "binance",
 		},
 		model.CurrencyPrice{
 			CurrencyID: 1,
-			Price:      2,
+			Price:      decimal.RequireFromString("2"),
 			Time:       now,
+			Source:     "kraken",
 		},
 	)
 
+	columns := []string{"id", "currency_id", "price", "time", "source"}
+
+	// both samples share time, the batch keeps the first one
+	mock.ExpectBegin()
+	mock.ExpectQuery("insert into currency_price").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 1, "1", now, "binance"))
+	mock.ExpectCommit()
+	res, err := repo.Create(context.Background(), model.PriceConflictKeepFirst, in...)
+	assert.NoError(t, err)
+	assert.Equal(t, model.CreateCurrencyPricesRes{
+		Inserted:     []model.CurrencyPrice{{ID: 1, CurrencyID: 1, Price: decimal.RequireFromString("1"), Time: now, Source: "binance"}},
+		Deduplicated: 1,
+	}, res)
+
+	// the second sample is stored already, keep_last updates its price and source
+	in[1].Time = now + 1
 	mock.ExpectBegin()
-	mock.ExpectExec("insert into currency_price").WithArgs(1, float64(1), now).WillReturnResult(sqlmock.NewResult(1, 1))
-	mock.ExpectExec("insert into currency_price").WithArgs(1, float64(2), now).WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectQuery("insert into currency_price").
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(2, 1, "1", now, "binance"))
+	mock.ExpectQuery(`update currency_price p set price = i.price, source = i.source (.+) returning p.id`).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, 1, "2", now+1, "kraken"))
 	mock.ExpectCommit()
-	assert.NoError(t, repo.Create(context.Background(), in...))
+	res, err = repo.Create(context.Background(), model.PriceConflictKeepLast, in...)
+	assert.NoError(t, err)
+	assert.Len(t, res.Inserted, 1)
+	assert.Equal(t, []model.CurrencyPrice{{ID: 1, CurrencyID: 1, Price: decimal.RequireFromString("2"), Time: now + 1, Source: "kraken"}}, res.Replaced)
+	assert.Equal(t, 0, res.Deduplicated)
 
 	expectedErr := fmt.Errorf("some error")
 
 	mock.ExpectBegin()
-	mock.ExpectExec("insert into currency_price").WithArgs(1, float64(1), now).WillReturnResult(sqlmock.NewResult(1, 1))
-	mock.ExpectExec("insert into currency_price").WithArgs(1, float64(2), now).WillReturnError(expectedErr)
+	mock.ExpectQuery("insert into currency_price").WillReturnError(expectedErr)
 	mock.ExpectRollback()
-	assert.Error(t, expectedErr, repo.Create(context.Background(), in...))
+	_, err = repo.Create(context.Background(), model.PriceConflictKeepFirst, in...)
+	assert.ErrorIs(t, err, expectedErr)
 
 	mock.ExpectBegin()
-	mock.ExpectExec("insert into currency_price").WithArgs(1, float64(1), now).WillReturnResult(sqlmock.NewResult(1, 1))
-	mock.ExpectExec("insert into currency_price").WithArgs(1, float64(2), now).WillReturnError(fmt.Errorf("other error"))
+	mock.ExpectQuery("insert into currency_price").WillReturnRows(sqlmock.NewRows(columns))
+	mock.ExpectQuery("update currency_price p set price").WillReturnError(fmt.Errorf("other error"))
 	mock.ExpectRollback().WillReturnError(expectedErr)
-	assert.Error(t, expectedErr, repo.Create(context.Background(), in...))
+	_, err = repo.Create(context.Background(), model.PriceConflictKeepLast, in...)
+	assert.ErrorIs(t, err, expectedErr)
 
-	mock.ExpectQuery("select id, currency_id, price, time from currency_price").
-		WillReturnRows(sqlmock.NewRows([]string{"id", "currency_id", "price", "time"}).AddRow(1, 1, 10.4, 1))
-	res, err := repo.List(context.Background())
+	// nothing to insert, no queries
+	res, err = repo.Create(context.Background(), model.PriceConflictKeepFirst)
 	assert.NoError(t, err)
-	assert.Equal(t, []model.CurrencyPrice{{ID: 1, CurrencyID: 1, Price: 10.4, Time: 1}}, res)
+	assert.Empty(t, res.Inserted)
 
-	mock.ExpectQuery("select id, currency_id, price, time from currency_price").
+	mock.ExpectQuery(`select p.id, c.symbol, p.price, p.time, p.source from currency_price p join currency c on c.id = p.currency_id order by p.time asc, p.id asc limit \$1`).
+		WithArgs(10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "symbol", "price", "time", "source"}).AddRow(1, "BTCUSDT", 10.4, 1, "binance"))
+	prices, err := repo.List(context.Background(), model.ListCurrencyPricesFilter{Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []model.CurrencyPriceDTO{{ID: 1, Symbol: "BTCUSDT", Price: decimal.RequireFromString("10.4"), Time: 1, Source: "binance"}}, prices)
+
+	mock.ExpectQuery(`select p.id, c.symbol, p.price, p.time, p.source from currency_price p join currency c on c.id = p.currency_id `+
+		`where c.symbol = any\(\$1\) and p.time >= \$2 and p.time <= \$3 and \(p.time, p.id\) < \(\$4, \$5\) `+
+		`order by p.time desc, p.id desc limit \$6`).
+		WithArgs(sqlmock.AnyArg(), int64(1), int64(5), int64(4), 2, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "symbol", "price", "time", "source"}).AddRow(1, "BTCUSDT", 10.4, 1, "binance"))
+	prices, err = repo.List(context.Background(), model.ListCurrencyPricesFilter{
+		Symbols:   []string{"BTCUSDT"},
+		StartTime: 1,
+		EndTime:   5,
+		Order:     model.OrderDesc,
+		Limit:     10,
+		After:     &model.PriceCursor{Time: 4, ID: 2},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []model.CurrencyPriceDTO{{ID: 1, Symbol: "BTCUSDT", Price: decimal.RequireFromString("10.4"), Time: 1, Source: "binance"}}, prices)
+
+	mock.ExpectQuery("select p.id, c.symbol, p.price, p.time, p.source from currency_price").
 		WillReturnError(expectedErr)
-	res, err = repo.List(context.Background())
+	prices, err = repo.List(context.Background(), model.ListCurrencyPricesFilter{Limit: 10})
+	assert.Error(t, err)
+	assert.Nil(t, prices)
+}
+
+func TestCurrencyPriceDeleteByCurrency(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer db.Close()
+
+	repo := NewCurrencyPrice(db)
+
+	mock.ExpectExec("delete from currency_price").WithArgs(1, 100).WillReturnResult(sqlmock.NewResult(0, 42))
+	deleted, err := repo.DeleteByCurrency(context.Background(), 1, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, 42, deleted)
+
+	mock.ExpectExec("delete from currency_price").WithArgs(1, 100).WillReturnError(fmt.Errorf("some error"))
+	_, err = repo.DeleteByCurrency(context.Background(), 1, 100)
 	assert.Error(t, err)
-	assert.Equal(t, []model.CurrencyPrice(nil), res)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCurrencyPriceStat(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer db.Close()
+
+	repo := NewCurrencyPrice(db)
+
+	columns := []string{
+		"symbol", "open_price", "last_price", "high_price", "low_price", "avg_price",
+		"price_change_percent", "count", "open_time", "close_time",
+	}
+
+	mock.ExpectQuery("with stat as").
+		WithArgs(int64(1), int64(2), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow("BTCUSDT", 10, 12, 13, 9, 11, 20, 3, 1, 2))
+	res, err := repo.Stat(context.Background(), 1, 2, "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Equal(t, []model.GetCurrencyStat24HDTO{{
+		Symbol:             "BTCUSDT",
+		Source:             model.StatSourceLocal,
+		OpenPrice:          decimal.RequireFromString("10"),
+		LastPrice:          decimal.RequireFromString("12"),
+		HighPrice:          decimal.RequireFromString("13"),
+		LowPrice:           decimal.RequireFromString("9"),
+		AvgPrice:           decimal.RequireFromString("11"),
+		PriceChangePercent: decimal.RequireFromString("20"),
+		Count:              3,
+		OpenTime:           1,
+		CloseTime:          2,
+	}}, res)
+
+	expectedErr := fmt.Errorf("some error")
+
+	mock.ExpectQuery("with stat as").WillReturnError(expectedErr)
+	res, err = repo.Stat(context.Background(), 1, 2, "BTCUSDT")
+	assert.Error(t, err)
+	assert.Nil(t, res)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
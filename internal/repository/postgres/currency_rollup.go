@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"gexabyte/internal/model"
+)
+
+type CurrencyRollupRepo struct {
+	db *sql.DB
+}
+
+func NewCurrencyRollup(db *sql.DB) *CurrencyRollupRepo {
+	return &CurrencyRollupRepo{
+		db: db,
+	}
+}
+
+func (r *CurrencyRollupRepo) Rebuild(ctx context.Context, currencyID int, interval string, startTime, endTime int64) error {
+	query := `
+	insert into currency_rollup(currency_id, "interval", bucket_time, open_price, open_time, close_price, close_time, high_price, low_price, sum_price, "count")
+	select currency_id, $2::varchar, "time" - "time" % $3::bigint as bucket_time,
+		(array_agg(price order by "time"))[1], min("time"),
+		(array_agg(price order by "time" desc))[1], max("time"),
+		max(price), min(price), sum(price), count(*)
+	from currency_price
+	where currency_id = $1 and "time" >= $4 and "time" <= $5
+	group by currency_id, bucket_time
+	on conflict (currency_id, "interval", bucket_time) do update set
+		open_price = excluded.open_price,
+		open_time = excluded.open_time,
+		close_price = excluded.close_price,
+		close_time = excluded.close_time,
+		high_price = excluded.high_price,
+		low_price = excluded.low_price,
+		sum_price = excluded.sum_price,
+		"count" = excluded."count"`
+
+	startTime, endTime = model.RollupRange(interval, startTime, endTime)
+	size := model.KlineInterval.GetDuration(interval).Milliseconds()
+
+	if _, err := r.db.ExecContext(ctx, query, currencyID, interval, size, startTime, endTime); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *CurrencyRollupRepo) List(ctx context.Context, currencyID int, interval string, startTime, endTime int64) ([]model.CurrencyRollup, error) {
+	query := `
+	select currency_id, "interval", bucket_time, open_price, open_time, close_price, close_time, high_price, low_price, sum_price, count
+	from currency_rollup
+	where currency_id = $1 and "interval" = $2 and bucket_time >= $3 and bucket_time <= $4
+	order by bucket_time`
+
+	rows, err := r.db.QueryContext(ctx, query, currencyID, interval, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.CurrencyRollup
+	for rows.Next() {
+		var item model.CurrencyRollup
+		if err := rows.Scan(
+			&item.CurrencyID,
+			&item.Interval,
+			&item.BucketTime,
+			&item.OpenPrice,
+			&item.OpenTime,
+			&item.ClosePrice,
+			&item.CloseTime,
+			&item.HighPrice,
+			&item.LowPrice,
+			&item.SumPrice,
+			&item.Count,
+		); err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
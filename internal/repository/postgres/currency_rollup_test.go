@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"gexabyte/internal/model"
+	"log"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurrencyRollup(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer db.Close()
+
+	repo := NewCurrencyRollup(db)
+
+	in := model.CurrencyRollup{
+		CurrencyID: 1,
+		Interval:   "1h",
+		BucketTime: 0,
+		OpenPrice:  decimal.RequireFromString("1"),
+		ClosePrice: decimal.RequireFromString("2"),
+		HighPrice:  decimal.RequireFromString("3"),
+		LowPrice:   decimal.RequireFromString("0.5"),
+		SumPrice:   decimal.RequireFromString("6"),
+		Count:      4,
+		OpenTime:   10,
+		CloseTime:  20,
+	}
+
+	// the range is widened to whole buckets
+	mock.ExpectExec(`insert into currency_rollup(.+) select (.+) from currency_price`).
+		WithArgs(1, "1h", int64(3600000), int64(0), int64(2*3600000-1)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	assert.NoError(t, repo.Rebuild(context.Background(), 1, "1h", 10, 3600000))
+
+	mock.ExpectExec("insert into currency_rollup").WillReturnError(fmt.Errorf("some error"))
+	assert.Error(t, repo.Rebuild(context.Background(), 1, "1h", 10, 20))
+
+	columns := []string{"currency_id", "interval", "bucket_time", "open_price", "open_time", "close_price", "close_time", "high_price", "low_price", "sum_price", "count"}
+
+	mock.ExpectQuery("select (.+) from currency_rollup").
+		WithArgs(1, "1h", int64(0), int64(3600000)).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "1h", 0, 1, 10, 2, 20, 3, 0.5, 6, 4))
+	res, err := repo.List(context.Background(), 1, "1h", 0, 3600000)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.CurrencyRollup{in}, res)
+
+	mock.ExpectQuery("select (.+) from currency_rollup").WillReturnError(fmt.Errorf("some error"))
+	res, err = repo.List(context.Background(), 1, "1h", 0, 3600000)
+	assert.Error(t, err)
+	assert.Nil(t, res)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,12 +2,15 @@ package postgres
 
 import (
 	"context"
+	"database/sql/driver"
 	"fmt"
 	"gexabyte/internal/model"
 	"log"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,14 +25,76 @@ func TestCurrency(t *testing.T) {
 
 	symbol := "BTCUSDT"
 
-	mock.ExpectExec("insert into currency").WithArgs(symbol).WillReturnResult(sqlmock.NewResult(1, 1))
-	assert.NoError(t, repo.Create(context.Background(), symbol))
+	info := model.Currency{
+		Symbol:     symbol,
+		BaseAsset:  "BTC",
+		QuoteAsset: "USDT",
+		Status:     model.SymbolStatusTrading,
+		TickSize:   decimal.RequireFromString("0.01"),
+		StepSize:   decimal.RequireFromString("0.00001"),
+	}
+	infoArgs := []driver.Value{symbol, "BTC", "USDT", model.SymbolStatusTrading, info.TickSize, info.StepSize}
+
+	columns := []string{"id", "symbol", "active", "base_asset", "quote_asset", "status", "tick_size", "step_size"}
+	row := func(active bool) *sqlmock.Rows {
+		return sqlmock.NewRows(columns).AddRow(1, symbol, active, "BTC", "USDT", model.SymbolStatusTrading, "0.01", "0.00001")
+	}
+	stored := func(active bool) model.Currency {
+		res := info
+		res.ID, res.Active = 1, active
+		return res
+	}
+
+	mock.ExpectQuery(`insert into currency\(symbol, base_asset, quote_asset, status, tick_size, step_size\)`).WithArgs(infoArgs...).WillReturnRows(row(true))
+	created, err := repo.Create(context.Background(), info)
+	assert.NoError(t, err)
+	assert.Equal(t, stored(true), created)
+
+	mock.ExpectQuery(`insert into currency`).WithArgs(infoArgs...).WillReturnError(fmt.Errorf("duplicate value"))
+	_, err = repo.Create(context.Background(), info)
+	assert.Error(t, err)
 
-	mock.ExpectExec("insert into currency").WithArgs(symbol).WillReturnError(fmt.Errorf("duplicate value"))
-	assert.Error(t, repo.Create(context.Background(), symbol))
+	mock.ExpectQuery(`insert into currency`).WithArgs(infoArgs...).WillReturnError(&pq.Error{Code: uniqueViolation})
+	_, err = repo.Create(context.Background(), info)
+	assert.ErrorIs(t, err, model.ErrAlreadyExists)
 
-	mock.ExpectQuery("select id, symbol from currency").WithoutArgs().WillReturnRows(sqlmock.NewRows([]string{"id", "symbol"}).AddRow(1, symbol))
+	mock.ExpectQuery("select id, symbol, active, base_asset, quote_asset, status, tick_size, step_size from currency").WithoutArgs().WillReturnRows(row(false))
 	res, err := repo.List(context.Background())
 	assert.NoError(t, err)
-	assert.Equal(t, model.Currency{ID: 1, Symbol: symbol}, res[0])
+	assert.Equal(t, stored(false), res[0])
+
+	mock.ExpectQuery("select (.+) from currency where symbol").WithArgs(symbol).WillReturnRows(row(true))
+	item, err := repo.GetBySymbol(context.Background(), symbol)
+	assert.NoError(t, err)
+	assert.Equal(t, stored(true), item)
+
+	mock.ExpectQuery("select (.+) from currency where symbol").WithArgs(symbol).WillReturnRows(sqlmock.NewRows(columns))
+	_, err = repo.GetBySymbol(context.Background(), symbol)
+	assert.ErrorIs(t, err, model.ErrNotFound)
+
+	mock.ExpectQuery("update currency set active").WithArgs(symbol, false).WillReturnRows(row(false))
+	item, err = repo.SetActive(context.Background(), symbol, false)
+	assert.NoError(t, err)
+	assert.Equal(t, stored(false), item)
+
+	mock.ExpectQuery("update currency set active").WithArgs(symbol, true).WillReturnRows(sqlmock.NewRows(columns))
+	_, err = repo.SetActive(context.Background(), symbol, true)
+	assert.ErrorIs(t, err, model.ErrNotFound)
+
+	mock.ExpectExec("update currency set base_asset").WithArgs(infoArgs...).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.UpdateInfo(context.Background(), info))
+
+	mock.ExpectExec("update currency set base_asset").WithArgs(infoArgs...).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.UpdateInfo(context.Background(), info), model.ErrNotFound)
+
+	mock.ExpectExec("delete from currency where id").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Delete(context.Background(), 1))
+
+	mock.ExpectExec("delete from currency where id").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+	assert.ErrorIs(t, repo.Delete(context.Background(), 1), model.ErrNotFound)
+
+	mock.ExpectExec("delete from currency where id").WithArgs(1).WillReturnError(&pq.Error{Code: foreignKeyViolation})
+	assert.ErrorIs(t, repo.Delete(context.Background(), 1), model.ErrHasPrices)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
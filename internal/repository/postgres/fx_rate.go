@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"gexabyte/internal/model"
+
+	"github.com/lib/pq"
+)
+
+type FxRateRepo struct {
+	db *sql.DB
+}
+
+func NewFxRate(db *sql.DB) *FxRateRepo {
+	return &FxRateRepo{
+		db: db,
+	}
+}
+
+func (r *FxRateRepo) Create(ctx context.Context, rates ...model.FxRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+
+	currencies := make([]string, 0, len(rates))
+	values := make([]string, 0, len(rates))
+	times := make([]int64, 0, len(rates))
+	sources := make([]string, 0, len(rates))
+	for _, rate := range rates {
+		currencies = append(currencies, rate.Currency)
+		values = append(values, rate.Rate.String())
+		times = append(times, rate.Time)
+		sources = append(sources, rate.Source)
+	}
+
+	query := `
+	insert into fx_rate(currency, rate, time, source)
+	select * from unnest($1::varchar[], $2::numeric[], $3::bigint[], $4::varchar[])
+	on conflict (currency, time) do nothing`
+
+	_, err := r.db.ExecContext(ctx, query, pq.Array(currencies), pq.Array(values), pq.Array(times), pq.Array(sources))
+	return err
+}
+
+func (r *FxRateRepo) Around(ctx context.Context, currency string, startTime, endTime int64) ([]model.FxRate, error) {
+	query := `
+	select id, currency, rate, time, source from (
+		(select id, currency, rate, time, source from fx_rate where currency = $1 and time < $2 order by time desc limit 1)
+		union all
+		(select id, currency, rate, time, source from fx_rate where currency = $1 and time >= $2 and time <= $3)
+		union all
+		(select id, currency, rate, time, source from fx_rate where currency = $1 and time > $3 order by time limit 1)
+	) r
+	order by time`
+
+	rows, err := r.db.QueryContext(ctx, query, currency, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.FxRate
+	for rows.Next() {
+		var item model.FxRate
+		if err := rows.Scan(
+			&item.ID,
+			&item.Currency,
+			&item.Rate,
+			&item.Time,
+			&item.Source,
+		); err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
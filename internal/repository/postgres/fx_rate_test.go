@@ -0,0 +1,53 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"gexabyte/internal/model"
+	"log"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFxRate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer db.Close()
+
+	repo := NewFxRate(db)
+
+	in := model.FxRate{ID: 1, Currency: "EUR", Rate: decimal.RequireFromString("0.92"), Time: 1000, Source: "static"}
+
+	mock.ExpectExec("insert into fx_rate").
+		WithArgs(pq.Array([]string{"EUR"}), pq.Array([]string{"0.92"}), pq.Array([]int64{1000}), pq.Array([]string{"static"})).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Create(context.Background(), in))
+
+	// nothing to store makes no query
+	assert.NoError(t, repo.Create(context.Background()))
+
+	mock.ExpectExec("insert into fx_rate").WillReturnError(fmt.Errorf("some error"))
+	assert.Error(t, repo.Create(context.Background(), in))
+
+	columns := []string{"id", "currency", "rate", "time", "source"}
+
+	mock.ExpectQuery("select (.+) from fx_rate (.+) union all (.+) order by time").
+		WithArgs("EUR", int64(2000), int64(3000)).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "EUR", "0.92", 1000, "static"))
+	res, err := repo.Around(context.Background(), "EUR", 2000, 3000)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.FxRate{in}, res)
+
+	mock.ExpectQuery("select (.+) from fx_rate").WillReturnError(fmt.Errorf("some error"))
+	res, err = repo.Around(context.Background(), "EUR", 2000, 3000)
+	assert.Error(t, err)
+	assert.Nil(t, res)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
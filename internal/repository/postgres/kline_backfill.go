@@ -0,0 +1,99 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"gexabyte/internal/model"
+
+	"github.com/lib/pq"
+)
+
+type KlineBackfillRepo struct {
+	db *sql.DB
+}
+
+func NewKlineBackfill(db *sql.DB) *KlineBackfillRepo {
+	return &KlineBackfillRepo{
+		db: db,
+	}
+}
+
+func (r *KlineBackfillRepo) Save(ctx context.Context, backfill model.KlineBackfill) error {
+	query := `
+	insert into kline_backfill(currency_id, "interval", start_time, end_time, "cursor", status, error, attempts, updated_at)
+	values($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	on conflict (currency_id, "interval") do update set
+		start_time = excluded.start_time,
+		end_time = excluded.end_time,
+		"cursor" = excluded."cursor",
+		status = excluded.status,
+		error = excluded.error,
+		attempts = excluded.attempts,
+		updated_at = excluded.updated_at`
+
+	_, err := r.db.ExecContext(ctx, query,
+		backfill.CurrencyID, backfill.Interval, backfill.StartTime, backfill.EndTime,
+		backfill.Cursor, backfill.Status, backfill.Error, backfill.Attempts, backfill.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *KlineBackfillRepo) ListByCurrency(ctx context.Context, currencyID int) ([]model.KlineBackfill, error) {
+	query := `
+	select b.currency_id, c.symbol, b."interval", b.start_time, b.end_time, b."cursor", b.status, b.error, b.attempts, b.updated_at
+	from kline_backfill b
+	join currency c on c.id = b.currency_id
+	where b.currency_id = $1
+	order by b."interval"`
+
+	return r.list(ctx, query, currencyID)
+}
+
+func (r *KlineBackfillRepo) ListByStatus(ctx context.Context, statuses ...string) ([]model.KlineBackfill, error) {
+	query := `
+	select b.currency_id, c.symbol, b."interval", b.start_time, b.end_time, b."cursor", b.status, b.error, b.attempts, b.updated_at
+	from kline_backfill b
+	join currency c on c.id = b.currency_id
+	where b.status = any($1)
+	order by b.updated_at`
+
+	return r.list(ctx, query, pq.Array(statuses))
+}
+
+func (r *KlineBackfillRepo) list(ctx context.Context, query string, args ...any) ([]model.KlineBackfill, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.KlineBackfill
+	for rows.Next() {
+		var item model.KlineBackfill
+		if err := rows.Scan(
+			&item.CurrencyID,
+			&item.Symbol,
+			&item.Interval,
+			&item.StartTime,
+			&item.EndTime,
+			&item.Cursor,
+			&item.Status,
+			&item.Error,
+			&item.Attempts,
+			&item.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"gexabyte/internal/model"
+	"log"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKlineBackfill(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		log.Fatalln(err)
+	}
+	defer db.Close()
+
+	repo := NewKlineBackfill(db)
+
+	in := model.KlineBackfill{
+		CurrencyID: 1,
+		Symbol:     "BTCUSDT",
+		Interval:   "1h",
+		StartTime:  1,
+		EndTime:    3,
+		Cursor:     2,
+		Status:     model.BackfillStatusRunning,
+		Attempts:   1,
+		UpdatedAt:  4,
+	}
+
+	mock.ExpectExec("insert into kline_backfill").WithArgs(1, "1h", int64(1), int64(3), int64(2), model.BackfillStatusRunning, "", 1, int64(4)).WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Save(context.Background(), in))
+
+	expectedErr := fmt.Errorf("some error")
+
+	mock.ExpectExec("insert into kline_backfill").WillReturnError(expectedErr)
+	assert.Error(t, repo.Save(context.Background(), in))
+
+	columns := []string{"currency_id", "symbol", "interval", "start_time", "end_time", "cursor", "status", "error", "attempts", "updated_at"}
+
+	mock.ExpectQuery("select b.currency_id").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "BTCUSDT", "1h", 1, 3, 2, model.BackfillStatusRunning, "", 1, 4))
+	res, err := repo.ListByCurrency(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.KlineBackfill{in}, res)
+
+	mock.ExpectQuery("select b.currency_id").WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows(columns).AddRow(1, "BTCUSDT", "1h", 1, 3, 2, model.BackfillStatusRunning, "", 1, 4))
+	res, err = repo.ListByStatus(context.Background(), model.BackfillStatusPending, model.BackfillStatusRunning)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.KlineBackfill{in}, res)
+
+	mock.ExpectQuery("select b.currency_id").WillReturnError(expectedErr)
+	res, err = repo.ListByStatus(context.Background(), model.BackfillStatusPending)
+	assert.Error(t, err)
+	assert.Nil(t, res)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS currency_kline;
\ No newline at end of file
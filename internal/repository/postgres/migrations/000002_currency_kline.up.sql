@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS "currency_kline" (
+  "currency_id" bigint NOT NULL,
+  "interval" varchar NOT NULL,
+  "open_time" bigint NOT NULL,
+  "close_time" bigint NOT NULL,
+  "open_price" numeric(20,10) NOT NULL,
+  "close_price" numeric(20,10) NOT NULL,
+  "high_price" numeric(20,10) NOT NULL,
+  "low_price" numeric(20,10) NOT NULL,
+
+  PRIMARY KEY(currency_id, "interval", open_time),
+  FOREIGN KEY(currency_id) REFERENCES currency(id) ON DELETE CASCADE
+);
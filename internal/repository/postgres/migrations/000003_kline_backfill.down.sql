@@ -0,0 +1 @@
+DROP TABLE IF EXISTS kline_backfill;
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS "kline_backfill" (
+  "currency_id" bigint NOT NULL,
+  "interval" varchar NOT NULL,
+  "start_time" bigint NOT NULL,
+  "end_time" bigint NOT NULL,
+  "cursor" bigint NOT NULL,
+  "status" varchar NOT NULL,
+  "error" varchar NOT NULL DEFAULT '',
+  "updated_at" bigint NOT NULL,
+
+  PRIMARY KEY(currency_id, "interval"),
+  FOREIGN KEY(currency_id) REFERENCES currency(id) ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "currency_price_time_id_idx";
+DROP INDEX IF EXISTS "currency_price_currency_id_time_id_idx";
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS "currency_price_currency_id_time_id_idx" ON "currency_price" ("currency_id", "time", "id");
+CREATE INDEX IF NOT EXISTS "currency_price_time_id_idx" ON "currency_price" ("time", "id");
@@ -0,0 +1,22 @@
+ALTER TABLE "currency_price" RENAME TO "currency_price_partitioned";
+ALTER SEQUENCE "currency_price_id_seq" OWNED BY NONE;
+
+CREATE TABLE "currency_price" (
+  "id" bigint PRIMARY KEY DEFAULT nextval('currency_price_id_seq'),
+  "price" numeric(20,10) NOT NULL,
+  "currency_id" bigint NOT NULL,
+  "time" bigint NOT NULL,
+
+  FOREIGN KEY(currency_id) REFERENCES currency(id) ON DELETE RESTRICT
+);
+
+ALTER SEQUENCE "currency_price_id_seq" OWNED BY "currency_price"."id";
+
+INSERT INTO "currency_price"(id, price, currency_id, "time")
+SELECT id, price, currency_id, "time" FROM "currency_price_partitioned";
+
+-- drops all partitions together with the parent, detached ones are left as is
+DROP TABLE "currency_price_partitioned";
+
+CREATE INDEX IF NOT EXISTS "currency_price_currency_id_time_id_idx" ON "currency_price" ("currency_id", "time", "id");
+CREATE INDEX IF NOT EXISTS "currency_price_time_id_idx" ON "currency_price" ("time", "id");
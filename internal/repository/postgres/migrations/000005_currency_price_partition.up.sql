@@ -0,0 +1,45 @@
+-- currency_price becomes partitioned by month of time (unix milliseconds, UTC).
+-- Old months are removed by dropping whole partitions, so retention leaves no dead rows behind.
+ALTER TABLE "currency_price" RENAME TO "currency_price_old";
+ALTER SEQUENCE "currency_price_id_seq" OWNED BY NONE;
+
+CREATE TABLE "currency_price" (
+  "id" bigint NOT NULL DEFAULT nextval('currency_price_id_seq'),
+  "price" numeric(20,10) NOT NULL,
+  "currency_id" bigint NOT NULL,
+  "time" bigint NOT NULL,
+
+  PRIMARY KEY(id, "time"),
+  FOREIGN KEY(currency_id) REFERENCES currency(id) ON DELETE RESTRICT
+) PARTITION BY RANGE ("time");
+
+ALTER SEQUENCE "currency_price_id_seq" OWNED BY "currency_price"."id";
+
+-- catches prices outside of created months, the service creates months ahead so it stays empty
+CREATE TABLE "currency_price_default" PARTITION OF "currency_price" DEFAULT;
+
+-- partitions of stored months up to the next one, the service maintains them later
+DO $$
+DECLARE
+  m timestamp;
+  last timestamp := date_trunc('month', now() AT TIME ZONE 'UTC') + interval '1 month';
+BEGIN
+  SELECT date_trunc('month', to_timestamp(min("time") / 1000.0) AT TIME ZONE 'UTC') INTO m FROM "currency_price_old";
+  m := least(coalesce(m, last), last);
+
+  WHILE m <= last LOOP
+    EXECUTE format('CREATE TABLE IF NOT EXISTS %I PARTITION OF "currency_price" FOR VALUES FROM (%s) TO (%s)',
+      'currency_price_p' || to_char(m, 'YYYYMM'),
+      (extract(epoch FROM m) * 1000)::bigint,
+      (extract(epoch FROM m + interval '1 month') * 1000)::bigint);
+    m := m + interval '1 month';
+  END LOOP;
+END $$;
+
+INSERT INTO "currency_price"(id, price, currency_id, "time")
+SELECT id, price, currency_id, "time" FROM "currency_price_old";
+
+DROP TABLE "currency_price_old";
+
+CREATE INDEX IF NOT EXISTS "currency_price_currency_id_time_id_idx" ON "currency_price" ("currency_id", "time", "id");
+CREATE INDEX IF NOT EXISTS "currency_price_time_id_idx" ON "currency_price" ("time", "id");
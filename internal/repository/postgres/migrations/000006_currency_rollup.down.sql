@@ -0,0 +1 @@
+DROP TABLE IF EXISTS currency_rollup;
\ No newline at end of file
@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS "currency_rollup" (
+  "currency_id" bigint NOT NULL,
+  "interval" varchar NOT NULL,
+  "bucket_time" bigint NOT NULL,
+  "open_price" numeric(20,10) NOT NULL,
+  "open_time" bigint NOT NULL,
+  "close_price" numeric(20,10) NOT NULL,
+  "close_time" bigint NOT NULL,
+  "high_price" numeric(20,10) NOT NULL,
+  "low_price" numeric(20,10) NOT NULL,
+  "sum_price" numeric NOT NULL,
+  "count" bigint NOT NULL,
+
+  PRIMARY KEY(currency_id, "interval", bucket_time),
+  FOREIGN KEY(currency_id) REFERENCES currency(id) ON DELETE CASCADE
+);
+
+-- summaries of prices stored before rollups appeared, the service rebuilds buckets of new ones from stored prices
+INSERT INTO "currency_rollup"(currency_id, "interval", bucket_time, open_price, open_time, close_price, close_time, high_price, low_price, sum_price, "count")
+SELECT
+  p.currency_id,
+  b."interval",
+  p."time" - p."time" % b.ms,
+  (array_agg(p.price ORDER BY p."time", p.id))[1],
+  min(p."time"),
+  (array_agg(p.price ORDER BY p."time" DESC, p.id DESC))[1],
+  max(p."time"),
+  max(p.price),
+  min(p.price),
+  sum(p.price),
+  count(*)
+FROM "currency_price" p
+CROSS JOIN (VALUES ('1h', 3600000::bigint), ('1d', 86400000::bigint)) AS b("interval", ms)
+GROUP BY p.currency_id, b."interval", p."time" - p."time" % b.ms
+ON CONFLICT DO NOTHING;
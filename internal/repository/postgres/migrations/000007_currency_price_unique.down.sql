@@ -0,0 +1 @@
+ALTER TABLE "currency_price" DROP CONSTRAINT IF EXISTS "currency_price_currency_id_time_key";
@@ -0,0 +1,33 @@
+-- samples of the same currency and time collapse to the first stored one,
+-- summaries of touched buckets are dropped and built again below
+WITH removed AS (
+  DELETE FROM "currency_price" p
+  USING "currency_price" d
+  WHERE p.currency_id = d.currency_id AND p."time" = d."time" AND p.id > d.id
+  RETURNING p.currency_id, p."time"
+)
+DELETE FROM "currency_rollup" r
+USING removed d
+CROSS JOIN (VALUES ('1h', 3600000::bigint), ('1d', 86400000::bigint)) AS b("interval", ms)
+WHERE r.currency_id = d.currency_id AND r."interval" = b."interval" AND r.bucket_time = d."time" - d."time" % b.ms;
+
+INSERT INTO "currency_rollup"(currency_id, "interval", bucket_time, open_price, open_time, close_price, close_time, high_price, low_price, sum_price, "count")
+SELECT
+  p.currency_id,
+  b."interval",
+  p."time" - p."time" % b.ms,
+  (array_agg(p.price ORDER BY p."time", p.id))[1],
+  min(p."time"),
+  (array_agg(p.price ORDER BY p."time" DESC, p.id DESC))[1],
+  max(p."time"),
+  max(p.price),
+  min(p.price),
+  sum(p.price),
+  count(*)
+FROM "currency_price" p
+CROSS JOIN (VALUES ('1h', 3600000::bigint), ('1d', 86400000::bigint)) AS b("interval", ms)
+GROUP BY p.currency_id, b."interval", p."time" - p."time" % b.ms
+ON CONFLICT DO NOTHING;
+
+-- the key includes "time", so it is enforced by every monthly partition
+ALTER TABLE "currency_price" ADD CONSTRAINT "currency_price_currency_id_time_key" UNIQUE ("currency_id", "time");
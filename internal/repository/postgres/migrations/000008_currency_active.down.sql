@@ -0,0 +1 @@
+ALTER TABLE "currency" DROP COLUMN IF EXISTS "active";
@@ -0,0 +1,2 @@
+-- inactive pairs are not polled but keep their history
+ALTER TABLE "currency" ADD COLUMN IF NOT EXISTS "active" boolean NOT NULL DEFAULT true;
@@ -0,0 +1,6 @@
+ALTER TABLE "currency"
+  DROP COLUMN IF EXISTS "base_asset",
+  DROP COLUMN IF EXISTS "quote_asset",
+  DROP COLUMN IF EXISTS "status",
+  DROP COLUMN IF EXISTS "tick_size",
+  DROP COLUMN IF EXISTS "step_size";
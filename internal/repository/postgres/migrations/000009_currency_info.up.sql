@@ -0,0 +1,7 @@
+-- metadata of the pair from exchangeInfo, rows created before are filled by the service on the next refresh
+ALTER TABLE "currency"
+  ADD COLUMN IF NOT EXISTS "base_asset" varchar NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS "quote_asset" varchar NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS "status" varchar NOT NULL DEFAULT '',
+  ADD COLUMN IF NOT EXISTS "tick_size" numeric(20,10) NOT NULL DEFAULT 0,
+  ADD COLUMN IF NOT EXISTS "step_size" numeric(20,10) NOT NULL DEFAULT 0;
@@ -0,0 +1,2 @@
+ALTER TABLE "currency_price"
+  DROP COLUMN IF EXISTS "source";
@@ -0,0 +1,3 @@
+-- provider the price came from, prices stored before came from binance
+ALTER TABLE "currency_price"
+  ADD COLUMN IF NOT EXISTS "source" varchar NOT NULL DEFAULT 'binance';
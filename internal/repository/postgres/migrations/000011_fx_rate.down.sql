@@ -0,0 +1 @@
+DROP TABLE IF EXISTS fx_rate;
\ No newline at end of file
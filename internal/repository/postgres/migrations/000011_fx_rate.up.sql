@@ -0,0 +1,10 @@
+-- rates of fiat currencies to USD, history of what the fx provider quoted
+CREATE TABLE IF NOT EXISTS "fx_rate" (
+  "id" bigserial PRIMARY KEY,
+  "currency" varchar NOT NULL,
+  "rate" numeric(30,10) NOT NULL,
+  "time" bigint NOT NULL,
+  "source" varchar NOT NULL,
+
+  UNIQUE("currency", "time")
+);
@@ -0,0 +1,10 @@
+-- ranges of candles which were asked from the provider, whatever it returned for them is all it has
+CREATE TABLE IF NOT EXISTS "currency_kline_sync" (
+  "currency_id" bigint NOT NULL,
+  "interval" varchar NOT NULL,
+  "start_time" bigint NOT NULL,
+  "end_time" bigint NOT NULL,
+
+  PRIMARY KEY(currency_id, "interval", start_time, end_time),
+  FOREIGN KEY(currency_id) REFERENCES currency(id) ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+ALTER TABLE "kline_backfill"
+  DROP COLUMN IF EXISTS "attempts";
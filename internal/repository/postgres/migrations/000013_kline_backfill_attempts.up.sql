@@ -0,0 +1,3 @@
+-- failures of the backfill in a row, a failed backfill is retried with a delay growing with them
+ALTER TABLE "kline_backfill"
+  ADD COLUMN IF NOT EXISTS "attempts" integer NOT NULL DEFAULT 0;
@@ -0,0 +1,193 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"gexabyte/internal/model"
+	"math"
+	"time"
+)
+
+// backfillRescanInterval is how often pending backfills are looked up in case a wakeup was missed.
+const backfillRescanInterval = time.Minute
+
+// A failed backfill is retried after backfillRetryDelay, the delay doubles with every failure in a row
+// up to backfillRetryMaxDelay, so a venue which is down is not hammered and a transient error leaves no gap.
+const (
+	backfillRetryDelay    = time.Minute
+	backfillRetryMaxDelay = time.Hour
+)
+
+type BackfillConfig struct {
+	// Months of history loaded for a new currency.
+	Months int
+	// Intervals of candles which are loaded, each one is a separate backfill.
+	Intervals []string
+	// RequestDelay is a pause between requests to the provider, so backfill does not eat the rate limit.
+	RequestDelay time.Duration
+}
+
+func (s *Currency) ListBackfills(ctx context.Context, symbol string) ([]model.KlineBackfill, error) {
+	currency, err := s.currencyRepo.GetBySymbol(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	backfills, err := s.klineBackfillRepo.ListByCurrency(ctx, currency.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range backfills {
+		backfills[i].Progress = backfillProgress(backfills[i])
+	}
+
+	return backfills, nil
+}
+
+// startBackfill saves pending backfills of a new currency and wakes up backfillLoop.
+func (s *Currency) startBackfill(ctx context.Context, currency model.Currency) error {
+	now := time.Now()
+
+	for _, interval := range s.backfill.Intervals {
+		startTime, _ := s.solvePagination(now.AddDate(0, -s.backfill.Months, 0).UnixMilli(), now.UnixMilli(), 1, 1, interval)
+
+		backfill := model.KlineBackfill{
+			CurrencyID: currency.ID,
+			Symbol:     currency.Symbol,
+			Interval:   interval,
+
+			StartTime: startTime,
+			EndTime:   klineOpenTime(now.UnixMilli(), interval) - 1, // only closed candles
+			Cursor:    startTime,
+
+			Status:    model.BackfillStatusPending,
+			UpdatedAt: now.UnixMilli(),
+		}
+		if err := s.klineBackfillRepo.Save(ctx, backfill); err != nil {
+			return err
+		}
+	}
+
+	select {
+	case s.backfillWakeup <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+// backfillLoop runs backfills one by one, so they share the rate limit of the provider politely.
+func (s *Currency) backfillLoop(ctx context.Context) {
+	// interrupted and failed backfills are resumed from their cursor after restart
+	s.runBackfills(ctx, model.BackfillStatusPending, model.BackfillStatusRunning, model.BackfillStatusFailed)
+
+	ticker := time.NewTicker(backfillRescanInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.backfillWakeup:
+		case <-ticker.C:
+		}
+
+		s.runBackfills(ctx, model.BackfillStatusPending, model.BackfillStatusFailed)
+	}
+}
+
+// runBackfills runs backfills of the statuses one by one, failed ones wait for their retry delay.
+func (s *Currency) runBackfills(ctx context.Context, statuses ...string) {
+	backfills, err := s.klineBackfillRepo.ListByStatus(ctx, statuses...)
+	if err != nil {
+		s.logger.Error("backfillLoop: failed to list backfills: " + err.Error())
+		return
+	}
+
+	for _, backfill := range backfills {
+		if !backfillDue(backfill, time.Now()) {
+			continue
+		}
+
+		if err := s.runBackfill(ctx, backfill); err != nil {
+			s.logger.Error("backfillLoop: failed to backfill "+backfill.Symbol+" "+backfill.Interval+": "+err.Error(),
+				"symbol", backfill.Symbol, "interval", backfill.Interval)
+		}
+	}
+}
+
+// runBackfill stores candles chunk by chunk starting from the cursor and saves progress after each chunk.
+func (s *Currency) runBackfill(ctx context.Context, backfill model.KlineBackfill) error {
+	currency := model.Currency{ID: backfill.CurrencyID, Symbol: backfill.Symbol}
+
+	backfill.Status = model.BackfillStatusRunning
+	backfill.Error = ""
+	if err := s.saveBackfill(ctx, backfill); err != nil {
+		return err
+	}
+
+	for backfill.Cursor <= backfill.EndTime {
+		nextChunkStart, _ := s.solvePagination(backfill.Cursor, backfill.EndTime, klineChunkSize, 2, backfill.Interval)
+		chunkEnd := min(backfill.EndTime, nextChunkStart-1)
+
+		if err := s.syncKlinesChunk(ctx, currency, backfill.Interval, backfill.Cursor, chunkEnd); err != nil {
+			if ctx.Err() != nil { // stays running and is resumed after restart
+				return err
+			}
+
+			backfill.Status = model.BackfillStatusFailed
+			backfill.Error = err.Error()
+			backfill.Attempts++
+			return errors.Join(err, s.saveBackfill(ctx, backfill))
+		}
+
+		backfill.Cursor = chunkEnd + 1
+		backfill.Attempts = 0
+		if err := s.saveBackfill(ctx, backfill); err != nil {
+			return err
+		}
+
+		if backfill.Cursor > backfill.EndTime {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.backfill.RequestDelay):
+		}
+	}
+
+	backfill.Status = model.BackfillStatusDone
+	return s.saveBackfill(ctx, backfill)
+}
+
+// backfillDue tells whether the backfill may run at now, a failed one is due once its retry delay passed.
+func backfillDue(backfill model.KlineBackfill, now time.Time) bool {
+	if backfill.Status != model.BackfillStatusFailed {
+		return true
+	}
+
+	delay := backfillRetryMaxDelay
+	if shift := backfill.Attempts - 1; shift < 8 { // 2^8 minutes are past the max delay already
+		delay = min(backfillRetryDelay<<max(shift, 0), backfillRetryMaxDelay)
+	}
+
+	return now.Sub(time.UnixMilli(backfill.UpdatedAt)) >= delay
+}
+
+func (s *Currency) saveBackfill(ctx context.Context, backfill model.KlineBackfill) error {
+	backfill.UpdatedAt = time.Now().UnixMilli()
+	return s.klineBackfillRepo.Save(ctx, backfill)
+}
+
+// backfillProgress returns loaded part of the backfill range in percents.
+func backfillProgress(backfill model.KlineBackfill) float64 {
+	if backfill.Status == model.BackfillStatusDone || backfill.EndTime < backfill.StartTime {
+		return 100
+	}
+
+	progress := float64(backfill.Cursor-backfill.StartTime) / float64(backfill.EndTime+1-backfill.StartTime) * 100
+	return math.Round(min(progress, 100)*100) / 100
+}
@@ -0,0 +1,185 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+	"gexabyte/internal/model"
+	mock_repository "gexabyte/internal/repository/mock"
+	"gexabyte/pkg/clients/binance"
+	mock_binance "gexabyte/pkg/clients/binance/mock"
+	"log/slog"
+	"testing"
+	"time"
+
+	binance_connector "github.com/binance/binance-connector-go"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartBackfill(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	klineBackfillRepo := mock_repository.NewMockKlineBackfill(ctrl)
+
+	service := Currency{
+		klineBackfillRepo: klineBackfillRepo,
+
+		backfill: BackfillConfig{Months: 3, Intervals: []string{"1d"}},
+	}
+
+	now := time.Now()
+	klineBackfillRepo.EXPECT().Save(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, backfill model.KlineBackfill) error {
+			assert.Equal(t, 1, backfill.CurrencyID)
+			assert.Equal(t, "1d", backfill.Interval)
+			assert.Equal(t, model.BackfillStatusPending, backfill.Status)
+			assert.Equal(t, backfill.StartTime, backfill.Cursor)
+			assert.Equal(t, ceilDay(now.AddDate(0, -3, 0).UnixMilli()), backfill.StartTime)
+			assert.Equal(t, divDay(now.UnixMilli())-1, backfill.EndTime)
+			return nil
+		})
+
+	// nil wakeup channel must not block
+	err := service.startBackfill(context.Background(), model.Currency{ID: 1, Symbol: "BTCUSDT"})
+	assert.NoError(t, err)
+}
+
+func TestRunBackfill(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyKlineRepo := mock_repository.NewMockCurrencyKline(ctrl)
+	klineBackfillRepo := mock_repository.NewMockKlineBackfill(ctrl)
+	binanceClient := mock_binance.NewMockClient(ctrl)
+
+	service := Currency{
+		currencyKlineRepo: currencyKlineRepo,
+		klineBackfillRepo: klineBackfillRepo,
+		binanceClient:     binanceClient,
+		provider:          binance.NewProvider(binanceClient),
+	}
+
+	minute := time.Minute.Milliseconds()
+	unexpectedErr := fmt.Errorf("unexpected")
+
+	// resumed from the second chunk of 1500 candles
+	backfill := model.KlineBackfill{
+		CurrencyID: 1,
+		Symbol:     "BTCUSDT",
+		Interval:   "1m",
+		StartTime:  0,
+		EndTime:    1500*minute - 1,
+		Cursor:     1000 * minute,
+		Status:     model.BackfillStatusRunning,
+		Attempts:   2,
+	}
+
+	var saved []model.KlineBackfill
+	klineBackfillRepo.EXPECT().Save(gomock.Any(), gomock.Any()).AnyTimes().
+		DoAndReturn(func(_ context.Context, b model.KlineBackfill) error {
+			saved = append(saved, b)
+			return nil
+		})
+
+	gomock.InOrder(
+		currencyKlineRepo.EXPECT().Count(gomock.Any(), 1, "1m", 1000*minute, 1500*minute-1).Times(1).Return(0, nil),
+		currencyKlineRepo.EXPECT().Synced(gomock.Any(), 1, "1m", 1000*minute, 1500*minute-1).Times(1).Return(false, nil),
+		binanceClient.EXPECT().KlineService(gomock.Any(), "BTCUSDT", "1m", 1000*minute, 1500*minute-1, klineChunkSize).Times(1).
+			Return([]*binance_connector.KlinesResponse{{Open: "1", Close: "1", High: "1", Low: "1"}}, nil),
+		currencyKlineRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(1).Return(nil),
+		currencyKlineRepo.EXPECT().MarkSynced(gomock.Any(), 1, "1m", 1000*minute, 1500*minute-1).Times(1).Return(nil),
+	)
+
+	err := service.runBackfill(context.Background(), backfill)
+	assert.NoError(t, err)
+	assert.Equal(t, model.BackfillStatusRunning, saved[0].Status)
+	assert.Equal(t, 1500*minute, saved[1].Cursor)
+	assert.Zero(t, saved[1].Attempts, "a stored chunk resets failures")
+	assert.Equal(t, model.BackfillStatusDone, saved[len(saved)-1].Status)
+
+	// failed chunk keeps the cursor
+	saved = nil
+	currencyKlineRepo.EXPECT().Count(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(0, nil)
+	currencyKlineRepo.EXPECT().Synced(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(false, nil)
+	binanceClient.EXPECT().KlineService(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil, unexpectedErr)
+
+	err = service.runBackfill(context.Background(), backfill)
+	assert.ErrorIs(t, err, unexpectedErr)
+	last := saved[len(saved)-1]
+	assert.Equal(t, model.BackfillStatusFailed, last.Status)
+	assert.Equal(t, unexpectedErr.Error(), last.Error)
+	assert.Equal(t, 1000*minute, last.Cursor)
+	assert.Equal(t, 3, last.Attempts)
+}
+
+func TestRunBackfills(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	klineBackfillRepo := mock_repository.NewMockKlineBackfill(ctrl)
+
+	service := Currency{
+		klineBackfillRepo: klineBackfillRepo,
+		logger:            slog.Default(),
+	}
+
+	// failed just now, it waits for its retry delay
+	klineBackfillRepo.EXPECT().ListByStatus(gomock.Any(), model.BackfillStatusPending, model.BackfillStatusFailed).Times(1).
+		Return([]model.KlineBackfill{{Status: model.BackfillStatusFailed, Attempts: 1, UpdatedAt: time.Now().UnixMilli()}}, nil)
+	klineBackfillRepo.EXPECT().Save(gomock.Any(), gomock.Any()).Times(0)
+	service.runBackfills(context.Background(), model.BackfillStatusPending, model.BackfillStatusFailed)
+}
+
+func TestBackfillDue(t *testing.T) {
+	now := time.Now()
+	failed := func(attempts int, ago time.Duration) model.KlineBackfill {
+		return model.KlineBackfill{Status: model.BackfillStatusFailed, Attempts: attempts, UpdatedAt: now.Add(-ago).UnixMilli()}
+	}
+
+	assert.True(t, backfillDue(model.KlineBackfill{Status: model.BackfillStatusPending, UpdatedAt: now.UnixMilli()}, now))
+
+	assert.False(t, backfillDue(failed(1, 30*time.Second), now))
+	assert.True(t, backfillDue(failed(1, time.Minute), now))
+	assert.True(t, backfillDue(failed(0, time.Minute), now), "failed before attempts were counted")
+
+	// the delay doubles with every failure up to an hour
+	assert.False(t, backfillDue(failed(3, 3*time.Minute), now))
+	assert.True(t, backfillDue(failed(3, 4*time.Minute), now))
+	assert.False(t, backfillDue(failed(100, 59*time.Minute), now))
+	assert.True(t, backfillDue(failed(100, time.Hour), now))
+}
+
+func TestListBackfills(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyRepo := mock_repository.NewMockCurrency(ctrl)
+	klineBackfillRepo := mock_repository.NewMockKlineBackfill(ctrl)
+
+	service := Currency{
+		currencyRepo:      currencyRepo,
+		klineBackfillRepo: klineBackfillRepo,
+	}
+
+	currencyRepo.EXPECT().GetBySymbol(gomock.Any(), "BTCUSDT").Times(1).Return(model.Currency{ID: 1, Symbol: "BTCUSDT"}, nil)
+	klineBackfillRepo.EXPECT().ListByCurrency(gomock.Any(), 1).Times(1).Return([]model.KlineBackfill{
+		{CurrencyID: 1, Symbol: "BTCUSDT", Interval: "1h", StartTime: 0, EndTime: 99, Cursor: 25, Status: model.BackfillStatusRunning},
+	}, nil)
+
+	res, err := service.ListBackfills(context.Background(), "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Equal(t, 25.0, res[0].Progress)
+
+	currencyRepo.EXPECT().GetBySymbol(gomock.Any(), "UNKNOWN").Times(1).Return(model.Currency{}, model.ErrNotFound)
+	res, err = service.ListBackfills(context.Background(), "UNKNOWN")
+	assert.ErrorIs(t, err, model.ErrNotFound)
+	assert.Nil(t, res)
+}
+
+func TestBackfillProgress(t *testing.T) {
+	assert.Equal(t, 0.0, backfillProgress(model.KlineBackfill{StartTime: 0, EndTime: 99, Cursor: 0}))
+	assert.Equal(t, 50.0, backfillProgress(model.KlineBackfill{StartTime: 0, EndTime: 99, Cursor: 50}))
+	assert.Equal(t, 100.0, backfillProgress(model.KlineBackfill{StartTime: 0, EndTime: 99, Cursor: 100}))
+	assert.Equal(t, 100.0, backfillProgress(model.KlineBackfill{Status: model.BackfillStatusDone}))
+}
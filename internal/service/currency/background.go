@@ -7,6 +7,13 @@ import (
 
 func (s *Currency) RunBackgroudProcesses(ctx context.Context) {
 	go s.priceCheckLoop(ctx)
+	go s.backfillLoop(ctx)
+	go s.retentionLoop(ctx)
+	go s.rollupLoop(ctx)
+	go s.exchangeInfoLoop(ctx)
+	if s.ingest.Stream {
+		go s.streamLoop(ctx)
+	}
 }
 
 func (s *Currency) priceCheckLoop(ctx context.Context) {
@@ -15,6 +22,11 @@ func (s *Currency) priceCheckLoop(ctx context.Context) {
 		case <-ctx.Done():
 			return
 		case <-s.priceCheckTicker.C:
+			if s.streamHealthy() { // polling is the fallback of the stream
+				s.priceCheckTicker.Reset(s.priceCheckInterval)
+				continue
+			}
+
 			_, err := s.GetCurrentPrices(ctx)
 			if err != nil {
 				s.logger.Error("priceCheckLoop: failed to get current prices: " + err.Error())
@@ -0,0 +1,182 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+	"gexabyte/internal/model"
+	"gexabyte/pkg/clients/marketdata"
+	"gexabyte/pkg/workerpool"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Methods of the consensus price.
+const (
+	ConsensusMedian = "median"
+	ConsensusVWAP   = "vwap"
+)
+
+type ConsensusConfig struct {
+	// Method is median or vwap, current prices are taken from the primary provider alone when empty.
+	Method string
+	// Band is the largest relative deviation from the median a quote may have, 0.02 keeps quotes within 2%.
+	Band decimal.Decimal
+	// MinSources is how many quotes have to stay within the band for a price to be given.
+	MinSources int
+	// Timeout limits asking one venue, zero waits for venues as long as the caller does.
+	Timeout time.Duration
+}
+
+// quote is the price of the symbol on one provider, volume is its 24h volume which weighs the price.
+type quote struct {
+	source string
+	price  decimal.Decimal
+	volume decimal.Decimal
+	err    error
+}
+
+// fetchConsensusPrice asks all providers for the symbol at the same time and agrees on a price of their quotes.
+// When no provider quotes the symbol the error of the primary one is returned, so a symbol listed nowhere stays invalid.
+func (s *Currency) fetchConsensusPrice(ctx context.Context, symbol string) (model.GetCurrencyPriceDTO, error) {
+	tasks := make([]workerpool.Task[quote], 0, len(s.providers))
+	for _, provider := range s.providers {
+		tasks = append(tasks, func(ctx context.Context) (quote, error) {
+			return s.fetchQuote(ctx, provider, symbol), nil
+		})
+	}
+
+	// a venue which does not answer in time is rejected, so the others still agree within the timeout of the caller
+	quotes := make([]quote, 0, len(tasks))
+	for _, res := range workerpool.All(ctx, s.consensusPool(), tasks...) {
+		if res.Err != nil { // the caller is gone, the task was not run
+			return model.GetCurrencyPriceDTO{}, res.Err
+		}
+		quotes = append(quotes, res.Value)
+	}
+	slices.SortFunc(quotes, func(a, b quote) int { return strings.Compare(a.source, b.source) })
+
+	if failedAll(quotes) {
+		return model.GetCurrencyPriceDTO{}, primaryErr(quotes, s.provider.Name())
+	}
+
+	price, used, rejected := agree(quotes, s.consensus.Method, s.consensus.Band)
+	if len(used) < max(s.consensus.MinSources, 1) {
+		return model.GetCurrencyPriceDTO{}, fmt.Errorf("%w: %s quoted within the band by %d of %d sources",
+			model.ErrNoConsensus, symbol, len(used), len(quotes))
+	}
+
+	return model.GetCurrencyPriceDTO{
+		Symbol:   symbol,
+		Price:    price,
+		Sources:  used,
+		Rejected: rejected,
+	}, nil
+}
+
+// fetchQuote takes the last price of the symbol on the provider, with its volume when prices are weighted.
+func (s *Currency) fetchQuote(ctx context.Context, provider marketdata.Provider, symbol string) quote {
+	q := quote{source: provider.Name()}
+	if s.consensus.Method == ConsensusVWAP {
+		stat, err := provider.Stat24h(ctx, symbol)
+		q.price, q.volume, q.err = stat.LastPrice, stat.Volume, err
+		return q
+	}
+
+	price, err := provider.Price(ctx, symbol)
+	q.price, q.err = price.Price, err
+	return q
+}
+
+// agree drops failed quotes and the ones deviating from the median by more than band,
+// then prices the rest by their median or by their volume weighted average.
+// Without volumes the weighted average falls back to the median.
+func agree(quotes []quote, method string, band decimal.Decimal) (decimal.Decimal, []string, []model.RejectedQuoteDTO) {
+	rejected := make([]model.RejectedQuoteDTO, 0)
+	valid := make([]quote, 0, len(quotes))
+	for _, q := range quotes {
+		switch {
+		case q.err != nil:
+			rejected = append(rejected, model.RejectedQuoteDTO{Source: q.source, Reason: q.err.Error()})
+		case !q.price.IsPositive():
+			rejected = append(rejected, model.RejectedQuoteDTO{Source: q.source, Price: q.price, Reason: "non-positive price"})
+		default:
+			valid = append(valid, q)
+		}
+	}
+	if len(valid) == 0 {
+		return decimal.Zero, nil, rejected
+	}
+
+	mid := median(valid)
+	used := make([]string, 0, len(valid))
+	accepted := make([]quote, 0, len(valid))
+	for _, q := range valid {
+		deviation := q.price.Sub(mid).Abs().Div(mid)
+		if deviation.GreaterThan(band) {
+			rejected = append(rejected, model.RejectedQuoteDTO{
+				Source: q.source,
+				Price:  q.price,
+				Reason: "deviates " + deviation.Mul(decimal.NewFromInt(100)).Round(2).String() + "% from the median",
+			})
+			continue
+		}
+		used = append(used, q.source)
+		accepted = append(accepted, q)
+	}
+	if len(accepted) == 0 {
+		return decimal.Zero, nil, rejected
+	}
+
+	if method == ConsensusVWAP {
+		sum, volume := decimal.Zero, decimal.Zero
+		for _, q := range accepted {
+			sum = sum.Add(q.price.Mul(q.volume))
+			volume = volume.Add(q.volume)
+		}
+		if volume.IsPositive() {
+			return sum.DivRound(volume, priceScale), used, rejected
+		}
+	}
+
+	return median(accepted), used, rejected
+}
+
+// median of prices of the quotes, the mean of the middle two for an even count.
+func median(quotes []quote) decimal.Decimal {
+	prices := make([]decimal.Decimal, 0, len(quotes))
+	for _, q := range quotes {
+		prices = append(prices, q.price)
+	}
+	slices.SortFunc(prices, func(a, b decimal.Decimal) int { return a.Cmp(b) })
+
+	mid := len(prices) / 2
+	if len(prices)%2 == 1 {
+		return prices[mid]
+	}
+
+	return prices[mid-1].Add(prices[mid]).Div(decimal.NewFromInt(2))
+}
+
+func failedAll(quotes []quote) bool {
+	for _, q := range quotes {
+		if q.err == nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+// primaryErr returns the error of the primary provider, or of the first one when the primary is not among quotes.
+func primaryErr(quotes []quote, primary string) error {
+	for _, q := range quotes {
+		if q.source == primary {
+			return q.err
+		}
+	}
+
+	return quotes[0].err
+}
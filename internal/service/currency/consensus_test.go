@@ -0,0 +1,226 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"gexabyte/internal/model"
+	mock_repository "gexabyte/internal/repository/mock"
+	"gexabyte/pkg/clients/marketdata"
+	mock_marketdata "gexabyte/pkg/clients/marketdata/mock"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAgree(t *testing.T) {
+	band := decimal.RequireFromString("0.02")
+	q := func(source, price, volume string) quote {
+		return quote{source: source, price: decimal.RequireFromString(price), volume: decimal.RequireFromString(volume)}
+	}
+
+	tc := []struct {
+		name     string
+		quotes   []quote
+		method   string
+		price    string
+		used     []string
+		rejected []string
+	}{
+		{
+			name:   "median of odd count",
+			quotes: []quote{q("binance", "100", "0"), q("bybit", "101", "0"), q("kraken", "100.5", "0")},
+			method: ConsensusMedian,
+			price:  "100.5",
+			used:   []string{"binance", "bybit", "kraken"},
+		},
+		{
+			name:   "median of even count",
+			quotes: []quote{q("binance", "100", "0"), q("bybit", "101", "0")},
+			method: ConsensusMedian,
+			price:  "100.5",
+			used:   []string{"binance", "bybit"},
+		},
+		{
+			name:     "flash wick is rejected",
+			quotes:   []quote{q("binance", "100", "0"), q("bybit", "80", "0"), q("kraken", "100.4", "0")},
+			method:   ConsensusMedian,
+			price:    "100.2",
+			used:     []string{"binance", "kraken"},
+			rejected: []string{"bybit"},
+		},
+		{
+			name:   "weighted by volume",
+			quotes: []quote{q("binance", "100", "3"), q("kraken", "101", "1")},
+			method: ConsensusVWAP,
+			price:  "100.25",
+			used:   []string{"binance", "kraken"},
+		},
+		{
+			name:   "weighted without volume falls back to median",
+			quotes: []quote{q("binance", "100", "0"), q("kraken", "101", "0")},
+			method: ConsensusVWAP,
+			price:  "100.5",
+			used:   []string{"binance", "kraken"},
+		},
+		{
+			name:     "failed and non-positive quotes are rejected",
+			quotes:   []quote{q("binance", "100", "0"), {source: "bybit", err: marketdata.ErrUnavailable}, q("kraken", "0", "0")},
+			method:   ConsensusMedian,
+			price:    "100",
+			used:     []string{"binance"},
+			rejected: []string{"bybit", "kraken"},
+		},
+		{
+			name:     "nothing within the band",
+			quotes:   []quote{q("binance", "100", "0"), q("kraken", "110", "0")},
+			method:   ConsensusMedian,
+			price:    "0",
+			rejected: []string{"binance", "kraken"},
+		},
+	}
+
+	for _, test := range tc {
+		t.Run(test.name, func(t *testing.T) {
+			price, used, rejected := agree(test.quotes, test.method, band)
+
+			assert.Equal(t, test.price, price.String())
+			assert.Equal(t, test.used, used)
+
+			sources := make([]string, 0, len(rejected))
+			for _, r := range rejected {
+				sources = append(sources, r.Source)
+				assert.NotEmpty(t, r.Reason)
+			}
+			assert.ElementsMatch(t, test.rejected, sources)
+		})
+	}
+}
+
+func TestFetchConsensusPrice(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	newProvider := func(name string) *mock_marketdata.MockProvider {
+		provider := mock_marketdata.NewMockProvider(ctrl)
+		provider.EXPECT().Name().Return(name).AnyTimes()
+		return provider
+	}
+	binance, kraken, bybit := newProvider(marketdata.ProviderBinance), newProvider(marketdata.ProviderKraken), newProvider(marketdata.ProviderBybit)
+	price := func(provider *mock_marketdata.MockProvider, value string, err error) {
+		var res marketdata.Price
+		if err == nil {
+			res = marketdata.Price{Symbol: "BTCUSDT", Price: decimal.RequireFromString(value)}
+		}
+		provider.EXPECT().Price(gomock.Any(), "BTCUSDT").Times(1).Return(res, err)
+	}
+
+	service := NewCurrency(nil, nil, nil, nil, nil, nil, nil,
+		[]marketdata.Provider{binance, kraken, bybit}, nil, slog.Default(),
+		Config{
+			Consensus: ConsensusConfig{
+				Method:     ConsensusMedian,
+				Band:       decimal.RequireFromString("0.01"),
+				MinSources: 2,
+				Timeout:    50 * time.Millisecond,
+			},
+			PriceConflict:        model.PriceConflictKeepFirst,
+			ExchangeInfoInterval: time.Hour,
+		},
+	)
+
+	// a flash wick on one venue does not move the price
+	price(binance, "64000", nil)
+	price(kraken, "64100", nil)
+	price(bybit, "52000", nil)
+	res, err := service.fetchConsensusPrice(context.Background(), "BTCUSDT")
+	require.NoError(t, err)
+	assert.Equal(t, "64050", res.Price.String())
+	assert.Equal(t, []string{marketdata.ProviderBinance, marketdata.ProviderKraken}, res.Sources)
+	require.Len(t, res.Rejected, 1)
+	assert.Equal(t, marketdata.ProviderBybit, res.Rejected[0].Source)
+	assert.Equal(t, "52000", res.Rejected[0].Price.String())
+
+	// a failed venue is left out
+	price(binance, "64000", nil)
+	price(kraken, "64100", nil)
+	price(bybit, "", marketdata.ErrTimeout)
+	res, err = service.fetchConsensusPrice(context.Background(), "BTCUSDT")
+	require.NoError(t, err)
+	assert.Equal(t, []string{marketdata.ProviderBinance, marketdata.ProviderKraken}, res.Sources)
+	require.Len(t, res.Rejected, 1)
+	assert.Equal(t, marketdata.ErrTimeout.Error(), res.Rejected[0].Reason)
+
+	// a venue which hangs is cut off by the configured timeout
+	price(binance, "64000", nil)
+	price(kraken, "64100", nil)
+	bybit.EXPECT().Price(gomock.Any(), "BTCUSDT").Times(1).
+		DoAndReturn(func(ctx context.Context, _ string) (marketdata.Price, error) {
+			<-ctx.Done()
+			return marketdata.Price{}, ctx.Err()
+		})
+	res, err = service.fetchConsensusPrice(context.Background(), "BTCUSDT")
+	require.NoError(t, err)
+	assert.Equal(t, []string{marketdata.ProviderBinance, marketdata.ProviderKraken}, res.Sources)
+	require.Len(t, res.Rejected, 1)
+	assert.Equal(t, marketdata.ProviderBybit, res.Rejected[0].Source)
+
+	// too few venues agree
+	price(binance, "64000", nil)
+	price(kraken, "", marketdata.ErrInvalidSymbol)
+	price(bybit, "", marketdata.ErrUnavailable)
+	_, err = service.fetchConsensusPrice(context.Background(), "BTCUSDT")
+	assert.ErrorIs(t, err, model.ErrNoConsensus)
+
+	// every venue failed, the error of the primary one is kept
+	price(binance, "", marketdata.ErrInvalidSymbol)
+	price(kraken, "", marketdata.ErrInvalidSymbol)
+	price(bybit, "", marketdata.ErrUnavailable)
+	_, err = service.fetchConsensusPrice(context.Background(), "BTCUSDT")
+	assert.ErrorIs(t, err, marketdata.ErrInvalidSymbol)
+}
+
+func TestGetCurrentPricesConsensus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyRepo := mock_repository.NewMockCurrency(ctrl)
+	currencyPriceRepo := mock_repository.NewMockCurrencyPrice(ctrl)
+	binance := mock_marketdata.NewMockProvider(ctrl)
+	binance.EXPECT().Name().Return(marketdata.ProviderBinance).AnyTimes()
+	kraken := mock_marketdata.NewMockProvider(ctrl)
+	kraken.EXPECT().Name().Return(marketdata.ProviderKraken).AnyTimes()
+
+	service := NewCurrency(currencyRepo, currencyPriceRepo, nil, nil, nil, nil, nil,
+		[]marketdata.Provider{binance, kraken}, nil, slog.Default(),
+		Config{
+			Consensus:            ConsensusConfig{Method: ConsensusVWAP, Band: decimal.RequireFromString("0.02"), MinSources: 1},
+			PriceConflict:        model.PriceConflictKeepFirst,
+			ExchangeInfoInterval: time.Hour,
+		},
+	)
+
+	currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return([]model.Currency{{ID: 1, Symbol: "BTCUSDT", Active: true}}, nil)
+	binance.EXPECT().Stat24h(gomock.Any(), "BTCUSDT").Times(1).
+		Return(marketdata.Stat24h{LastPrice: decimal.NewFromInt(64000), Volume: decimal.NewFromInt(3)}, nil)
+	kraken.EXPECT().Stat24h(gomock.Any(), "BTCUSDT").Times(1).
+		Return(marketdata.Stat24h{}, errors.New("unexpected"))
+	currencyPriceRepo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, _ string, prices ...model.CurrencyPrice) (model.CreateCurrencyPricesRes, error) {
+			require.Len(t, prices, 1)
+			assert.Equal(t, "64000", prices[0].Price.String())
+			assert.Equal(t, model.PriceSourceConsensus, prices[0].Source)
+			return model.CreateCurrencyPricesRes{}, nil
+		})
+
+	res, err := service.GetCurrentPrices(context.Background(), "BTCUSDT")
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, []string{marketdata.ProviderBinance}, res[0].Sources)
+	require.Len(t, res[0].Rejected, 1)
+	assert.Equal(t, marketdata.ProviderKraken, res[0].Rejected[0].Source)
+}
@@ -0,0 +1,155 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+	"gexabyte/internal/model"
+	"gexabyte/pkg/clients/marketdata"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// convertHubs are assets a conversion goes through, in order, when the two assets make no pair.
+var convertHubs = []string{"USDT", "BTC"}
+
+// convertLiveTimeout limits asking the provider for a price of a leg, the stored price is taken after it.
+const convertLiveTimeout = 3 * time.Second
+
+// Convert converts the amount by the latest prices of a direct pair or of two pairs through a hub asset.
+// Live prices are preferred, a leg the provider fails to price takes its last stored price,
+// so tracked pairs keep converting while the provider is unreachable.
+func (s *Currency) Convert(ctx context.Context, req model.ConvertDTOReq) (model.ConvertDTORes, error) {
+	from, to := marketdata.NormalizeAsset(req.From), marketdata.NormalizeAsset(req.To)
+	now := time.Now().UnixMilli()
+
+	res := model.ConvertDTORes{From: from, To: to, Amount: req.Amount, Path: []model.ConvertLegDTO{}, QuoteTime: now}
+	if from == to {
+		res.Rate, res.Result = decimal.NewFromInt(1), req.Amount
+		return res, nil
+	}
+
+	pairs, err := s.convertPairs(ctx)
+	if err != nil {
+		return model.ConvertDTORes{}, err
+	}
+
+	legs := convertRoute(from, to, pairs)
+	if legs == nil {
+		return model.ConvertDTORes{}, fmt.Errorf("%w: %s to %s", model.ErrNoPath, from, to)
+	}
+
+	prices := make([]decimal.Decimal, 0, len(legs))
+	for i, leg := range legs {
+		legPrice, err := s.convertLegPrice(ctx, leg, now)
+		if err != nil {
+			return model.ConvertDTORes{}, err
+		}
+
+		prices = append(prices, legPrice.Price)
+		res.Path = append(res.Path, legPrice)
+		if i == 0 || legPrice.Time < res.QuoteTime {
+			res.QuoteTime = legPrice.Time
+		}
+	}
+
+	num, den := legRatio(legs, prices)
+	if den.IsZero() {
+		return model.ConvertDTORes{}, fmt.Errorf("zero price of %s", legSymbols(legs))
+	}
+	res.Rate = num.DivRound(den, priceScale)
+	res.Result = req.Amount.Mul(num).DivRound(den, priceScale)
+	res.AgeMs = max(now-res.QuoteTime, 0)
+
+	return res, nil
+}
+
+// convertPairs returns pairs a conversion may use by their assets: tracked ones and the ones trading on the exchange.
+// Without exchange info only tracked pairs are used, they have stored prices to fall back on.
+func (s *Currency) convertPairs(ctx context.Context) (map[marketdata.Pair]model.Currency, error) {
+	tracked, err := s.currencyRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs := make(map[marketdata.Pair]model.Currency)
+	listed, err := s.exchangeSymbols(ctx)
+	if err != nil {
+		s.logger.Warn("convertPairs: failed to get exchange info, only tracked pairs are used: " + err.Error())
+	}
+	for _, info := range listed {
+		if info.Status != model.SymbolStatusTrading {
+			continue
+		}
+		if base, quote := pairAssets(info); base != "" && quote != "" {
+			pairs[marketdata.Pair{Base: base, Quote: quote}] = info
+		}
+	}
+	for _, currency := range tracked {
+		if base, quote := pairAssets(currency); base != "" && quote != "" {
+			pairs[marketdata.Pair{Base: base, Quote: quote}] = currency
+		}
+	}
+
+	return pairs, nil
+}
+
+// convertRoute picks a pair of the two assets, or two pairs through the first hub which connects them.
+func convertRoute(from, to string, pairs map[marketdata.Pair]model.Currency) []syntheticLeg {
+	leg := func(from, to string) (syntheticLeg, bool) {
+		if currency, ok := pairs[marketdata.Pair{Base: from, Quote: to}]; ok {
+			return syntheticLeg{currency: currency, to: to}, true
+		}
+		if currency, ok := pairs[marketdata.Pair{Base: to, Quote: from}]; ok {
+			return syntheticLeg{currency: currency, inverted: true, to: to}, true
+		}
+		return syntheticLeg{}, false
+	}
+
+	if direct, ok := leg(from, to); ok {
+		return []syntheticLeg{direct}
+	}
+
+	for _, hub := range convertHubs {
+		if hub == from || hub == to {
+			continue
+		}
+		first, ok := leg(from, hub)
+		if !ok {
+			continue
+		}
+		second, ok := leg(hub, to)
+		if !ok {
+			continue
+		}
+
+		return []syntheticLeg{first, second}
+	}
+
+	return nil
+}
+
+// convertLegPrice takes the live price of the leg, or its last stored price when the provider fails.
+func (s *Currency) convertLegPrice(ctx context.Context, leg syntheticLeg, now int64) (model.ConvertLegDTO, error) {
+	res := model.ConvertLegDTO{Symbol: leg.currency.Symbol, Inverted: leg.inverted}
+
+	c, cancel := context.WithTimeout(ctx, convertLiveTimeout)
+	defer cancel()
+
+	price, err := s.quoteCurrentPrice(c, leg.currency.Symbol)
+	if err == nil {
+		res.Price, res.Time = price.Price, now
+		return res, nil
+	}
+
+	stored, storedErr := s.lastStoredPrice(ctx, leg.currency.Symbol)
+	if storedErr != nil {
+		s.logger.Error("convertLegPrice: failed to get last stored price: " + storedErr.Error())
+	}
+	if stored == nil {
+		return model.ConvertLegDTO{}, err
+	}
+
+	res.Price, res.Time, res.Stored = stored.Price, stored.Time, true
+	return res, nil
+}
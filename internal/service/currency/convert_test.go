@@ -0,0 +1,106 @@
+package currency
+
+import (
+	"context"
+	"gexabyte/internal/model"
+	"gexabyte/pkg/clients/marketdata"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertRoute(t *testing.T) {
+	pairs := map[marketdata.Pair]model.Currency{
+		{Base: "ETH", Quote: "BTC"}:  {Symbol: "ETHBTC"},
+		{Base: "ETH", Quote: "USDT"}: {Symbol: "ETHUSDT"},
+		{Base: "BTC", Quote: "USDT"}: {Symbol: "BTCUSDT"},
+		{Base: "SOL", Quote: "USDT"}: {Symbol: "SOLUSDT"},
+		{Base: "TRX", Quote: "BTC"}:  {Symbol: "TRXBTC"},
+		{Base: "LINK", Quote: "BTC"}: {Symbol: "LINKBTC"},
+	}
+
+	tc := []struct {
+		name   string
+		from   string
+		to     string
+		legs   []string
+		invert []bool
+	}{
+		{name: "direct", from: "ETH", to: "BTC", legs: []string{"ETHBTC"}, invert: []bool{false}},
+		{name: "inverted", from: "BTC", to: "ETH", legs: []string{"ETHBTC"}, invert: []bool{true}},
+		{name: "through usdt", from: "SOL", to: "ETH", legs: []string{"SOLUSDT", "ETHUSDT"}, invert: []bool{false, true}},
+		{name: "through btc", from: "TRX", to: "LINK", legs: []string{"TRXBTC", "LINKBTC"}, invert: []bool{false, true}},
+		{name: "no path", from: "SOL", to: "LINK"},
+	}
+
+	for _, test := range tc {
+		t.Run(test.name, func(t *testing.T) {
+			legs := convertRoute(test.from, test.to, pairs)
+			if test.legs == nil {
+				assert.Nil(t, legs)
+				return
+			}
+
+			invert := make([]bool, 0, len(legs))
+			for _, leg := range legs {
+				invert = append(invert, leg.inverted)
+			}
+			assert.Equal(t, test.legs, legSymbols(legs))
+			assert.Equal(t, test.invert, invert)
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, currencyRepo, currencyPriceRepo, provider := newSyntheticService(ctrl)
+	currencyRepo.EXPECT().List(gomock.Any()).Return([]model.Currency{
+		{ID: 1, Symbol: "SOLUSDT", Active: true, BaseAsset: "SOL", QuoteAsset: "USDT"},
+	}, nil).AnyTimes()
+
+	// ETHBTC trades on the exchange
+	provider.EXPECT().Price(gomock.Any(), "ETHBTC").Times(1).Return(marketdata.Price{Price: decimal.RequireFromString("0.05")}, nil)
+	res, err := service.Convert(context.Background(), model.ConvertDTOReq{From: "eth", To: "btc", Amount: decimal.RequireFromString("1.5")})
+	require.NoError(t, err)
+	assert.Equal(t, "ETH", res.From)
+	assert.Equal(t, "BTC", res.To)
+	assert.Equal(t, "0.05", res.Rate.String())
+	assert.Equal(t, "0.075", res.Result.String())
+	require.Len(t, res.Path, 1)
+	assert.False(t, res.Path[0].Stored)
+
+	// the provider is unreachable, the tracked leg takes its stored price
+	provider.EXPECT().Price(gomock.Any(), "SOLUSDT").Times(1).Return(marketdata.Price{}, marketdata.ErrUnavailable)
+	provider.EXPECT().Price(gomock.Any(), "BTCUSDT").Times(1).Return(marketdata.Price{Price: decimal.NewFromInt(60000)}, nil)
+	currencyPriceRepo.EXPECT().List(gomock.Any(), model.ListCurrencyPricesFilter{Symbols: []string{"SOLUSDT"}, Order: model.OrderDesc, Limit: 1}).Times(1).
+		Return([]model.CurrencyPriceDTO{{Symbol: "SOLUSDT", Price: decimal.NewFromInt(150), Time: 1000}}, nil)
+	res, err = service.Convert(context.Background(), model.ConvertDTOReq{From: "SOL", To: "BTC", Amount: decimal.NewFromInt(4)})
+	require.NoError(t, err)
+	assert.Equal(t, "0.0025", res.Rate.String())
+	assert.Equal(t, "0.01", res.Result.String())
+	require.Len(t, res.Path, 2)
+	assert.Equal(t, model.ConvertLegDTO{Symbol: "SOLUSDT", Price: decimal.NewFromInt(150), Time: 1000, Stored: true}, res.Path[0])
+	assert.True(t, res.Path[1].Inverted)
+	assert.Equal(t, int64(1000), res.QuoteTime)
+	assert.Positive(t, res.AgeMs)
+
+	// a pair without stored prices keeps the error of the provider
+	provider.EXPECT().Price(gomock.Any(), "BTCUSDT").Times(1).Return(marketdata.Price{}, marketdata.ErrTimeout)
+	currencyPriceRepo.EXPECT().List(gomock.Any(), gomock.Any()).Times(1).Return(nil, nil)
+	_, err = service.Convert(context.Background(), model.ConvertDTOReq{From: "USDT", To: "BTC", Amount: decimal.NewFromInt(1)})
+	assert.ErrorIs(t, err, marketdata.ErrTimeout)
+
+	_, err = service.Convert(context.Background(), model.ConvertDTOReq{From: "DOGE", To: "BTC", Amount: decimal.NewFromInt(1)})
+	assert.ErrorIs(t, err, model.ErrNoPath)
+
+	res, err = service.Convert(context.Background(), model.ConvertDTOReq{From: "XBT", To: "BTC", Amount: decimal.NewFromInt(2)})
+	require.NoError(t, err)
+	assert.Equal(t, "1", res.Rate.String())
+	assert.Equal(t, "2", res.Result.String())
+	assert.Empty(t, res.Path)
+}
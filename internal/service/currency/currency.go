@@ -2,50 +2,334 @@ package currency
 
 import (
 	"context"
+	"errors"
 	"gexabyte/internal/model"
 	"gexabyte/internal/repository"
 	"gexabyte/pkg/clients/binance"
+	"gexabyte/pkg/clients/marketdata"
 	"log/slog"
+	"slices"
+	"strings"
+	"sync"
 	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
 const LoggerGroup = "CurrencyService"
 
+// purgeBatchSize is how many prices one delete statement of a purge removes.
+const purgeBatchSize = 5000
+
+type Config struct {
+	Backfill  BackfillConfig
+	Retention RetentionConfig
+	Ingest    IngestConfig
+	Consensus ConsensusConfig
+	// PriceConflict decides which price stays for the same currency and time, see model.PriceConflictKeepFirst.
+	PriceConflict string
+	// ExchangeInfoInterval is how long symbols of the exchange are cached.
+	ExchangeInfoInterval time.Duration
+	// FetchWorkers bounds calls of a provider one request makes at the same time, zero makes them all at once.
+	FetchWorkers int
+	// FetchTaskTimeout limits each of these calls, zero limits them only by the timeout of the request.
+	FetchTaskTimeout time.Duration
+}
+
 type Currency struct {
 	currencyRepo      repository.Currency
 	currencyPriceRepo repository.CurrencyPrice
+	currencyKlineRepo repository.CurrencyKline
+	klineBackfillRepo repository.KlineBackfill
+
+	currencyPricePartitionRepo repository.CurrencyPricePartition
+	currencyRollupRepo         repository.CurrencyRollup
 
+	// binanceClient is kept for request weight of the exchange, market data is taken from providers.
 	binanceClient binance.Client
+	// provider is the primary venue of prices, candles and symbols, providers are all configured venues by name.
+	provider     marketdata.Provider
+	providers    map[string]marketdata.Provider
+	marketStream binance.MarketStream
 
 	logger *slog.Logger
 
+	fetchWorkers     int
+	fetchTaskTimeout time.Duration
+
 	priceCheckTicker   *time.Ticker
 	priceCheckInterval time.Duration
+
+	backfill       BackfillConfig
+	backfillWakeup chan struct{}
+
+	retention RetentionConfig
+
+	priceConflict string
+
+	exchangeInfoInterval time.Duration
+	exchangeInfoMu       sync.Mutex
+	exchangeInfo         map[string]model.Currency // symbols of the exchange with their metadata
+	exchangeInfoAt       time.Time
+	exchangeInfoGroup    singleflight.Group // one refresh of the exchange info at a time
+
+	rollupMu      sync.Mutex
+	rollupPending map[int]rollupRange // by currency id
+	rollupWakeup  chan struct{}
+
+	purging sync.Map // ids of currencies whose prices are being purged
+
+	ingest       IngestConfig
+	ticks        streamTicks
+	streamWakeup chan struct{}
+
+	consensus ConsensusConfig
 }
 
+// NewCurrency takes market data from the first of providers, the others are only asked by name.
 func NewCurrency(
 	currencyRepo repository.Currency,
 	currencyPriceRepo repository.CurrencyPrice,
+	currencyKlineRepo repository.CurrencyKline,
+	klineBackfillRepo repository.KlineBackfill,
+	currencyPricePartitionRepo repository.CurrencyPricePartition,
+	currencyRollupRepo repository.CurrencyRollup,
 	binanceClient binance.Client,
+	providers []marketdata.Provider,
+	marketStream binance.MarketStream,
 	logger *slog.Logger,
+	cfg Config,
 ) *Currency {
+	byName := make(map[string]marketdata.Provider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+
 	return &Currency{
 		currencyRepo:      currencyRepo,
 		currencyPriceRepo: currencyPriceRepo,
+		currencyKlineRepo: currencyKlineRepo,
+		klineBackfillRepo: klineBackfillRepo,
+
+		currencyPricePartitionRepo: currencyPricePartitionRepo,
+		currencyRollupRepo:         currencyRollupRepo,
 
 		binanceClient: binanceClient,
+		provider:      providers[0],
+		providers:     byName,
+		marketStream:  marketStream,
 
 		logger: logger.WithGroup(LoggerGroup),
 
+		fetchWorkers:     cfg.FetchWorkers,
+		fetchTaskTimeout: cfg.FetchTaskTimeout,
+
 		priceCheckTicker:   time.NewTicker(10 * time.Minute),
 		priceCheckInterval: 10 * time.Minute,
+
+		backfill:       cfg.Backfill,
+		backfillWakeup: make(chan struct{}, 1),
+
+		retention: cfg.Retention,
+
+		priceConflict: cfg.PriceConflict,
+
+		exchangeInfoInterval: cfg.ExchangeInfoInterval,
+
+		rollupWakeup: make(chan struct{}, 1),
+
+		ingest:       cfg.Ingest,
+		streamWakeup: make(chan struct{}, 1),
+
+		consensus: cfg.Consensus,
 	}
 }
 
+// Create starts tracking the symbol and schedules backfill of its candles.
+// Symbols which are not listed or not trading on the exchange are rejected with model.ErrInvalidSymbol.
 func (s *Currency) Create(ctx context.Context, symbol string) error {
-	return s.currencyRepo.Create(ctx, symbol)
+	info, err := s.lookupSymbol(ctx, symbol)
+	if err != nil {
+		return err
+	}
+
+	currency, err := s.currencyRepo.Create(ctx, info)
+	if err != nil {
+		return err
+	}
+	s.wakeStream()
+
+	if err := s.startBackfill(ctx, currency); err != nil {
+		s.logger.Error("Create: failed to start backfill: "+err.Error(), "symbol", symbol)
+	}
+
+	return nil
+}
+
+// CreateMany starts tracking the symbols one by one and reports the outcome of each, a failed symbol does not stop the rest.
+func (s *Currency) CreateMany(ctx context.Context, symbols ...string) []model.CreateCurrencyResDTO {
+	res := make([]model.CreateCurrencyResDTO, 0, len(symbols))
+	seen := make(map[string]struct{}, len(symbols))
+	for _, symbol := range symbols {
+		if _, ok := seen[symbol]; ok {
+			continue
+		}
+		seen[symbol] = struct{}{}
+
+		item := model.CreateCurrencyResDTO{Symbol: symbol, Status: model.CreateCurrencyStatusCreated}
+		if err := s.Create(ctx, symbol); err != nil {
+			item.Error = err.Error()
+			switch {
+			case errors.Is(err, model.ErrAlreadyExists):
+				item.Status = model.CreateCurrencyStatusExists
+			case errors.Is(err, model.ErrInvalidSymbol):
+				item.Status = model.CreateCurrencyStatusInvalid
+			default:
+				item.Status = model.CreateCurrencyStatusFailed
+				s.logger.Error("CreateMany: failed to create currency: "+err.Error(), "symbol", symbol)
+			}
+		}
+
+		res = append(res, item)
+	}
+
+	return res
+}
+
+// ListSymbols returns pairs of the exchange matching the filter ordered by symbol, marking the tracked ones.
+func (s *Currency) ListSymbols(ctx context.Context, filter model.ListSymbolsFilter) ([]model.SymbolDTO, error) {
+	symbols, err := s.exchangeSymbols(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	currencies, err := s.currencyRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tracked := make(map[string]struct{}, len(currencies))
+	for _, currency := range currencies {
+		tracked[currency.Symbol] = struct{}{}
+	}
+
+	search := strings.ToUpper(filter.Search)
+	res := make([]model.SymbolDTO, 0)
+	for _, info := range symbols {
+		if (filter.BaseAsset != "" && !strings.EqualFold(info.BaseAsset, filter.BaseAsset)) ||
+			(filter.QuoteAsset != "" && !strings.EqualFold(info.QuoteAsset, filter.QuoteAsset)) ||
+			(filter.Status != "" && !strings.EqualFold(info.Status, filter.Status)) ||
+			!strings.Contains(info.Symbol, search) {
+			continue
+		}
+
+		_, ok := tracked[info.Symbol]
+		res = append(res, model.SymbolDTO{
+			Symbol:     info.Symbol,
+			BaseAsset:  info.BaseAsset,
+			QuoteAsset: info.QuoteAsset,
+			Status:     info.Status,
+			TickSize:   info.TickSize,
+			StepSize:   info.StepSize,
+			Tracked:    ok,
+		})
+	}
+
+	slices.SortFunc(res, func(a, b model.SymbolDTO) int {
+		return strings.Compare(a.Symbol, b.Symbol)
+	})
+
+	return res, nil
 }
 
 func (s *Currency) List(ctx context.Context) ([]model.Currency, error) {
 	return s.currencyRepo.List(ctx)
 }
+
+// SetActive pauses or resumes polling prices of the pair, stored history is kept.
+func (s *Currency) SetActive(ctx context.Context, symbol string, active bool) (model.Currency, error) {
+	currency, err := s.currencyRepo.SetActive(ctx, symbol, active)
+	if err != nil {
+		return model.Currency{}, err
+	}
+	s.wakeStream()
+
+	return currency, nil
+}
+
+// Delete stops tracking the pair. Without purge it fails with model.ErrHasPrices while prices are stored.
+// With purge the pair is deactivated at once, its prices are removed in batches in background
+// and the pair is deleted after them. Repeated call continues an interrupted purge.
+func (s *Currency) Delete(ctx context.Context, symbol string, purge bool) error {
+	currency, err := s.currencyRepo.GetBySymbol(ctx, symbol)
+	if err != nil {
+		return err
+	}
+
+	if !purge {
+		if err := s.currencyRepo.Delete(ctx, currency.ID); err != nil {
+			return err
+		}
+		s.wakeStream()
+		return nil
+	}
+
+	if currency.Active {
+		if _, err := s.currencyRepo.SetActive(ctx, symbol, false); err != nil {
+			return err
+		}
+		s.wakeStream()
+	}
+
+	if _, running := s.purging.LoadOrStore(currency.ID, struct{}{}); !running {
+		go func() {
+			defer s.purging.Delete(currency.ID)
+			s.purgeCurrency(context.Background(), currency)
+		}()
+	}
+
+	return nil
+}
+
+// purgeCurrency removes prices of the currency batch by batch and then the currency itself.
+func (s *Currency) purgeCurrency(ctx context.Context, currency model.Currency) {
+	total := 0
+	for {
+		deleted, err := s.currencyPriceRepo.DeleteByCurrency(ctx, currency.ID, purgeBatchSize)
+		if err != nil {
+			s.logger.Error("purgeCurrency: failed to delete prices: "+err.Error(), "symbol", currency.Symbol, "deleted", total)
+			return
+		}
+
+		total += deleted
+		if deleted < purgeBatchSize {
+			break
+		}
+	}
+
+	// rollups of the currency are deleted with it, rebuilding pending ones would fail forever
+	s.dropPendingRollups(currency.ID)
+
+	if err := s.currencyRepo.Delete(ctx, currency.ID); err != nil {
+		s.logger.Error("purgeCurrency: failed to delete currency: "+err.Error(), "symbol", currency.Symbol)
+		return
+	}
+
+	s.logger.Info("purgeCurrency: currency deleted", "symbol", currency.Symbol, "prices", total)
+}
+
+// GetBinanceWeight returns request weight of the exchange spent by the service within the current minute.
+func (s *Currency) GetBinanceWeight() model.BinanceWeightDTO {
+	usage := s.binanceClient.WeightUsage()
+
+	res := model.BinanceWeightDTO{
+		Used:      usage.Used,
+		Limit:     usage.Limit,
+		ResetTime: usage.ResetAt.UnixMilli(),
+	}
+	if !usage.BlockedUntil.IsZero() {
+		res.BlockedUntil = usage.BlockedUntil.UnixMilli()
+	}
+
+	return res
+}
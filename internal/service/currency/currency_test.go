@@ -5,11 +5,15 @@ import (
 	"fmt"
 	"gexabyte/internal/model"
 	mock_repository "gexabyte/internal/repository/mock"
+	"gexabyte/pkg/clients/binance"
 	mock_binance "gexabyte/pkg/clients/binance/mock"
+	"log/slog"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestCreateCurrency(t *testing.T) {
@@ -18,23 +22,148 @@ func TestCreateCurrency(t *testing.T) {
 
 	currencyRepo := mock_repository.NewMockCurrency(ctrl)
 	currencyPriceRepo := mock_repository.NewMockCurrencyPrice(ctrl)
+	klineBackfillRepo := mock_repository.NewMockKlineBackfill(ctrl)
 	binanceClient := mock_binance.NewMockClient(ctrl)
 
 	service := Currency{
 		currencyRepo:      currencyRepo,
 		currencyPriceRepo: currencyPriceRepo,
+		klineBackfillRepo: klineBackfillRepo,
 		binanceClient:     binanceClient,
+		provider:          binance.NewProvider(binanceClient),
+		logger:            slog.Default(),
+
+		backfill:       BackfillConfig{Months: 1, Intervals: []string{"1h", "1d"}},
+		backfillWakeup: make(chan struct{}, 1),
+
+		exchangeInfoInterval: time.Hour,
+		exchangeInfo:         map[string]model.Currency{"symbol": {Symbol: "symbol", Status: model.SymbolStatusTrading}},
+		exchangeInfoAt:       time.Now(),
 	}
 
 	unexpectedErr := fmt.Errorf("unexpected")
 
-	currencyRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+	currencyRepo.EXPECT().Create(gomock.Any(), model.Currency{Symbol: "symbol", Status: model.SymbolStatusTrading}).Times(1).Return(model.Currency{ID: 1, Symbol: "symbol"}, nil)
+	klineBackfillRepo.EXPECT().Save(gomock.Any(), gomock.Any()).Times(2).Return(nil)
 	err := service.Create(context.Background(), "symbol")
 	assert.NoError(t, err)
+	assert.Len(t, service.backfillWakeup, 1)
+
+	// currency is created even if backfill is not scheduled
+	currencyRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(1).Return(model.Currency{ID: 1, Symbol: "symbol"}, nil)
+	klineBackfillRepo.EXPECT().Save(gomock.Any(), gomock.Any()).Times(1).Return(unexpectedErr)
+	err = service.Create(context.Background(), "symbol")
+	assert.NoError(t, err)
 
-	currencyRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(1).Return(unexpectedErr)
+	currencyRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(1).Return(model.Currency{}, unexpectedErr)
 	err = service.Create(context.Background(), "symbol")
 	assert.Error(t, err)
+
+	// symbols missing in the exchange info are not stored
+	currencyRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+	err = service.Create(context.Background(), "unknown")
+	assert.ErrorIs(t, err, model.ErrInvalidSymbol)
+}
+
+func TestCreateManyCurrencies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyRepo := mock_repository.NewMockCurrency(ctrl)
+
+	service := Currency{
+		currencyRepo: currencyRepo,
+		logger:       slog.Default(),
+
+		exchangeInfoInterval: time.Hour,
+		exchangeInfo: map[string]model.Currency{
+			"BTCUSDT":  {Symbol: "BTCUSDT", Status: model.SymbolStatusTrading},
+			"ETHUSDT":  {Symbol: "ETHUSDT", Status: model.SymbolStatusTrading},
+			"SOLUSDT":  {Symbol: "SOLUSDT", Status: model.SymbolStatusTrading},
+			"LUNAUSDT": {Symbol: "LUNAUSDT", Status: "BREAK"},
+		},
+		exchangeInfoAt: time.Now(),
+	}
+
+	currencyRepo.EXPECT().Create(gomock.Any(), model.Currency{Symbol: "BTCUSDT", Status: model.SymbolStatusTrading}).Times(1).
+		Return(model.Currency{ID: 1, Symbol: "BTCUSDT"}, nil)
+	currencyRepo.EXPECT().Create(gomock.Any(), model.Currency{Symbol: "ETHUSDT", Status: model.SymbolStatusTrading}).Times(1).
+		Return(model.Currency{}, model.ErrAlreadyExists)
+	currencyRepo.EXPECT().Create(gomock.Any(), model.Currency{Symbol: "SOLUSDT", Status: model.SymbolStatusTrading}).Times(1).
+		Return(model.Currency{}, fmt.Errorf("unexpected"))
+
+	// the repeated symbol is created once
+	res := service.CreateMany(context.Background(), "BTCUSDT", "ETHUSDT", "LUNAUSDT", "SOLUSDT", "BTCUSDT")
+	require.Len(t, res, 4)
+	assert.Equal(t, model.CreateCurrencyResDTO{Symbol: "BTCUSDT", Status: model.CreateCurrencyStatusCreated}, res[0])
+	assert.Equal(t, model.CreateCurrencyStatusExists, res[1].Status)
+	assert.Equal(t, model.CreateCurrencyStatusInvalid, res[2].Status)
+	assert.Contains(t, res[2].Error, "BREAK")
+	assert.Equal(t, model.CreateCurrencyStatusFailed, res[3].Status)
+}
+
+func TestListSymbols(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyRepo := mock_repository.NewMockCurrency(ctrl)
+	binanceClient := mock_binance.NewMockClient(ctrl)
+
+	service := Currency{
+		currencyRepo:         currencyRepo,
+		binanceClient:        binanceClient,
+		provider:             binance.NewProvider(binanceClient),
+		logger:               slog.Default(),
+		exchangeInfoInterval: time.Hour,
+	}
+
+	unexpectedErr := fmt.Errorf("unexpected")
+
+	eth := symbolInfo("ETHUSDT", model.SymbolStatusTrading)
+	eth.BaseAsset = "ETH"
+	ethBTC := symbolInfo("ETHBTC", model.SymbolStatusTrading)
+	ethBTC.BaseAsset, ethBTC.QuoteAsset = "ETH", "BTC"
+
+	binanceClient.EXPECT().ExchangeInfoService(gomock.Any()).Times(1).
+		Return(exchangeInfoResponse(symbolInfo("BTCUSDT", model.SymbolStatusTrading), eth, ethBTC, symbolInfo("BTCUP", "BREAK")), nil)
+	currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return([]model.Currency{{ID: 1, Symbol: "ETHUSDT"}}, nil)
+	res, err := service.ListSymbols(context.Background(), model.ListSymbolsFilter{})
+	require.NoError(t, err)
+	symbols := make([]string, 0, len(res))
+	for _, item := range res {
+		symbols = append(symbols, item.Symbol)
+	}
+	assert.Equal(t, []string{"BTCUP", "BTCUSDT", "ETHBTC", "ETHUSDT"}, symbols)
+	assert.True(t, res[3].Tracked)
+	assert.False(t, res[1].Tracked)
+
+	// the cache is fresh, filters are case insensitive
+	currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return(nil, nil)
+	res, err = service.ListSymbols(context.Background(), model.ListSymbolsFilter{BaseAsset: "eth", QuoteAsset: "USDT"})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, "ETHUSDT", res[0].Symbol)
+
+	currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return(nil, nil)
+	res, err = service.ListSymbols(context.Background(), model.ListSymbolsFilter{Status: "break", Search: "up"})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, "BTCUP", res[0].Symbol)
+
+	currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return(nil, nil)
+	res, err = service.ListSymbols(context.Background(), model.ListSymbolsFilter{Search: "DOGE"})
+	require.NoError(t, err)
+	assert.NotNil(t, res)
+	assert.Empty(t, res)
+
+	currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return(nil, unexpectedErr)
+	_, err = service.ListSymbols(context.Background(), model.ListSymbolsFilter{})
+	assert.ErrorIs(t, err, unexpectedErr)
+
+	service.exchangeInfo = nil
+	binanceClient.EXPECT().ExchangeInfoService(gomock.Any()).Times(1).Return(nil, unexpectedErr)
+	_, err = service.ListSymbols(context.Background(), model.ListSymbolsFilter{})
+	assert.ErrorIs(t, err, unexpectedErr)
 }
 
 func TestListCurrency(t *testing.T) {
@@ -49,6 +178,7 @@ func TestListCurrency(t *testing.T) {
 		currencyRepo:      currencyRepo,
 		currencyPriceRepo: currencyPriceRepo,
 		binanceClient:     binanceClient,
+		provider:          binance.NewProvider(binanceClient),
 	}
 
 	unexpectedErr := fmt.Errorf("unexpected")
@@ -63,3 +193,93 @@ func TestListCurrency(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, res)
 }
+
+func TestDeleteCurrency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyRepo := mock_repository.NewMockCurrency(ctrl)
+	currencyPriceRepo := mock_repository.NewMockCurrencyPrice(ctrl)
+
+	service := Currency{
+		currencyRepo:      currencyRepo,
+		currencyPriceRepo: currencyPriceRepo,
+		logger:            slog.Default(),
+	}
+
+	currency := model.Currency{ID: 1, Symbol: "BTCUSDT", Active: true}
+
+	currencyRepo.EXPECT().GetBySymbol(gomock.Any(), "BTCUSDT").Times(1).Return(currency, nil)
+	currencyRepo.EXPECT().Delete(gomock.Any(), 1).Times(1).Return(model.ErrHasPrices)
+	err := service.Delete(context.Background(), "BTCUSDT", false)
+	assert.ErrorIs(t, err, model.ErrHasPrices)
+
+	currencyRepo.EXPECT().GetBySymbol(gomock.Any(), "UNKNOWN").Times(1).Return(model.Currency{}, model.ErrNotFound)
+	err = service.Delete(context.Background(), "UNKNOWN", true)
+	assert.ErrorIs(t, err, model.ErrNotFound)
+
+	// purge deactivates the pair before returning, the rest goes in background
+	done := make(chan struct{})
+	currencyRepo.EXPECT().GetBySymbol(gomock.Any(), "BTCUSDT").Times(1).Return(currency, nil)
+	currencyRepo.EXPECT().SetActive(gomock.Any(), "BTCUSDT", false).Times(1).Return(model.Currency{ID: 1, Symbol: "BTCUSDT"}, nil)
+	currencyPriceRepo.EXPECT().DeleteByCurrency(gomock.Any(), 1, purgeBatchSize).Times(1).Return(0, nil)
+	currencyRepo.EXPECT().Delete(gomock.Any(), 1).Times(1).DoAndReturn(func(context.Context, int) error {
+		close(done)
+		return nil
+	})
+	err = service.Delete(context.Background(), "BTCUSDT", true)
+	assert.NoError(t, err)
+	<-done
+}
+
+func TestPurgeCurrency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyRepo := mock_repository.NewMockCurrency(ctrl)
+	currencyPriceRepo := mock_repository.NewMockCurrencyPrice(ctrl)
+
+	service := Currency{
+		currencyRepo:      currencyRepo,
+		currencyPriceRepo: currencyPriceRepo,
+		logger:            slog.Default(),
+
+		rollupPending: map[int]rollupRange{1: {startTime: 1, endTime: 2}, 2: {startTime: 3, endTime: 4}},
+	}
+
+	currency := model.Currency{ID: 1, Symbol: "BTCUSDT"}
+
+	// full batches are repeated until a short one
+	gomock.InOrder(
+		currencyPriceRepo.EXPECT().DeleteByCurrency(gomock.Any(), 1, purgeBatchSize).Times(2).Return(purgeBatchSize, nil),
+		currencyPriceRepo.EXPECT().DeleteByCurrency(gomock.Any(), 1, purgeBatchSize).Times(1).Return(10, nil),
+		currencyRepo.EXPECT().Delete(gomock.Any(), 1).Times(1).Return(nil),
+	)
+	service.purgeCurrency(context.Background(), currency)
+	assert.Equal(t, map[int]rollupRange{2: {startTime: 3, endTime: 4}}, service.rollupPending)
+
+	// the currency stays while its prices are not removed
+	currencyPriceRepo.EXPECT().DeleteByCurrency(gomock.Any(), 1, purgeBatchSize).Times(1).Return(0, fmt.Errorf("unexpected"))
+	currencyRepo.EXPECT().Delete(gomock.Any(), gomock.Any()).Times(0)
+	service.purgeCurrency(context.Background(), currency)
+}
+
+func TestGetBinanceWeight(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	binanceClient := mock_binance.NewMockClient(ctrl)
+
+	service := Currency{
+		binanceClient: binanceClient,
+		provider:      binance.NewProvider(binanceClient),
+	}
+
+	reset := time.Date(2024, time.March, 15, 10, 1, 0, 0, time.UTC)
+
+	binanceClient.EXPECT().WeightUsage().Times(1).Return(binance.WeightUsage{Used: 120, Limit: 5000, ResetAt: reset})
+	assert.Equal(t, model.BinanceWeightDTO{Used: 120, Limit: 5000, ResetTime: reset.UnixMilli()}, service.GetBinanceWeight())
+
+	binanceClient.EXPECT().WeightUsage().Times(1).Return(binance.WeightUsage{Used: 5000, Limit: 5000, ResetAt: reset, BlockedUntil: reset})
+	assert.Equal(t, reset.UnixMilli(), service.GetBinanceWeight().BlockedUntil)
+}
@@ -0,0 +1,170 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+	"gexabyte/internal/model"
+	"gexabyte/pkg/clients/marketdata"
+	"time"
+)
+
+// exchangeInfoMissRefresh is how old the cache must be to be refreshed for a symbol missing in it,
+// so requests of unknown symbols do not hit the exchange on every call.
+const exchangeInfoMissRefresh = time.Minute
+
+// exchangeInfoRefreshTimeout limits a refresh, it is shared by all waiting callers and does not end with any of them.
+const exchangeInfoRefreshTimeout = 10 * time.Second
+
+// exchangeInfoLoop refreshes symbols of the exchange on start and then every configured interval.
+func (s *Currency) exchangeInfoLoop(ctx context.Context) {
+	if err := s.syncExchangeInfo(ctx); err != nil {
+		s.logger.Error("exchangeInfoLoop: failed to sync exchange info: " + err.Error())
+	}
+
+	ticker := time.NewTicker(s.exchangeInfoInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.syncExchangeInfo(ctx); err != nil {
+				s.logger.Error("exchangeInfoLoop: failed to sync exchange info: " + err.Error())
+			}
+		}
+	}
+}
+
+// syncExchangeInfo refreshes the cache and copies changed metadata into tracked pairs.
+func (s *Currency) syncExchangeInfo(ctx context.Context) error {
+	symbols, err := s.refreshExchangeInfo(ctx)
+	if err != nil {
+		return err
+	}
+
+	currencies, err := s.currencyRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, currency := range currencies {
+		info, ok := symbols[currency.Symbol]
+		if !ok {
+			s.logger.Warn("syncExchangeInfo: tracked symbol is not listed", "symbol", currency.Symbol)
+			continue
+		}
+		if info.Status != model.SymbolStatusTrading && currency.Status != info.Status {
+			s.logger.Warn("syncExchangeInfo: tracked symbol is not trading", "symbol", currency.Symbol, "status", info.Status)
+		}
+		if currency.SameInfo(info) {
+			continue
+		}
+
+		if err := s.currencyRepo.UpdateInfo(ctx, info); err != nil {
+			s.logger.Error("syncExchangeInfo: failed to update currency: "+err.Error(), "symbol", currency.Symbol)
+		}
+	}
+
+	return nil
+}
+
+// lookupSymbol returns metadata of a symbol open for trading, otherwise model.ErrInvalidSymbol with the reason.
+// The cache is refreshed when it is older than the interval, or older than a minute and misses the symbol.
+func (s *Currency) lookupSymbol(ctx context.Context, symbol string) (model.Currency, error) {
+	cached, at := s.cachedExchangeInfo()
+
+	info, ok := cached[symbol]
+	age := time.Since(at)
+	if cached == nil || age > s.exchangeInfoInterval || (!ok && age > exchangeInfoMissRefresh) {
+		symbols, err := s.refreshExchangeInfo(ctx)
+		if err != nil && cached == nil {
+			return model.Currency{}, err
+		}
+		if err != nil {
+			s.logger.Error("lookupSymbol: failed to refresh exchange info, cached one is used: " + err.Error())
+		} else {
+			info, ok = symbols[symbol]
+		}
+	}
+
+	if !ok {
+		return model.Currency{}, fmt.Errorf("%w: %s is not listed on the exchange", model.ErrInvalidSymbol, symbol)
+	}
+	if info.Status != model.SymbolStatusTrading {
+		return model.Currency{}, fmt.Errorf("%w: %s has status %s on the exchange", model.ErrInvalidSymbol, symbol, info.Status)
+	}
+
+	return info, nil
+}
+
+// exchangeSymbols returns symbols of the exchange, the cache is refreshed when it is older than the interval.
+// The map is replaced on refresh and never changed, so it is safe to read without the lock.
+func (s *Currency) exchangeSymbols(ctx context.Context) (map[string]model.Currency, error) {
+	cached, at := s.cachedExchangeInfo()
+	if cached != nil && time.Since(at) <= s.exchangeInfoInterval {
+		return cached, nil
+	}
+
+	symbols, err := s.refreshExchangeInfo(ctx)
+	if err != nil && cached == nil {
+		return nil, err
+	}
+	if err != nil {
+		s.logger.Error("exchangeSymbols: failed to refresh exchange info, cached one is used: " + err.Error())
+		return cached, nil
+	}
+
+	return symbols, nil
+}
+
+// cachedExchangeInfo returns the cached symbols and the time they were loaded, nil if they never were.
+func (s *Currency) cachedExchangeInfo() (map[string]model.Currency, time.Time) {
+	s.exchangeInfoMu.Lock()
+	defer s.exchangeInfoMu.Unlock()
+
+	return s.exchangeInfo, s.exchangeInfoAt
+}
+
+// refreshExchangeInfo loads symbols of the exchange into the cache.
+// The request is made without exchangeInfoMu, so readers of the cache do not wait for the exchange,
+// and concurrent refreshes share one request. The request is detached from ctx of the first caller,
+// so a caller which is gone does not fail the refresh for the others.
+func (s *Currency) refreshExchangeInfo(ctx context.Context) (map[string]model.Currency, error) {
+	res, err, _ := s.exchangeInfoGroup.Do("exchangeInfo", func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), exchangeInfoRefreshTimeout)
+		defer cancel()
+
+		res, err := s.provider.Symbols(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed get exchange info: %w", err)
+		}
+
+		symbols := make(map[string]model.Currency, len(res))
+		for _, symbol := range res {
+			symbols[symbol.Symbol] = currencyFromSymbolInfo(symbol)
+		}
+
+		s.exchangeInfoMu.Lock()
+		s.exchangeInfo, s.exchangeInfoAt = symbols, time.Now()
+		s.exchangeInfoMu.Unlock()
+
+		return symbols, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res.(map[string]model.Currency), nil
+}
+
+func currencyFromSymbolInfo(info marketdata.SymbolInfo) model.Currency {
+	return model.Currency{
+		Symbol:     info.Symbol,
+		BaseAsset:  info.BaseAsset,
+		QuoteAsset: info.QuoteAsset,
+		Status:     info.Status,
+		TickSize:   info.TickSize,
+		StepSize:   info.StepSize,
+	}
+}
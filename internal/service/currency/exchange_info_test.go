@@ -0,0 +1,202 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+	"gexabyte/internal/model"
+	mock_repository "gexabyte/internal/repository/mock"
+	"gexabyte/pkg/clients/binance"
+	mock_binance "gexabyte/pkg/clients/binance/mock"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	binance_connector "github.com/binance/binance-connector-go"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func exchangeInfoResponse(symbols ...*binance_connector.SymbolInfo) *binance_connector.ExchangeInfoResponse {
+	return &binance_connector.ExchangeInfoResponse{Symbols: symbols}
+}
+
+func symbolInfo(symbol, status string) *binance_connector.SymbolInfo {
+	return &binance_connector.SymbolInfo{
+		Symbol:     symbol,
+		Status:     status,
+		BaseAsset:  "BTC",
+		QuoteAsset: "USDT",
+		Filters: []*binance_connector.SymbolFilter{
+			{FilterType: "PRICE_FILTER", TickSize: "0.01000000"},
+			{FilterType: "LOT_SIZE", StepSize: "0.00001000"},
+			{FilterType: "NOTIONAL"},
+		},
+	}
+}
+
+// symbolCurrency is the currency of symbolInfo.
+func symbolCurrency(symbol, status string) model.Currency {
+	return model.Currency{
+		Symbol:     symbol,
+		Status:     status,
+		BaseAsset:  "BTC",
+		QuoteAsset: "USDT",
+		TickSize:   decimal.RequireFromString("0.01000000"),
+		StepSize:   decimal.RequireFromString("0.00001000"),
+	}
+}
+
+func TestLookupSymbol(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	binanceClient := mock_binance.NewMockClient(ctrl)
+
+	service := Currency{
+		binanceClient:        binanceClient,
+		provider:             binance.NewProvider(binanceClient),
+		logger:               slog.Default(),
+		exchangeInfoInterval: time.Hour,
+	}
+
+	unexpectedErr := fmt.Errorf("unexpected")
+
+	// nothing cached yet
+	binanceClient.EXPECT().ExchangeInfoService(gomock.Any()).Times(1).Return(nil, unexpectedErr)
+	_, err := service.lookupSymbol(context.Background(), "BTCUSDT")
+	assert.ErrorIs(t, err, unexpectedErr)
+
+	binanceClient.EXPECT().ExchangeInfoService(gomock.Any()).Times(1).
+		Return(exchangeInfoResponse(symbolInfo("BTCUSDT", model.SymbolStatusTrading), symbolInfo("LUNAUSDT", "BREAK")), nil)
+	res, err := service.lookupSymbol(context.Background(), "BTCUSDT")
+	require.NoError(t, err)
+	assert.Equal(t, "BTC", res.BaseAsset)
+	assert.Equal(t, "USDT", res.QuoteAsset)
+	assert.True(t, res.TickSize.Equal(decimal.RequireFromString("0.01")))
+	assert.True(t, res.StepSize.Equal(decimal.RequireFromString("0.00001")))
+
+	// fresh cache is used for known and unknown symbols
+	binanceClient.EXPECT().ExchangeInfoService(gomock.Any()).Times(0)
+	_, err = service.lookupSymbol(context.Background(), "LUNAUSDT")
+	assert.ErrorIs(t, err, model.ErrInvalidSymbol)
+	assert.ErrorContains(t, err, "BREAK")
+
+	_, err = service.lookupSymbol(context.Background(), "NEWUSDT")
+	assert.ErrorIs(t, err, model.ErrInvalidSymbol)
+
+	// a miss refreshes the cache older than a minute
+	service.exchangeInfoAt = time.Now().Add(-2 * exchangeInfoMissRefresh)
+	binanceClient.EXPECT().ExchangeInfoService(gomock.Any()).Times(1).
+		Return(exchangeInfoResponse(symbolInfo("BTCUSDT", model.SymbolStatusTrading), symbolInfo("NEWUSDT", model.SymbolStatusTrading)), nil)
+	res, err = service.lookupSymbol(context.Background(), "NEWUSDT")
+	require.NoError(t, err)
+	assert.Equal(t, "NEWUSDT", res.Symbol)
+
+	// stale cache is kept when the exchange fails
+	service.exchangeInfoAt = time.Now().Add(-2 * service.exchangeInfoInterval)
+	binanceClient.EXPECT().ExchangeInfoService(gomock.Any()).Times(1).Return(nil, unexpectedErr)
+	_, err = service.lookupSymbol(context.Background(), "BTCUSDT")
+	assert.NoError(t, err)
+}
+
+func TestLookupSymbolRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	binanceClient := mock_binance.NewMockClient(ctrl)
+
+	service := Currency{
+		binanceClient:        binanceClient,
+		provider:             binance.NewProvider(binanceClient),
+		logger:               slog.Default(),
+		exchangeInfoInterval: time.Hour,
+		exchangeInfo:         map[string]model.Currency{"BTCUSDT": symbolCurrency("BTCUSDT", model.SymbolStatusTrading)},
+		exchangeInfoAt:       time.Now().Add(-2 * exchangeInfoMissRefresh),
+	}
+
+	// misses share one slow refresh of the exchange, it outlives the callers which started it
+	started, release := make(chan struct{}), make(chan struct{})
+	binanceClient.EXPECT().ExchangeInfoService(gomock.Any()).Times(1).
+		DoAndReturn(func(ctx context.Context) (*binance_connector.ExchangeInfoResponse, error) {
+			close(started)
+			<-release
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			return exchangeInfoResponse(symbolInfo("BTCUSDT", model.SymbolStatusTrading), symbolInfo("NEWUSDT", model.SymbolStatusTrading)), nil
+		})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := service.lookupSymbol(ctx, "NEWUSDT")
+			errs <- err
+		}()
+	}
+	<-started
+
+	// cached symbols are served while the refresh waits for the exchange
+	res, err := service.lookupSymbol(context.Background(), "BTCUSDT")
+	require.NoError(t, err)
+	assert.Equal(t, "BTCUSDT", res.Symbol)
+
+	cancel()
+	close(release)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+	_, ok := service.exchangeInfo["NEWUSDT"]
+	assert.True(t, ok, "the refresh is stored")
+}
+
+func TestSyncExchangeInfo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyRepo := mock_repository.NewMockCurrency(ctrl)
+	binanceClient := mock_binance.NewMockClient(ctrl)
+
+	service := Currency{
+		currencyRepo:         currencyRepo,
+		binanceClient:        binanceClient,
+		provider:             binance.NewProvider(binanceClient),
+		logger:               slog.Default(),
+		exchangeInfoInterval: time.Hour,
+	}
+
+	unexpectedErr := fmt.Errorf("unexpected")
+
+	btc := symbolCurrency("BTCUSDT", model.SymbolStatusTrading)
+	eth := symbolCurrency("ETHUSDT", "HALT")
+
+	stored := btc
+	stored.ID, stored.Active = 1, true
+
+	binanceClient.EXPECT().ExchangeInfoService(gomock.Any()).Times(1).
+		Return(exchangeInfoResponse(symbolInfo("BTCUSDT", model.SymbolStatusTrading), symbolInfo("ETHUSDT", "HALT")), nil)
+	currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return([]model.Currency{
+		stored,
+		{ID: 2, Symbol: "ETHUSDT", Active: true},
+		{ID: 3, Symbol: "GONEUSDT", Active: true},
+	}, nil)
+	// only the pair with changed metadata is updated
+	currencyRepo.EXPECT().UpdateInfo(gomock.Any(), eth).Times(1).Return(nil)
+	assert.NoError(t, service.syncExchangeInfo(context.Background()))
+	assert.Len(t, service.exchangeInfo, 2)
+
+	binanceClient.EXPECT().ExchangeInfoService(gomock.Any()).Times(1).Return(nil, unexpectedErr)
+	assert.ErrorIs(t, service.syncExchangeInfo(context.Background()), unexpectedErr)
+
+	binanceClient.EXPECT().ExchangeInfoService(gomock.Any()).Times(1).Return(exchangeInfoResponse(), nil)
+	currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return(nil, unexpectedErr)
+	assert.ErrorIs(t, service.syncExchangeInfo(context.Background()), unexpectedErr)
+}
@@ -2,12 +2,19 @@ package currency
 
 import (
 	"context"
+	"errors"
 	"gexabyte/internal/model"
+	"gexabyte/pkg/clients/marketdata"
 	"math"
-	"strconv"
 	"time"
 )
 
+// klineChunkSize is the max amount of candles asked in one request, venues other than binance return fewer.
+const klineChunkSize = 1000
+
+// klineSyncDelay is how long after its end a chunk is still asked again for missing candles.
+const klineSyncDelay = time.Minute
+
 /*
 Чтобы получать корректные значения мне нужно для начала понять по какому принципу берутся интервалы на бинансе.
 Они округляются в зависимости от выбранного интервала, нужно потыкать чтобы понять как правильно округлять значения.
@@ -26,55 +33,195 @@ import (
 Так, секунды, минуты и часы считать будет легко, потому что можно просто используя ceil
 */
 func (s *Currency) GetPriceHistorical(ctx context.Context, req model.GetCurrencyPriceHistoricalDTOReq) (*model.GetCurrencyPriceHistoricalDTORes, error) {
-	st, mp := s.solvePagination(req.StartTime, req.EndTime, req.Limit, req.Page, req.Interval)
-	req.StartTime = st
+	currency, err := s.currencyRepo.GetBySymbol(ctx, req.Symbol)
+	if errors.Is(err, model.ErrNotFound) {
+		// candles are stored only for tracked symbols, others are made of tracked legs or proxied from the provider
+		legs, err := s.lookupSyntheticLegs(ctx, req.Symbol)
+		if err != nil {
+			return nil, err
+		}
+		if legs != nil {
+			return s.syntheticPriceHistorical(ctx, req, legs)
+		}
 
-	res, err := s.binanceClient.KlineService(ctx, req.Symbol, req.Interval, req.StartTime, req.EndTime, req.Limit)
+		return s.fetchPriceHistorical(ctx, req)
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	prices := make([]model.CurrencyPriceInterval, 0, len(res))
-	for i := 0; i < len(res); i++ {
-		r := res[i]
+	return s.readPriceHistorical(ctx, currency, req)
+}
 
-		openPrice, err := strconv.ParseFloat(r.Open, 64)
-		if err != nil {
+// readPriceHistorical serves candles from currency_kline.
+// Closed candles which are missing in the window of the requested page are fetched from the provider once and stored,
+// the rest of the range is left to backfill, so a long range costs no more requests than one page.
+// The still open candle is never stored and is fetched live when its page is requested.
+func (s *Currency) readPriceHistorical(ctx context.Context, currency model.Currency, req model.GetCurrencyPriceHistoricalDTOReq) (*model.GetCurrencyPriceHistoricalDTORes, error) {
+	pageStart, maxPage := s.solvePagination(req.StartTime, req.EndTime, req.Limit, req.Page, req.Interval)
+	nextPageStart, _ := s.solvePagination(req.StartTime, req.EndTime, req.Limit, req.Page+1, req.Interval)
+	pageEnd := min(req.EndTime, nextPageStart-1)
+
+	liveOpenTime := klineOpenTime(time.Now().UnixMilli(), req.Interval)
+	closedEndTime := min(pageEnd, liveOpenTime-1)
+
+	prices := make([]model.CurrencyPriceInterval, 0, req.Limit)
+	if pageStart <= closedEndTime {
+		if err := s.syncKlines(ctx, currency, req.Interval, pageStart, closedEndTime); err != nil {
 			return nil, err
 		}
-		closePrice, err := strconv.ParseFloat(r.Close, 64)
+
+		klines, err := s.currencyKlineRepo.List(ctx, currency.ID, req.Interval, pageStart, closedEndTime, req.Limit, 0)
 		if err != nil {
 			return nil, err
 		}
-		highPrice, err := strconv.ParseFloat(r.High, 64)
-		if err != nil {
-			return nil, err
+
+		for _, k := range klines {
+			prices = append(prices, model.CurrencyPriceInterval{
+				OpenPrice:  k.OpenPrice,
+				ClosePrice: k.ClosePrice,
+
+				HighPrice: k.HighPrice,
+				LowPrice:  k.LowPrice,
+
+				OpenTime:  k.OpenTime,
+				CloseTime: k.CloseTime,
+			})
 		}
-		lowPrice, err := strconv.ParseFloat(r.Low, 64)
+	}
+
+	if pageStart <= liveOpenTime && liveOpenTime <= pageEnd && len(prices) < req.Limit {
+		res, err := s.provider.Candles(ctx, currency.Symbol, req.Interval, liveOpenTime, req.EndTime, 1)
 		if err != nil {
 			return nil, err
 		}
+		prices = append(prices, candlePrices(res)...)
+	}
 
-		prices = append(prices, model.CurrencyPriceInterval{
-			OpenPrice:  openPrice,
-			ClosePrice: closePrice,
+	return &model.GetCurrencyPriceHistoricalDTORes{
+		Page:    req.Page,
+		MaxPage: maxPage,
+
+		Prices: prices,
+	}, nil
+}
 
-			HighPrice: highPrice,
-			LowPrice:  lowPrice,
+// syncKlines stores every closed candle between startTime and endTime.
+// The range is split into chunks of klineChunkSize candles, only chunks with missing candles are requested from the provider.
+func (s *Currency) syncKlines(ctx context.Context, currency model.Currency, interval string, startTime, endTime int64) error {
+	if endTime < startTime {
+		return nil
+	}
 
-			OpenTime:  int64(r.OpenTime),
-			CloseTime: int64(r.CloseTime),
-		})
+	_, chunks := s.solvePagination(startTime, endTime, klineChunkSize, 1, interval)
+	for page := 1; page <= chunks; page++ {
+		chunkStart, _ := s.solvePagination(startTime, endTime, klineChunkSize, page, interval)
+		if chunkStart > endTime {
+			break
+		}
+		nextChunkStart, _ := s.solvePagination(startTime, endTime, klineChunkSize, page+1, interval)
+		chunkEnd := min(endTime, nextChunkStart-1)
+
+		if err := s.syncKlinesChunk(ctx, currency, interval, chunkStart, chunkEnd); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncKlinesChunk asks the provider for candles of the chunk unless they are all stored or the chunk was asked before.
+// The venue may have fewer candles than the range holds (the pair was listed later, trading was halted),
+// what it returned is all it has, so the chunk is remembered as synced once it closed more than klineSyncDelay ago.
+func (s *Currency) syncKlinesChunk(ctx context.Context, currency model.Currency, interval string, startTime, endTime int64) error {
+	_, expected := s.solvePagination(startTime, endTime, 1, 1, interval)
+
+	stored, err := s.currencyKlineRepo.Count(ctx, currency.ID, interval, startTime, endTime)
+	if err != nil {
+		return err
+	}
+	if stored >= expected {
+		return nil
+	}
+
+	synced, err := s.currencyKlineRepo.Synced(ctx, currency.ID, interval, startTime, endTime)
+	if err != nil {
+		return err
+	}
+	if synced {
+		return nil
+	}
+
+	res, err := s.provider.Candles(ctx, currency.Symbol, interval, startTime, endTime, klineChunkSize)
+	if err != nil {
+		return err
+	}
+
+	if len(res) > 0 {
+		klines := make([]model.CurrencyKline, 0, len(res))
+		for _, p := range res {
+			klines = append(klines, model.CurrencyKline{
+				CurrencyID: currency.ID,
+				Interval:   interval,
+
+				OpenPrice:  p.OpenPrice,
+				ClosePrice: p.ClosePrice,
+				HighPrice:  p.HighPrice,
+				LowPrice:   p.LowPrice,
+
+				OpenTime:  p.OpenTime,
+				CloseTime: p.CloseTime,
+			})
+		}
+
+		if err := s.currencyKlineRepo.Create(ctx, klines...); err != nil {
+			return err
+		}
+	}
+
+	// the venue may publish a just closed candle with a delay
+	if endTime >= time.Now().Add(-klineSyncDelay).UnixMilli() {
+		return nil
+	}
+
+	return s.currencyKlineRepo.MarkSynced(ctx, currency.ID, interval, startTime, endTime)
+}
+
+func (s *Currency) fetchPriceHistorical(ctx context.Context, req model.GetCurrencyPriceHistoricalDTOReq) (*model.GetCurrencyPriceHistoricalDTORes, error) {
+	st, mp := s.solvePagination(req.StartTime, req.EndTime, req.Limit, req.Page, req.Interval)
+	req.StartTime = st
+
+	res, err := s.provider.Candles(ctx, req.Symbol, req.Interval, req.StartTime, req.EndTime, req.Limit)
+	if err != nil {
+		return nil, err
 	}
 
 	return &model.GetCurrencyPriceHistoricalDTORes{
 		Page:    req.Page,
 		MaxPage: mp,
 
-		Prices: prices,
+		Prices: candlePrices(res),
 	}, nil
 }
 
+func candlePrices(candles []marketdata.Candle) []model.CurrencyPriceInterval {
+	prices := make([]model.CurrencyPriceInterval, 0, len(candles))
+	for _, c := range candles {
+		prices = append(prices, model.CurrencyPriceInterval{
+			OpenPrice:  c.OpenPrice,
+			ClosePrice: c.ClosePrice,
+
+			HighPrice: c.HighPrice,
+			LowPrice:  c.LowPrice,
+
+			OpenTime:  c.OpenTime,
+			CloseTime: c.CloseTime,
+		})
+	}
+
+	return prices
+}
+
 func (s *Currency) solvePagination(startTime, endTime int64, limit, page int, interval string) (sTime int64, maxPage int) {
 	countSkipIntervals := (page - 1) * limit
 	i := time.Second.Milliseconds() // interval
@@ -103,13 +250,13 @@ func (s *Currency) solvePagination(startTime, endTime int64, limit, page int, in
 
 	case "1M":
 		startTime, endTime = ceilMonth(startTime), divMonth(endTime)
-		st, et := time.UnixMilli(startTime), time.UnixMilli(endTime)
+		st, et := time.UnixMilli(startTime).UTC(), time.UnixMilli(endTime).UTC()
 
 		for st.Before(et) {
 			maxPage += 1
 			st = st.AddDate(0, limit, 0)
 		}
-		st = time.UnixMilli(startTime)
+		st = time.UnixMilli(startTime).UTC()
 
 		for i := 0; i < countSkipIntervals; i++ {
 			st = st.AddDate(0, 1, 0)
@@ -125,8 +272,8 @@ func ceil(x, div int64) int64 {
 }
 
 func ceilDay(in int64) int64 {
-	t := time.UnixMilli(in)
-	d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+	t := time.UnixMilli(in).UTC()
+	d := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
 
 	if !t.Equal(d) {
 		return d.AddDate(0, 0, 1).UnixMilli() // return next day
@@ -135,12 +282,12 @@ func ceilDay(in int64) int64 {
 }
 
 func divDay(in int64) int64 {
-	t := time.UnixMilli(in)
-	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local).UnixMilli()
+	t := time.UnixMilli(in).UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).UnixMilli()
 }
 
 func ceilWeek(in int64) int64 {
-	d := time.UnixMilli(ceilDay(in))
+	d := time.UnixMilli(ceilDay(in)).UTC()
 	for d.Weekday() != time.Monday {
 		d = d.AddDate(0, 0, 1)
 	}
@@ -149,7 +296,7 @@ func ceilWeek(in int64) int64 {
 }
 
 func divWeek(in int64) int64 {
-	d := time.UnixMilli(divDay(in))
+	d := time.UnixMilli(divDay(in)).UTC()
 	for d.Weekday() != time.Monday {
 		d = d.AddDate(0, 0, -1)
 	}
@@ -157,8 +304,8 @@ func divWeek(in int64) int64 {
 }
 
 func ceilMonth(in int64) int64 {
-	d := time.UnixMilli(in)
-	m := time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, time.Local)
+	d := time.UnixMilli(in).UTC()
+	m := time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, time.UTC)
 
 	if !d.Equal(m) {
 		return m.AddDate(0, 1, 0).UnixMilli()
@@ -167,6 +314,19 @@ func ceilMonth(in int64) int64 {
 }
 
 func divMonth(in int64) int64 {
-	d := time.UnixMilli(in)
-	return time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, time.Local).UnixMilli()
+	d := time.UnixMilli(in).UTC()
+	return time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+}
+
+// klineOpenTime returns open time of the candle which contains in.
+func klineOpenTime(in int64, interval string) int64 {
+	switch interval {
+	case "1w":
+		return divWeek(in)
+	case "1M":
+		return divMonth(in)
+	}
+
+	i := model.KlineInterval.GetDuration(interval).Milliseconds()
+	return (in / i) * i
 }
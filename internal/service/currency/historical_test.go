@@ -5,11 +5,14 @@ import (
 	"fmt"
 	"gexabyte/internal/model"
 	mock_repository "gexabyte/internal/repository/mock"
+	"gexabyte/pkg/clients/binance"
 	mock_binance "gexabyte/pkg/clients/binance/mock"
 	"testing"
+	"time"
 
 	binance_connector "github.com/binance/binance-connector-go"
 	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -276,12 +279,15 @@ func TestGetPriceHistorical(t *testing.T) {
 
 	currencyRepo := mock_repository.NewMockCurrency(ctrl)
 	currencyPriceRepo := mock_repository.NewMockCurrencyPrice(ctrl)
+	currencyKlineRepo := mock_repository.NewMockCurrencyKline(ctrl)
 	binanceClient := mock_binance.NewMockClient(ctrl)
 
 	service := Currency{
 		currencyRepo:      currencyRepo,
 		currencyPriceRepo: currencyPriceRepo,
+		currencyKlineRepo: currencyKlineRepo,
 		binanceClient:     binanceClient,
+		provider:          binance.NewProvider(binanceClient),
 	}
 
 	unexpectedErr := fmt.Errorf("unexpected")
@@ -314,9 +320,11 @@ func TestGetPriceHistorical(t *testing.T) {
 		checkResult func(t *testing.T, res *model.GetCurrencyPriceHistoricalDTORes, err error)
 	}{
 		{
-			name:  "OK",
+			name:  "OK untracked symbol",
 			input: func() model.GetCurrencyPriceHistoricalDTOReq { return defaultReq },
 			buildStubs: func(binance *mock_binance.MockClient) {
+				currencyRepo.EXPECT().GetBySymbol(gomock.Any(), gomock.Eq("1")).Times(1).Return(model.Currency{}, model.ErrNotFound)
+				currencyKlineRepo.EXPECT().Count(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 				binance.EXPECT().KlineService(
 					gomock.Any(),
 					gomock.Eq("1"),
@@ -332,10 +340,147 @@ func TestGetPriceHistorical(t *testing.T) {
 				assert.Equal(t, 3, res.MaxPage) // in 5sec with interval 2sec is 3 page
 			},
 		},
+		{
+			name:  "OK stored candles",
+			input: func() model.GetCurrencyPriceHistoricalDTOReq { return defaultReq },
+			buildStubs: func(binance *mock_binance.MockClient) {
+				currencyRepo.EXPECT().GetBySymbol(gomock.Any(), gomock.Eq("1")).Times(1).Return(model.Currency{ID: 1, Symbol: "1"}, nil)
+				// the first page is 1000..2999, both of its candles are stored
+				currencyKlineRepo.EXPECT().Count(gomock.Any(), 1, "1s", int64(1000), int64(2999)).Times(1).Return(2, nil)
+				binance.EXPECT().KlineService(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				currencyKlineRepo.EXPECT().List(gomock.Any(), 1, "1s", int64(1000), int64(2999), 2, 0).Times(1).Return([]model.CurrencyKline{
+					{CurrencyID: 1, Interval: "1s", OpenTime: 1000, CloseTime: 1999, OpenPrice: decimal.RequireFromString("1.1")},
+					{CurrencyID: 1, Interval: "1s", OpenTime: 2000, CloseTime: 2999, OpenPrice: decimal.RequireFromString("2.2")},
+				}, nil)
+			},
+			checkResult: func(t *testing.T, res *model.GetCurrencyPriceHistoricalDTORes, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, res.Page)
+				assert.Equal(t, 3, res.MaxPage)
+				assert.Equal(t, 2, len(res.Prices))
+				assert.Equal(t, "2.2", res.Prices[1].OpenPrice.String())
+			},
+		},
+		{
+			name:  "OK missing candles are fetched and stored",
+			input: func() model.GetCurrencyPriceHistoricalDTOReq { return defaultReq },
+			buildStubs: func(binance *mock_binance.MockClient) {
+				currencyRepo.EXPECT().GetBySymbol(gomock.Any(), gomock.Eq("1")).Times(1).Return(model.Currency{ID: 1, Symbol: "1"}, nil)
+				gomock.InOrder(
+					currencyKlineRepo.EXPECT().Count(gomock.Any(), 1, "1s", int64(1000), int64(2999)).Times(1).Return(1, nil),
+					currencyKlineRepo.EXPECT().Synced(gomock.Any(), 1, "1s", int64(1000), int64(2999)).Times(1).Return(false, nil),
+					binance.EXPECT().KlineService(gomock.Any(), "1", "1s", int64(1000), int64(2999), klineChunkSize).Times(1).Return(defaultRes, nil),
+					currencyKlineRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(1).
+						DoAndReturn(func(_ context.Context, klines ...model.CurrencyKline) error {
+							assert.Equal(t, []model.CurrencyKline{{
+								CurrencyID: 1,
+								Interval:   "1s",
+								OpenPrice:  decimal.RequireFromString("1.1"),
+								ClosePrice: decimal.RequireFromString("2.2"),
+								HighPrice:  decimal.RequireFromString("3.3"),
+								LowPrice:   decimal.RequireFromString("4.4"),
+								OpenTime:   1000,
+								CloseTime:  5000,
+							}}, klines)
+							return nil
+						}),
+					currencyKlineRepo.EXPECT().MarkSynced(gomock.Any(), 1, "1s", int64(1000), int64(2999)).Times(1).Return(nil),
+					currencyKlineRepo.EXPECT().List(gomock.Any(), 1, "1s", int64(1000), int64(2999), 2, 0).Times(1).Return(nil, nil),
+				)
+			},
+			checkResult: func(t *testing.T, res *model.GetCurrencyPriceHistoricalDTORes, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 3, res.MaxPage)
+			},
+		},
+		{
+			name: "OK only the window of the page is synced",
+			input: func() model.GetCurrencyPriceHistoricalDTOReq {
+				req := defaultReq
+				req.EndTime = 1_000_000
+				req.Page = 2
+				return req
+			},
+			buildStubs: func(binance *mock_binance.MockClient) {
+				currencyRepo.EXPECT().GetBySymbol(gomock.Any(), gomock.Eq("1")).Times(1).Return(model.Currency{ID: 1, Symbol: "1"}, nil)
+				gomock.InOrder(
+					currencyKlineRepo.EXPECT().Count(gomock.Any(), 1, "1s", int64(3000), int64(4999)).Times(1).Return(0, nil),
+					currencyKlineRepo.EXPECT().Synced(gomock.Any(), 1, "1s", int64(3000), int64(4999)).Times(1).Return(false, nil),
+					binance.EXPECT().KlineService(gomock.Any(), "1", "1s", int64(3000), int64(4999), klineChunkSize).Times(1).Return(nil, nil),
+					currencyKlineRepo.EXPECT().MarkSynced(gomock.Any(), 1, "1s", int64(3000), int64(4999)).Times(1).Return(nil),
+					currencyKlineRepo.EXPECT().List(gomock.Any(), 1, "1s", int64(3000), int64(4999), 2, 0).Times(1).Return(nil, nil),
+				)
+			},
+			checkResult: func(t *testing.T, res *model.GetCurrencyPriceHistoricalDTORes, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 2, res.Page)
+				assert.Equal(t, 500, res.MaxPage)
+			},
+		},
+		{
+			name: "OK live candle on the last page",
+			input: func() model.GetCurrencyPriceHistoricalDTOReq {
+				now := time.Now().UnixMilli()
+				return model.GetCurrencyPriceHistoricalDTOReq{
+					Symbol:    "1",
+					Interval:  "1d",
+					StartTime: klineOpenTime(now, "1d") - 24*time.Hour.Milliseconds(),
+					EndTime:   now,
+					Page:      1,
+					Limit:     2,
+				}
+			},
+			buildStubs: func(binance *mock_binance.MockClient) {
+				today := klineOpenTime(time.Now().UnixMilli(), "1d")
+				yesterday := today - 24*time.Hour.Milliseconds()
+
+				currencyRepo.EXPECT().GetBySymbol(gomock.Any(), gomock.Eq("1")).Times(1).Return(model.Currency{ID: 1, Symbol: "1"}, nil)
+				currencyKlineRepo.EXPECT().Count(gomock.Any(), 1, "1d", yesterday, today-1).Times(1).Return(1, nil)
+				currencyKlineRepo.EXPECT().List(gomock.Any(), 1, "1d", yesterday, today-1, 2, 0).Times(1).Return([]model.CurrencyKline{
+					{CurrencyID: 1, Interval: "1d", OpenTime: yesterday, CloseTime: today - 1},
+				}, nil)
+				binance.EXPECT().KlineService(gomock.Any(), "1", "1d", today, gomock.Any(), 1).Times(1).Return([]*binance_connector.KlinesResponse{
+					{OpenTime: uint64(today), CloseTime: uint64(today + 24*time.Hour.Milliseconds() - 1), Open: "1", Close: "1", High: "1", Low: "1"},
+				}, nil)
+			},
+			checkResult: func(t *testing.T, res *model.GetCurrencyPriceHistoricalDTORes, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, res.MaxPage)
+				assert.Equal(t, 2, len(res.Prices))
+			},
+		},
+		{
+			name:  "error from currency db",
+			input: func() model.GetCurrencyPriceHistoricalDTOReq { return defaultReq },
+			buildStubs: func(binance *mock_binance.MockClient) {
+				currencyRepo.EXPECT().GetBySymbol(gomock.Any(), gomock.Eq("1")).Times(1).Return(model.Currency{}, unexpectedErr)
+				binance.EXPECT().KlineService(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResult: func(t *testing.T, res *model.GetCurrencyPriceHistoricalDTORes, err error) {
+				assert.Error(t, err)
+				assert.Empty(t, res)
+			},
+		},
+		{
+			name:  "error from binance on sync",
+			input: func() model.GetCurrencyPriceHistoricalDTOReq { return defaultReq },
+			buildStubs: func(binance *mock_binance.MockClient) {
+				currencyRepo.EXPECT().GetBySymbol(gomock.Any(), gomock.Eq("1")).Times(1).Return(model.Currency{ID: 1, Symbol: "1"}, nil)
+				currencyKlineRepo.EXPECT().Count(gomock.Any(), 1, "1s", int64(1000), int64(2999)).Times(1).Return(0, nil)
+				currencyKlineRepo.EXPECT().Synced(gomock.Any(), 1, "1s", int64(1000), int64(2999)).Times(1).Return(false, nil)
+				binance.EXPECT().KlineService(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil, unexpectedErr)
+				currencyKlineRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResult: func(t *testing.T, res *model.GetCurrencyPriceHistoricalDTORes, err error) {
+				assert.Error(t, err)
+				assert.Empty(t, res)
+			},
+		},
 		{
 			name:  "error from binance",
 			input: func() model.GetCurrencyPriceHistoricalDTOReq { return defaultReq },
 			buildStubs: func(binance *mock_binance.MockClient) {
+				currencyRepo.EXPECT().GetBySymbol(gomock.Any(), gomock.Eq("1")).Times(1).Return(model.Currency{}, model.ErrNotFound)
 				binance.EXPECT().KlineService(
 					gomock.Any(),
 					gomock.Eq("1"),
@@ -362,3 +507,73 @@ func TestGetPriceHistorical(t *testing.T) {
 		})
 	}
 }
+
+func TestSyncKlines(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyKlineRepo := mock_repository.NewMockCurrencyKline(ctrl)
+	binanceClient := mock_binance.NewMockClient(ctrl)
+
+	service := Currency{
+		currencyKlineRepo: currencyKlineRepo,
+		binanceClient:     binanceClient,
+		provider:          binance.NewProvider(binanceClient),
+	}
+
+	minute := time.Minute.Milliseconds()
+	currency := model.Currency{ID: 1, Symbol: "BTCUSDT"}
+
+	// 2500 candles are split into 3 chunks, only the incomplete one is requested
+	gomock.InOrder(
+		currencyKlineRepo.EXPECT().Count(gomock.Any(), 1, "1m", int64(0), 1000*minute-1).Times(1).Return(1000, nil),
+		currencyKlineRepo.EXPECT().Count(gomock.Any(), 1, "1m", 1000*minute, 2000*minute-1).Times(1).Return(999, nil),
+		currencyKlineRepo.EXPECT().Synced(gomock.Any(), 1, "1m", 1000*minute, 2000*minute-1).Times(1).Return(false, nil),
+		binanceClient.EXPECT().KlineService(gomock.Any(), "BTCUSDT", "1m", 1000*minute, 2000*minute-1, klineChunkSize).Times(1).
+			Return([]*binance_connector.KlinesResponse{{Open: "1", Close: "1", High: "1", Low: "1"}}, nil),
+		currencyKlineRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(1).Return(nil),
+		currencyKlineRepo.EXPECT().MarkSynced(gomock.Any(), 1, "1m", 1000*minute, 2000*minute-1).Times(1).Return(nil),
+		currencyKlineRepo.EXPECT().Count(gomock.Any(), 1, "1m", 2000*minute, 2499*minute).Times(1).Return(500, nil),
+	)
+
+	err := service.syncKlines(context.Background(), currency, "1m", 0, 2499*minute)
+	assert.NoError(t, err)
+
+	// the venue had fewer candles when the chunk was asked, it is not asked again
+	gomock.InOrder(
+		currencyKlineRepo.EXPECT().Count(gomock.Any(), 1, "1m", int64(0), 999*minute).Times(1).Return(400, nil),
+		currencyKlineRepo.EXPECT().Synced(gomock.Any(), 1, "1m", int64(0), 999*minute).Times(1).Return(true, nil),
+	)
+	err = service.syncKlines(context.Background(), currency, "1m", 0, 999*minute)
+	assert.NoError(t, err)
+
+	// a venue without candles in a just closed range is asked again later
+	now := klineOpenTime(time.Now().UnixMilli(), "1m")
+	gomock.InOrder(
+		currencyKlineRepo.EXPECT().Count(gomock.Any(), 1, "1m", now-minute, now-1).Times(1).Return(0, nil),
+		currencyKlineRepo.EXPECT().Synced(gomock.Any(), 1, "1m", now-minute, now-1).Times(1).Return(false, nil),
+		binanceClient.EXPECT().KlineService(gomock.Any(), "BTCUSDT", "1m", now-minute, now-1, klineChunkSize).Times(1).Return(nil, nil),
+		currencyKlineRepo.EXPECT().MarkSynced(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0),
+	)
+	err = service.syncKlines(context.Background(), currency, "1m", now-minute, now-1)
+	assert.NoError(t, err)
+
+	// empty range
+	err = service.syncKlines(context.Background(), currency, "1m", minute, 0)
+	assert.NoError(t, err)
+}
+
+func TestKlineOpenTime(t *testing.T) {
+	assert.Equal(t, int64(60000), klineOpenTime(60000+59999, "1m"))
+	assert.Equal(t, int64(1704067200000), klineOpenTime(1704412800000, "1w")) // Fri 5 January 2024 -> Mon 1 January 2024
+	assert.Equal(t, int64(1704067200000), klineOpenTime(1706486400000, "1M")) // Mon 29 January 2024 -> 1 January 2024
+
+	// candles are aligned in UTC whatever the zone of the server is
+	local := time.Local
+	time.Local = time.FixedZone("UTC+5", 5*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	assert.Equal(t, int64(1704067200000), klineOpenTime(1706486400000, "1M"))
+	assert.Equal(t, int64(1704067200000), klineOpenTime(1704412800000, "1w"))
+	assert.Equal(t, int64(1704067200000), ceilMonth(1703980800000)) // Sun 31 December 2023 -> 1 January 2024
+}
@@ -2,13 +2,18 @@ package currency
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"gexabyte/internal/model"
-	"reflect"
-	"strconv"
+	"gexabyte/pkg/clients/marketdata"
+	"gexabyte/pkg/workerpool"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
+// priceScale is the scale prices are stored with, computed prices are rounded to it.
+const priceScale = 10
+
 // TODO: добавить проверку и обработку в хендлере если symbol не существует, написать функцию которая это проверит
 func (s *Currency) GetCurrentPrices(ctx context.Context, symbols ...string) ([]model.GetCurrencyPriceDTO, error) {
 	dbSymbols, err := s.List(ctx)
@@ -19,32 +24,55 @@ func (s *Currency) GetCurrentPrices(ctx context.Context, symbols ...string) ([]m
 	allSymbols := make([]string, 0, len(dbSymbols))
 	symbolID := make(map[string]int, len(dbSymbols))
 	for _, curr := range dbSymbols {
+		if !curr.Active { // deactivated pairs keep history but are not polled
+			continue
+		}
 		allSymbols = append(allSymbols, curr.Symbol)
 		symbolID[curr.Symbol] = curr.ID
 	}
+	synthetic := make(map[string][]syntheticLeg)
 	for _, symbol := range symbols {
-		if _, ok := symbolID[symbol]; !ok {
-			allSymbols = append(allSymbols, symbol)
+		if _, ok := symbolID[symbol]; ok {
+			continue
 		}
+
+		legs, err := s.syntheticLegs(ctx, symbol, dbSymbols)
+		if err != nil {
+			return nil, err
+		}
+		if legs != nil { // legs are tracked, so they are fetched anyway
+			synthetic[symbol] = legs
+			continue
+		}
+		allSymbols = append(allSymbols, symbol)
 	}
 
 	startReqTime := time.Now().UnixMilli()
-	symbolPrice, err := s.fetchCurrentPrices(ctx, allSymbols...)
+	symbolPrice, err := s.fetchCurrentPrices(ctx, startReqTime, allSymbols...)
 	if err != nil {
 		return nil, err
 	}
 
+	source := s.provider.Name()
+	if s.consensus.Method != "" { // flash wicks of a single venue never reach the history
+		source = model.PriceSourceConsensus
+	}
+
 	{ // update all prices and save to db and update ticker
 		saveDB := make([]model.CurrencyPrice, 0, len(symbolPrice))
 		for symbol, id := range symbolID { // save only which tracked
+			if symbolPrice[symbol].Stale { // already stored
+				continue
+			}
 			saveDB = append(saveDB, model.CurrencyPrice{
 				CurrencyID: id,
-				Price:      symbolPrice[symbol],
+				Price:      symbolPrice[symbol].Price,
 				Time:       startReqTime,
+				Source:     source,
 			})
 		}
 		if len(saveDB) > 0 { // case when db currency is empty
-			err := s.CreatePrice(ctx, saveDB...)
+			_, err := s.CreatePrice(ctx, saveDB...)
 			if err != nil {
 				return nil, err
 			}
@@ -52,82 +80,138 @@ func (s *Currency) GetCurrentPrices(ctx context.Context, symbols ...string) ([]m
 		s.priceCheckTicker.Reset(s.priceCheckInterval)
 	}
 
+	for symbol, legs := range synthetic {
+		symbolPrice[symbol] = syntheticCurrentPrice(symbol, legs, symbolPrice)
+	}
+
 	result := make([]model.GetCurrencyPriceDTO, 0, len(symbolPrice))
 	for _, symbol := range symbols {
-		result = append(result, model.GetCurrencyPriceDTO{
-			Symbol: symbol,
-			Price:  symbolPrice[symbol],
-			Time:   startReqTime,
-		})
+		result = append(result, symbolPrice[symbol])
 	}
 
 	return result, err
 }
 
-func (s *Currency) fetchCurrentPrices(ctx context.Context, symbols ...string) (map[string]float64, error) {
-	prices := make(map[string]float64, len(symbols))
-
-	type task struct {
-		symbol string
-		price  float64
-		err    error
-	}
-
-	c, cancel := context.WithTimeout(ctx, 3*time.Second)
+// fetchCurrentPrices requests prices of the symbols at reqTime.
+// While the circuit of the exchange is open the last stored prices are returned marked as stale.
+func (s *Currency) fetchCurrentPrices(ctx context.Context, reqTime int64, symbols ...string) (map[string]model.GetCurrencyPriceDTO, error) {
+	c, cancel := context.WithTimeout(ctx, fetchTimeout)
 	defer cancel()
 
-	taskFuncs := []doTaskFunc{}
+	tasks := make([]workerpool.Task[model.GetCurrencyPriceDTO], 0, len(symbols))
 	for _, symbol := range symbols {
-		taskFuncs = append(taskFuncs, func() interface{} {
-			price, err := s.fetchCurrentPrice(ctx, symbol)
+		tasks = append(tasks, func(ctx context.Context) (model.GetCurrencyPriceDTO, error) {
+			price, err := s.quoteCurrentPrice(ctx, symbol)
+			if errors.Is(err, marketdata.ErrCircuitOpen) {
+				stored, storedErr := s.lastStoredPrice(ctx, symbol)
+				if storedErr != nil {
+					s.logger.Error("fetchCurrentPrices: failed to get last stored price: " + storedErr.Error())
+				}
+				if storedErr == nil && stored != nil {
+					return *stored, nil
+				}
+			}
 			if err != nil {
-				return task{err: err}
+				return model.GetCurrencyPriceDTO{}, err
 			}
 
-			return task{
-				symbol: symbol,
-				price:  price,
-			}
+			price.Time = reqTime
+			return price, nil
 		})
 	}
 
-	priceStream := s.taskResultStream(ctx, taskFuncs...)
-	for i := 0; i < len(symbols); i++ {
-		select {
-		case <-c.Done():
-			return nil, context.DeadlineExceeded
-		case out, ok := <-priceStream:
-			if !ok {
-				return nil, fmt.Errorf("read from close channel")
-			}
-
-			res, ok := out.(task)
-			if !ok {
-				return nil, fmt.Errorf("incorrect type data: %s", reflect.TypeOf(out))
-			}
-			if res.err != nil {
-				return nil, res.err
-			}
+	items, err := workerpool.Collect(c, s.fetchPool(), tasks...)
+	if err != nil {
+		return nil, fetchErr(ctx, c, err)
+	}
 
-			prices[res.symbol] = res.price
-		}
+	prices := make(map[string]model.GetCurrencyPriceDTO, len(items))
+	for _, price := range items {
+		prices[price.Symbol] = price
 	}
 
 	return prices, nil
 }
 
-func (s *Currency) fetchCurrentPrice(ctx context.Context, symbol string) (price float64, err error) {
-	res, err := s.binanceClient.TickerPriceService(ctx, symbol)
+// lastStoredPrice returns the latest stored price of the symbol marked as stale, nil when none is stored.
+func (s *Currency) lastStoredPrice(ctx context.Context, symbol string) (*model.GetCurrencyPriceDTO, error) {
+	prices, err := s.currencyPriceRepo.List(ctx, model.ListCurrencyPricesFilter{
+		Symbols: []string{symbol},
+		Order:   model.OrderDesc,
+		Limit:   1,
+	})
+	if err != nil || len(prices) == 0 {
+		return nil, err
+	}
+
+	return &model.GetCurrencyPriceDTO{
+		Symbol: symbol,
+		Price:  prices[0].Price,
+		Time:   prices[0].Time,
+		Stale:  true,
+	}, nil
+}
+
+// quoteCurrentPrice takes the price of the primary provider, or agrees on one of all providers in consensus mode.
+func (s *Currency) quoteCurrentPrice(ctx context.Context, symbol string) (model.GetCurrencyPriceDTO, error) {
+	if s.consensus.Method != "" {
+		return s.fetchConsensusPrice(ctx, symbol)
+	}
+
+	price, err := s.fetchCurrentPrice(ctx, symbol)
 	if err != nil {
-		return 0, err
+		return model.GetCurrencyPriceDTO{}, err
 	}
 
-	return strconv.ParseFloat(res.Price, 64)
+	return model.GetCurrencyPriceDTO{Symbol: symbol, Price: price}, nil
 }
 
-func (s *Currency) CreatePrice(ctx context.Context, rates ...model.CurrencyPrice) error {
-	return s.currencyPriceRepo.Create(ctx, rates...)
+func (s *Currency) fetchCurrentPrice(ctx context.Context, symbol string) (price decimal.Decimal, err error) {
+	res, err := s.provider.Price(ctx, symbol)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	return res.Price, nil
 }
-func (s *Currency) ListPrices(ctx context.Context) ([]model.CurrencyPrice, error) {
-	return s.currencyPriceRepo.List(ctx)
+
+// CreatePrice stores prices and schedules rebuilding rollups of the inserted and replaced ones.
+// Samples of already stored currency and time are deduplicated by the configured policy.
+func (s *Currency) CreatePrice(ctx context.Context, rates ...model.CurrencyPrice) (model.CreateCurrencyPricesRes, error) {
+	res, err := s.currencyPriceRepo.Create(ctx, s.priceConflict, rates...)
+	if err != nil {
+		return model.CreateCurrencyPricesRes{}, err
+	}
+
+	s.enqueueRollup(res.Inserted...)
+	s.enqueueRollup(res.Replaced...)
+
+	if res.Deduplicated > 0 || len(res.Replaced) > 0 {
+		s.logger.Debug("CreatePrice: deduplicated prices",
+			"inserted", len(res.Inserted), "replaced", len(res.Replaced), "deduplicated", res.Deduplicated)
+	}
+
+	return res, nil
+}
+
+// ListPrices returns a page of stored prices and the cursor of the next page.
+func (s *Currency) ListPrices(ctx context.Context, filter model.ListCurrencyPricesFilter) (model.ListCurrencyPricesDTORes, error) {
+	limit := filter.Limit
+	filter.Limit++ // one extra price tells that next page exists
+
+	prices, err := s.currencyPriceRepo.List(ctx, filter)
+	if err != nil {
+		return model.ListCurrencyPricesDTORes{}, err
+	}
+
+	res := model.ListCurrencyPricesDTORes{Prices: prices}
+	if len(prices) > limit {
+		res.Prices = prices[:limit]
+		res.NextCursor = res.Prices[limit-1].Cursor().Encode()
+	}
+	if res.Prices == nil {
+		res.Prices = []model.CurrencyPriceDTO{}
+	}
+
+	return res, nil
 }
@@ -5,12 +5,17 @@ import (
 	"fmt"
 	"gexabyte/internal/model"
 	mock_repository "gexabyte/internal/repository/mock"
+	"gexabyte/pkg/clients/binance"
 	mock_binance "gexabyte/pkg/clients/binance/mock"
+	"gexabyte/pkg/clients/marketdata"
+	mock_marketdata "gexabyte/pkg/clients/marketdata/mock"
+	"log/slog"
 	"testing"
 	"time"
 
 	binance_connector "github.com/binance/binance-connector-go"
 	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -26,16 +31,39 @@ func TestCreatePrice(t *testing.T) {
 		currencyRepo:      currencyRepo,
 		currencyPriceRepo: currencyPriceRepo,
 		binanceClient:     binanceClient,
+		provider:          binance.NewProvider(binanceClient),
+
+		logger: slog.Default(),
+
+		priceConflict: model.PriceConflictKeepFirst,
+
+		rollupWakeup: make(chan struct{}, 1),
 	}
 
 	unexpectedErr := fmt.Errorf("unexpected")
 
-	currencyPriceRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(1).Return(nil)
-	err := service.CreatePrice(context.Background(), model.CurrencyPrice{})
+	stored := model.CurrencyPrice{ID: 1, CurrencyID: 1, Price: decimal.RequireFromString("1"), Time: 1}
+	repeated := model.CurrencyPrice{CurrencyID: 1, Price: decimal.RequireFromString("2"), Time: 1}
+
+	currencyPriceRepo.EXPECT().Create(gomock.Any(), gomock.Eq(model.PriceConflictKeepFirst), gomock.Any()).Times(1).
+		Return(model.CreateCurrencyPricesRes{Inserted: []model.CurrencyPrice{stored}, Deduplicated: 1}, nil)
+	res, err := service.CreatePrice(context.Background(), stored, repeated)
 	assert.NoError(t, err)
+	assert.Equal(t, 1, res.Deduplicated)
+	assert.Equal(t, map[int]rollupRange{1: {startTime: 1, endTime: 1}}, service.rollupPending, "only inserted prices go to rollups")
+
+	// keep_last overwrites the stored price, its bucket is rebuilt as well
+	service.priceConflict = model.PriceConflictKeepLast
+	replaced := model.CurrencyPrice{ID: 2, CurrencyID: 2, Price: decimal.RequireFromString("3"), Time: 5}
+	currencyPriceRepo.EXPECT().Create(gomock.Any(), gomock.Eq(model.PriceConflictKeepLast), gomock.Any()).Times(1).
+		Return(model.CreateCurrencyPricesRes{Replaced: []model.CurrencyPrice{replaced}}, nil)
+	res, err = service.CreatePrice(context.Background(), replaced)
+	assert.NoError(t, err)
+	assert.Equal(t, []model.CurrencyPrice{replaced}, res.Replaced)
+	assert.Equal(t, map[int]rollupRange{1: {startTime: 1, endTime: 1}, 2: {startTime: 5, endTime: 5}}, service.rollupPending)
 
-	currencyPriceRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(1).Return(unexpectedErr)
-	err = service.CreatePrice(context.Background(), model.CurrencyPrice{})
+	currencyPriceRepo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(model.CreateCurrencyPricesRes{}, unexpectedErr)
+	_, err = service.CreatePrice(context.Background(), model.CurrencyPrice{})
 	assert.Error(t, err)
 }
 
@@ -51,23 +79,42 @@ func TestListPrices(t *testing.T) {
 		currencyRepo:      currencyRepo,
 		currencyPriceRepo: currencyPriceRepo,
 		binanceClient:     binanceClient,
+		provider:          binance.NewProvider(binanceClient),
 	}
 
 	unexpectedErr := fmt.Errorf("unexpected")
 
-	currencyPriceRepo.EXPECT().List(gomock.Any()).Times(1).Return([]model.CurrencyPrice{}, nil)
-	res, err := service.ListPrices(context.Background())
+	prices := []model.CurrencyPriceDTO{
+		{ID: 1, Symbol: "BTCUSDT", Price: decimal.RequireFromString("1"), Time: 1},
+		{ID: 2, Symbol: "BTCUSDT", Price: decimal.RequireFromString("2"), Time: 2},
+		{ID: 3, Symbol: "BTCUSDT", Price: decimal.RequireFromString("3"), Time: 3},
+	}
+
+	// one more price than limit is requested to know about next page
+	currencyPriceRepo.EXPECT().List(gomock.Any(), model.ListCurrencyPricesFilter{Limit: 3}).Times(1).Return(prices, nil)
+	res, err := service.ListPrices(context.Background(), model.ListCurrencyPricesFilter{Limit: 2})
 	assert.NoError(t, err)
-	assert.NotNil(t, res)
+	assert.Equal(t, prices[:2], res.Prices)
+	assert.Equal(t, model.PriceCursor{Time: 2, ID: 2}.Encode(), res.NextCursor)
 
-	currencyPriceRepo.EXPECT().List(gomock.Any()).Times(1).Return(nil, unexpectedErr)
-	res, err = service.ListPrices(context.Background())
+	currencyPriceRepo.EXPECT().List(gomock.Any(), model.ListCurrencyPricesFilter{Limit: 4}).Times(1).Return(prices, nil)
+	res, err = service.ListPrices(context.Background(), model.ListCurrencyPricesFilter{Limit: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, prices, res.Prices)
+	assert.Empty(t, res.NextCursor)
+
+	currencyPriceRepo.EXPECT().List(gomock.Any(), gomock.Any()).Times(1).Return(nil, nil)
+	res, err = service.ListPrices(context.Background(), model.ListCurrencyPricesFilter{Limit: 3})
+	assert.NoError(t, err)
+	assert.NotNil(t, res.Prices)
+
+	currencyPriceRepo.EXPECT().List(gomock.Any(), gomock.Any()).Times(1).Return(nil, unexpectedErr)
+	_, err = service.ListPrices(context.Background(), model.ListCurrencyPricesFilter{Limit: 3})
 	assert.Error(t, err)
-	assert.Nil(t, res)
 }
 
 type currencyPriceMatcher struct {
-	currencyIDPrice map[int]float64
+	currencyIDPrice map[int]decimal.Decimal
 }
 
 func (c currencyPriceMatcher) Matches(x interface{}) bool {
@@ -78,7 +125,7 @@ func (c currencyPriceMatcher) Matches(x interface{}) bool {
 
 	for _, p := range prices {
 		v, ok := c.currencyIDPrice[p.CurrencyID]
-		if !ok || v != p.Price {
+		if !ok || !v.Equal(p.Price) {
 			return false
 		}
 	}
@@ -102,12 +149,17 @@ func TestGetCurrentPrices(t *testing.T) {
 		currencyRepo:      currencyRepo,
 		currencyPriceRepo: currencyPriceRepo,
 		binanceClient:     binanceClient,
+		provider:          binance.NewProvider(binanceClient),
 
 		priceCheckTicker:   time.NewTicker(10 * time.Minute),
 		priceCheckInterval: 10 * time.Minute,
+
+		logger: slog.Default(),
 	}
 
 	unexpectedErr := fmt.Errorf("unexpected")
+	circuitOpenErr := &binance.Error{Kind: binance.ErrCircuitOpen}
+	lastPriceFilter := model.ListCurrencyPricesFilter{Symbols: []string{"1"}, Order: model.OrderDesc, Limit: 1}
 
 	tc := []struct {
 		name        string
@@ -119,21 +171,21 @@ func TestGetCurrentPrices(t *testing.T) {
 			name:    "OK",
 			symbols: []string{"1"},
 			buildStubs: func() {
-				currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return([]model.Currency{{ID: 1, Symbol: "1"}}, nil)
+				currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return([]model.Currency{{ID: 1, Symbol: "1", Active: true}}, nil)
 
 				binanceClient.EXPECT().TickerPriceService(gomock.Any(), gomock.Eq("1")).Times(1).Return(&binance_connector.TickerPriceResponse{Symbol: "1", Price: "1.1"}, nil)
 
-				currencyPriceRepo.EXPECT().Create(gomock.Any(), currencyPriceMatcher{
-					currencyIDPrice: map[int]float64{
-						1: 1.1,
+				currencyPriceRepo.EXPECT().Create(gomock.Any(), gomock.Any(), currencyPriceMatcher{
+					currencyIDPrice: map[int]decimal.Decimal{
+						1: decimal.RequireFromString("1.1"),
 					},
-				}).Times(1).Return(nil)
+				}).Times(1).Return(model.CreateCurrencyPricesRes{}, nil)
 			},
 			checkResult: func(t *testing.T, res []model.GetCurrencyPriceDTO, err error) {
 				assert.NoError(t, err)
 				assert.NotEmpty(t, res)
 				assert.Equal(t, res[0].Symbol, "1")
-				assert.Equal(t, res[0].Price, 1.1)
+				assert.Equal(t, "1.1", res[0].Price.String())
 			},
 		},
 		{
@@ -141,65 +193,111 @@ func TestGetCurrentPrices(t *testing.T) {
 			symbols: []string{"2"},
 			buildStubs: func() {
 				// note than we have tracked symbol in bd, that will refresh, but will not return
-				currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return([]model.Currency{{ID: 1, Symbol: "1"}}, nil)
+				currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return([]model.Currency{{ID: 1, Symbol: "1", Active: true}}, nil)
 
-				// mb flucky test cause here is possible race
-				binanceClient.EXPECT().TickerPriceService(gomock.Any(), gomock.Any()).Times(1).Return(&binance_connector.TickerPriceResponse{Symbol: "2", Price: "2.2"}, nil)
-				binanceClient.EXPECT().TickerPriceService(gomock.Any(), gomock.Any()).Times(1).Return(&binance_connector.TickerPriceResponse{Symbol: "1", Price: "1.1"}, nil)
+				binanceClient.EXPECT().TickerPriceService(gomock.Any(), gomock.Eq("2")).Times(1).Return(&binance_connector.TickerPriceResponse{Symbol: "2", Price: "2.2"}, nil)
+				binanceClient.EXPECT().TickerPriceService(gomock.Any(), gomock.Eq("1")).Times(1).Return(&binance_connector.TickerPriceResponse{Symbol: "1", Price: "1.1"}, nil)
 
-				currencyPriceRepo.EXPECT().Create(gomock.Any(), currencyPriceMatcher{
-					currencyIDPrice: map[int]float64{
-						1: 1.1,
+				currencyPriceRepo.EXPECT().Create(gomock.Any(), gomock.Any(), currencyPriceMatcher{
+					currencyIDPrice: map[int]decimal.Decimal{
+						1: decimal.RequireFromString("1.1"),
 					},
-				}).Times(1).Return(nil)
+				}).Times(1).Return(model.CreateCurrencyPricesRes{}, nil)
 			},
 			checkResult: func(t *testing.T, res []model.GetCurrencyPriceDTO, err error) {
 				assert.NoError(t, err)
 				assert.NotEmpty(t, res)
 
-				expectedSymbolPrice := map[string]float64{
-					"2": 2.2,
+				expectedSymbolPrice := map[string]string{
+					"2": "2.2",
 				}
 
 				for _, r := range res {
 					v, ok := expectedSymbolPrice[r.Symbol]
 					assert.True(t, ok)
-					assert.Equal(t, r.Price, v)
+					assert.Equal(t, v, r.Price.String())
 				}
 
 			},
 		},
+		{
+			name:    "OK inactive symbol is not polled",
+			symbols: []string{"2"},
+			buildStubs: func() {
+				currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return([]model.Currency{{ID: 1, Symbol: "1", Active: false}}, nil)
+				binanceClient.EXPECT().TickerPriceService(gomock.Any(), gomock.Eq("2")).Times(1).Return(&binance_connector.TickerPriceResponse{Symbol: "2", Price: "2.2"}, nil)
+				currencyPriceRepo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResult: func(t *testing.T, res []model.GetCurrencyPriceDTO, err error) {
+				assert.NoError(t, err)
+				assert.Len(t, res, 1)
+				assert.Equal(t, "2.2", res[0].Price.String())
+			},
+		},
 		{
 			name:    "OK currency table is empty",
 			symbols: []string{"2"},
 			buildStubs: func() {
 				currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return(nil, nil)
 				binanceClient.EXPECT().TickerPriceService(gomock.Any(), gomock.Any()).Times(1).Return(&binance_connector.TickerPriceResponse{Symbol: "2", Price: "2.2"}, nil)
-				currencyPriceRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+				currencyPriceRepo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResult: func(t *testing.T, res []model.GetCurrencyPriceDTO, err error) {
 				assert.NoError(t, err)
 				assert.NotEmpty(t, res)
 
-				expectedSymbolPrice := map[string]float64{
-					"2": 2.2,
+				expectedSymbolPrice := map[string]string{
+					"2": "2.2",
 				}
 
 				for _, r := range res {
 					v, ok := expectedSymbolPrice[r.Symbol]
 					assert.True(t, ok)
-					assert.Equal(t, r.Price, v)
+					assert.Equal(t, v, r.Price.String())
 				}
 
 			},
 		},
+		{
+			name:    "OK stale price while circuit is open",
+			symbols: []string{"1"},
+			buildStubs: func() {
+				currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return([]model.Currency{{ID: 1, Symbol: "1", Active: true}}, nil)
+				binanceClient.EXPECT().TickerPriceService(gomock.Any(), gomock.Eq("1")).Times(1).Return(nil, circuitOpenErr)
+				currencyPriceRepo.EXPECT().List(gomock.Any(), lastPriceFilter).Times(1).Return([]model.CurrencyPriceDTO{
+					{ID: 7, Symbol: "1", Price: decimal.RequireFromString("1.05"), Time: 1700000000000},
+				}, nil)
+				// stale price is already stored
+				currencyPriceRepo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResult: func(t *testing.T, res []model.GetCurrencyPriceDTO, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []model.GetCurrencyPriceDTO{
+					{Symbol: "1", Price: decimal.RequireFromString("1.05"), Time: 1700000000000, Stale: true},
+				}, res)
+			},
+		},
+		{
+			name:    "error circuit is open and no price is stored",
+			symbols: []string{"1"},
+			buildStubs: func() {
+				currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return(nil, nil)
+				binanceClient.EXPECT().TickerPriceService(gomock.Any(), gomock.Eq("1")).Times(1).Return(nil, circuitOpenErr)
+				currencyPriceRepo.EXPECT().List(gomock.Any(), lastPriceFilter).Times(1).Return(nil, nil)
+				currencyPriceRepo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResult: func(t *testing.T, res []model.GetCurrencyPriceDTO, err error) {
+				assert.ErrorIs(t, err, binance.ErrCircuitOpen)
+				assert.Nil(t, res)
+			},
+		},
 		{
 			name:    "error from currency db",
 			symbols: []string{"1"},
 			buildStubs: func() {
 				currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return(nil, unexpectedErr)
 				binanceClient.EXPECT().TickerPriceService(gomock.Any(), gomock.Any()).Times(0)
-				currencyPriceRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+				currencyPriceRepo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResult: func(t *testing.T, res []model.GetCurrencyPriceDTO, err error) {
 				assert.Error(t, err)
@@ -212,7 +310,7 @@ func TestGetCurrentPrices(t *testing.T) {
 			buildStubs: func() {
 				currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return(nil, nil)
 				binanceClient.EXPECT().TickerPriceService(gomock.Any(), gomock.Any()).Times(1).Return(nil, unexpectedErr)
-				currencyPriceRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(0)
+				currencyPriceRepo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResult: func(t *testing.T, res []model.GetCurrencyPriceDTO, err error) {
 				assert.Error(t, err)
@@ -244,6 +342,7 @@ func TestFetchCurrentPrice(t *testing.T) {
 		currencyRepo:      currencyRepo,
 		currencyPriceRepo: currencyPriceRepo,
 		binanceClient:     binanceClient,
+		provider:          binance.NewProvider(binanceClient),
 	}
 
 	unexpectedErr := fmt.Errorf("unexpected")
@@ -257,7 +356,7 @@ func TestFetchCurrentPrice(t *testing.T) {
 		name        string
 		symbol      string
 		buildStubs  func(binance *mock_binance.MockClient)
-		checkResult func(t *testing.T, res float64, err error)
+		checkResult func(t *testing.T, res decimal.Decimal, err error)
 	}{
 		{
 			name:   "OK",
@@ -265,7 +364,7 @@ func TestFetchCurrentPrice(t *testing.T) {
 			buildStubs: func(binance *mock_binance.MockClient) {
 				binance.EXPECT().TickerPriceService(gomock.Any(), gomock.Eq("1")).Times(1).Return(defaultCurrentPrice, nil)
 			},
-			checkResult: func(t *testing.T, res float64, err error) {
+			checkResult: func(t *testing.T, res decimal.Decimal, err error) {
 				assert.NoError(t, err)
 			},
 		},
@@ -277,7 +376,7 @@ func TestFetchCurrentPrice(t *testing.T) {
 				cp.Price = "incorrect"
 				binance.EXPECT().TickerPriceService(gomock.Any(), gomock.Eq("1")).Times(1).Return(&cp, nil)
 			},
-			checkResult: func(t *testing.T, res float64, err error) {
+			checkResult: func(t *testing.T, res decimal.Decimal, err error) {
 				assert.Error(t, err)
 			},
 		},
@@ -287,7 +386,7 @@ func TestFetchCurrentPrice(t *testing.T) {
 			buildStubs: func(binance *mock_binance.MockClient) {
 				binance.EXPECT().TickerPriceService(gomock.Any(), gomock.Eq("1")).Times(1).Return(nil, unexpectedErr)
 			},
-			checkResult: func(t *testing.T, res float64, err error) {
+			checkResult: func(t *testing.T, res decimal.Decimal, err error) {
 				assert.Error(t, err)
 			},
 		},
@@ -303,3 +402,31 @@ func TestFetchCurrentPrice(t *testing.T) {
 		})
 	}
 }
+
+func TestFetchCurrentPricesTaskTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	provider := mock_marketdata.NewMockProvider(ctrl)
+
+	service := Currency{
+		provider:         provider,
+		logger:           slog.Default(),
+		fetchWorkers:     2,
+		fetchTaskTimeout: 50 * time.Millisecond,
+	}
+
+	// the hung symbol holds one worker until its own timeout, the other worker serves the rest
+	provider.EXPECT().Price(gomock.Any(), "HANGUSDT").Times(1).
+		DoAndReturn(func(ctx context.Context, _ string) (marketdata.Price, error) {
+			<-ctx.Done()
+			return marketdata.Price{}, ctx.Err()
+		})
+	provider.EXPECT().Price(gomock.Any(), "BTCUSDT").Times(1).Return(marketdata.Price{Symbol: "BTCUSDT", Price: decimal.RequireFromString("1")}, nil)
+	provider.EXPECT().Price(gomock.Any(), "ETHUSDT").Times(1).Return(marketdata.Price{Symbol: "ETHUSDT", Price: decimal.RequireFromString("2")}, nil)
+
+	started := time.Now()
+	_, err := service.fetchCurrentPrices(context.Background(), 1, "HANGUSDT", "BTCUSDT", "ETHUSDT")
+	assert.ErrorIs(t, err, marketdata.ErrTimeout)
+	assert.Less(t, time.Since(started), fetchTimeout, "the task is cut off before the timeout of the request")
+}
@@ -0,0 +1,60 @@
+package currency
+
+import (
+	"context"
+	"time"
+)
+
+// Modes of removing expired months of prices.
+const (
+	RetentionModeDrop   = "drop"
+	RetentionModeDetach = "detach"
+)
+
+type RetentionConfig struct {
+	// Months of prices kept besides the current one, zero keeps prices forever.
+	Months int
+	// AheadMonths of partitions created in advance besides the current one.
+	AheadMonths int
+	// Detach keeps expired partitions as standalone tables instead of dropping them.
+	Detach bool
+	// Interval between maintenance runs.
+	Interval time.Duration
+}
+
+// retentionLoop maintains partitions of prices on start and then every configured interval.
+func (s *Currency) retentionLoop(ctx context.Context) {
+	if err := s.maintainPrices(ctx, time.Now()); err != nil {
+		s.logger.Error("retentionLoop: failed to maintain prices: " + err.Error())
+	}
+
+	ticker := time.NewTicker(s.retention.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if err := s.maintainPrices(ctx, now); err != nil {
+				s.logger.Error("retentionLoop: failed to maintain prices: " + err.Error())
+			}
+		}
+	}
+}
+
+// maintainPrices creates partitions of the coming months and removes months older than retention.
+func (s *Currency) maintainPrices(ctx context.Context, now time.Time) error {
+	now = now.UTC()
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	if err := s.currencyPricePartitionRepo.Ensure(ctx, month.UnixMilli(), month.AddDate(0, s.retention.AheadMonths, 0).UnixMilli()); err != nil {
+		return err
+	}
+
+	if s.retention.Months <= 0 {
+		return nil
+	}
+
+	return s.currencyPricePartitionRepo.DropBefore(ctx, month.AddDate(0, -s.retention.Months, 0).UnixMilli(), s.retention.Detach)
+}
@@ -0,0 +1,94 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+	mock_repository "gexabyte/internal/repository/mock"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintainPrices(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	partitionRepo := mock_repository.NewMockCurrencyPricePartition(ctrl)
+
+	now := time.Date(2024, time.March, 15, 10, 0, 0, 0, time.UTC)
+	march := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	may := time.Date(2024, time.May, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	december := time.Date(2023, time.December, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+
+	unexpectedErr := fmt.Errorf("unexpected")
+
+	tc := []struct {
+		name       string
+		retention  RetentionConfig
+		buildStubs func()
+		isErr      bool
+	}{
+		{
+			name:      "drop",
+			retention: RetentionConfig{Months: 3, AheadMonths: 2},
+			buildStubs: func() {
+				partitionRepo.EXPECT().Ensure(gomock.Any(), march, may).Times(1).Return(nil)
+				partitionRepo.EXPECT().DropBefore(gomock.Any(), december, false).Times(1).Return(nil)
+			},
+		},
+		{
+			name:      "detach",
+			retention: RetentionConfig{Months: 3, AheadMonths: 2, Detach: true},
+			buildStubs: func() {
+				partitionRepo.EXPECT().Ensure(gomock.Any(), march, may).Times(1).Return(nil)
+				partitionRepo.EXPECT().DropBefore(gomock.Any(), december, true).Times(1).Return(nil)
+			},
+		},
+		{
+			name:      "keep forever",
+			retention: RetentionConfig{AheadMonths: 2},
+			buildStubs: func() {
+				partitionRepo.EXPECT().Ensure(gomock.Any(), march, may).Times(1).Return(nil)
+				partitionRepo.EXPECT().DropBefore(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+		},
+		{
+			name:      "ensure error",
+			retention: RetentionConfig{Months: 3, AheadMonths: 2},
+			buildStubs: func() {
+				partitionRepo.EXPECT().Ensure(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(unexpectedErr)
+				partitionRepo.EXPECT().DropBefore(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			isErr: true,
+		},
+		{
+			name:      "drop error",
+			retention: RetentionConfig{Months: 3, AheadMonths: 2},
+			buildStubs: func() {
+				partitionRepo.EXPECT().Ensure(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil)
+				partitionRepo.EXPECT().DropBefore(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(unexpectedErr)
+			},
+			isErr: true,
+		},
+	}
+
+	for _, test := range tc {
+		t.Run(test.name, func(t *testing.T) {
+			test.buildStubs()
+
+			service := Currency{
+				currencyPricePartitionRepo: partitionRepo,
+				retention:                  test.retention,
+			}
+
+			err := service.maintainPrices(context.Background(), now)
+			if test.isErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package currency
+
+import (
+	"context"
+	"gexabyte/internal/model"
+	"slices"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// rollupRetryInterval is how often rollups which failed to rebuild are retried.
+	rollupRetryInterval = time.Minute
+	// rollupCatchUp is how far back buckets are rebuilt on start.
+	rollupCatchUp = 24 * time.Hour
+)
+
+// GetRollups returns hourly or daily summaries of stored prices of a tracked symbol.
+func (s *Currency) GetRollups(ctx context.Context, req model.GetCurrencyRollupsDTOReq) (*model.GetCurrencyRollupsDTORes, error) {
+	currency, err := s.currencyRepo.GetBySymbol(ctx, req.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	// bucket which contains start time is included
+	rollups, err := s.currencyRollupRepo.List(ctx, currency.ID, req.Interval, klineOpenTime(req.StartTime, req.Interval), req.EndTime)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range rollups {
+		if rollups[i].Count > 0 {
+			rollups[i].AvgPrice = rollups[i].SumPrice.Div(decimal.NewFromInt(int64(rollups[i].Count)))
+		}
+	}
+	if rollups == nil {
+		rollups = []model.CurrencyRollup{}
+	}
+
+	return &model.GetCurrencyRollupsDTORes{
+		Symbol:   currency.Symbol,
+		Interval: req.Interval,
+		Rollups:  rollups,
+	}, nil
+}
+
+// rollupRange is the span of times of stored prices of one currency whose buckets have to be rebuilt.
+type rollupRange struct {
+	startTime, endTime int64
+}
+
+func (r rollupRange) join(other rollupRange) rollupRange {
+	return rollupRange{startTime: min(r.startTime, other.startTime), endTime: max(r.endTime, other.endTime)}
+}
+
+// enqueueRollup hands times of stored prices over to rollupLoop.
+func (s *Currency) enqueueRollup(prices ...model.CurrencyPrice) {
+	if len(prices) == 0 {
+		return
+	}
+
+	s.rollupMu.Lock()
+	for _, p := range prices {
+		s.addPendingRollup(p.CurrencyID, rollupRange{startTime: p.Time, endTime: p.Time})
+	}
+	s.rollupMu.Unlock()
+
+	select {
+	case s.rollupWakeup <- struct{}{}:
+	default:
+	}
+}
+
+// addPendingRollup widens the pending range of the currency, the caller must hold rollupMu.
+func (s *Currency) addPendingRollup(currencyID int, r rollupRange) {
+	if s.rollupPending == nil {
+		s.rollupPending = make(map[int]rollupRange)
+	}
+
+	if pending, ok := s.rollupPending[currencyID]; ok {
+		r = r.join(pending)
+	}
+	s.rollupPending[currencyID] = r
+}
+
+// dropPendingRollups forgets pending prices of the currency.
+func (s *Currency) dropPendingRollups(currencyID int) {
+	s.rollupMu.Lock()
+	defer s.rollupMu.Unlock()
+
+	delete(s.rollupPending, currencyID)
+}
+
+// rollupLoop rebuilds rollups of stored prices.
+// Pending prices are kept in memory, so buckets of the last rollupCatchUp are rebuilt on start
+// to cover prices which were stored right before a restart.
+func (s *Currency) rollupLoop(ctx context.Context) {
+	if err := s.catchUpRollups(ctx, time.Now()); err != nil {
+		s.logger.Error("rollupLoop: failed to catch up rollups: " + err.Error())
+	}
+
+	ticker := time.NewTicker(rollupRetryInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.flushRollups(ctx); err != nil {
+			s.logger.Error("rollupLoop: failed to rebuild rollups: " + err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.rollupWakeup:
+		case <-ticker.C:
+		}
+	}
+}
+
+// catchUpRollups schedules rebuilding of buckets of every currency since rollupCatchUp before now.
+func (s *Currency) catchUpRollups(ctx context.Context, now time.Time) error {
+	currencies, err := s.currencyRepo.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	r := rollupRange{startTime: now.Add(-rollupCatchUp).UnixMilli(), endTime: now.UnixMilli()}
+
+	s.rollupMu.Lock()
+	defer s.rollupMu.Unlock()
+
+	for _, c := range currencies {
+		s.addPendingRollup(c.ID, r)
+	}
+
+	return nil
+}
+
+// flushRollups rebuilds buckets of pending prices from the stored ones.
+// Rebuilding is idempotent, so on failure the ranges are kept pending and rebuilt whole later.
+func (s *Currency) flushRollups(ctx context.Context) error {
+	s.rollupMu.Lock()
+	pending := s.rollupPending
+	s.rollupPending = nil
+	s.rollupMu.Unlock()
+
+	ids := make([]int, 0, len(pending))
+	for id := range pending {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	for i, id := range ids {
+		r := pending[id]
+		for _, interval := range model.RollupIntervals {
+			if err := s.currencyRollupRepo.Rebuild(ctx, id, interval, r.startTime, r.endTime); err != nil {
+				s.rollupMu.Lock()
+				for _, id := range ids[i:] {
+					s.addPendingRollup(id, pending[id])
+				}
+				s.rollupMu.Unlock()
+
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+	"gexabyte/internal/model"
+	mock_repository "gexabyte/internal/repository/mock"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFlushRollups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyRollupRepo := mock_repository.NewMockCurrencyRollup(ctrl)
+
+	service := Currency{
+		currencyRollupRepo: currencyRollupRepo,
+		rollupWakeup:       make(chan struct{}, 1),
+	}
+
+	price := func(currencyID int, time int64) model.CurrencyPrice {
+		return model.CurrencyPrice{CurrencyID: currencyID, Price: decimal.RequireFromString("10"), Time: time}
+	}
+
+	// nothing pending, nothing rebuilt
+	assert.NoError(t, service.flushRollups(context.Background()))
+
+	service.enqueueRollup(price(1, 20), price(2, 5), price(1, 10))
+	assert.Len(t, service.rollupWakeup, 1)
+	assert.Equal(t, map[int]rollupRange{1: {startTime: 10, endTime: 20}, 2: {startTime: 5, endTime: 5}}, service.rollupPending)
+
+	// ranges which failed are kept whole for the next try, even if some intervals were rebuilt
+	gomock.InOrder(
+		currencyRollupRepo.EXPECT().Rebuild(gomock.Any(), 1, model.RollupInterval1h, int64(10), int64(20)).Times(1).Return(nil),
+		currencyRollupRepo.EXPECT().Rebuild(gomock.Any(), 1, model.RollupInterval1d, int64(10), int64(20)).Times(1).Return(fmt.Errorf("unexpected")),
+	)
+	assert.Error(t, service.flushRollups(context.Background()))
+	assert.Equal(t, map[int]rollupRange{1: {startTime: 10, endTime: 20}, 2: {startTime: 5, endTime: 5}}, service.rollupPending)
+
+	// prices stored meanwhile widen the pending range
+	service.enqueueRollup(price(1, 30))
+
+	gomock.InOrder(
+		currencyRollupRepo.EXPECT().Rebuild(gomock.Any(), 1, model.RollupInterval1h, int64(10), int64(30)).Times(1).Return(nil),
+		currencyRollupRepo.EXPECT().Rebuild(gomock.Any(), 1, model.RollupInterval1d, int64(10), int64(30)).Times(1).Return(nil),
+		currencyRollupRepo.EXPECT().Rebuild(gomock.Any(), 2, model.RollupInterval1h, int64(5), int64(5)).Times(1).Return(nil),
+		currencyRollupRepo.EXPECT().Rebuild(gomock.Any(), 2, model.RollupInterval1d, int64(5), int64(5)).Times(1).Return(nil),
+	)
+	assert.NoError(t, service.flushRollups(context.Background()))
+	assert.Empty(t, service.rollupPending)
+}
+
+func TestCatchUpRollups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyRepo := mock_repository.NewMockCurrency(ctrl)
+
+	service := Currency{
+		currencyRepo: currencyRepo,
+	}
+
+	now := time.UnixMilli(100 * 24 * time.Hour.Milliseconds())
+	since := now.Add(-rollupCatchUp).UnixMilli()
+
+	currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return([]model.Currency{{ID: 1}, {ID: 2}}, nil)
+	service.enqueueRollup(model.CurrencyPrice{CurrencyID: 2, Time: 10})
+	assert.NoError(t, service.catchUpRollups(context.Background(), now))
+	assert.Equal(t, map[int]rollupRange{
+		1: {startTime: since, endTime: now.UnixMilli()},
+		2: {startTime: 10, endTime: now.UnixMilli()},
+	}, service.rollupPending)
+
+	currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return(nil, fmt.Errorf("unexpected"))
+	assert.Error(t, service.catchUpRollups(context.Background(), now))
+}
+
+func TestGetRollups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyRepo := mock_repository.NewMockCurrency(ctrl)
+	currencyRollupRepo := mock_repository.NewMockCurrencyRollup(ctrl)
+
+	service := Currency{
+		currencyRepo:       currencyRepo,
+		currencyRollupRepo: currencyRollupRepo,
+	}
+
+	day := 24 * time.Hour.Milliseconds()
+	req := model.GetCurrencyRollupsDTOReq{Symbol: "BTCUSDT", Interval: "1d", StartTime: day + 10, EndTime: 3 * day}
+
+	currencyRepo.EXPECT().GetBySymbol(gomock.Any(), "BTCUSDT").Times(1).Return(model.Currency{ID: 1, Symbol: "BTCUSDT"}, nil)
+	currencyRollupRepo.EXPECT().List(gomock.Any(), 1, "1d", day, 3*day).Times(1).Return([]model.CurrencyRollup{
+		{CurrencyID: 1, Interval: "1d", BucketTime: day, SumPrice: decimal.RequireFromString("30"), Count: 3},
+	}, nil)
+	res, err := service.GetRollups(context.Background(), req)
+	assert.NoError(t, err)
+	assert.Equal(t, "BTCUSDT", res.Symbol)
+	assert.Equal(t, "1d", res.Interval)
+	assert.Equal(t, "10", res.Rollups[0].AvgPrice.String())
+
+	currencyRepo.EXPECT().GetBySymbol(gomock.Any(), "BTCUSDT").Times(1).Return(model.Currency{}, model.ErrNotFound)
+	_, err = service.GetRollups(context.Background(), req)
+	assert.ErrorIs(t, err, model.ErrNotFound)
+
+	currencyRepo.EXPECT().GetBySymbol(gomock.Any(), "BTCUSDT").Times(1).Return(model.Currency{ID: 1, Symbol: "BTCUSDT"}, nil)
+	currencyRollupRepo.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil, fmt.Errorf("unexpected"))
+	_, err = service.GetRollups(context.Background(), req)
+	assert.Error(t, err)
+}
@@ -2,94 +2,144 @@ package currency
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"gexabyte/internal/model"
-	"reflect"
-	"strconv"
+	"gexabyte/pkg/clients/marketdata"
+	"gexabyte/pkg/workerpool"
+	"slices"
+	"strings"
 	"time"
 )
 
-func (s *Currency) GetStat24H(ctx context.Context, symbols ...string) ([]model.GetCurrencyStat24HDTO, error) {
-	return s.fetchStats24H(ctx, symbols...)
+// GetStat24H returns the rolling 24h summary of symbols.
+// With model.StatSourceLocal it is aggregated from the prices we stored,
+// so only tracked symbols are present in the result. Other sources are names of configured providers.
+func (s *Currency) GetStat24H(ctx context.Context, source string, symbols ...string) ([]model.GetCurrencyStat24HDTO, error) {
+	if source == model.StatSourceLocal {
+		return s.calcStats24H(ctx, symbols...)
+	}
+
+	provider, ok := s.providerByName(source)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", model.ErrUnknownSource, source)
+	}
+
+	return s.fetchStats24H(ctx, provider, symbols...)
 }
 
-func (s *Currency) fetchStats24H(ctx context.Context, symbols ...string) ([]model.GetCurrencyStat24HDTO, error) {
-	result := make([]model.GetCurrencyStat24HDTO, 0, len(symbols))
-	c, cancel := context.WithTimeout(ctx, 3*time.Second)
-	defer cancel()
+// providerByName returns the configured provider of the name.
+func (s *Currency) providerByName(name string) (marketdata.Provider, bool) {
+	if s.provider != nil && s.provider.Name() == name {
+		return s.provider, true
+	}
 
-	type task struct {
-		item model.GetCurrencyStat24HDTO
-		err  error
+	provider, ok := s.providers[name]
+	return provider, ok
+}
+
+// calcStats24H aggregates stored prices of tracked symbols, synthetic symbols are aggregated of stored prices of their legs.
+func (s *Currency) calcStats24H(ctx context.Context, symbols ...string) ([]model.GetCurrencyStat24HDTO, error) {
+	endTime := time.Now()
+	startTime := endTime.Add(-24 * time.Hour)
+
+	stats, err := s.currencyPriceRepo.Stat(ctx, startTime.UnixMilli(), endTime.UnixMilli(), symbols...)
+	if err != nil {
+		return nil, err
 	}
 
-	taskFuncs := make([]doTaskFunc, 0, len(symbols))
+	found := make(map[string]struct{}, len(stats))
+	for _, stat := range stats {
+		found[stat.Symbol] = struct{}{}
+	}
 	for _, symbol := range symbols {
-		taskFuncs = append(taskFuncs, func() interface{} {
-			res, err := s.fetchStat24H(ctx, symbol)
-			if err != nil {
-				return task{err: err}
-			}
+		if _, ok := found[symbol]; ok {
+			continue
+		}
+		found[symbol] = struct{}{}
 
-			return task{item: res}
-		})
+		legs, err := s.lookupSyntheticLegs(ctx, symbol)
+		if err != nil {
+			return nil, err
+		}
+		if legs == nil {
+			continue
+		}
+
+		stat, err := s.calcSyntheticStat24H(ctx, symbol, legs, startTime.UnixMilli(), endTime.UnixMilli())
+		if err != nil {
+			return nil, err
+		}
+		if stat != nil {
+			stats = append(stats, *stat)
+		}
 	}
+	slices.SortFunc(stats, func(a, b model.GetCurrencyStat24HDTO) int { return strings.Compare(a.Symbol, b.Symbol) })
 
-	statStream := s.taskResultStream(ctx, taskFuncs...)
-	for i := 0; i < len(symbols); i++ {
-		select {
-		case <-c.Done():
-			return nil, context.DeadlineExceeded
-		case out, ok := <-statStream:
-			if !ok {
-				return nil, fmt.Errorf("read from close channel")
-			}
+	return stats, nil
+}
 
-			res, ok := out.(task)
-			if !ok {
-				return nil, fmt.Errorf("incorrect type data: %s", reflect.TypeOf(out))
-			}
-			if res.err != nil {
-				return nil, res.err
+func (s *Currency) fetchStats24H(ctx context.Context, provider marketdata.Provider, symbols ...string) ([]model.GetCurrencyStat24HDTO, error) {
+	c, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	tasks := make([]workerpool.Task[model.GetCurrencyStat24HDTO], 0, len(symbols))
+	for _, symbol := range symbols {
+		tasks = append(tasks, func(ctx context.Context) (model.GetCurrencyStat24HDTO, error) {
+			res, err := s.fetchStat24H(ctx, provider, symbol)
+			if errors.Is(err, marketdata.ErrInvalidSymbol) {
+				res, err = s.fetchSyntheticStat24H(ctx, provider, symbol, err)
 			}
+			return res, err
+		})
+	}
 
-			result = append(result, res.item)
-		}
+	result, err := workerpool.Collect(c, s.fetchPool(), tasks...)
+	if err != nil {
+		return nil, fetchErr(ctx, c, err)
 	}
 
 	return result, nil
 }
 
-func (s *Currency) fetchStat24H(ctx context.Context, symbol string) (model.GetCurrencyStat24HDTO, error) {
-	res, err := s.binanceClient.Ticker24hService(ctx, symbol)
+func (s *Currency) fetchStat24H(ctx context.Context, provider marketdata.Provider, symbol string) (model.GetCurrencyStat24HDTO, error) {
+	res, err := provider.Stat24h(ctx, symbol)
 	if err != nil {
 		return model.GetCurrencyStat24HDTO{}, err
 	}
 
-	openPrice, err := strconv.ParseFloat(res.OpenPrice, 64)
-	if err != nil {
-		return model.GetCurrencyStat24HDTO{}, err
-	}
-	lastPrice, err := strconv.ParseFloat(res.LastPrice, 64)
+	return model.GetCurrencyStat24HDTO{
+		Symbol:    res.Symbol,
+		Source:    provider.Name(),
+		OpenPrice: res.OpenPrice,
+		LastPrice: res.LastPrice,
+		HighPrice: res.HighPrice,
+		LowPrice:  res.LowPrice,
+		OpenTime:  res.OpenTime,
+		CloseTime: res.CloseTime,
+		Count:     res.Count,
+	}, nil
+}
+
+// fetchSyntheticStat24H makes the summary of a symbol the provider does not list of summaries of its legs.
+// The error of the symbol is returned when it has no legs.
+func (s *Currency) fetchSyntheticStat24H(ctx context.Context, provider marketdata.Provider, symbol string, notListed error) (model.GetCurrencyStat24HDTO, error) {
+	legs, err := s.lookupSyntheticLegs(ctx, symbol)
 	if err != nil {
 		return model.GetCurrencyStat24HDTO{}, err
 	}
-	highPrice, err := strconv.ParseFloat(res.HighPrice, 64)
-	if err != nil {
-		return model.GetCurrencyStat24HDTO{}, err
+	if legs == nil {
+		return model.GetCurrencyStat24HDTO{}, notListed
 	}
-	lowPrice, err := strconv.ParseFloat(res.LowPrice, 64)
-	if err != nil {
-		return model.GetCurrencyStat24HDTO{}, err
+
+	stats := make([]model.GetCurrencyStat24HDTO, 0, len(legs))
+	for _, leg := range legs {
+		stat, err := s.fetchStat24H(ctx, provider, leg.currency.Symbol)
+		if err != nil {
+			return model.GetCurrencyStat24HDTO{}, err
+		}
+		stats = append(stats, stat)
 	}
 
-	return model.GetCurrencyStat24HDTO{
-		Symbol:    res.Symbol,
-		OpenPrice: openPrice,
-		LastPrice: lastPrice,
-		HighPrice: highPrice,
-		LowPrice:  lowPrice,
-		OpenTime:  int64(res.OpenTime),
-		CloseTime: int64(res.CloseTime),
-	}, nil
+	return syntheticStat24H(symbol, legs, stats), nil
 }
@@ -5,12 +5,14 @@ import (
 	"fmt"
 	"gexabyte/internal/model"
 	mock_repository "gexabyte/internal/repository/mock"
+	"gexabyte/pkg/clients/binance"
 	mock_binance "gexabyte/pkg/clients/binance/mock"
-	"strconv"
 	"testing"
+	"time"
 
 	binance_connector "github.com/binance/binance-connector-go"
 	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -26,6 +28,7 @@ func TestGetStat24H(t *testing.T) {
 		currencyRepo:      currencyRepo,
 		currencyPriceRepo: currencyPriceRepo,
 		binanceClient:     binanceClient,
+		provider:          binance.NewProvider(binanceClient),
 	}
 
 	unexpectedErr := fmt.Errorf("unexpected")
@@ -56,16 +59,16 @@ func TestGetStat24H(t *testing.T) {
 				assert.NoError(t, err)
 				assert.NotEmpty(t, res)
 
-				openPrice, err := strconv.ParseFloat(ticker24hDefaultResopnce.OpenPrice, 64)
+				openPrice, err := decimal.NewFromString(ticker24hDefaultResopnce.OpenPrice)
 				assert.NoError(t, err)
 				assert.Equal(t, openPrice, res[0].OpenPrice)
-				lastPrice, err := strconv.ParseFloat(ticker24hDefaultResopnce.LastPrice, 64)
+				lastPrice, err := decimal.NewFromString(ticker24hDefaultResopnce.LastPrice)
 				assert.NoError(t, err)
 				assert.Equal(t, lastPrice, res[0].LastPrice)
-				highPrice, err := strconv.ParseFloat(ticker24hDefaultResopnce.HighPrice, 64)
+				highPrice, err := decimal.NewFromString(ticker24hDefaultResopnce.HighPrice)
 				assert.NoError(t, err)
 				assert.Equal(t, highPrice, res[0].HighPrice)
-				lowPrice, err := strconv.ParseFloat(ticker24hDefaultResopnce.LowPrice, 64)
+				lowPrice, err := decimal.NewFromString(ticker24hDefaultResopnce.LowPrice)
 				assert.NoError(t, err)
 				assert.Equal(t, lowPrice, res[0].LowPrice)
 			},
@@ -81,16 +84,16 @@ func TestGetStat24H(t *testing.T) {
 				assert.NotEmpty(t, res)
 				assert.Equal(t, 3, len(res))
 
-				openPrice, err := strconv.ParseFloat(ticker24hDefaultResopnce.OpenPrice, 64)
+				openPrice, err := decimal.NewFromString(ticker24hDefaultResopnce.OpenPrice)
 				assert.NoError(t, err)
 				assert.Equal(t, openPrice, res[0].OpenPrice)
-				lastPrice, err := strconv.ParseFloat(ticker24hDefaultResopnce.LastPrice, 64)
+				lastPrice, err := decimal.NewFromString(ticker24hDefaultResopnce.LastPrice)
 				assert.NoError(t, err)
 				assert.Equal(t, lastPrice, res[0].LastPrice)
-				highPrice, err := strconv.ParseFloat(ticker24hDefaultResopnce.HighPrice, 64)
+				highPrice, err := decimal.NewFromString(ticker24hDefaultResopnce.HighPrice)
 				assert.NoError(t, err)
 				assert.Equal(t, highPrice, res[0].HighPrice)
-				lowPrice, err := strconv.ParseFloat(ticker24hDefaultResopnce.LowPrice, 64)
+				lowPrice, err := decimal.NewFromString(ticker24hDefaultResopnce.LowPrice)
 				assert.NoError(t, err)
 				assert.Equal(t, lowPrice, res[0].LowPrice)
 			},
@@ -113,7 +116,90 @@ func TestGetStat24H(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			test.buildStubs(binanceClient)
 
-			res, err := service.GetStat24H(context.Background(), test.symbols...)
+			res, err := service.GetStat24H(context.Background(), model.StatSourceBinance, test.symbols...)
+
+			test.checkResult(t, res, err)
+		})
+	}
+}
+
+func TestGetStat24HLocal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyRepo := mock_repository.NewMockCurrency(ctrl)
+	currencyPriceRepo := mock_repository.NewMockCurrencyPrice(ctrl)
+	binanceClient := mock_binance.NewMockClient(ctrl)
+
+	service := Currency{
+		currencyRepo:      currencyRepo,
+		currencyPriceRepo: currencyPriceRepo,
+		binanceClient:     binanceClient,
+		provider:          binance.NewProvider(binanceClient),
+	}
+
+	unexpectedErr := fmt.Errorf("unexpected")
+
+	stat := model.GetCurrencyStat24HDTO{
+		Symbol:    "BTCUSDT",
+		Source:    model.StatSourceLocal,
+		OpenPrice: decimal.RequireFromString("1.1"),
+		LastPrice: decimal.RequireFromString("2.2"),
+		Count:     2,
+	}
+
+	tc := []struct {
+		name        string
+		source      string
+		buildStubs  func()
+		checkResult func(t *testing.T, res []model.GetCurrencyStat24HDTO, err error)
+	}{
+		{
+			name:   "OK",
+			source: model.StatSourceLocal,
+			buildStubs: func() {
+				currencyPriceRepo.EXPECT().Stat(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Eq("BTCUSDT")).Times(1).
+					DoAndReturn(func(_ context.Context, startTime, endTime int64, _ ...string) ([]model.GetCurrencyStat24HDTO, error) {
+						assert.Equal(t, (24 * time.Hour).Milliseconds(), endTime-startTime)
+						return []model.GetCurrencyStat24HDTO{stat}, nil
+					})
+				binanceClient.EXPECT().Ticker24hService(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResult: func(t *testing.T, res []model.GetCurrencyStat24HDTO, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, []model.GetCurrencyStat24HDTO{stat}, res)
+			},
+		},
+		{
+			name:   "error from db",
+			source: model.StatSourceLocal,
+			buildStubs: func() {
+				currencyPriceRepo.EXPECT().Stat(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil, unexpectedErr)
+			},
+			checkResult: func(t *testing.T, res []model.GetCurrencyStat24HDTO, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, res)
+			},
+		},
+		{
+			name:   "unknown source",
+			source: "unknown",
+			buildStubs: func() {
+				currencyPriceRepo.EXPECT().Stat(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+				binanceClient.EXPECT().Ticker24hService(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResult: func(t *testing.T, res []model.GetCurrencyStat24HDTO, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, res)
+			},
+		},
+	}
+
+	for _, test := range tc {
+		t.Run(test.name, func(t *testing.T) {
+			test.buildStubs()
+
+			res, err := service.GetStat24H(context.Background(), test.source, "BTCUSDT")
 
 			test.checkResult(t, res, err)
 		})
@@ -132,6 +218,7 @@ func TestFetchStat24H(t *testing.T) {
 		currencyRepo:      currencyRepo,
 		currencyPriceRepo: currencyPriceRepo,
 		binanceClient:     binanceClient,
+		provider:          binance.NewProvider(binanceClient),
 	}
 
 	ticker24hDefaultResopnce := &binance_connector.Ticker24hrResponse{
@@ -218,7 +305,7 @@ func TestFetchStat24H(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			test.buildStubs(binanceClient)
 
-			res, err := service.fetchStat24H(context.Background(), test.symbol)
+			res, err := service.fetchStat24H(context.Background(), service.provider, test.symbol)
 
 			test.checkResult(t, res, err)
 		})
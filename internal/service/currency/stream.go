@@ -0,0 +1,165 @@
+package currency
+
+import (
+	"context"
+	"gexabyte/internal/model"
+	"gexabyte/pkg/clients/binance"
+	"gexabyte/pkg/clients/marketdata"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Modes of ingesting current prices.
+const (
+	IngestModePoll   = "poll"
+	IngestModeStream = "stream"
+)
+
+const (
+	// streamSymbolsRefresh is how often tracked pairs are reloaded in case a wakeup was missed.
+	streamSymbolsRefresh = time.Minute
+	// streamStaleAfter is how long the stream may stay silent before polling takes over.
+	streamStaleAfter = time.Minute
+)
+
+type IngestConfig struct {
+	// Stream takes prices of tracked pairs from market streams, polling only covers for a stream which is down.
+	Stream bool
+	// SampleInterval is how often the last pushed prices are stored.
+	SampleInterval time.Duration
+}
+
+// streamTicks keeps the last tick of every tracked pair between samples.
+type streamTicks struct {
+	mu         sync.Mutex
+	currencies map[string]int // ids of tracked pairs by symbol
+	last       map[string]binance.Tick
+	receivedAt time.Time
+}
+
+// streamLoop subscribes to prices of tracked pairs and stores them every sample interval.
+func (s *Currency) streamLoop(ctx context.Context) {
+	s.refreshStreamSymbols(ctx)
+
+	go func() {
+		s.marketStream.Run(ctx, s.handleTick, func(err error) {
+			s.logger.Warn("streamLoop: market stream failed: " + err.Error())
+		})
+	}()
+
+	sample := time.NewTicker(s.ingest.SampleInterval)
+	defer sample.Stop()
+	refresh := time.NewTicker(streamSymbolsRefresh)
+	defer refresh.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sample.C:
+			if err := s.storeTicks(ctx); err != nil {
+				s.logger.Error("streamLoop: failed to store prices: " + err.Error())
+			}
+		case <-refresh.C:
+			s.refreshStreamSymbols(ctx)
+		case <-s.streamWakeup:
+			s.refreshStreamSymbols(ctx)
+		}
+	}
+}
+
+// wakeStream makes streamLoop resubscribe after tracked pairs were changed.
+func (s *Currency) wakeStream() {
+	select {
+	case s.streamWakeup <- struct{}{}:
+	default:
+	}
+}
+
+// refreshStreamSymbols subscribes to active pairs only, ticks of other pairs are dropped.
+func (s *Currency) refreshStreamSymbols(ctx context.Context) {
+	currencies, err := s.currencyRepo.List(ctx)
+	if err != nil {
+		s.logger.Error("refreshStreamSymbols: failed to list currencies: " + err.Error())
+		return
+	}
+
+	ids := make(map[string]int, len(currencies))
+	symbols := make([]string, 0, len(currencies))
+	for _, currency := range currencies {
+		if !currency.Active {
+			continue
+		}
+		ids[currency.Symbol] = currency.ID
+		symbols = append(symbols, currency.Symbol)
+	}
+
+	s.ticks.mu.Lock()
+	s.ticks.currencies = ids
+	for symbol := range s.ticks.last {
+		if _, ok := ids[symbol]; !ok {
+			delete(s.ticks.last, symbol)
+		}
+	}
+	s.ticks.mu.Unlock()
+
+	s.marketStream.SetSymbols(symbols...)
+}
+
+func (s *Currency) handleTick(tick binance.Tick) {
+	s.ticks.mu.Lock()
+	defer s.ticks.mu.Unlock()
+
+	if _, ok := s.ticks.currencies[tick.Symbol]; !ok {
+		return
+	}
+
+	if s.ticks.last == nil {
+		s.ticks.last = make(map[string]binance.Tick)
+	}
+	s.ticks.last[tick.Symbol] = tick
+	s.ticks.receivedAt = time.Now()
+}
+
+// storeTicks stores the last ticks received since the previous sample.
+func (s *Currency) storeTicks(ctx context.Context) error {
+	s.ticks.mu.Lock()
+	last := s.ticks.last
+	s.ticks.last = nil
+	prices := make([]model.CurrencyPrice, 0, len(last))
+	for symbol, tick := range last {
+		price, err := decimal.NewFromString(tick.Price)
+		if err != nil {
+			s.logger.Warn("storeTicks: malformed price: "+err.Error(), "symbol", symbol)
+			continue
+		}
+		prices = append(prices, model.CurrencyPrice{
+			CurrencyID: s.ticks.currencies[symbol],
+			Price:      price,
+			Time:       tick.Time,
+			Source:     marketdata.ProviderBinance,
+		})
+	}
+	s.ticks.mu.Unlock()
+
+	if len(prices) == 0 {
+		return nil
+	}
+
+	_, err := s.CreatePrice(ctx, prices...)
+	return err
+}
+
+// streamHealthy tells whether prices come from the stream, otherwise polling takes over.
+func (s *Currency) streamHealthy() bool {
+	if !s.ingest.Stream || !s.marketStream.Connected() {
+		return false
+	}
+
+	s.ticks.mu.Lock()
+	defer s.ticks.mu.Unlock()
+
+	return time.Since(s.ticks.receivedAt) < streamStaleAfter
+}
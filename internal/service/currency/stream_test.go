@@ -0,0 +1,98 @@
+package currency
+
+import (
+	"context"
+	"gexabyte/internal/model"
+	mock_repository "gexabyte/internal/repository/mock"
+	"gexabyte/pkg/clients/binance"
+	mock_binance "gexabyte/pkg/clients/binance/mock"
+	"gexabyte/pkg/clients/marketdata"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamTicks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyRepo := mock_repository.NewMockCurrency(ctrl)
+	currencyPriceRepo := mock_repository.NewMockCurrencyPrice(ctrl)
+	marketStream := mock_binance.NewMockMarketStream(ctrl)
+
+	service := Currency{
+		currencyRepo:      currencyRepo,
+		currencyPriceRepo: currencyPriceRepo,
+		marketStream:      marketStream,
+		logger:            slog.Default(),
+		priceConflict:     model.PriceConflictKeepFirst,
+		ingest:            IngestConfig{Stream: true, SampleInterval: time.Second},
+	}
+
+	// inactive pairs are not subscribed
+	currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return([]model.Currency{
+		{ID: 1, Symbol: "BTCUSDT", Active: true},
+		{ID: 2, Symbol: "ETHUSDT", Active: false},
+		{ID: 3, Symbol: "SOLUSDT", Active: true},
+	}, nil)
+	marketStream.EXPECT().SetSymbols("BTCUSDT", "SOLUSDT").Times(1)
+	service.refreshStreamSymbols(context.Background())
+
+	// only the last tick of a pair within the sample is stored
+	service.handleTick(binance.Tick{Symbol: "BTCUSDT", Price: "64000.1", Time: 1000})
+	service.handleTick(binance.Tick{Symbol: "ETHUSDT", Price: "3500", Time: 1000})
+	service.handleTick(binance.Tick{Symbol: "BTCUSDT", Price: "64000.2", Time: 2000})
+	currencyPriceRepo.EXPECT().Create(gomock.Any(), model.PriceConflictKeepFirst, model.CurrencyPrice{
+		CurrencyID: 1,
+		Price:      decimal.RequireFromString("64000.2"),
+		Time:       2000,
+		Source:     marketdata.ProviderBinance,
+	}).Times(1).Return(model.CreateCurrencyPricesRes{}, nil)
+	assert.NoError(t, service.storeTicks(context.Background()))
+
+	// nothing is pushed since the last sample
+	assert.NoError(t, service.storeTicks(context.Background()))
+
+	// the pair is deactivated, its pending tick is dropped
+	service.handleTick(binance.Tick{Symbol: "SOLUSDT", Price: "150", Time: 3000})
+	currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return([]model.Currency{{ID: 1, Symbol: "BTCUSDT", Active: true}}, nil)
+	marketStream.EXPECT().SetSymbols("BTCUSDT").Times(1)
+	service.refreshStreamSymbols(context.Background())
+	assert.NoError(t, service.storeTicks(context.Background()))
+}
+
+func TestStreamHealthy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	marketStream := mock_binance.NewMockMarketStream(ctrl)
+
+	service := Currency{
+		marketStream: marketStream,
+		ingest:       IngestConfig{Stream: true, SampleInterval: time.Second},
+	}
+	service.ticks.currencies = map[string]int{"BTCUSDT": 1}
+
+	// nothing is received yet
+	marketStream.EXPECT().Connected().Times(1).Return(true)
+	assert.False(t, service.streamHealthy())
+
+	service.handleTick(binance.Tick{Symbol: "BTCUSDT", Price: "64000", Time: 1000})
+	marketStream.EXPECT().Connected().Times(1).Return(true)
+	assert.True(t, service.streamHealthy())
+
+	marketStream.EXPECT().Connected().Times(1).Return(false)
+	assert.False(t, service.streamHealthy())
+
+	service.ticks.receivedAt = time.Now().Add(-streamStaleAfter)
+	marketStream.EXPECT().Connected().Times(1).Return(true)
+	assert.False(t, service.streamHealthy())
+
+	// polling mode does not look at the stream
+	service.ingest.Stream = false
+	assert.False(t, service.streamHealthy())
+}
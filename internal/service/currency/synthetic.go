@@ -0,0 +1,385 @@
+package currency
+
+import (
+	"context"
+	"gexabyte/internal/model"
+	"gexabyte/pkg/clients/marketdata"
+	"slices"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// syntheticMaxLegs is the longest chain of tracked pairs a synthetic symbol is made of.
+const syntheticMaxLegs = 3
+
+// syntheticPageSize is how many stored prices of legs are read at once.
+const syntheticPageSize = 1000
+
+// syntheticLeg converts one asset into another by a tracked pair, an inverted leg goes from the quote of the pair to its base.
+type syntheticLeg struct {
+	currency model.Currency
+	inverted bool
+	to       string
+}
+
+// lookupSyntheticLegs is syntheticLegs over all tracked pairs, they are not loaded for symbols which do not parse.
+func (s *Currency) lookupSyntheticLegs(ctx context.Context, symbol string) ([]syntheticLeg, error) {
+	if _, err := marketdata.ParseSymbol(symbol); err != nil {
+		return nil, nil
+	}
+
+	tracked, err := s.currencyRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.syntheticLegs(ctx, symbol, tracked)
+}
+
+// syntheticLegs returns the route of tracked pairs from the base asset of the symbol to its quote asset.
+// Symbols which are tracked or trade on the exchange are not synthetic, they give nil legs as do symbols without a route.
+func (s *Currency) syntheticLegs(ctx context.Context, symbol string, tracked []model.Currency) ([]syntheticLeg, error) {
+	pair, err := marketdata.ParseSymbol(symbol)
+	if err != nil {
+		return nil, nil
+	}
+	for _, currency := range tracked {
+		if currency.Symbol == symbol {
+			return nil, nil
+		}
+	}
+
+	legs := findRoute(pair, tracked)
+	if legs == nil {
+		return nil, nil
+	}
+
+	listed, err := s.exchangeSymbols(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if info, ok := listed[symbol]; ok && info.Status == model.SymbolStatusTrading {
+		return nil, nil
+	}
+
+	return legs, nil
+}
+
+// findRoute searches the shortest chain of active pairs from the base asset to the quote one, nil when there is none.
+// Pairs are tried in order of symbols, so the same chain is found every time.
+func findRoute(pair marketdata.Pair, currencies []model.Currency) []syntheticLeg {
+	if pair.Base == pair.Quote {
+		return nil
+	}
+
+	sorted := slices.Clone(currencies)
+	slices.SortFunc(sorted, func(a, b model.Currency) int { return strings.Compare(a.Symbol, b.Symbol) })
+
+	edges := make(map[string][]syntheticLeg)
+	for _, currency := range sorted {
+		if !currency.Active { // legs have to be polled
+			continue
+		}
+		base, quote := pairAssets(currency)
+		if base == "" || quote == "" {
+			continue
+		}
+		edges[base] = append(edges[base], syntheticLeg{currency: currency, to: quote})
+		edges[quote] = append(edges[quote], syntheticLeg{currency: currency, inverted: true, to: base})
+	}
+
+	type path struct {
+		asset string
+		legs  []syntheticLeg
+	}
+	visited := map[string]bool{pair.Base: true}
+	queue := []path{{asset: pair.Base}}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		if len(p.legs) == syntheticMaxLegs {
+			continue
+		}
+
+		for _, leg := range edges[p.asset] {
+			if visited[leg.to] {
+				continue
+			}
+			legs := append(slices.Clone(p.legs), leg)
+			if leg.to == pair.Quote {
+				return legs
+			}
+			visited[leg.to] = true
+			queue = append(queue, path{asset: leg.to, legs: legs})
+		}
+	}
+
+	return nil
+}
+
+// pairAssets returns assets of the tracked pair, pairs stored before assets were recorded are parsed.
+func pairAssets(currency model.Currency) (string, string) {
+	if currency.BaseAsset != "" && currency.QuoteAsset != "" {
+		return marketdata.NormalizeAsset(currency.BaseAsset), marketdata.NormalizeAsset(currency.QuoteAsset)
+	}
+
+	pair, err := marketdata.ParseSymbol(currency.Symbol)
+	if err != nil {
+		return "", ""
+	}
+
+	return pair.Base, pair.Quote
+}
+
+func legSymbols(legs []syntheticLeg) []string {
+	symbols := make([]string, 0, len(legs))
+	for _, leg := range legs {
+		symbols = append(symbols, leg.currency.Symbol)
+	}
+
+	return symbols
+}
+
+// syntheticPrice multiplies prices of the legs, prices of inverted legs divide.
+func syntheticPrice(legs []syntheticLeg, prices []decimal.Decimal) decimal.Decimal {
+	num, den := legRatio(legs, prices)
+	if den.IsZero() {
+		return decimal.Zero
+	}
+
+	return num.DivRound(den, priceScale)
+}
+
+// legRatio returns the product of prices of direct legs and the one of inverted legs, the price is their ratio.
+// Dividing once keeps the precision which dividing by every inverted leg loses.
+func legRatio(legs []syntheticLeg, prices []decimal.Decimal) (num, den decimal.Decimal) {
+	num, den = decimal.NewFromInt(1), decimal.NewFromInt(1)
+	for i, leg := range legs {
+		if leg.inverted {
+			den = den.Mul(prices[i])
+		} else {
+			num = num.Mul(prices[i])
+		}
+	}
+
+	return num, den
+}
+
+// syntheticCurrentPrice makes the price of the symbol of current prices of its legs.
+func syntheticCurrentPrice(symbol string, legs []syntheticLeg, prices map[string]model.GetCurrencyPriceDTO) model.GetCurrencyPriceDTO {
+	res := model.GetCurrencyPriceDTO{Symbol: symbol, Legs: make([]model.PriceLegDTO, 0, len(legs))}
+	legPrices := make([]decimal.Decimal, 0, len(legs))
+	for i, leg := range legs {
+		price := prices[leg.currency.Symbol]
+		legPrices = append(legPrices, price.Price)
+		res.Legs = append(res.Legs, model.PriceLegDTO{
+			Symbol:   leg.currency.Symbol,
+			Price:    price.Price,
+			Time:     price.Time,
+			Inverted: leg.inverted,
+			Stale:    price.Stale,
+		})
+
+		if i == 0 || price.Time < res.Time {
+			res.Time = price.Time
+		}
+		res.Stale = res.Stale || price.Stale
+	}
+	res.Price = syntheticPrice(legs, legPrices)
+
+	return res
+}
+
+// syntheticStat24H makes the summary of the symbol of summaries of its legs.
+// High and low are bounds, legs do not peak at the same time.
+func syntheticStat24H(symbol string, legs []syntheticLeg, stats []model.GetCurrencyStat24HDTO) model.GetCurrencyStat24HDTO {
+	opens, lasts, highs, lows := legPrices(legs, len(stats), func(i int) (decimal.Decimal, decimal.Decimal, decimal.Decimal, decimal.Decimal) {
+		return stats[i].OpenPrice, stats[i].LastPrice, stats[i].HighPrice, stats[i].LowPrice
+	})
+
+	res := model.GetCurrencyStat24HDTO{
+		Symbol:    symbol,
+		Source:    stats[0].Source,
+		OpenPrice: syntheticPrice(legs, opens),
+		LastPrice: syntheticPrice(legs, lasts),
+		HighPrice: syntheticPrice(legs, highs),
+		LowPrice:  syntheticPrice(legs, lows),
+		OpenTime:  stats[0].OpenTime,
+		CloseTime: stats[0].CloseTime,
+		Count:     stats[0].Count,
+		Legs:      legSymbols(legs),
+	}
+	for _, stat := range stats[1:] {
+		res.OpenTime = max(res.OpenTime, stat.OpenTime)
+		res.CloseTime = min(res.CloseTime, stat.CloseTime)
+		res.Count = min(res.Count, stat.Count)
+	}
+
+	return res
+}
+
+// syntheticCandle makes a candle of the symbol of candles of its legs with the same open time.
+// High and low are bounds, legs do not peak at the same time.
+func syntheticCandle(legs []syntheticLeg, candles []model.CurrencyPriceInterval) model.CurrencyPriceInterval {
+	opens, closes, highs, lows := legPrices(legs, len(candles), func(i int) (decimal.Decimal, decimal.Decimal, decimal.Decimal, decimal.Decimal) {
+		return candles[i].OpenPrice, candles[i].ClosePrice, candles[i].HighPrice, candles[i].LowPrice
+	})
+
+	return model.CurrencyPriceInterval{
+		OpenPrice:  syntheticPrice(legs, opens),
+		ClosePrice: syntheticPrice(legs, closes),
+
+		HighPrice: syntheticPrice(legs, highs),
+		LowPrice:  syntheticPrice(legs, lows),
+
+		OpenTime:  candles[0].OpenTime,
+		CloseTime: candles[0].CloseTime,
+	}
+}
+
+// legPrices splits open, close, high and low prices of every leg, the low of an inverted leg gives the high of the symbol.
+func legPrices(legs []syntheticLeg, n int, prices func(i int) (decimal.Decimal, decimal.Decimal, decimal.Decimal, decimal.Decimal)) (opens, closes, highs, lows []decimal.Decimal) {
+	for i := 0; i < n; i++ {
+		open, closePrice, high, low := prices(i)
+		if legs[i].inverted {
+			high, low = low, high
+		}
+		opens, closes = append(opens, open), append(closes, closePrice)
+		highs, lows = append(highs, high), append(lows, low)
+	}
+
+	return opens, closes, highs, lows
+}
+
+// calcSyntheticStat24H aggregates the symbol of prices of its legs stored between startTime and endTime, nil without them.
+// A sample is taken at every stored time once all legs have a price, each leg counts with its last price by then.
+func (s *Currency) calcSyntheticStat24H(ctx context.Context, symbol string, legs []syntheticLeg, startTime, endTime int64) (*model.GetCurrencyStat24HDTO, error) {
+	index := make(map[string]int, len(legs))
+	for i, leg := range legs {
+		index[leg.currency.Symbol] = i
+	}
+	last := make([]decimal.Decimal, len(legs))
+	seen := make([]bool, len(legs))
+	priced := 0
+
+	var stat *model.GetCurrencyStat24HDTO
+	sum := decimal.Zero
+	sample := func(at int64) {
+		if priced < len(legs) {
+			return
+		}
+
+		price := syntheticPrice(legs, last)
+		if stat == nil {
+			stat = &model.GetCurrencyStat24HDTO{
+				Symbol:    symbol,
+				Source:    model.StatSourceLocal,
+				OpenPrice: price,
+				HighPrice: price,
+				LowPrice:  price,
+				OpenTime:  at,
+				Legs:      legSymbols(legs),
+			}
+		}
+		stat.LastPrice, stat.CloseTime = price, at
+		stat.HighPrice = decimal.Max(stat.HighPrice, price)
+		stat.LowPrice = decimal.Min(stat.LowPrice, price)
+		stat.Count++
+		sum = sum.Add(price)
+	}
+
+	filter := model.ListCurrencyPricesFilter{
+		Symbols:   legSymbols(legs),
+		StartTime: startTime,
+		EndTime:   endTime,
+		Order:     model.OrderAsc,
+		Limit:     syntheticPageSize,
+	}
+	pending := false
+	var pendingTime int64
+	for {
+		prices, err := s.currencyPriceRepo.List(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range prices {
+			if pending && p.Time != pendingTime { // legs stored at the same time make one sample
+				sample(pendingTime)
+			}
+
+			i := index[p.Symbol]
+			if !seen[i] {
+				seen[i] = true
+				priced++
+			}
+			last[i] = p.Price
+			pending, pendingTime = true, p.Time
+		}
+
+		if len(prices) < filter.Limit {
+			break
+		}
+		cursor := prices[len(prices)-1].Cursor()
+		filter.After = &cursor
+	}
+	if pending {
+		sample(pendingTime)
+	}
+
+	if stat != nil {
+		stat.AvgPrice = sum.Div(decimal.NewFromInt(int64(stat.Count)))
+		stat.PriceChangePercent = model.PriceChangePercent(stat.OpenPrice, stat.LastPrice)
+	}
+
+	return stat, nil
+}
+
+// syntheticPriceHistorical makes candles of the symbol of stored candles of its legs, pages are the pages of the legs.
+// Only candles present in every leg are combined.
+func (s *Currency) syntheticPriceHistorical(ctx context.Context, req model.GetCurrencyPriceHistoricalDTOReq, legs []syntheticLeg) (*model.GetCurrencyPriceHistoricalDTORes, error) {
+	var first *model.GetCurrencyPriceHistoricalDTORes
+	byOpenTime := make([]map[int64]model.CurrencyPriceInterval, 0, len(legs))
+	for _, leg := range legs {
+		legReq := req
+		legReq.Symbol = leg.currency.Symbol
+
+		res, err := s.readPriceHistorical(ctx, leg.currency, legReq)
+		if err != nil {
+			return nil, err
+		}
+		if first == nil {
+			first = res
+		}
+
+		candles := make(map[int64]model.CurrencyPriceInterval, len(res.Prices))
+		for _, c := range res.Prices {
+			candles[c.OpenTime] = c
+		}
+		byOpenTime = append(byOpenTime, candles)
+	}
+
+	prices := make([]model.CurrencyPriceInterval, 0, len(first.Prices))
+	for _, c := range first.Prices {
+		candles := make([]model.CurrencyPriceInterval, 0, len(legs))
+		for _, legCandles := range byOpenTime {
+			candle, ok := legCandles[c.OpenTime]
+			if !ok {
+				break
+			}
+			candles = append(candles, candle)
+		}
+		if len(candles) == len(legs) {
+			prices = append(prices, syntheticCandle(legs, candles))
+		}
+	}
+
+	return &model.GetCurrencyPriceHistoricalDTORes{
+		Page:    first.Page,
+		MaxPage: first.MaxPage,
+
+		Prices: prices,
+		Legs:   legSymbols(legs),
+	}, nil
+}
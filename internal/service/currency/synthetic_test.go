@@ -0,0 +1,180 @@
+package currency
+
+import (
+	"context"
+	"gexabyte/internal/model"
+	mock_repository "gexabyte/internal/repository/mock"
+	"gexabyte/pkg/clients/marketdata"
+	mock_marketdata "gexabyte/pkg/clients/marketdata/mock"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindRoute(t *testing.T) {
+	tracked := []model.Currency{
+		{ID: 1, Symbol: "SOLUSDT", Active: true, BaseAsset: "SOL", QuoteAsset: "USDT"},
+		{ID: 2, Symbol: "BTCUSDT", Active: true, BaseAsset: "BTC", QuoteAsset: "USDT"},
+		{ID: 3, Symbol: "EURUSDT", Active: true}, // assets are parsed of the symbol
+		{ID: 4, Symbol: "ETHBTC", Active: true, BaseAsset: "ETH", QuoteAsset: "BTC"},
+		{ID: 5, Symbol: "TRXUSDT", Active: false, BaseAsset: "TRX", QuoteAsset: "USDT"},
+	}
+
+	tc := []struct {
+		name   string
+		pair   marketdata.Pair
+		legs   []string
+		invert []bool
+	}{
+		{name: "cross of two quotes", pair: marketdata.Pair{Base: "SOL", Quote: "BTC"}, legs: []string{"SOLUSDT", "BTCUSDT"}, invert: []bool{false, true}},
+		{name: "inverted pair", pair: marketdata.Pair{Base: "USDT", Quote: "SOL"}, legs: []string{"SOLUSDT"}, invert: []bool{true}},
+		{name: "three legs", pair: marketdata.Pair{Base: "ETH", Quote: "EUR"}, legs: []string{"ETHBTC", "BTCUSDT", "EURUSDT"}, invert: []bool{false, false, true}},
+		{name: "inactive pair is no leg", pair: marketdata.Pair{Base: "TRX", Quote: "BTC"}},
+		{name: "unknown asset", pair: marketdata.Pair{Base: "DOGE", Quote: "BTC"}},
+		{name: "same asset", pair: marketdata.Pair{Base: "BTC", Quote: "BTC"}},
+	}
+
+	for _, test := range tc {
+		t.Run(test.name, func(t *testing.T) {
+			legs := findRoute(test.pair, tracked)
+			if test.legs == nil {
+				assert.Nil(t, legs)
+				return
+			}
+
+			invert := make([]bool, 0, len(legs))
+			for _, leg := range legs {
+				invert = append(invert, leg.inverted)
+			}
+			assert.Equal(t, test.legs, legSymbols(legs))
+			assert.Equal(t, test.invert, invert)
+		})
+	}
+}
+
+func TestSyntheticCandle(t *testing.T) {
+	legs := []syntheticLeg{{currency: model.Currency{Symbol: "SOLUSDT"}}, {currency: model.Currency{Symbol: "BTCUSDT"}, inverted: true}}
+	candle := func(open, closePrice, high, low string) model.CurrencyPriceInterval {
+		return model.CurrencyPriceInterval{
+			OpenPrice:  decimal.RequireFromString(open),
+			ClosePrice: decimal.RequireFromString(closePrice),
+			HighPrice:  decimal.RequireFromString(high),
+			LowPrice:   decimal.RequireFromString(low),
+			OpenTime:   1000,
+			CloseTime:  1999,
+		}
+	}
+
+	res := syntheticCandle(legs, []model.CurrencyPriceInterval{
+		candle("150", "160", "165", "145"),
+		candle("60000", "64000", "66000", "50000"),
+	})
+
+	assert.Equal(t, "0.0025", res.OpenPrice.String())
+	assert.Equal(t, "0.0025", res.ClosePrice.String())
+	// the highest SOL goes with the lowest BTC
+	assert.Equal(t, "0.0033", res.HighPrice.String())
+	assert.Equal(t, "0.0021969697", res.LowPrice.String())
+	assert.Equal(t, int64(1000), res.OpenTime)
+	assert.Equal(t, int64(1999), res.CloseTime)
+}
+
+func newSyntheticService(ctrl *gomock.Controller) (*Currency, *mock_repository.MockCurrency, *mock_repository.MockCurrencyPrice, *mock_marketdata.MockProvider) {
+	currencyRepo := mock_repository.NewMockCurrency(ctrl)
+	currencyPriceRepo := mock_repository.NewMockCurrencyPrice(ctrl)
+	provider := mock_marketdata.NewMockProvider(ctrl)
+	provider.EXPECT().Name().Return(marketdata.ProviderBinance).AnyTimes()
+	provider.EXPECT().Symbols(gomock.Any()).Return([]marketdata.SymbolInfo{
+		{Symbol: "SOLUSDT", BaseAsset: "SOL", QuoteAsset: "USDT", Status: marketdata.StatusTrading},
+		{Symbol: "BTCUSDT", BaseAsset: "BTC", QuoteAsset: "USDT", Status: marketdata.StatusTrading},
+		{Symbol: "ETHBTC", BaseAsset: "ETH", QuoteAsset: "BTC", Status: marketdata.StatusTrading},
+	}, nil).AnyTimes()
+
+	service := NewCurrency(currencyRepo, currencyPriceRepo, nil, nil, nil, nil, nil,
+		[]marketdata.Provider{provider}, nil, slog.Default(),
+		Config{PriceConflict: model.PriceConflictKeepFirst, ExchangeInfoInterval: time.Hour},
+	)
+
+	return service, currencyRepo, currencyPriceRepo, provider
+}
+
+func TestGetCurrentPricesSynthetic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, currencyRepo, currencyPriceRepo, provider := newSyntheticService(ctrl)
+
+	currencyRepo.EXPECT().List(gomock.Any()).Times(1).Return([]model.Currency{
+		{ID: 1, Symbol: "SOLUSDT", Active: true, BaseAsset: "SOL", QuoteAsset: "USDT"},
+		{ID: 2, Symbol: "BTCUSDT", Active: true, BaseAsset: "BTC", QuoteAsset: "USDT"},
+	}, nil)
+	provider.EXPECT().Price(gomock.Any(), "SOLUSDT").Times(1).Return(marketdata.Price{Price: decimal.NewFromInt(150)}, nil)
+	provider.EXPECT().Price(gomock.Any(), "BTCUSDT").Times(1).Return(marketdata.Price{Price: decimal.NewFromInt(60000)}, nil)
+	currencyPriceRepo.EXPECT().Create(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, _ string, prices ...model.CurrencyPrice) (model.CreateCurrencyPricesRes, error) {
+			assert.Len(t, prices, 2, "only legs are stored")
+			return model.CreateCurrencyPricesRes{}, nil
+		})
+
+	res, err := service.GetCurrentPrices(context.Background(), "SOLBTC")
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, "SOLBTC", res[0].Symbol)
+	assert.Equal(t, "0.0025", res[0].Price.String())
+	require.Len(t, res[0].Legs, 2)
+	assert.Equal(t, "SOLUSDT", res[0].Legs[0].Symbol)
+	assert.Equal(t, "BTCUSDT", res[0].Legs[1].Symbol)
+	assert.True(t, res[0].Legs[1].Inverted)
+	assert.Equal(t, res[0].Time, res[0].Legs[0].Time)
+}
+
+func TestGetStat24HLocalSynthetic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	service, currencyRepo, currencyPriceRepo, _ := newSyntheticService(ctrl)
+
+	currencyPriceRepo.EXPECT().Stat(gomock.Any(), gomock.Any(), gomock.Any(), "ETHBTC", "SOLBTC").Times(1).
+		Return([]model.GetCurrencyStat24HDTO{}, nil)
+	currencyRepo.EXPECT().List(gomock.Any()).Times(2).Return([]model.Currency{
+		{ID: 1, Symbol: "SOLUSDT", Active: true, BaseAsset: "SOL", QuoteAsset: "USDT"},
+		{ID: 2, Symbol: "BTCUSDT", Active: true, BaseAsset: "BTC", QuoteAsset: "USDT"},
+		{ID: 3, Symbol: "ETHUSDT", Active: true, BaseAsset: "ETH", QuoteAsset: "USDT"},
+	}, nil)
+	price := func(symbol, price string, time int64) model.CurrencyPriceDTO {
+		return model.CurrencyPriceDTO{Symbol: symbol, Price: decimal.RequireFromString(price), Time: time}
+	}
+	currencyPriceRepo.EXPECT().List(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, filter model.ListCurrencyPricesFilter) ([]model.CurrencyPriceDTO, error) {
+			assert.Equal(t, []string{"SOLUSDT", "BTCUSDT"}, filter.Symbols)
+			assert.Equal(t, model.OrderAsc, filter.Order)
+			return []model.CurrencyPriceDTO{
+				price("SOLUSDT", "140", 1), // no BTC price yet
+				price("SOLUSDT", "150", 2),
+				price("BTCUSDT", "60000", 2),
+				price("SOLUSDT", "180", 3), // BTC keeps its last price
+				price("SOLUSDT", "160", 4),
+				price("BTCUSDT", "80000", 4),
+			}, nil
+		})
+
+	// ETHBTC has legs but trades on the exchange, so it is not synthetic
+	res, err := service.GetStat24H(context.Background(), model.StatSourceLocal, "ETHBTC", "SOLBTC")
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	assert.Equal(t, "SOLBTC", res[0].Symbol)
+	assert.Equal(t, []string{"SOLUSDT", "BTCUSDT"}, res[0].Legs)
+	assert.Equal(t, "0.0025", res[0].OpenPrice.String())
+	assert.Equal(t, "0.002", res[0].LastPrice.String())
+	assert.Equal(t, "0.003", res[0].HighPrice.String())
+	assert.Equal(t, "0.002", res[0].LowPrice.String())
+	assert.Equal(t, "0.0025", res[0].AvgPrice.String())
+	assert.Equal(t, 3, res[0].Count)
+	assert.Equal(t, int64(2), res[0].OpenTime)
+	assert.Equal(t, int64(4), res[0].CloseTime)
+}
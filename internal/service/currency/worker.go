@@ -2,33 +2,34 @@ package currency
 
 import (
 	"context"
-	"sync"
+	"errors"
+	"fmt"
+	"gexabyte/pkg/clients/marketdata"
+	"gexabyte/pkg/workerpool"
+	"time"
 )
 
-type doTaskFunc func() interface{}
+// fetchTimeout limits asking a provider for prices or summaries of all symbols of one request.
+const fetchTimeout = 3 * time.Second
 
-// Do your tasks parallel.
-// <-chan stream results each of your funcs.
-func (s *Currency) taskResultStream(ctx context.Context, tasks ...doTaskFunc) <-chan interface{} {
-	out := make(chan interface{}, len(tasks))
+// fetchPool bounds calls of a provider one request makes at the same time, each one is limited on its own,
+// so a symbol which hangs frees its worker before fetchTimeout.
+func (s *Currency) fetchPool() workerpool.Config {
+	return workerpool.Config{Workers: s.fetchWorkers, TaskTimeout: s.fetchTaskTimeout}
+}
 
-	var wgClose sync.WaitGroup
-	wgClose.Add(len(tasks))
-	go func() {
-		wgClose.Wait()
-		close(out)
-	}()
+// consensusPool asks all venues at once, each one is limited on its own,
+// so a venue which hangs is rejected and the others still agree within fetchTimeout.
+func (s *Currency) consensusPool() workerpool.Config {
+	return workerpool.Config{TaskTimeout: s.consensus.Timeout}
+}
 
-	for _, f := range tasks {
-		go func() {
-			defer wgClose.Done()
-			result := f()
-			select {
-			case <-ctx.Done():
-			case out <- result:
-			}
-		}()
+// fetchErr returns a fetch stopped by fetchTimeout or by the timeout of its task as a timeout of the provider,
+// failures of the caller are returned as is.
+func fetchErr(ctx, c context.Context, err error) error {
+	if ctx.Err() == nil && (errors.Is(c.Err(), context.DeadlineExceeded) || errors.Is(err, context.DeadlineExceeded)) {
+		return fmt.Errorf("%w: %w", marketdata.ErrTimeout, context.DeadlineExceeded)
 	}
 
-	return out
+	return err
 }
@@ -0,0 +1,129 @@
+package fx
+
+import (
+	"context"
+	"fmt"
+	"gexabyte/internal/model"
+	"gexabyte/internal/repository"
+	"gexabyte/pkg/clients/fxrate"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const LoggerGroup = "FxService"
+
+type Config struct {
+	// Currencies are kept in the rate history, prices are converted only to them.
+	Currencies []string
+	// Refresh is how often rates are taken from the provider.
+	Refresh time.Duration
+}
+
+type Fx struct {
+	fxRateRepo repository.FxRate
+	provider   fxrate.Provider
+
+	logger *slog.Logger
+
+	cfg Config
+}
+
+func NewFx(fxRateRepo repository.FxRate, provider fxrate.Provider, logger *slog.Logger, cfg Config) *Fx {
+	currencies := make([]string, 0, len(cfg.Currencies))
+	for _, currency := range cfg.Currencies {
+		if currency = strings.ToUpper(strings.TrimSpace(currency)); currency != "" {
+			currencies = append(currencies, currency)
+		}
+	}
+	cfg.Currencies = currencies
+
+	return &Fx{
+		fxRateRepo: fxRateRepo,
+		provider:   provider,
+		logger:     logger.WithGroup(LoggerGroup),
+		cfg:        cfg,
+	}
+}
+
+func (s *Fx) RunBackgroudProcesses(ctx context.Context) {
+	go s.refreshLoop(ctx)
+}
+
+// refreshLoop stores rates on start and every refresh interval after it.
+func (s *Fx) refreshLoop(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.Refresh)
+	defer ticker.Stop()
+
+	for {
+		if _, err := s.Refresh(ctx); err != nil {
+			s.logger.Error("refreshLoop: failed to refresh fx rates: " + err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh takes the latest rates of the configured currencies and stores them in the history.
+// A rate the provider quotes with an already stored time is not stored twice.
+func (s *Fx) Refresh(ctx context.Context) ([]model.FxRate, error) {
+	if len(s.cfg.Currencies) == 0 {
+		return nil, nil
+	}
+
+	quoted, err := s.provider.Rates(ctx, s.cfg.Currencies...)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make([]model.FxRate, 0, len(quoted))
+	for _, rate := range quoted {
+		rates = append(rates, model.FxRate{Currency: rate.Currency, Rate: rate.Rate, Time: rate.Time, Source: s.provider.Name()})
+	}
+
+	if err := s.fxRateRepo.Create(ctx, rates...); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+// Rates returns stored rates of the currency which are closest to times between startTime and endTime.
+// USD and its stablecoins convert at par. While nothing of the currency is stored, like right after start,
+// the rate is asked from the provider. Currencies out of the configured ones fail with model.ErrNoFxRate.
+func (s *Fx) Rates(ctx context.Context, currency string, startTime, endTime int64) (model.FxRates, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if model.IsFxBaseAsset(currency) {
+		return model.FxRates{{Currency: currency, Rate: decimal.NewFromInt(1)}}, nil
+	}
+	if !slices.Contains(s.cfg.Currencies, currency) {
+		return nil, fmt.Errorf("%w: %s is not converted to, currencies are %s", model.ErrNoFxRate, currency, strings.Join(s.cfg.Currencies, ", "))
+	}
+
+	rates, err := s.fxRateRepo.Around(ctx, currency, startTime, endTime)
+	if err != nil {
+		return nil, err
+	}
+	if len(rates) > 0 {
+		return rates, nil
+	}
+
+	fresh, err := s.Refresh(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, rate := range fresh {
+		if rate.Currency == currency {
+			return model.FxRates{rate}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s has no rate of %s", model.ErrNoFxRate, s.provider.Name(), currency)
+}
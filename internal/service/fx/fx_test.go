@@ -0,0 +1,93 @@
+package fx
+
+import (
+	"context"
+	"fmt"
+	"gexabyte/internal/model"
+	mock_repository "gexabyte/internal/repository/mock"
+	"gexabyte/pkg/clients/fxrate"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	fxRateRepo := mock_repository.NewMockFxRate(ctrl)
+	provider := fxrate.NewStatic(map[string]decimal.Decimal{"EUR": decimal.RequireFromString("0.92")})
+	service := NewFx(fxRateRepo, provider, slog.Default(), Config{Currencies: []string{" eur", "KZT"}, Refresh: time.Hour})
+
+	stored := model.FxRates{
+		{Currency: "EUR", Rate: decimal.RequireFromString("0.9"), Time: 1000},
+		{Currency: "EUR", Rate: decimal.RequireFromString("0.95"), Time: 3000},
+	}
+	fxRateRepo.EXPECT().Around(gomock.Any(), "EUR", int64(1500), int64(2500)).Times(1).Return([]model.FxRate(stored), nil)
+	rates, err := service.Rates(context.Background(), "eur", 1500, 2500)
+	require.NoError(t, err)
+	assert.Equal(t, stored, rates)
+
+	// nothing is stored yet, the rate is asked from the provider and stored
+	fxRateRepo.EXPECT().Around(gomock.Any(), "EUR", int64(0), int64(0)).Times(1).Return(nil, nil)
+	fxRateRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ context.Context, rates ...model.FxRate) error {
+			require.Len(t, rates, 1)
+			assert.Equal(t, "EUR", rates[0].Currency)
+			assert.Equal(t, fxrate.ProviderStatic, rates[0].Source)
+			return nil
+		})
+	rates, err = service.Rates(context.Background(), "EUR", 0, 0)
+	require.NoError(t, err)
+	require.Len(t, rates, 1)
+	assert.Equal(t, "0.92", rates[0].Rate.String())
+
+	// the provider has no rate of a configured currency
+	fxRateRepo.EXPECT().Around(gomock.Any(), "KZT", int64(0), int64(0)).Times(1).Return(nil, nil)
+	fxRateRepo.EXPECT().Create(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+	_, err = service.Rates(context.Background(), "KZT", 0, 0)
+	assert.ErrorIs(t, err, model.ErrNoFxRate)
+
+	_, err = service.Rates(context.Background(), "GBP", 0, 0)
+	assert.ErrorIs(t, err, model.ErrNoFxRate)
+
+	rates, err = service.Rates(context.Background(), "usdt", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "1", rates.At(100).String())
+
+	fxRateRepo.EXPECT().Around(gomock.Any(), "EUR", gomock.Any(), gomock.Any()).Times(1).Return(nil, fmt.Errorf("some error"))
+	_, err = service.Rates(context.Background(), "EUR", 0, 0)
+	assert.Error(t, err)
+}
+
+func TestRatesAt(t *testing.T) {
+	rates := model.FxRates{
+		{Rate: decimal.NewFromInt(1), Time: 1000},
+		{Rate: decimal.NewFromInt(2), Time: 2000},
+		{Rate: decimal.NewFromInt(3), Time: 3000},
+	}
+
+	tc := []struct {
+		time int64
+		rate string
+	}{
+		{time: 0, rate: "1"},
+		{time: 1000, rate: "1"},
+		{time: 1400, rate: "1"},
+		{time: 1500, rate: "1"}, // equally close, the earlier one
+		{time: 1600, rate: "2"},
+		{time: 2999, rate: "3"},
+		{time: 9000, rate: "3"},
+	}
+
+	for _, test := range tc {
+		assert.Equal(t, test.rate, rates.At(test.time).String(), "time %d", test.time)
+	}
+
+	assert.True(t, model.FxRates{}.At(1000).IsZero())
+}
@@ -6,36 +6,113 @@ import (
 	"gexabyte/internal/model"
 	"gexabyte/internal/repository"
 	"gexabyte/internal/service/currency"
+	"gexabyte/internal/service/fx"
 	"gexabyte/pkg/clients/binance"
+	"gexabyte/pkg/clients/fxrate"
+	"gexabyte/pkg/clients/marketdata"
 	"log/slog"
+
+	"github.com/shopspring/decimal"
 )
 
 type Manager struct {
 	Currency Currency
+	Fx       Fx
 }
 
 type Currency interface {
 	// Symbol
 	Create(ctx context.Context, symbol string) error
+	CreateMany(ctx context.Context, symbols ...string) []model.CreateCurrencyResDTO
+	ListSymbols(ctx context.Context, filter model.ListSymbolsFilter) ([]model.SymbolDTO, error)
 	List(ctx context.Context) ([]model.Currency, error)
+	ListBackfills(ctx context.Context, symbol string) ([]model.KlineBackfill, error)
+	SetActive(ctx context.Context, symbol string, active bool) (model.Currency, error)
+	Delete(ctx context.Context, symbol string, purge bool) error
 
 	// Price
-	CreatePrice(ctx context.Context, rates ...model.CurrencyPrice) error
-	ListPrices(ctx context.Context) ([]model.CurrencyPrice, error)
+	CreatePrice(ctx context.Context, rates ...model.CurrencyPrice) (model.CreateCurrencyPricesRes, error)
+	ListPrices(ctx context.Context, filter model.ListCurrencyPricesFilter) (model.ListCurrencyPricesDTORes, error)
 	GetCurrentPrices(ctx context.Context, symbols ...string) ([]model.GetCurrencyPriceDTO, error)
-	GetStat24H(ctx context.Context, symbols ...string) ([]model.GetCurrencyStat24HDTO, error)
+	GetStat24H(ctx context.Context, source string, symbols ...string) ([]model.GetCurrencyStat24HDTO, error)
 	GetPriceHistorical(ctx context.Context, req model.GetCurrencyPriceHistoricalDTOReq) (*model.GetCurrencyPriceHistoricalDTORes, error)
+	GetRollups(ctx context.Context, req model.GetCurrencyRollupsDTOReq) (*model.GetCurrencyRollupsDTORes, error)
+	Convert(ctx context.Context, req model.ConvertDTOReq) (model.ConvertDTORes, error)
+
+	// Monitoring
+	GetBinanceWeight() model.BinanceWeightDTO
+
+	RunBackgroudProcesses(ctx context.Context)
+}
+
+type Fx interface {
+	// Rates returns rates of the currency to USD which are closest to times between startTime and endTime, ordered by time.
+	Rates(ctx context.Context, currency string, startTime, endTime int64) (model.FxRates, error)
+
+	RunBackgroudProcesses(ctx context.Context)
 }
 
 func New(
 	cfg *config.Config,
 	logger *slog.Logger,
 	binanceClient binance.Client,
+	providers []marketdata.Provider,
+	marketStream binance.MarketStream,
+	fxProvider fxrate.Provider,
 	repository *repository.Manager,
 ) *Manager {
-	currency := currency.NewCurrency(repository.Currency, repository.CurrencyPrice, binanceClient, logger)
+	currency := currency.NewCurrency(
+		repository.Currency,
+		repository.CurrencyPrice,
+		repository.CurrencyKline,
+		repository.KlineBackfill,
+		repository.CurrencyPricePartition,
+		repository.CurrencyRollup,
+		binanceClient,
+		providers,
+		marketStream,
+		logger,
+		currency.Config{
+			Backfill: currency.BackfillConfig{
+				Months:       cfg.Backfill.Months,
+				Intervals:    cfg.Backfill.Intervals,
+				RequestDelay: cfg.Backfill.RequestDelay,
+			},
+			Retention: currency.RetentionConfig{
+				Months:      cfg.Retention.Months,
+				AheadMonths: cfg.Retention.AheadMonths,
+				Detach:      cfg.Retention.Mode == currency.RetentionModeDetach,
+				Interval:    cfg.Retention.Interval,
+			},
+			Ingest: currency.IngestConfig{
+				Stream:         cfg.Ingest.Mode == currency.IngestModeStream,
+				SampleInterval: cfg.Ingest.SampleInterval,
+			},
+			Consensus: currency.ConsensusConfig{
+				Method:     cfg.Consensus.Method,
+				Band:       decimal.NewFromFloat(cfg.Consensus.Band),
+				MinSources: cfg.Consensus.MinSources,
+				Timeout:    cfg.Consensus.Timeout,
+			},
+			PriceConflict:        cfg.PriceConflict,
+			ExchangeInfoInterval: cfg.Binance.ExchangeInfoRefresh,
+			FetchWorkers:         cfg.Market.Workers,
+			FetchTaskTimeout:     cfg.Market.TaskTimeout,
+		},
+	)
+
+	fx := fx.NewFx(
+		repository.FxRate,
+		fxProvider,
+		logger,
+		fx.Config{
+			Currencies: cfg.Fx.Currencies,
+			Refresh:    cfg.Fx.Refresh,
+		},
+	)
 
 	return &Manager{
 		Currency: currency,
+		Fx:       fx,
 	}
 }
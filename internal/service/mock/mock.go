@@ -35,6 +35,21 @@ func (m *MockCurrency) EXPECT() *MockCurrencyMockRecorder {
 	return m.recorder
 }
 
+// Convert mocks base method.
+func (m *MockCurrency) Convert(ctx context.Context, req model.ConvertDTOReq) (model.ConvertDTORes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Convert", ctx, req)
+	ret0, _ := ret[0].(model.ConvertDTORes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Convert indicates an expected call of Convert.
+func (mr *MockCurrencyMockRecorder) Convert(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Convert", reflect.TypeOf((*MockCurrency)(nil).Convert), ctx, req)
+}
+
 // Create mocks base method.
 func (m *MockCurrency) Create(ctx context.Context, symbol string) error {
 	m.ctrl.T.Helper()
@@ -49,16 +64,36 @@ func (mr *MockCurrencyMockRecorder) Create(ctx, symbol interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockCurrency)(nil).Create), ctx, symbol)
 }
 
+// CreateMany mocks base method.
+func (m *MockCurrency) CreateMany(ctx context.Context, symbols ...string) []model.CreateCurrencyResDTO {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx}
+	for _, a := range symbols {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateMany", varargs...)
+	ret0, _ := ret[0].([]model.CreateCurrencyResDTO)
+	return ret0
+}
+
+// CreateMany indicates an expected call of CreateMany.
+func (mr *MockCurrencyMockRecorder) CreateMany(ctx interface{}, symbols ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx}, symbols...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMany", reflect.TypeOf((*MockCurrency)(nil).CreateMany), varargs...)
+}
+
 // CreatePrice mocks base method.
-func (m *MockCurrency) CreatePrice(ctx context.Context, rates ...model.CurrencyPrice) error {
+func (m *MockCurrency) CreatePrice(ctx context.Context, rates ...model.CurrencyPrice) (model.CreateCurrencyPricesRes, error) {
 	m.ctrl.T.Helper()
 	varargs := []interface{}{ctx}
 	for _, a := range rates {
 		varargs = append(varargs, a)
 	}
 	ret := m.ctrl.Call(m, "CreatePrice", varargs...)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(model.CreateCurrencyPricesRes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // CreatePrice indicates an expected call of CreatePrice.
@@ -68,6 +103,34 @@ func (mr *MockCurrencyMockRecorder) CreatePrice(ctx interface{}, rates ...interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePrice", reflect.TypeOf((*MockCurrency)(nil).CreatePrice), varargs...)
 }
 
+// Delete mocks base method.
+func (m *MockCurrency) Delete(ctx context.Context, symbol string, purge bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Delete", ctx, symbol, purge)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Delete indicates an expected call of Delete.
+func (mr *MockCurrencyMockRecorder) Delete(ctx, symbol, purge interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockCurrency)(nil).Delete), ctx, symbol, purge)
+}
+
+// GetBinanceWeight mocks base method.
+func (m *MockCurrency) GetBinanceWeight() model.BinanceWeightDTO {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBinanceWeight")
+	ret0, _ := ret[0].(model.BinanceWeightDTO)
+	return ret0
+}
+
+// GetBinanceWeight indicates an expected call of GetBinanceWeight.
+func (mr *MockCurrencyMockRecorder) GetBinanceWeight() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBinanceWeight", reflect.TypeOf((*MockCurrency)(nil).GetBinanceWeight))
+}
+
 // GetCurrentPrices mocks base method.
 func (m *MockCurrency) GetCurrentPrices(ctx context.Context, symbols ...string) ([]model.GetCurrencyPriceDTO, error) {
 	m.ctrl.T.Helper()
@@ -103,10 +166,25 @@ func (mr *MockCurrencyMockRecorder) GetPriceHistorical(ctx, req interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPriceHistorical", reflect.TypeOf((*MockCurrency)(nil).GetPriceHistorical), ctx, req)
 }
 
+// GetRollups mocks base method.
+func (m *MockCurrency) GetRollups(ctx context.Context, req model.GetCurrencyRollupsDTOReq) (*model.GetCurrencyRollupsDTORes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRollups", ctx, req)
+	ret0, _ := ret[0].(*model.GetCurrencyRollupsDTORes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRollups indicates an expected call of GetRollups.
+func (mr *MockCurrencyMockRecorder) GetRollups(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRollups", reflect.TypeOf((*MockCurrency)(nil).GetRollups), ctx, req)
+}
+
 // GetStat24H mocks base method.
-func (m *MockCurrency) GetStat24H(ctx context.Context, symbols ...string) ([]model.GetCurrencyStat24HDTO, error) {
+func (m *MockCurrency) GetStat24H(ctx context.Context, source string, symbols ...string) ([]model.GetCurrencyStat24HDTO, error) {
 	m.ctrl.T.Helper()
-	varargs := []interface{}{ctx}
+	varargs := []interface{}{ctx, source}
 	for _, a := range symbols {
 		varargs = append(varargs, a)
 	}
@@ -117,9 +195,9 @@ func (m *MockCurrency) GetStat24H(ctx context.Context, symbols ...string) ([]mod
 }
 
 // GetStat24H indicates an expected call of GetStat24H.
-func (mr *MockCurrencyMockRecorder) GetStat24H(ctx interface{}, symbols ...interface{}) *gomock.Call {
+func (mr *MockCurrencyMockRecorder) GetStat24H(ctx, source interface{}, symbols ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	varargs := append([]interface{}{ctx}, symbols...)
+	varargs := append([]interface{}{ctx, source}, symbols...)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStat24H", reflect.TypeOf((*MockCurrency)(nil).GetStat24H), varargs...)
 }
 
@@ -138,17 +216,124 @@ func (mr *MockCurrencyMockRecorder) List(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockCurrency)(nil).List), ctx)
 }
 
+// ListBackfills mocks base method.
+func (m *MockCurrency) ListBackfills(ctx context.Context, symbol string) ([]model.KlineBackfill, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBackfills", ctx, symbol)
+	ret0, _ := ret[0].([]model.KlineBackfill)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBackfills indicates an expected call of ListBackfills.
+func (mr *MockCurrencyMockRecorder) ListBackfills(ctx, symbol interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBackfills", reflect.TypeOf((*MockCurrency)(nil).ListBackfills), ctx, symbol)
+}
+
 // ListPrices mocks base method.
-func (m *MockCurrency) ListPrices(ctx context.Context) ([]model.CurrencyPrice, error) {
+func (m *MockCurrency) ListPrices(ctx context.Context, filter model.ListCurrencyPricesFilter) (model.ListCurrencyPricesDTORes, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListPrices", ctx)
-	ret0, _ := ret[0].([]model.CurrencyPrice)
+	ret := m.ctrl.Call(m, "ListPrices", ctx, filter)
+	ret0, _ := ret[0].(model.ListCurrencyPricesDTORes)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // ListPrices indicates an expected call of ListPrices.
-func (mr *MockCurrencyMockRecorder) ListPrices(ctx interface{}) *gomock.Call {
+func (mr *MockCurrencyMockRecorder) ListPrices(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPrices", reflect.TypeOf((*MockCurrency)(nil).ListPrices), ctx, filter)
+}
+
+// ListSymbols mocks base method.
+func (m *MockCurrency) ListSymbols(ctx context.Context, filter model.ListSymbolsFilter) ([]model.SymbolDTO, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListSymbols", ctx, filter)
+	ret0, _ := ret[0].([]model.SymbolDTO)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListSymbols indicates an expected call of ListSymbols.
+func (mr *MockCurrencyMockRecorder) ListSymbols(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListSymbols", reflect.TypeOf((*MockCurrency)(nil).ListSymbols), ctx, filter)
+}
+
+// RunBackgroudProcesses mocks base method.
+func (m *MockCurrency) RunBackgroudProcesses(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RunBackgroudProcesses", ctx)
+}
+
+// RunBackgroudProcesses indicates an expected call of RunBackgroudProcesses.
+func (mr *MockCurrencyMockRecorder) RunBackgroudProcesses(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunBackgroudProcesses", reflect.TypeOf((*MockCurrency)(nil).RunBackgroudProcesses), ctx)
+}
+
+// SetActive mocks base method.
+func (m *MockCurrency) SetActive(ctx context.Context, symbol string, active bool) (model.Currency, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetActive", ctx, symbol, active)
+	ret0, _ := ret[0].(model.Currency)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetActive indicates an expected call of SetActive.
+func (mr *MockCurrencyMockRecorder) SetActive(ctx, symbol, active interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetActive", reflect.TypeOf((*MockCurrency)(nil).SetActive), ctx, symbol, active)
+}
+
+// MockFx is a mock of Fx interface.
+type MockFx struct {
+	ctrl     *gomock.Controller
+	recorder *MockFxMockRecorder
+}
+
+// MockFxMockRecorder is the mock recorder for MockFx.
+type MockFxMockRecorder struct {
+	mock *MockFx
+}
+
+// NewMockFx creates a new mock instance.
+func NewMockFx(ctrl *gomock.Controller) *MockFx {
+	mock := &MockFx{ctrl: ctrl}
+	mock.recorder = &MockFxMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFx) EXPECT() *MockFxMockRecorder {
+	return m.recorder
+}
+
+// Rates mocks base method.
+func (m *MockFx) Rates(ctx context.Context, currency string, startTime, endTime int64) (model.FxRates, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rates", ctx, currency, startTime, endTime)
+	ret0, _ := ret[0].(model.FxRates)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rates indicates an expected call of Rates.
+func (mr *MockFxMockRecorder) Rates(ctx, currency, startTime, endTime interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rates", reflect.TypeOf((*MockFx)(nil).Rates), ctx, currency, startTime, endTime)
+}
+
+// RunBackgroudProcesses mocks base method.
+func (m *MockFx) RunBackgroudProcesses(ctx context.Context) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RunBackgroudProcesses", ctx)
+}
+
+// RunBackgroudProcesses indicates an expected call of RunBackgroudProcesses.
+func (mr *MockFxMockRecorder) RunBackgroudProcesses(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPrices", reflect.TypeOf((*MockCurrency)(nil).ListPrices), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunBackgroudProcesses", reflect.TypeOf((*MockFx)(nil).RunBackgroudProcesses), ctx)
 }
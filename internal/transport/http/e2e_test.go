@@ -0,0 +1,208 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"gexabyte/internal/config"
+	"gexabyte/internal/model"
+	"gexabyte/internal/repository"
+	"gexabyte/internal/service"
+	"gexabyte/internal/service/currency"
+	"gexabyte/pkg/clients/binance"
+	"gexabyte/pkg/clients/binance/fake"
+	"gexabyte/pkg/clients/fxrate"
+	"gexabyte/pkg/clients/marketdata"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newE2EServer runs the service on the memory repository against the fake exchange.
+// The exchange lists default pairs and the given ones. Background processes are started only in the stream mode.
+func newE2EServer(t *testing.T, ingestMode string, symbols ...fake.Symbol) (*fake.Server, *httptest.Server) {
+	exchange := fake.NewServer(fake.Config{
+		Symbols:        append(fake.DefaultSymbols(), symbols...),
+		Seed:           1,
+		StreamInterval: 10 * time.Millisecond,
+	})
+	t.Cleanup(exchange.Close)
+
+	cfg := &config.Config{DBDriver: repository.DriverMemory, PriceConflict: model.PriceConflictKeepFirst}
+	cfg.Binance.ExchangeInfoRefresh = time.Hour
+	cfg.Ingest.Mode = ingestMode
+	cfg.Ingest.SampleInterval = 50 * time.Millisecond
+	cfg.Retention.Interval = time.Hour
+	cfg.Fx.Currencies = []string{"EUR"}
+	cfg.Fx.Refresh = time.Hour
+
+	repo, err := repository.NewRepository(cfg)
+	require.NoError(t, err)
+
+	binanceClient := binance.New(&binance.Config{BaseURL: exchange.URL, Timeout: time.Second})
+	marketStream := binance.NewStream(binance.StreamConfig{URL: "ws" + strings.TrimPrefix(exchange.URL, "http")})
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	providers := []marketdata.Provider{binance.NewProvider(binanceClient)}
+
+	fxProvider := fxrate.NewStatic(map[string]decimal.Decimal{"EUR": decimal.RequireFromString("0.5")})
+
+	service := service.New(cfg, logger, binanceClient, providers, marketStream, fxProvider, repo)
+	if ingestMode == currency.IngestModeStream {
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		service.Currency.RunBackgroudProcesses(ctx)
+	}
+
+	server := New(cfg, logger, service)
+	api := httptest.NewServer(server.setupRouter())
+	t.Cleanup(api.Close)
+
+	return exchange, api
+}
+
+func e2eRequest(t *testing.T, api *httptest.Server, method, path string, body string) (int, []byte) {
+	req, err := http.NewRequest(method, api.URL+"/api/v1"+path, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := api.Client().Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+
+	return res.StatusCode, data
+}
+
+func symbolsQuery(symbols string) string {
+	return "?symbols=" + url.QueryEscape(symbols)
+}
+
+func TestE2E(t *testing.T) {
+	exchange, api := newE2EServer(t, currency.IngestModePoll,
+		fake.Symbol{Symbol: "ADAUSDT", BaseAsset: "ADA", QuoteAsset: "USDT", TickSize: "0.0001", Price: decimal.RequireFromString("0.45")},
+		fake.Symbol{Symbol: "LUNAUSDT", BaseAsset: "LUNA", QuoteAsset: "USDT", Status: "BREAK", Price: decimal.NewFromInt(1)},
+	)
+
+	do := func(method, path string, body string) (int, []byte) {
+		return e2eRequest(t, api, method, path, body)
+	}
+
+	t.Run("current prices are fetched and stored", func(t *testing.T) {
+		exchange.Script("BTCUSDT", decimal.RequireFromString("64123.45"))
+
+		status, body := do(http.MethodGet, "/prices/current"+symbolsQuery(`["BTCUSDT"]`), "")
+		require.Equal(t, http.StatusOK, status, string(body))
+
+		var prices []model.GetCurrencyPriceDTO
+		require.NoError(t, json.Unmarshal(body, &prices))
+		require.Len(t, prices, 1)
+		assert.Equal(t, "64123.45", prices[0].Price.String())
+		assert.False(t, prices[0].Stale)
+
+		status, body = do(http.MethodGet, "/prices"+symbolsQuery(`["BTCUSDT"]`), "")
+		require.Equal(t, http.StatusOK, status, string(body))
+
+		var stored model.ListCurrencyPricesDTORes
+		require.NoError(t, json.Unmarshal(body, &stored))
+		require.Len(t, stored.Prices, 1)
+		assert.Equal(t, "64123.45", stored.Prices[0].Price.String())
+	})
+
+	t.Run("stored prices convert to fiat", func(t *testing.T) {
+		status, body := do(http.MethodGet, "/prices"+symbolsQuery(`["BTCUSDT"]`)+"&convert=EUR", "")
+		require.Equal(t, http.StatusOK, status, string(body))
+
+		var stored model.ListCurrencyPricesDTORes
+		require.NoError(t, json.Unmarshal(body, &stored))
+		require.Len(t, stored.Prices, 1)
+		assert.Equal(t, "32061.725", stored.Prices[0].Price.String())
+
+		status, _ = do(http.MethodGet, "/prices"+symbolsQuery(`["BTCUSDT"]`)+"&convert=GBP", "")
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("unknown symbol", func(t *testing.T) {
+		status, body := do(http.MethodGet, "/prices/current"+symbolsQuery(`["BTCUSDX"]`), "")
+		assert.Equal(t, http.StatusNotFound, status)
+		assert.Contains(t, string(body), `"code":"invalid_symbol"`)
+	})
+
+	t.Run("currency is validated against exchange info", func(t *testing.T) {
+		status, body := do(http.MethodPost, "/currency", `{"symbol": "ADAUSDT"}`)
+		require.Equal(t, http.StatusCreated, status, string(body))
+
+		status, _ = do(http.MethodPost, "/currency", `{"symbol": "LUNAUSDT"}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, status)
+
+		status, body = do(http.MethodGet, "/currencies", "")
+		require.Equal(t, http.StatusOK, status, string(body))
+
+		var currencies []model.Currency
+		require.NoError(t, json.Unmarshal(body, &currencies))
+		symbols := make(map[string]model.Currency, len(currencies))
+		for _, currency := range currencies {
+			symbols[currency.Symbol] = currency
+		}
+		require.Contains(t, symbols, "ADAUSDT")
+		assert.NotContains(t, symbols, "LUNAUSDT")
+		assert.Equal(t, "ADA", symbols["ADAUSDT"].BaseAsset)
+		assert.Equal(t, "0.0001", symbols["ADAUSDT"].TickSize.String())
+	})
+
+	t.Run("24h summary of the exchange", func(t *testing.T) {
+		status, body := do(http.MethodGet, "/stat/24h"+symbolsQuery(`["ETHUSDT"]`)+"&source=binance", "")
+		require.Equal(t, http.StatusOK, status, string(body))
+		assert.Contains(t, string(body), "ETHUSDT")
+	})
+
+	t.Run("exchange failure", func(t *testing.T) {
+		exchange.Fail("/api/v3/ticker/price", http.StatusInternalServerError, "", 10)
+
+		status, body := do(http.MethodGet, "/prices/current"+symbolsQuery(`["ETHUSDT"]`), "")
+		assert.Equal(t, http.StatusBadGateway, status)
+		assert.Contains(t, string(body), `"code":"upstream_unavailable"`)
+	})
+}
+
+func TestE2EStream(t *testing.T) {
+	exchange, api := newE2EServer(t, currency.IngestModeStream,
+		fake.Symbol{Symbol: "BNBUSDT", BaseAsset: "BNB", QuoteAsset: "USDT", Price: decimal.NewFromInt(600)},
+	)
+
+	storedPrices := func(symbol string) []model.CurrencyPriceDTO {
+		status, body := e2eRequest(t, api, http.MethodGet, "/prices"+symbolsQuery(`["`+symbol+`"]`), "")
+		require.Equal(t, http.StatusOK, status, string(body))
+
+		var res model.ListCurrencyPricesDTORes
+		require.NoError(t, json.Unmarshal(body, &res))
+		return res.Prices
+	}
+
+	// seeded pairs are subscribed on start and sampled prices are stored
+	require.Eventually(t, func() bool { return len(storedPrices("ETHUSDT")) >= 2 }, 5*time.Second, 20*time.Millisecond)
+	assert.Zero(t, exchange.Calls("/api/v3/ticker/price"))
+
+	// a new pair is subscribed at once
+	status, body := e2eRequest(t, api, http.MethodPost, "/currency", `{"symbol": "BNBUSDT"}`)
+	require.Equal(t, http.StatusCreated, status, string(body))
+	require.Eventually(t, func() bool { return len(storedPrices("BNBUSDT")) > 0 }, 5*time.Second, 20*time.Millisecond)
+
+	// a deactivated pair is unsubscribed
+	status, body = e2eRequest(t, api, http.MethodPatch, "/currency/BNBUSDT", `{"active": false}`)
+	require.Equal(t, http.StatusOK, status, string(body))
+	time.Sleep(200 * time.Millisecond)
+	count := len(storedPrices("BNBUSDT"))
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, count, len(storedPrices("BNBUSDT")))
+}
@@ -1,5 +1,66 @@
 package http
 
+import (
+	"errors"
+	"gexabyte/internal/model"
+	"gexabyte/pkg/clients/marketdata"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
 type ErrMsg struct {
 	Err string `json:"error"`
+	// Code is a stable name of the error, set for failures of the exchange.
+	Code string `json:"code,omitempty"`
+}
+
+// Codes of failures of market data providers.
+const (
+	ErrCodeInvalidSymbol       = "invalid_symbol"
+	ErrCodeInvalidInterval     = "invalid_interval"
+	ErrCodeBadRequest          = "bad_request"
+	ErrCodeRateLimited         = "rate_limited"
+	ErrCodeIPBanned            = "ip_banned"
+	ErrCodeUpstreamTimeout     = "upstream_timeout"
+	ErrCodeUpstreamUnavailable = "upstream_unavailable"
+	ErrCodeCircuitOpen         = "upstream_circuit_open"
+	ErrCodeNoConsensus         = "no_consensus"
+)
+
+var upstreamErrors = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{marketdata.ErrInvalidSymbol, http.StatusNotFound, ErrCodeInvalidSymbol},
+	{marketdata.ErrInvalidInterval, http.StatusBadRequest, ErrCodeInvalidInterval},
+	{marketdata.ErrBadRequest, http.StatusBadRequest, ErrCodeBadRequest},
+	{marketdata.ErrRateLimited, http.StatusTooManyRequests, ErrCodeRateLimited},
+	{marketdata.ErrIPBanned, http.StatusTooManyRequests, ErrCodeIPBanned},
+	{marketdata.ErrTimeout, http.StatusGatewayTimeout, ErrCodeUpstreamTimeout},
+	{marketdata.ErrUnavailable, http.StatusBadGateway, ErrCodeUpstreamUnavailable},
+	{marketdata.ErrCircuitOpen, http.StatusServiceUnavailable, ErrCodeCircuitOpen},
+	{model.ErrNoConsensus, http.StatusBadGateway, ErrCodeNoConsensus},
+}
+
+// writeUpstreamError responds with the status and code of a failure of a market data provider.
+// It returns false and writes nothing for other errors.
+func writeUpstreamError(c *gin.Context, err error) bool {
+	for _, upstream := range upstreamErrors {
+		if !errors.Is(err, upstream.err) {
+			continue
+		}
+
+		if retryAfter := marketdata.RetryAfter(err); retryAfter > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		}
+
+		c.JSON(upstream.status, ErrMsg{Err: err.Error(), Code: upstream.code})
+		return true
+	}
+
+	return false
 }
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gexabyte/internal/model"
+	"gexabyte/pkg/clients/marketdata"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fxCurrency returns the currency of the convert param, empty when prices stay in their quote asset.
+// Only prices quoted in USD or its stablecoins convert, a bad request is written for other symbols and ok is false.
+func fxCurrency(c *gin.Context, symbols ...string) (currency string, ok bool) {
+	currency = strings.ToUpper(strings.TrimSpace(c.Query("convert")))
+	if currency == "" {
+		return "", true
+	}
+
+	for _, symbol := range symbols {
+		pair, err := marketdata.ParseSymbol(symbol)
+		if err != nil || !model.IsFxBaseAsset(pair.Quote) {
+			c.JSON(http.StatusBadRequest, ErrMsg{Err: fmt.Sprintf("%s does not quote in %s, it cannot be converted", symbol, strings.Join(model.FxBaseAssets, ", "))})
+			return "", false
+		}
+	}
+
+	return currency, true
+}
+
+// fxRates returns rates of the currency closest to times between startTime and endTime.
+// Failures are written to the response and ok is false.
+func (s *Server) fxRates(c *gin.Context, ctx context.Context, currency string, startTime, endTime int64) (rates model.FxRates, ok bool) {
+	rates, err := s.service.Fx.Rates(ctx, currency, startTime, endTime)
+	if err != nil {
+		if errors.Is(err, model.ErrNoFxRate) {
+			c.JSON(http.StatusBadRequest, ErrMsg{Err: err.Error()})
+			return nil, false
+		}
+		if writeUpstreamError(c, err) {
+			return nil, false
+		}
+		c.JSON(http.StatusInternalServerError, ErrMsg{Err: err.Error()})
+		return nil, false
+	}
+
+	return rates, true
+}
+
+// timeRange returns the earliest and the latest of times.
+func timeRange(times ...int64) (startTime, endTime int64) {
+	for i, t := range times {
+		if i == 0 || t < startTime {
+			startTime = t
+		}
+		if i == 0 || t > endTime {
+			endTime = t
+		}
+	}
+
+	return startTime, endTime
+}
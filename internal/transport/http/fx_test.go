@@ -0,0 +1,157 @@
+package http
+
+import (
+	"fmt"
+	"gexabyte/internal/model"
+	"gexabyte/internal/service"
+	mock_service "gexabyte/internal/service/mock"
+	"gexabyte/pkg/clients/marketdata"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertFx(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyService := mock_service.NewMockCurrency(ctrl)
+	fxService := mock_service.NewMockFx(ctrl)
+	service := service.Manager{Currency: currencyService, Fx: fxService}
+
+	server := Server{
+		service: &service,
+		logger:  slog.Default(),
+	}
+
+	eurRates := model.FxRates{
+		{Currency: "EUR", Rate: decimal.RequireFromString("0.9"), Time: 1000},
+		{Currency: "EUR", Rate: decimal.RequireFromString("0.8"), Time: 2000},
+	}
+
+	tc := []struct {
+		name          string
+		path          string
+		buildStubs    func(currency *mock_service.MockCurrency, fx *mock_service.MockFx)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "current prices",
+			path: "/prices/current?convert=eur&symbols=" + url.QueryEscape(`["BTCUSDT"]`),
+			buildStubs: func(currency *mock_service.MockCurrency, fx *mock_service.MockFx) {
+				currency.EXPECT().GetCurrentPrices(gomock.Any(), "BTCUSDT").Times(1).Return([]model.GetCurrencyPriceDTO{
+					{Symbol: "BTCUSDT", Price: decimal.NewFromInt(60000), Time: 1900},
+				}, nil)
+				fx.EXPECT().Rates(gomock.Any(), "EUR", int64(1900), int64(1900)).Times(1).Return(eurRates, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.JSONEq(t, `[{"Symbol": "BTCUSDT", "Price": "48000", "Time": 1900}]`, recorder.Body.String())
+			},
+		},
+		{
+			name: "candles take rates of their times",
+			path: "/prices/historical?convert=EUR&symbol=BTCUSDT&interval=1s&startTime=1000&endTime=2000&page=1&limit=10",
+			buildStubs: func(currency *mock_service.MockCurrency, fx *mock_service.MockFx) {
+				currency.EXPECT().GetPriceHistorical(gomock.Any(), gomock.Any()).Times(1).Return(&model.GetCurrencyPriceHistoricalDTORes{
+					Page: 1, MaxPage: 1,
+					Prices: []model.CurrencyPriceInterval{{
+						OpenPrice: decimal.NewFromInt(100), ClosePrice: decimal.NewFromInt(110),
+						HighPrice: decimal.NewFromInt(120), LowPrice: decimal.NewFromInt(90),
+						OpenTime: 1000, CloseTime: 1999,
+					}},
+				}, nil)
+				fx.EXPECT().Rates(gomock.Any(), "EUR", int64(1000), int64(1999)).Times(1).Return(eurRates, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.JSONEq(t, `{"page": 1, "max_page": 1, "prices": [{"open_price": "90", "close_price": "88",
+					"high_price": "96", "low_price": "72", "open_time": 1000, "close_time": 1999}]}`, recorder.Body.String())
+			},
+		},
+		{
+			name: "stat recomputes change",
+			path: "/stat/24h?convert=EUR&symbols=" + url.QueryEscape(`["BTCUSDT"]`),
+			buildStubs: func(currency *mock_service.MockCurrency, fx *mock_service.MockFx) {
+				currency.EXPECT().GetStat24H(gomock.Any(), model.StatSourceLocal, "BTCUSDT").Times(1).Return([]model.GetCurrencyStat24HDTO{{
+					Symbol: "BTCUSDT", Source: model.StatSourceLocal,
+					OpenPrice: decimal.NewFromInt(100), LastPrice: decimal.NewFromInt(100),
+					HighPrice: decimal.NewFromInt(100), LowPrice: decimal.NewFromInt(100), AvgPrice: decimal.NewFromInt(100),
+					OpenTime: 1000, CloseTime: 2000, Count: 2,
+				}}, nil)
+				fx.EXPECT().Rates(gomock.Any(), "EUR", int64(1000), int64(2000)).Times(1).Return(eurRates, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"open_price":"90","last_price":"80"`)
+				assert.Contains(t, recorder.Body.String(), `"price_change_percent":"-11.11111111111111"`)
+			},
+		},
+		{
+			name: "stored prices of all symbols",
+			path: "/prices?convert=EUR",
+			buildStubs: func(currency *mock_service.MockCurrency, fx *mock_service.MockFx) {
+				currency.EXPECT().ListPrices(gomock.Any(), gomock.Any()).Times(1).Return(model.ListCurrencyPricesDTORes{
+					Prices: []model.CurrencyPriceDTO{{ID: 1, Symbol: "ETHBTC", Price: decimal.RequireFromString("0.05"), Time: 1000}},
+				}, nil)
+				fx.EXPECT().Rates(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), "ETHBTC")
+			},
+		},
+		{
+			name: "symbol not quoted in usd",
+			path: "/prices/rollups?convert=EUR&symbol=ETHBTC&interval=1h&startTime=1&endTime=2",
+			buildStubs: func(currency *mock_service.MockCurrency, fx *mock_service.MockFx) {
+				currency.EXPECT().GetRollups(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "unknown currency",
+			path: "/prices/rollups?convert=GBP&symbol=BTCUSDT&interval=1h&startTime=1&endTime=2",
+			buildStubs: func(currency *mock_service.MockCurrency, fx *mock_service.MockFx) {
+				currency.EXPECT().GetRollups(gomock.Any(), gomock.Any()).Times(1).Return(&model.GetCurrencyRollupsDTORes{}, nil)
+				fx.EXPECT().Rates(gomock.Any(), "GBP", gomock.Any(), gomock.Any()).Times(1).Return(nil, fmt.Errorf("%w: GBP", model.ErrNoFxRate))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "fx provider unavailable",
+			path: "/prices/rollups?convert=EUR&symbol=BTCUSDT&interval=1h&startTime=1&endTime=2",
+			buildStubs: func(currency *mock_service.MockCurrency, fx *mock_service.MockFx) {
+				currency.EXPECT().GetRollups(gomock.Any(), gomock.Any()).Times(1).Return(&model.GetCurrencyRollupsDTORes{}, nil)
+				fx.EXPECT().Rates(gomock.Any(), "EUR", gomock.Any(), gomock.Any()).Times(1).Return(nil, marketdata.ErrUnavailable)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadGateway, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tc {
+		t.Run(test.name, func(t *testing.T) {
+			test.buildStubs(currencyService, fxService)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1"+test.path, nil)
+			rec := httptest.NewRecorder()
+
+			router := server.setupRouter()
+			router.ServeHTTP(rec, req)
+
+			test.checkResponse(t, rec)
+		})
+	}
+}
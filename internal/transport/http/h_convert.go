@@ -0,0 +1,64 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"gexabyte/internal/model"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/shopspring/decimal"
+)
+
+// Convert godoc
+//
+//	@Summary		Convert an amount
+//	@Description	Converts the amount by the latest price of a direct pair, or of two pairs through USDT or BTC when the assets make no pair.
+//	@Description	Prices are asked from the primary provider, a pair it fails to price takes its last stored price marked `stored`, so tracked pairs convert while the provider is unreachable.
+//	@Description	`quote_time` is the time of the oldest price of the path and `age_ms` is its age.
+//	@Tags			prices
+//	@Produce		json
+//	@Param			from	query		string	true	"Asset to convert from"		example(ETH)
+//	@Param			to		query		string	true	"Asset to convert to"		example(BTC)
+//	@Param			amount	query		string	true	"Amount of the from asset"	example(1.5)
+//	@Success		200		{object}	model.ConvertDTORes
+//	@Failure		400		{object}	ErrMsg	"Invalid request parameters"
+//	@Failure		404		{object}	ErrMsg	"No pair or path through USDT or BTC connects the assets"
+//	@Failure		429		{object}	ErrMsg	"Provider rate limit or ban, code rate_limited or ip_banned, Retry-After is set when known"
+//	@Failure		502		{object}	ErrMsg	"Provider failed, code upstream_unavailable"
+//	@Failure		503		{object}	ErrMsg	"Provider keeps failing and is not called, code upstream_circuit_open"
+//	@Failure		504		{object}	ErrMsg	"Provider timed out, code upstream_timeout"
+//	@Failure		500		{object}	ErrMsg	"Internal server error"
+//	@Router			/convert [get]
+func (s *Server) Convert(c *gin.Context) {
+	from, to := c.Query("from"), c.Query("to")
+	if from == "" || to == "" {
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: "from and to params are required"})
+		return
+	}
+
+	amount, err := decimal.NewFromString(c.Query("amount"))
+	if err != nil || !amount.IsPositive() {
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: "amount must be a positive number"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Copy(), 10*time.Second)
+	defer cancel()
+
+	res, err := s.service.Currency.Convert(ctx, model.ConvertDTOReq{From: from, To: to, Amount: amount})
+	if err != nil {
+		if errors.Is(err, model.ErrNoPath) {
+			c.JSON(http.StatusNotFound, ErrMsg{Err: err.Error()})
+			return
+		}
+		if writeUpstreamError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrMsg{Err: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"fmt"
+	"gexabyte/internal/model"
+	"gexabyte/internal/service"
+	mock_service "gexabyte/internal/service/mock"
+	"gexabyte/pkg/clients/marketdata"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvert(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyService := mock_service.NewMockCurrency(ctrl)
+	service := service.Manager{Currency: currencyService}
+
+	server := Server{
+		service: &service,
+		logger:  slog.Default(),
+	}
+
+	tc := []struct {
+		name          string
+		query         string
+		buildStubs    func(service *mock_service.MockCurrency)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "?from=ETH&to=BTC&amount=1.5",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				req := model.ConvertDTOReq{From: "ETH", To: "BTC", Amount: decimal.RequireFromString("1.5")}
+				service.EXPECT().Convert(gomock.Any(), req).Times(1).Return(model.ConvertDTORes{
+					From: "ETH", To: "BTC", Amount: decimal.RequireFromString("1.5"),
+					Result: decimal.RequireFromString("0.075"), Rate: decimal.RequireFromString("0.05"),
+					Path: []model.ConvertLegDTO{{
+						Symbol: "ETHBTC", Price: decimal.RequireFromString("0.05"), Time: 1000, Stored: true,
+					}},
+					QuoteTime: 1000, AgeMs: 500,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.JSONEq(t, `{"from": "ETH", "to": "BTC", "amount": "1.5", "result": "0.075", "rate": "0.05",
+					"path": [{"symbol": "ETHBTC", "price": "0.05", "time": 1000, "inverted": false, "stored": true}],
+					"quote_time": 1000, "age_ms": 500}`, recorder.Body.String())
+			},
+		},
+		{
+			name:  "bad request no to",
+			query: "?from=ETH&amount=1",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().Convert(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "bad request invalid amount",
+			query: "?from=ETH&to=BTC&amount=1,5",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().Convert(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "bad request negative amount",
+			query: "?from=ETH&to=BTC&amount=-1",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().Convert(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "no path",
+			query: "?from=ETH&to=XYZ&amount=1",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().Convert(gomock.Any(), gomock.Any()).Times(1).Return(model.ConvertDTORes{}, fmt.Errorf("%w: ETH to XYZ", model.ErrNoPath))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:  "provider unavailable",
+			query: "?from=ETH&to=BTC&amount=1",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().Convert(gomock.Any(), gomock.Any()).Times(1).Return(model.ConvertDTORes{}, marketdata.ErrUnavailable)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadGateway, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"code":"upstream_unavailable"`)
+			},
+		},
+		{
+			name:  "internal server error",
+			query: "?from=ETH&to=BTC&amount=1",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().Convert(gomock.Any(), gomock.Any()).Times(1).Return(model.ConvertDTORes{}, fmt.Errorf("unexpected"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tc {
+		t.Run(test.name, func(t *testing.T) {
+			test.buildStubs(currencyService)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/convert"+test.query, nil)
+			rec := httptest.NewRecorder()
+
+			router := server.setupRouter()
+			router.ServeHTTP(rec, req)
+
+			test.checkResponse(t, rec)
+		})
+	}
+}
@@ -2,7 +2,11 @@ package http
 
 import (
 	"context"
+	"errors"
+	"gexabyte/internal/model"
+	"gexabyte/pkg/clients/marketdata"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -22,27 +26,162 @@ type CreateCurrencyReq struct {
 //	@Param			currency	body	CreateCurrencyReq	true	"Currency to create"
 //	@Success		201
 //	@Failure		400	{object}	ErrMsg	"Invalid request parameters"
+//	@Failure		409	{object}	ErrMsg	"Currency is already tracked"
+//	@Failure		422	{object}	ErrMsg	"Symbol is not listed or not trading on the exchange"
+//	@Failure		429	{object}	ErrMsg	"Provider rate limit or ban, code rate_limited or ip_banned, Retry-After is set when known"
+//	@Failure		502	{object}	ErrMsg	"Provider failed, code upstream_unavailable"
+//	@Failure		503	{object}	ErrMsg	"Provider keeps failing and is not called, code upstream_circuit_open"
+//	@Failure		504	{object}	ErrMsg	"Provider timed out, code upstream_timeout"
 //	@Failure		500	{object}	ErrMsg	"Internal server error"
 //	@Router			/currency [post]
 func (s *Server) CreateCurrency(c *gin.Context) {
 	var req CreateCurrencyReq
 
 	if err := c.BindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, ErrMsg{err.Error()})
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: err.Error()})
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(c.Copy(), 5*time.Second)
 	defer cancel()
 
-	if err := s.service.Currency.Create(ctx, req.Symbol); err != nil {
-		c.JSON(http.StatusInternalServerError, ErrMsg{err.Error()})
+	if err := s.service.Currency.Create(ctx, marketdata.NormalizeSymbol(req.Symbol)); err != nil {
+		if errors.Is(err, model.ErrAlreadyExists) {
+			c.JSON(http.StatusConflict, ErrMsg{Err: err.Error()})
+			return
+		}
+		if errors.Is(err, model.ErrInvalidSymbol) {
+			c.JSON(http.StatusUnprocessableEntity, ErrMsg{Err: err.Error()})
+			return
+		}
+		if writeUpstreamError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrMsg{Err: err.Error()})
 		return
 	}
 
 	c.Status(http.StatusCreated)
 }
 
+type CreateCurrenciesReq struct {
+	Symbols []string `json:"symbols" binding:"required,min=1,max=100,dive,required,uppercase"`
+}
+
+// CreateCurrencies godoc
+//
+//	@Summary		Create many
+//	@Description	Creates tracked pairs of the list. Each symbol is validated and stored on its own,
+//	@Description	the response tells per symbol whether it was created, already tracked, invalid or failed.
+//	@Tags			currency
+//	@Accept			json
+//	@Produce		json
+//	@Param			currencies	body		CreateCurrenciesReq			true	"Symbols to create, up to 100"
+//	@Success		200			{array}		model.CreateCurrencyResDTO	"Outcome per symbol"
+//	@Failure		400			{object}	ErrMsg						"Invalid request parameters"
+//	@Router			/currencies [post]
+func (s *Server) CreateCurrencies(c *gin.Context) {
+	var req CreateCurrenciesReq
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Copy(), 30*time.Second)
+	defer cancel()
+
+	c.JSON(http.StatusOK, s.service.Currency.CreateMany(ctx, normalizeSymbols(req.Symbols)...))
+}
+
+type UpdateCurrencyReq struct {
+	Active *bool `json:"active" binding:"required"`
+}
+
+// UpdateCurrency godoc
+//
+//	@Summary		Update
+//	@Description	Deactivates or reactivates a tracked pair. Inactive pairs are not polled for prices but keep their history.
+//	@Tags			currency
+//	@Accept			json
+//	@Produce		json
+//	@Param			symbol		path		string				true	"Currency symbol"
+//	@Param			currency	body		UpdateCurrencyReq	true	"Fields to update"
+//	@Success		200			{object}	model.Currency		"Updated currency"
+//	@Failure		400			{object}	ErrMsg				"Invalid request parameters"
+//	@Failure		404			{object}	ErrMsg				"Currency is not tracked"
+//	@Failure		500			{object}	ErrMsg				"Internal server error"
+//	@Router			/currency/{symbol} [patch]
+func (s *Server) UpdateCurrency(c *gin.Context) {
+	var req UpdateCurrencyReq
+
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Copy(), 5*time.Second)
+	defer cancel()
+
+	res, err := s.service.Currency.SetActive(ctx, marketdata.NormalizeSymbol(c.Param("symbol")), *req.Active)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrMsg{Err: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrMsg{Err: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// DeleteCurrency godoc
+//
+//	@Summary		Delete
+//	@Description	Stops tracking a pair. A pair with stored prices is deleted only with purge=true:
+//	@Description	it is deactivated at once, prices are removed in background and the pair is deleted after them.
+//	@Tags			currency
+//	@Produce		json
+//	@Param			symbol	path	string	true	"Currency symbol"
+//	@Param			purge	query	bool	false	"Remove stored prices too"
+//	@Success		202		"Purge is started"
+//	@Success		204		"Currency is deleted"
+//	@Failure		400		{object}	ErrMsg	"Invalid request parameters"
+//	@Failure		404		{object}	ErrMsg	"Currency is not tracked"
+//	@Failure		409		{object}	ErrMsg	"Currency has stored prices, deactivate it or pass purge=true"
+//	@Failure		500		{object}	ErrMsg	"Internal server error"
+//	@Router			/currency/{symbol} [delete]
+func (s *Server) DeleteCurrency(c *gin.Context) {
+	purge, err := strconv.ParseBool(c.DefaultQuery("purge", "false"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: "purge: " + err.Error()})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Copy(), 5*time.Second)
+	defer cancel()
+
+	if err := s.service.Currency.Delete(ctx, marketdata.NormalizeSymbol(c.Param("symbol")), purge); err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrMsg{Err: err.Error()})
+			return
+		}
+		if errors.Is(err, model.ErrHasPrices) {
+			c.JSON(http.StatusConflict, ErrMsg{Err: err.Error() + ", deactivate it or pass purge=true"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrMsg{Err: err.Error()})
+		return
+	}
+
+	if purge {
+		c.Status(http.StatusAccepted)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
 // ListPrices godoc
 //
 //	@Summary		List currencies
@@ -58,7 +197,35 @@ func (s *Server) ListCurrencies(c *gin.Context) {
 
 	res, err := s.service.Currency.List(ctx)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrMsg{err.Error()})
+		c.JSON(http.StatusInternalServerError, ErrMsg{Err: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// ListBackfills godoc
+//
+//	@Summary		List backfills of currency
+//	@Description	Retrieves progress of loading historical candles of a tracked pair, one item per interval.
+//	@Tags			currency
+//	@Produce		json
+//	@Param			symbol	path		string				true	"Currency symbol"
+//	@Success		200		{array}		model.KlineBackfill	"Backfill progress per interval"
+//	@Failure		404		{object}	ErrMsg				"Currency is not tracked"
+//	@Failure		500		{object}	ErrMsg				"Internal server error"
+//	@Router			/currency/{symbol}/backfill [get]
+func (s *Server) ListBackfills(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Copy(), 5*time.Second)
+	defer cancel()
+
+	res, err := s.service.Currency.ListBackfills(ctx, marketdata.NormalizeSymbol(c.Param("symbol")))
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrMsg{Err: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrMsg{Err: err.Error()})
 		return
 	}
 
@@ -13,6 +13,7 @@ import (
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -64,6 +65,28 @@ func TestCreateCurrency(t *testing.T) {
 				assert.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name:   "already tracked",
+			symbol: "BTCUSDT",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().Create(gomock.Any(), gomock.Any()).Times(1).Return(model.ErrAlreadyExists)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:   "invalid symbol",
+			symbol: "LUNAUSDT",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().Create(gomock.Any(), gomock.Any()).Times(1).
+					Return(fmt.Errorf("%w: LUNAUSDT has status BREAK on the exchange", model.ErrInvalidSymbol))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), "status BREAK")
+			},
+		},
 		{
 			name:   "internal server error",
 			symbol: "BTCUSDT",
@@ -96,6 +119,76 @@ func TestCreateCurrency(t *testing.T) {
 
 }
 
+func TestCreateCurrencies(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyService := mock_service.NewMockCurrency(ctrl)
+	service := service.Manager{Currency: currencyService}
+
+	server := Server{
+		service: &service,
+		logger:  slog.Default(),
+	}
+
+	tc := []struct {
+		name          string
+		body          string
+		buildStubs    func(service *mock_service.MockCurrency)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: `{"symbols": ["BTCUSDT", "LUNAUSDT"]}`,
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().CreateMany(gomock.Any(), "BTCUSDT", "LUNAUSDT").Times(1).Return([]model.CreateCurrencyResDTO{
+					{Symbol: "BTCUSDT", Status: model.CreateCurrencyStatusCreated},
+					{Symbol: "LUNAUSDT", Status: model.CreateCurrencyStatusInvalid, Error: "invalid symbol"},
+				})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.JSONEq(t, `[{"symbol": "BTCUSDT", "status": "created"},
+					{"symbol": "LUNAUSDT", "status": "invalid", "error": "invalid symbol"}]`, recorder.Body.String())
+			},
+		},
+		{
+			name: "empty list",
+			body: `{"symbols": []}`,
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().CreateMany(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "lower case symbol",
+			body: `{"symbols": ["BTCUSDT", "ethusdt"]}`,
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().CreateMany(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tc {
+		t.Run(test.name, func(t *testing.T) {
+			test.buildStubs(currencyService)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/currencies", bytes.NewBufferString(test.body))
+			rec := httptest.NewRecorder()
+
+			router := server.setupRouter()
+			router.ServeHTTP(rec, req)
+
+			test.checkResponse(t, rec)
+		})
+	}
+}
+
 func TestListCurrency(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -148,3 +241,241 @@ func TestListCurrency(t *testing.T) {
 	}
 
 }
+
+func TestListBackfills(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyService := mock_service.NewMockCurrency(ctrl)
+	service := service.Manager{Currency: currencyService}
+
+	server := Server{
+		service: &service,
+		logger:  slog.Default(),
+	}
+
+	tc := []struct {
+		name          string
+		buildStubs    func(service *mock_service.MockCurrency)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().ListBackfills(gomock.Any(), gomock.Eq("BTCUSDT")).Times(1).Return([]model.KlineBackfill{{Symbol: "BTCUSDT", Interval: "1h"}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "not found",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().ListBackfills(gomock.Any(), gomock.Eq("BTCUSDT")).Times(1).Return(nil, model.ErrNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "internal server error",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().ListBackfills(gomock.Any(), gomock.Any()).Times(1).Return(nil, fmt.Errorf("unexpected"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tc {
+		t.Run(test.name, func(t *testing.T) {
+			test.buildStubs(currencyService)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/currency/BTCUSDT/backfill", nil)
+			rec := httptest.NewRecorder()
+
+			router := server.setupRouter()
+			router.ServeHTTP(rec, req)
+
+			test.checkResponse(t, rec)
+		})
+	}
+}
+
+func TestUpdateCurrency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyService := mock_service.NewMockCurrency(ctrl)
+	service := service.Manager{Currency: currencyService}
+
+	server := Server{
+		service: &service,
+		logger:  slog.Default(),
+	}
+
+	tc := []struct {
+		name          string
+		body          string
+		buildStubs    func(service *mock_service.MockCurrency)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: `{"active": false}`,
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().SetActive(gomock.Any(), gomock.Eq("BTCUSDT"), gomock.Eq(false)).Times(1).
+					Return(model.Currency{
+						ID: 1, Symbol: "BTCUSDT", Active: false,
+						BaseAsset: "BTC", QuoteAsset: "USDT", Status: model.SymbolStatusTrading,
+						TickSize: decimal.RequireFromString("0.01"), StepSize: decimal.RequireFromString("0.00001"),
+					}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.JSONEq(t, `{"id": 1, "symbol": "BTCUSDT", "active": false, "base_asset": "BTC", "quote_asset": "USDT",
+					"status": "TRADING", "tick_size": "0.01", "step_size": "0.00001"}`, recorder.Body.String())
+			},
+		},
+		{
+			name: "missing field",
+			body: `{}`,
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().SetActive(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "not found",
+			body: `{"active": true}`,
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().SetActive(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(model.Currency{}, model.ErrNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "internal server error",
+			body: `{"active": true}`,
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().SetActive(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(model.Currency{}, fmt.Errorf("unexpected"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tc {
+		t.Run(test.name, func(t *testing.T) {
+			test.buildStubs(currencyService)
+
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/currency/BTCUSDT", bytes.NewBufferString(test.body))
+			rec := httptest.NewRecorder()
+
+			router := server.setupRouter()
+			router.ServeHTTP(rec, req)
+
+			test.checkResponse(t, rec)
+		})
+	}
+}
+
+func TestDeleteCurrency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyService := mock_service.NewMockCurrency(ctrl)
+	service := service.Manager{Currency: currencyService}
+
+	server := Server{
+		service: &service,
+		logger:  slog.Default(),
+	}
+
+	tc := []struct {
+		name          string
+		query         string
+		buildStubs    func(service *mock_service.MockCurrency)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().Delete(gomock.Any(), gomock.Eq("BTCUSDT"), gomock.Eq(false)).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNoContent, recorder.Code)
+			},
+		},
+		{
+			name:  "OK purge",
+			query: "?purge=true",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().Delete(gomock.Any(), gomock.Eq("BTCUSDT"), gomock.Eq(true)).Times(1).Return(nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name:  "invalid purge",
+			query: "?purge=yes",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().Delete(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "has prices",
+			query: "",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().Delete(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(model.ErrHasPrices)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+		{
+			name:  "not found",
+			query: "",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().Delete(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(model.ErrNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:  "internal server error",
+			query: "",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().Delete(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(fmt.Errorf("unexpected"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tc {
+		t.Run(test.name, func(t *testing.T) {
+			test.buildStubs(currencyService)
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/v1/currency/BTCUSDT"+test.query, nil)
+			rec := httptest.NewRecorder()
+
+			router := server.setupRouter()
+			router.ServeHTTP(rec, req)
+
+			test.checkResponse(t, rec)
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetBinanceWeight godoc
+//
+//	@Summary		Binance request weight
+//	@Description	Request weight spent on Binance within the current minute and the limit of the client.
+//	@Description	Calls over the limit wait for the next minute or are rejected with 429.
+//	@Tags			monitoring
+//	@Produce		json
+//	@Success		200	{object}	model.BinanceWeightDTO
+//	@Router			/binance/weight [get]
+func (s *Server) GetBinanceWeight(c *gin.Context) {
+	c.JSON(http.StatusOK, s.service.Currency.GetBinanceWeight())
+}
@@ -0,0 +1,38 @@
+package http
+
+import (
+	"gexabyte/internal/model"
+	"gexabyte/internal/service"
+	mock_service "gexabyte/internal/service/mock"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetBinanceWeight(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyService := mock_service.NewMockCurrency(ctrl)
+	service := service.Manager{Currency: currencyService}
+
+	server := Server{
+		service: &service,
+		logger:  slog.Default(),
+	}
+
+	currencyService.EXPECT().GetBinanceWeight().Times(1).Return(model.BinanceWeightDTO{Used: 120, Limit: 5000, ResetTime: 1710496860000})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/binance/weight", nil)
+	rec := httptest.NewRecorder()
+
+	router := server.setupRouter()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"used": 120, "limit": 5000, "reset_time": 1710496860000}`, rec.Body.String())
+}
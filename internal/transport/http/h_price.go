@@ -3,33 +3,128 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"gexabyte/internal/model"
+	"gexabyte/pkg/clients/marketdata"
 	"net/http"
+	"slices"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	defaultPricesLimit = 100
+	maxPricesLimit     = 1000
+)
+
 // ListPrices godoc
 //
 //	@Summary		List currency prices
-//	@Description	Retrieves a list of current currency prices.
+//	@Description	Retrieves stored prices ordered by time. Pass `next_cursor` of the response as `cursor` to get the next page, the last page has no `next_cursor`.
+//	@Description	With `convert`, like `EUR`, prices of pairs quoted in USD or its stablecoins are converted by stored fx rates closest to their times.
 //	@Tags			prices
 //	@Produce		json
-//	@Success		200	{array}		[]model.CurrencyPrice	"A list of current currency prices"
-//	@Failure		500	{object}	ErrMsg					"Internal server error"
+//	@Param			symbols	query		string							false	"symbols, all tracked by default"	example(["BTCUSDT", "ETHUSDT"])
+//	@Param			from	query		int64							false	"Start time in Unix timestamp milliseconds, inclusive"
+//	@Param			to		query		int64							false	"End time in Unix timestamp milliseconds, inclusive"
+//	@Param			order	query		string							false	"Sort order by time"	Enums(asc, desc)	default(asc)
+//	@Param			limit	query		int								false	"Page size"				minimum(1)			maximum(1000)	default(100)
+//	@Param			cursor	query		string							false	"Cursor of the next page"
+//	@Param			convert	query		string							false	"Fiat currency to convert prices to"	example(EUR)
+//	@Success		200		{object}	model.ListCurrencyPricesDTORes	"A page of stored prices"
+//	@Failure		400		{object}	ErrMsg							"Invalid request parameters"
+//	@Failure		500		{object}	ErrMsg							"Internal server error"
 //	@Router			/prices [get]
 func (s *Server) ListPrices(c *gin.Context) {
+	filter := model.ListCurrencyPricesFilter{
+		Order: c.DefaultQuery("order", model.OrderAsc),
+		Limit: defaultPricesLimit,
+	}
+
+	if symbolsParam := c.Query("symbols"); len(symbolsParam) > 0 {
+		if err := json.Unmarshal([]byte(symbolsParam), &filter.Symbols); err != nil {
+			c.JSON(http.StatusBadRequest, ErrMsg{Err: "invalid symbols format"})
+			return
+		}
+		normalizeSymbols(filter.Symbols)
+	}
+
+	var err error
+	if from := c.Query("from"); from != "" {
+		if filter.StartTime, err = strconv.ParseInt(from, 10, 64); err != nil || filter.StartTime < 0 {
+			c.JSON(http.StatusBadRequest, ErrMsg{Err: "incorrect from"})
+			return
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		if filter.EndTime, err = strconv.ParseInt(to, 10, 64); err != nil || filter.EndTime < 0 {
+			c.JSON(http.StatusBadRequest, ErrMsg{Err: "incorrect to"})
+			return
+		}
+	}
+	if filter.StartTime > 0 && filter.EndTime > 0 && filter.EndTime < filter.StartTime {
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: "incorrect time - from is later than to"})
+		return
+	}
+
+	if filter.Order != model.OrderAsc && filter.Order != model.OrderDesc {
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: "incorrect order"})
+		return
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil || filter.Limit <= 0 || filter.Limit > maxPricesLimit {
+			c.JSON(http.StatusBadRequest, ErrMsg{Err: "incorrect limit"})
+			return
+		}
+	}
+
+	if cursor := c.Query("cursor"); cursor != "" {
+		after, err := model.DecodePriceCursor(cursor)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrMsg{Err: err.Error()})
+			return
+		}
+		filter.After = &after
+	}
+
+	currency, ok := fxCurrency(c, filter.Symbols...)
+	if !ok {
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(c.Copy(), 5*time.Second)
 	defer cancel()
 
-	res, err := s.service.Currency.ListPrices(ctx)
+	res, err := s.service.Currency.ListPrices(ctx, filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrMsg{err.Error()})
+		c.JSON(http.StatusInternalServerError, ErrMsg{Err: err.Error()})
 		return
 	}
 
+	if currency != "" {
+		symbols := make([]string, 0, len(res.Prices))
+		times := make([]int64, 0, len(res.Prices))
+		for _, price := range res.Prices {
+			symbols = append(symbols, price.Symbol)
+			times = append(times, price.Time)
+		}
+		if _, ok := fxCurrency(c, symbols...); !ok {
+			return
+		}
+
+		startTime, endTime := timeRange(times...)
+		rates, ok := s.fxRates(c, ctx, currency, startTime, endTime)
+		if !ok {
+			return
+		}
+		for i := range res.Prices {
+			res.Prices[i].ConvertFx(rates)
+		}
+	}
+
 	c.JSON(http.StatusOK, res)
 }
 
@@ -37,23 +132,37 @@ func (s *Server) ListPrices(c *gin.Context) {
 //
 //	@Summary		Get purrent prices of symbols
 //	@Description	Retrieves current prices fof symbols and save it in db.
+//	@Description	With CONSENSUS_METHOD set the price is agreed on by all market providers, `Sources` lists the used ones and `Rejected` the failed ones and outliers.
+//	@Description	Pairs the exchange does not list, like `SOLBTC`, are made of tracked pairs, `Legs` lists them with their prices and times.
+//	@Description	With `convert`, like `EUR`, prices of pairs quoted in USD or its stablecoins are converted by stored fx rates closest to their times.
 //	@Tags			prices
 //	@Produce		json
-//	@Param			symbols	query		string	true	"symbols"	example(["BTCUSDT", "ETHUSDT"])
+//	@Param			symbols	query		string	true	"symbols"								example(["BTCUSDT", "ETHUSDT"])
+//	@Param			convert	query		string	false	"Fiat currency to convert prices to"	example(EUR)
 //	@Success		200		{object}	[]model.GetCurrencyPriceDTO
 //	@Failure		400		{object}	ErrMsg	"Invalid request parameters"
+//	@Failure		404		{object}	ErrMsg	"Symbol is not listed on the provider, code invalid_symbol"
+//	@Failure		429		{object}	ErrMsg	"Provider rate limit or ban, code rate_limited or ip_banned, Retry-After is set when known"
+//	@Failure		502		{object}	ErrMsg	"Provider failed, code upstream_unavailable, or too few providers agree, code no_consensus"
+//	@Failure		503		{object}	ErrMsg	"Provider keeps failing and is not called, code upstream_circuit_open"
+//	@Failure		504		{object}	ErrMsg	"Provider timed out, code upstream_timeout"
 //	@Failure		500		{object}	ErrMsg	"Internal server error"
 //	@Router			/prices/current [get]
 func (s *Server) ListPricesCurrent(c *gin.Context) {
 	symbolsParam := c.Query("symbols")
 	if len(symbolsParam) == 0 {
-		c.JSON(http.StatusBadRequest, ErrMsg{"symbols param is required"})
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: "symbols param is required"})
 		return
 	}
 
 	var symbols []string
 	if err := json.Unmarshal([]byte(symbolsParam), &symbols); err != nil {
-		c.JSON(http.StatusBadRequest, ErrMsg{"invalid symbols format"})
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: "invalid symbols format"})
+		return
+	}
+
+	currency, ok := fxCurrency(c, normalizeSymbols(symbols)...)
+	if !ok {
 		return
 	}
 
@@ -62,10 +171,29 @@ func (s *Server) ListPricesCurrent(c *gin.Context) {
 
 	stats, err := s.service.Currency.GetCurrentPrices(ctx, symbols...)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrMsg{err.Error()})
+		if writeUpstreamError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrMsg{Err: err.Error()})
 		return
 	}
 
+	if currency != "" {
+		times := make([]int64, 0, len(stats))
+		for _, price := range stats {
+			times = append(times, price.Time)
+		}
+
+		startTime, endTime := timeRange(times...)
+		rates, ok := s.fxRates(c, ctx, currency, startTime, endTime)
+		if !ok {
+			return
+		}
+		for i := range stats {
+			stats[i].ConvertFx(rates)
+		}
+	}
+
 	c.JSON(http.StatusOK, stats)
 }
 
@@ -73,64 +201,77 @@ func (s *Server) ListPricesCurrent(c *gin.Context) {
 //
 //	@Summary		List historical currency prices
 //	@Description	Retrieves historical prices for a currency based on the specified parameters. Requires `symbol`, `interval`, `startTime`, `endTime`, `page`, and `limit` query parameters.
+//	@Description	Candles of pairs the exchange does not list are made of candles of tracked pairs listed in `legs`, their high and low are bounds.
+//	@Description	With `convert`, like `EUR`, prices of pairs quoted in USD or its stablecoins are converted by stored fx rates closest to their times.
 //	@Tags			prices
 //	@Produce		json
 //	@Param			symbol		query		string										true	"Currency symbol"
 //	@Param			interval	query		string										true	"Interval"	Enums(1s, 1m, 3m, 5m, 15m, 30m, 1h, 2h, 4h, 6h, 8h, 12h, 1d, 1w, 1M)
 //	@Param			startTime	query		int64										true	"Start time in Unix timestamp milliseconds"
 //	@Param			endTime		query		int64										true	"End time in Unix timestamp milliseconds"
-//	@Param			page		query		int											true	"Page number"		minimum(1)
-//	@Param			limit		query		int											true	"Max limit is 1000"	minimum(1)	maximum(1000)
+//	@Param			page		query		int											true	"Page number"							minimum(1)
+//	@Param			limit		query		int											true	"Max limit is 1000"						minimum(1)	maximum(1000)
+//	@Param			convert		query		string										false	"Fiat currency to convert prices to"	example(EUR)
 //	@Success		200			{object}	[]model.GetCurrencyPriceHistoricalDTORes	"Successful response with historical price data"
 //	@Failure		400			{object}	ErrMsg										"Invalid request parameters"
+//	@Failure		404			{object}	ErrMsg										"Symbol is not listed on the provider, code invalid_symbol"
+//	@Failure		429			{object}	ErrMsg										"Provider rate limit or ban, code rate_limited or ip_banned, Retry-After is set when known"
+//	@Failure		502			{object}	ErrMsg										"Provider failed, code upstream_unavailable"
+//	@Failure		503			{object}	ErrMsg										"Provider keeps failing and is not called, code upstream_circuit_open"
+//	@Failure		504			{object}	ErrMsg										"Provider timed out, code upstream_timeout"
 //	@Failure		500			{object}	ErrMsg										"Internal server error"
 //	@Router			/prices/historical [get]
 func (s *Server) ListPricesHistorical(c *gin.Context) {
 	var req model.GetCurrencyPriceHistoricalDTOReq
 
-	req.Symbol = c.Query("symbol")
+	req.Symbol = marketdata.NormalizeSymbol(c.Query("symbol"))
 	req.Interval = c.Query("interval")
 
 	sT, err := strconv.Atoi(c.Query("startTime"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrMsg{err.Error()})
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: err.Error()})
 		return
 	}
 	req.StartTime = int64(sT)
 
 	eT, err := strconv.Atoi(c.Query("endTime"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrMsg{err.Error()})
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: err.Error()})
 		return
 	}
 	req.EndTime = int64(eT)
 
 	if time.UnixMilli(req.EndTime).Before(time.UnixMilli(req.StartTime)) {
-		c.JSON(http.StatusBadRequest, ErrMsg{"incorrect time - startTime is later than endTime"})
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: "incorrect time - startTime is later than endTime"})
 		return
 	}
 
 	req.Page, err = strconv.Atoi(c.Query("page"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrMsg{err.Error()})
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: err.Error()})
 		return
 	}
 
 	req.Limit, err = strconv.Atoi(c.Query("limit"))
 	if err != nil {
-		c.JSON(http.StatusBadRequest, ErrMsg{err.Error()})
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: err.Error()})
 		return
 	}
 
 	if req.Symbol == "" || req.Interval == "" ||
 		req.StartTime <= 0 || req.EndTime <= 0 ||
 		req.Limit <= 0 || req.Page <= 0 {
-		c.JSON(http.StatusBadRequest, ErrMsg{"all params are required"})
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: "all params are required"})
 		return
 	}
 
 	if !model.KlineInterval.IsCorrect(req.Interval) {
-		c.JSON(http.StatusBadRequest, ErrMsg{"incorrect interval format"})
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: "incorrect interval format"})
+		return
+	}
+
+	currency, ok := fxCurrency(c, req.Symbol)
+	if !ok {
 		return
 	}
 
@@ -139,9 +280,116 @@ func (s *Server) ListPricesHistorical(c *gin.Context) {
 
 	result, err := s.service.Currency.GetPriceHistorical(ctx, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrMsg{err.Error()})
+		if writeUpstreamError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrMsg{Err: err.Error()})
 		return
 	}
 
+	if currency != "" && result != nil {
+		times := make([]int64, 0, 2*len(result.Prices))
+		for _, candle := range result.Prices {
+			times = append(times, candle.OpenTime, candle.CloseTime)
+		}
+
+		startTime, endTime := timeRange(times...)
+		rates, ok := s.fxRates(c, ctx, currency, startTime, endTime)
+		if !ok {
+			return
+		}
+		for i := range result.Prices {
+			result.Prices[i].ConvertFx(rates)
+		}
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ListPricesRollups godoc
+//
+//	@Summary		List price rollups
+//	@Description	Retrieves hourly or daily summaries of stored prices of a tracked symbol: open, close, high, low and average price and number of prices. Buckets which contain `startTime` and `endTime` are included.
+//	@Description	With `convert`, like `EUR`, prices of pairs quoted in USD or its stablecoins are converted by stored fx rates closest to their times.
+//	@Tags			prices
+//	@Produce		json
+//	@Param			symbol		query		string							true	"Currency symbol"
+//	@Param			interval	query		string							true	"Interval"	Enums(1h, 1d)
+//	@Param			startTime	query		int64							true	"Start time in Unix timestamp milliseconds"
+//	@Param			endTime		query		int64							true	"End time in Unix timestamp milliseconds"
+//	@Param			convert		query		string							false	"Fiat currency to convert prices to"	example(EUR)
+//	@Success		200			{object}	model.GetCurrencyRollupsDTORes	"Summaries of stored prices"
+//	@Failure		400			{object}	ErrMsg							"Invalid request parameters"
+//	@Failure		404			{object}	ErrMsg							"Currency is not tracked"
+//	@Failure		500			{object}	ErrMsg							"Internal server error"
+//	@Router			/prices/rollups [get]
+func (s *Server) ListPricesRollups(c *gin.Context) {
+	var req model.GetCurrencyRollupsDTOReq
+
+	req.Symbol = marketdata.NormalizeSymbol(c.Query("symbol"))
+	req.Interval = c.Query("interval")
+
+	var err error
+	req.StartTime, err = strconv.ParseInt(c.Query("startTime"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: err.Error()})
+		return
+	}
+
+	req.EndTime, err = strconv.ParseInt(c.Query("endTime"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: err.Error()})
+		return
+	}
+
+	if req.Symbol == "" || req.StartTime <= 0 || req.EndTime <= 0 {
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: "all params are required"})
+		return
+	}
+
+	if req.EndTime < req.StartTime {
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: "incorrect time - startTime is later than endTime"})
+		return
+	}
+
+	if !slices.Contains(model.RollupIntervals, req.Interval) {
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: "incorrect interval format"})
+		return
+	}
+
+	currency, ok := fxCurrency(c, req.Symbol)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Copy(), 5*time.Second)
+	defer cancel()
+
+	result, err := s.service.Currency.GetRollups(ctx, req)
+	if err != nil {
+		if errors.Is(err, model.ErrNotFound) {
+			c.JSON(http.StatusNotFound, ErrMsg{Err: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrMsg{Err: err.Error()})
+		return
+	}
+
+	if currency != "" && result != nil {
+		times := make([]int64, 0, 2*len(result.Rollups))
+		for _, rollup := range result.Rollups {
+			times = append(times, rollup.OpenTime, rollup.CloseTime)
+		}
+
+		startTime, endTime := timeRange(times...)
+		rates, ok := s.fxRates(c, ctx, currency, startTime, endTime)
+		if !ok {
+			return
+		}
+		for i := range result.Rollups {
+			result.Rollups[i].ConvertFx(rates)
+		}
+	}
+
 	c.JSON(http.StatusOK, result)
 }
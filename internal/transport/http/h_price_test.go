@@ -5,6 +5,7 @@ import (
 	"gexabyte/internal/model"
 	"gexabyte/internal/service"
 	mock_service "gexabyte/internal/service/mock"
+	"gexabyte/pkg/clients/binance"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -28,24 +29,95 @@ func TestListPrices(t *testing.T) {
 		logger:  slog.Default(),
 	}
 
+	cursor := model.PriceCursor{Time: 4, ID: 2}
+
 	tc := []struct {
 		name          string
+		query         string
 		buildStubs    func(service *mock_service.MockCurrency)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
 		{
 			name: "OK",
 			buildStubs: func(service *mock_service.MockCurrency) {
-				service.EXPECT().ListPrices(gomock.Any()).Times(1).Return([]model.CurrencyPrice{}, nil)
+				service.EXPECT().ListPrices(gomock.Any(), model.ListCurrencyPricesFilter{Order: model.OrderAsc, Limit: defaultPricesLimit}).
+					Times(1).Return(model.ListCurrencyPricesDTORes{Prices: []model.CurrencyPriceDTO{}}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
+		{
+			name:  "all params",
+			query: `?symbols=["BTCUSDT"]&from=1&to=5&order=desc&limit=10&cursor=` + cursor.Encode(),
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().ListPrices(gomock.Any(), model.ListCurrencyPricesFilter{
+					Symbols:   []string{"BTCUSDT"},
+					StartTime: 1,
+					EndTime:   5,
+					Order:     model.OrderDesc,
+					Limit:     10,
+					After:     &cursor,
+				}).Times(1).Return(model.ListCurrencyPricesDTORes{Prices: []model.CurrencyPriceDTO{}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "invalid symbols",
+			query: "?symbols=BTCUSDT",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().ListPrices(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "from later than to",
+			query: "?from=5&to=1",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().ListPrices(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "invalid order",
+			query: "?order=random",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().ListPrices(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "limit above max",
+			query: "?limit=1001",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().ListPrices(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "invalid cursor",
+			query: "?cursor=!!!",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().ListPrices(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name: "internal server error",
 			buildStubs: func(service *mock_service.MockCurrency) {
-				service.EXPECT().ListPrices(gomock.Any()).Times(1).Return(nil, fmt.Errorf("unexpected"))
+				service.EXPECT().ListPrices(gomock.Any(), gomock.Any()).Times(1).Return(model.ListCurrencyPricesDTORes{}, fmt.Errorf("unexpected"))
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -57,7 +129,7 @@ func TestListPrices(t *testing.T) {
 		t.Run(test.name, func(t *testing.T) {
 			test.buildStubs(currencyService)
 
-			req := httptest.NewRequest(http.MethodGet, "/api/v1/prices", nil)
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/prices"+test.query, nil)
 			rec := httptest.NewRecorder()
 
 			router := server.setupRouter()
@@ -292,6 +364,85 @@ func TestListPricesCurrent(t *testing.T) {
 				assert.Equal(t, http.StatusBadRequest, recorder.Code)
 			},
 		},
+		{
+			name:  "no consensus",
+			query: "symbols",
+			value: `["BTCUSDT"]`,
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().GetCurrentPrices(gomock.Any(), gomock.Any()).Times(1).
+					Return(nil, fmt.Errorf("%w: BTCUSDT quoted within the band by 1 of 3 sources", model.ErrNoConsensus))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadGateway, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"code":"no_consensus"`)
+			},
+		},
+		{
+			name:  "unknown symbol",
+			query: "symbols",
+			value: `["BTCUSDX"]`,
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().GetCurrentPrices(gomock.Any(), gomock.Any()).Times(1).
+					Return(nil, &binance.Error{Kind: binance.ErrInvalidSymbol, Status: http.StatusBadRequest, Code: -1121, Message: "Invalid symbol."})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"code":"invalid_symbol"`)
+			},
+		},
+		{
+			name:  "rate limited",
+			query: "symbols",
+			value: `["BTCUSDT"]`,
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().GetCurrentPrices(gomock.Any(), gomock.Any()).Times(1).
+					Return(nil, fmt.Errorf("fetch: %w", &binance.Error{Kind: binance.ErrRateLimited, Status: http.StatusTooManyRequests, RetryAfter: 1500 * time.Millisecond}))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+				assert.Equal(t, "2", recorder.Header().Get("Retry-After"))
+				assert.Contains(t, recorder.Body.String(), `"code":"rate_limited"`)
+			},
+		},
+		{
+			name:  "upstream unavailable",
+			query: "symbols",
+			value: `["BTCUSDT"]`,
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().GetCurrentPrices(gomock.Any(), gomock.Any()).Times(1).
+					Return(nil, &binance.Error{Kind: binance.ErrUnavailable, Status: http.StatusServiceUnavailable})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadGateway, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"code":"upstream_unavailable"`)
+			},
+		},
+		{
+			name:  "circuit open",
+			query: "symbols",
+			value: `["BTCUSDT"]`,
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().GetCurrentPrices(gomock.Any(), gomock.Any()).Times(1).
+					Return(nil, &binance.Error{Kind: binance.ErrCircuitOpen, RetryAfter: 30 * time.Second})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+				assert.Equal(t, "30", recorder.Header().Get("Retry-After"))
+				assert.Contains(t, recorder.Body.String(), `"code":"upstream_circuit_open"`)
+			},
+		},
+		{
+			name:  "upstream timeout",
+			query: "symbols",
+			value: `["BTCUSDT"]`,
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().GetCurrentPrices(gomock.Any(), gomock.Any()).Times(1).Return(nil, binance.ErrTimeout)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusGatewayTimeout, recorder.Code)
+				assert.Contains(t, recorder.Body.String(), `"code":"upstream_timeout"`)
+			},
+		},
 		{
 			name:  "internal server error",
 			query: "symbols",
@@ -326,3 +477,99 @@ func TestListPricesCurrent(t *testing.T) {
 		})
 	}
 }
+
+func TestListPricesRollups(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyService := mock_service.NewMockCurrency(ctrl)
+	service := service.Manager{Currency: currencyService}
+
+	server := Server{
+		service: &service,
+		logger:  slog.Default(),
+	}
+
+	tc := []struct {
+		name          string
+		query         string
+		buildStubs    func(service *mock_service.MockCurrency)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "?symbol=BTCUSDT&interval=1h&startTime=1&endTime=2",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().GetRollups(gomock.Any(), model.GetCurrencyRollupsDTOReq{Symbol: "BTCUSDT", Interval: "1h", StartTime: 1, EndTime: 2}).
+					Times(1).Return(&model.GetCurrencyRollupsDTORes{Symbol: "BTCUSDT", Interval: "1h", Rollups: []model.CurrencyRollup{}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "missing symbol",
+			query: "?interval=1h&startTime=1&endTime=2",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().GetRollups(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "unsupported interval",
+			query: "?symbol=BTCUSDT&interval=1m&startTime=1&endTime=2",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().GetRollups(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "start later than end",
+			query: "?symbol=BTCUSDT&interval=1h&startTime=2&endTime=1",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().GetRollups(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "not tracked",
+			query: "?symbol=BTCUSDT&interval=1d&startTime=1&endTime=2",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().GetRollups(gomock.Any(), gomock.Any()).Times(1).Return(nil, model.ErrNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:  "internal server error",
+			query: "?symbol=BTCUSDT&interval=1d&startTime=1&endTime=2",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().GetRollups(gomock.Any(), gomock.Any()).Times(1).Return(nil, fmt.Errorf("unexpected"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tc {
+		t.Run(test.name, func(t *testing.T) {
+			test.buildStubs(currencyService)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/prices/rollups"+test.query, nil)
+			rec := httptest.NewRecorder()
+
+			router := server.setupRouter()
+			router.ServeHTTP(rec, req)
+
+			test.checkResponse(t, rec)
+		})
+	}
+}
@@ -3,7 +3,11 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"gexabyte/internal/model"
+	"gexabyte/pkg/clients/marketdata"
 	"net/http"
+	"slices"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -13,34 +17,79 @@ import (
 //
 //	@Summary		Get 24h statistics
 //	@Description	Retrieves 24-hour statistics for the specified symbols.
+//	@Description	With `source=local` (default) the summary is aggregated from stored prices of tracked symbols, with a name of a configured provider it is taken from that venue.
+//	@Description	Symbols of any venue, like `XBT/USDT` or `BTC-USDT`, are normalized to `BTCUSDT`.
+//	@Description	Summaries of pairs the exchange does not list are made of tracked pairs listed in `legs`.
+//	@Description	With `convert`, like `EUR`, prices of pairs quoted in USD or its stablecoins are converted by stored fx rates closest to their times.
 //	@Tags			stat
 //	@Produce		json
-//	@Param			symbols	query		string	true	"symbols"	example(["BTCUSDT", "ETHUSDT"])
+//	@Param			symbols	query		string	true	"symbols"								example(["BTCUSDT", "ETHUSDT"])
+//	@Param			source	query		string	false	"Source of the summary"					Enums(local, binance, kraken, coinbase, bybit)	default(local)
+//	@Param			convert	query		string	false	"Fiat currency to convert prices to"	example(EUR)
 //	@Success		200		{object}	[]model.GetCurrencyStat24HDTO
-//	@Failure		400		{object}	ErrMsg	"Invalid request parameters"
+//	@Failure		400		{object}	ErrMsg	"Invalid request parameters or the source is not configured"
+//	@Failure		404		{object}	ErrMsg	"Symbol is not listed on the provider, code invalid_symbol"
+//	@Failure		429		{object}	ErrMsg	"Provider rate limit or ban, code rate_limited or ip_banned, Retry-After is set when known"
+//	@Failure		502		{object}	ErrMsg	"Provider failed, code upstream_unavailable"
+//	@Failure		503		{object}	ErrMsg	"Provider keeps failing and is not called, code upstream_circuit_open"
+//	@Failure		504		{object}	ErrMsg	"Provider timed out, code upstream_timeout"
 //	@Failure		500		{object}	ErrMsg	"Internal server error"
 //	@Router			/stat/24h [get]
 func (s *Server) GetStat24H(c *gin.Context) {
 	symbolsParam := c.Query("symbols")
 	if len(symbolsParam) == 0 {
-		c.JSON(http.StatusBadRequest, ErrMsg{"symbols param is required"})
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: "symbols param is required"})
 		return
 	}
 
 	var symbols []string
 	if err := json.Unmarshal([]byte(symbolsParam), &symbols); err != nil {
-		c.JSON(http.StatusBadRequest, ErrMsg{"invalid symbols format"})
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: "invalid symbols format"})
+		return
+	}
+
+	source := c.DefaultQuery("source", model.StatSourceLocal)
+	if source != model.StatSourceLocal && !slices.Contains(marketdata.ProviderNames, source) {
+		c.JSON(http.StatusBadRequest, ErrMsg{Err: "incorrect source"})
+		return
+	}
+
+	currency, ok := fxCurrency(c, normalizeSymbols(symbols)...)
+	if !ok {
 		return
 	}
 
 	ctx, cancel := context.WithTimeout(c.Copy(), 5*time.Second)
 	defer cancel()
 
-	stats, err := s.service.Currency.GetStat24H(ctx, symbols...)
+	stats, err := s.service.Currency.GetStat24H(ctx, source, symbols...)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrMsg{err.Error()})
+		if errors.Is(err, model.ErrUnknownSource) {
+			c.JSON(http.StatusBadRequest, ErrMsg{Err: err.Error()})
+			return
+		}
+		if writeUpstreamError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrMsg{Err: err.Error()})
 		return
 	}
 
+	if currency != "" {
+		times := make([]int64, 0, 2*len(stats))
+		for _, stat := range stats {
+			times = append(times, stat.OpenTime, stat.CloseTime)
+		}
+
+		startTime, endTime := timeRange(times...)
+		rates, ok := s.fxRates(c, ctx, currency, startTime, endTime)
+		if !ok {
+			return
+		}
+		for i := range stats {
+			stats[i].ConvertFx(rates)
+		}
+	}
+
 	c.JSON(http.StatusOK, stats)
 }
@@ -5,6 +5,7 @@ import (
 	"gexabyte/internal/model"
 	"gexabyte/internal/service"
 	mock_service "gexabyte/internal/service/mock"
+	"gexabyte/pkg/clients/marketdata"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
@@ -30,6 +31,7 @@ func TestGetStat24H(t *testing.T) {
 		name          string
 		query         string
 		value         string
+		source        string
 		buildStubs    func(service *mock_service.MockCurrency)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
@@ -38,7 +40,7 @@ func TestGetStat24H(t *testing.T) {
 			query: "symbols",
 			value: `["BTCUSDT"]`,
 			buildStubs: func(service *mock_service.MockCurrency) {
-				service.EXPECT().GetStat24H(gomock.Any(), gomock.Eq([]string{"BTCUSDT"})).Times(1).Return([]model.GetCurrencyStat24HDTO{}, nil)
+				service.EXPECT().GetStat24H(gomock.Any(), gomock.Eq(model.StatSourceLocal), gomock.Eq([]string{"BTCUSDT"})).Times(1).Return([]model.GetCurrencyStat24HDTO{}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusOK, recorder.Code)
@@ -49,18 +51,66 @@ func TestGetStat24H(t *testing.T) {
 			query: "symbols",
 			value: `["BTCUSDT", "ETHUSDT"]`,
 			buildStubs: func(service *mock_service.MockCurrency) {
-				service.EXPECT().GetStat24H(gomock.Any(), gomock.Eq([]string{"BTCUSDT", "ETHUSDT"})).Times(1).Return([]model.GetCurrencyStat24HDTO{}, nil)
+				service.EXPECT().GetStat24H(gomock.Any(), gomock.Eq(model.StatSourceLocal), gomock.Eq([]string{"BTCUSDT", "ETHUSDT"})).Times(1).Return([]model.GetCurrencyStat24HDTO{}, nil)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
+		{
+			name:   "OK binance source",
+			query:  "symbols",
+			value:  `["BTCUSDT"]`,
+			source: model.StatSourceBinance,
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().GetStat24H(gomock.Any(), gomock.Eq(model.StatSourceBinance), gomock.Eq([]string{"BTCUSDT"})).Times(1).Return([]model.GetCurrencyStat24HDTO{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "OK kraken source with normalized symbols",
+			query:  "symbols",
+			value:  `["btc/usdt", "XBT-USD"]`,
+			source: marketdata.ProviderKraken,
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().GetStat24H(gomock.Any(), gomock.Eq(marketdata.ProviderKraken), gomock.Eq([]string{"BTCUSDT", "BTCUSD"})).Times(1).Return([]model.GetCurrencyStat24HDTO{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "bad request source is not configured",
+			query:  "symbols",
+			value:  `["BTCUSDT"]`,
+			source: marketdata.ProviderBybit,
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().GetStat24H(gomock.Any(), gomock.Eq(marketdata.ProviderBybit), gomock.Any()).Times(1).Return(nil, fmt.Errorf("%w: bybit", model.ErrUnknownSource))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "bad request incorrect source",
+			query:  "symbols",
+			value:  `["BTCUSDT"]`,
+			source: "unknown",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().GetStat24H(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
 		{
 			name:  "bad request symbols param is required",
 			query: "",
 			value: `["BTCUSDT"]`,
 			buildStubs: func(service *mock_service.MockCurrency) {
-				service.EXPECT().GetStat24H(gomock.Any(), gomock.Any()).Times(0)
+				service.EXPECT().GetStat24H(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -71,7 +121,7 @@ func TestGetStat24H(t *testing.T) {
 			query: "symbols",
 			value: `BTCUSDT"]`,
 			buildStubs: func(service *mock_service.MockCurrency) {
-				service.EXPECT().GetStat24H(gomock.Any(), gomock.Any()).Times(0)
+				service.EXPECT().GetStat24H(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusBadRequest, recorder.Code)
@@ -82,7 +132,7 @@ func TestGetStat24H(t *testing.T) {
 			query: "symbols",
 			value: `["BTCUSDT"]`,
 			buildStubs: func(service *mock_service.MockCurrency) {
-				service.EXPECT().GetStat24H(gomock.Any(), gomock.Any()).Times(1).Return(nil, fmt.Errorf("unexpected"))
+				service.EXPECT().GetStat24H(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil, fmt.Errorf("unexpected"))
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -96,11 +146,14 @@ func TestGetStat24H(t *testing.T) {
 
 			req := httptest.NewRequest(http.MethodGet, "/api/v1/stat/24h", nil)
 
+			q := req.URL.Query()
 			if test.query != "" {
-				q := req.URL.Query()
 				q.Add(test.query, test.value)
-				req.URL.RawQuery = q.Encode()
 			}
+			if test.source != "" {
+				q.Add("source", test.source)
+			}
+			req.URL.RawQuery = q.Encode()
 
 			rec := httptest.NewRecorder()
 
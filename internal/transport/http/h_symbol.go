@@ -0,0 +1,50 @@
+package http
+
+import (
+	"context"
+	"gexabyte/internal/model"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListSymbols godoc
+//
+//	@Summary		List symbols
+//	@Description	Retrieves pairs listed on the primary provider from the cached exchangeInfo, marking the tracked ones.
+//	@Tags			symbol
+//	@Produce		json
+//	@Param			base_asset	query		string			false	"Base asset, e.g. BTC"
+//	@Param			quote_asset	query		string			false	"Quote asset, e.g. USDT"
+//	@Param			status		query		string			false	"Exchange status, e.g. TRADING or BREAK"
+//	@Param			q			query		string			false	"Part of the symbol"
+//	@Success		200			{array}		model.SymbolDTO	"Listed pairs ordered by symbol"
+//	@Failure		429			{object}	ErrMsg			"Provider rate limit or ban, code rate_limited or ip_banned, Retry-After is set when known"
+//	@Failure		502			{object}	ErrMsg			"Provider failed, code upstream_unavailable"
+//	@Failure		503			{object}	ErrMsg			"Provider keeps failing and is not called, code upstream_circuit_open"
+//	@Failure		504			{object}	ErrMsg			"Provider timed out, code upstream_timeout"
+//	@Failure		500			{object}	ErrMsg			"Internal server error"
+//	@Router			/symbols [get]
+func (s *Server) ListSymbols(c *gin.Context) {
+	filter := model.ListSymbolsFilter{
+		BaseAsset:  c.Query("base_asset"),
+		QuoteAsset: c.Query("quote_asset"),
+		Status:     c.Query("status"),
+		Search:     c.Query("q"),
+	}
+
+	ctx, cancel := context.WithTimeout(c.Copy(), 10*time.Second)
+	defer cancel()
+
+	res, err := s.service.Currency.ListSymbols(ctx, filter)
+	if err != nil {
+		if writeUpstreamError(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrMsg{Err: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,86 @@
+package http
+
+import (
+	"fmt"
+	"gexabyte/internal/model"
+	"gexabyte/internal/service"
+	mock_service "gexabyte/internal/service/mock"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListSymbols(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	currencyService := mock_service.NewMockCurrency(ctrl)
+	service := service.Manager{Currency: currencyService}
+
+	server := Server{
+		service: &service,
+		logger:  slog.Default(),
+	}
+
+	tc := []struct {
+		name          string
+		query         string
+		buildStubs    func(service *mock_service.MockCurrency)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			query: "?quote_asset=USDT&base_asset=BTC&status=TRADING&q=BTC",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				filter := model.ListSymbolsFilter{BaseAsset: "BTC", QuoteAsset: "USDT", Status: "TRADING", Search: "BTC"}
+				service.EXPECT().ListSymbols(gomock.Any(), filter).Times(1).Return([]model.SymbolDTO{{
+					Symbol: "BTCUSDT", BaseAsset: "BTC", QuoteAsset: "USDT", Status: "TRADING",
+					TickSize: decimal.RequireFromString("0.01"), StepSize: decimal.RequireFromString("0.00001"), Tracked: true,
+				}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.JSONEq(t, `[{"symbol": "BTCUSDT", "base_asset": "BTC", "quote_asset": "USDT", "status": "TRADING",
+					"tick_size": "0.01", "step_size": "0.00001", "tracked": true}]`, recorder.Body.String())
+			},
+		},
+		{
+			name: "no filter",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().ListSymbols(gomock.Any(), model.ListSymbolsFilter{}).Times(1).Return([]model.SymbolDTO{}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusOK, recorder.Code)
+				assert.JSONEq(t, `[]`, recorder.Body.String())
+			},
+		},
+		{
+			name: "internal server error",
+			buildStubs: func(service *mock_service.MockCurrency) {
+				service.EXPECT().ListSymbols(gomock.Any(), gomock.Any()).Times(1).Return(nil, fmt.Errorf("unexpected"))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, test := range tc {
+		t.Run(test.name, func(t *testing.T) {
+			test.buildStubs(currencyService)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/symbols"+test.query, nil)
+			rec := httptest.NewRecorder()
+
+			router := server.setupRouter()
+			router.ServeHTTP(rec, req)
+
+			test.checkResponse(t, rec)
+		})
+	}
+}
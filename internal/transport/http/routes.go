@@ -19,14 +19,25 @@ func (s *Server) setupRouter() *gin.Engine {
 	api.GET("/ping", s.ping)
 
 	api.POST("/currency", s.CreateCurrency)
+	api.PATCH("/currency/:symbol", s.UpdateCurrency)
+	api.DELETE("/currency/:symbol", s.DeleteCurrency)
 	api.GET("/currencies", s.ListCurrencies)
+	api.POST("/currencies", s.CreateCurrencies)
+	api.GET("/currency/:symbol/backfill", s.ListBackfills)
+
+	api.GET("/symbols", s.ListSymbols)
 
 	api.GET("/prices", s.ListPrices)
 	api.GET("/prices/current", s.ListPricesCurrent)
 	api.GET("/prices/historical", s.ListPricesHistorical)
+	api.GET("/prices/rollups", s.ListPricesRollups)
 
 	api.GET("/stat/24h", s.GetStat24H)
 
+	api.GET("/convert", s.Convert)
+
+	api.GET("/binance/weight", s.GetBinanceWeight)
+
 	docs.SwaggerInfo.BasePath = "/api/v1"
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerfiles.Handler))
 	return r
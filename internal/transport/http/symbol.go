@@ -0,0 +1,12 @@
+package http
+
+import "gexabyte/pkg/clients/marketdata"
+
+// normalizeSymbols turns symbols of any venue, like XBT/USDT or BTC-USDT, into canonical ones in place.
+func normalizeSymbols(symbols []string) []string {
+	for i, symbol := range symbols {
+		symbols[i] = marketdata.NormalizeSymbol(symbol)
+	}
+
+	return symbols
+}
@@ -2,24 +2,54 @@ package binance
 
 import (
 	"context"
+	"net/http"
+	"strings"
 
 	binance_connector "github.com/binance/binance-connector-go"
 )
 
+// Client calls the exchange. Failed calls return *Error, whose kind is checked with errors.Is, e.g. ErrRateLimited.
 type Client interface {
 	KlineService(ctx context.Context, symbol, interval string, startTime, endTime int64, limit int) ([]*binance_connector.KlinesResponse, error)
 	TickerPriceService(ctx context.Context, symbol string) (*binance_connector.TickerPriceResponse, error)
 	Ticker24hService(ctx context.Context, symbol string) (*binance_connector.Ticker24hrResponse, error)
+	// ExchangeInfoService returns trading rules of all symbols of the exchange.
+	ExchangeInfoService(ctx context.Context) (*binance_connector.ExchangeInfoResponse, error)
+	// WeightUsage returns request weight spent within the current minute.
+	WeightUsage() WeightUsage
+}
+
+// MarketStream keeps a websocket subscription to prices of symbols.
+type MarketStream interface {
+	// SetSymbols replaces subscribed symbols, the running connection is resubscribed at once.
+	SetSymbols(symbols ...string)
+	// Run connects and passes ticks to handle until ctx ends, then it returns ctx.Err().
+	// The connection is restored after failures, which are reported to onError.
+	Run(ctx context.Context, handle func(Tick), onError func(error)) error
+	// Connected tells whether the stream is connected right now.
+	Connected() bool
 }
 
 type client struct {
 	binance *binance_connector.Client
+	limiter *weightLimiter
 }
 
 func New(cfg *Config) Client {
-	c := binance_connector.NewClient(cfg.ApiKey, cfg.SecretKey)
+	limiter := newWeightLimiter(cfg.WeightLimit)
+
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
 
-	return &client{c}
+	c := binance_connector.NewClient(cfg.ApiKey, cfg.SecretKey, strings.TrimSuffix(baseURL, "/"))
+	c.HTTPClient = &http.Client{
+		Transport: &statusTransport{next: http.DefaultTransport, limiter: limiter},
+		Timeout:   cfg.Timeout,
+	}
+
+	return &client{binance: c, limiter: limiter}
 }
 
 func (c *client) KlineService(ctx context.Context, symbol, interval string, startTime, endTime int64, limit int) ([]*binance_connector.KlinesResponse, error) {
@@ -30,22 +60,28 @@ func (c *client) KlineService(ctx context.Context, symbol, interval string, star
 		EndTime(uint64(endTime)).
 		Limit(int(limit)).
 		Do(ctx)
-	if err != nil {
-		return nil, err
-	}
 
-	// TODO: add error handler
-
-	return res, err
+	return res, wrapError(err)
 }
 
 func (c *client) TickerPriceService(ctx context.Context, symbol string) (*binance_connector.TickerPriceResponse, error) {
 	res, err := c.binance.NewTickerPriceService().Symbol(symbol).Do(ctx)
 
-	return res, err
+	return res, wrapError(err)
 }
+
 func (c *client) Ticker24hService(ctx context.Context, symbol string) (*binance_connector.Ticker24hrResponse, error) {
 	res, err := c.binance.NewTicker24hrService().Symbol(symbol).Do(ctx)
 
-	return res, err
+	return res, wrapError(err)
+}
+
+func (c *client) ExchangeInfoService(ctx context.Context) (*binance_connector.ExchangeInfoResponse, error) {
+	res, err := c.binance.NewExchangeInfoService().Do(ctx)
+
+	return res, wrapError(err)
+}
+
+func (c *client) WeightUsage() WeightUsage {
+	return c.limiter.usage()
 }
@@ -1,6 +1,17 @@
 package binance
 
+import "time"
+
+// DefaultBaseURL is the production api of the exchange.
+const DefaultBaseURL = "https://api.binance.com"
+
 type Config struct {
+	// BaseURL of the api, DefaultBaseURL when empty. It points the client to the testnet or a local stub.
+	BaseURL   string
 	ApiKey    string
 	SecretKey string
+	// Timeout limits one http call, no limit when zero.
+	Timeout time.Duration
+	// WeightLimit is request weight allowed per minute, DefaultWeightLimit when zero.
+	WeightLimit int
 }
@@ -0,0 +1,167 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gexabyte/pkg/clients/marketdata"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/binance/binance-connector-go/handlers"
+)
+
+// Kinds of failed calls, an *Error matches one of them with errors.Is.
+// They are the kinds of marketdata, so failures of all venues are handled alike.
+var (
+	ErrInvalidSymbol   = marketdata.ErrInvalidSymbol
+	ErrInvalidInterval = marketdata.ErrInvalidInterval
+	ErrBadRequest      = marketdata.ErrBadRequest
+	ErrRateLimited     = marketdata.ErrRateLimited
+	ErrIPBanned        = marketdata.ErrIPBanned
+	ErrTimeout         = marketdata.ErrTimeout
+	ErrUnavailable     = marketdata.ErrUnavailable
+	// ErrCircuitOpen is returned without calling the exchange while the endpoint keeps failing.
+	ErrCircuitOpen = marketdata.ErrCircuitOpen
+)
+
+// Error codes of the exchange, https://developers.binance.com/docs/binance-spot-api-docs/errors
+const (
+	codeTooManyRequests = -1003
+	codeBadInterval     = -1120
+	codeBadSymbol       = -1121
+)
+
+// Error is a failed call of the exchange.
+type Error struct {
+	Kind error
+	// Status is the http status of the response, zero when no response was received.
+	Status int
+	// Code and Message are taken from the response body when it has them.
+	Code    int64
+	Message string
+	// RetryAfter is how long the exchange asks to wait, zero when it does not tell.
+	RetryAfter time.Duration
+
+	err error
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.err != nil:
+		return fmt.Sprintf("binance: %s: %s", e.Kind, e.err)
+	case e.Message != "":
+		return fmt.Sprintf("binance: %s: code=%d, status=%d, msg=%s", e.Kind, e.Code, e.Status, e.Message)
+	}
+
+	return fmt.Sprintf("binance: %s: status=%d", e.Kind, e.Status)
+}
+
+// RetryAfterDuration lets marketdata.RetryAfter read RetryAfter.
+func (e *Error) RetryAfterDuration() time.Duration {
+	return e.RetryAfter
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// wrapError turns errors of the connector into *Error. Cancellation by the caller is returned as is.
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var binanceErr *Error
+	if errors.As(err, &binanceErr) {
+		return binanceErr
+	}
+
+	var apiErr *handlers.APIError
+	if errors.As(err, &apiErr) {
+		res := &Error{Kind: ErrBadRequest, Status: http.StatusBadRequest, Code: apiErr.Code, Message: apiErr.Message}
+		switch {
+		case apiErr.Code == codeBadSymbol:
+			res.Kind = ErrInvalidSymbol
+		case apiErr.Code == codeBadInterval:
+			res.Kind = ErrInvalidInterval
+		case apiErr.Code == codeTooManyRequests:
+			res.Kind = ErrRateLimited
+		case apiErr.Code > -1100: // codes from -1000 to -1099 are server or network issues
+			res.Kind = ErrUnavailable
+		}
+		return res
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return &Error{Kind: ErrTimeout, err: err}
+	}
+
+	return &Error{Kind: ErrUnavailable, err: err}
+}
+
+// statusTransport fails responses of rate limits, bans and server errors before the connector reads them,
+// because the connector keeps only the body and loses the status and headers.
+// Calls are let through only while their weight fits into the limit of the minute.
+type statusTransport struct {
+	next    http.RoundTripper
+	limiter *weightLimiter
+}
+
+func (t *statusTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.wait(req.Context(), requestWeight(req)); err != nil {
+		return nil, err
+	}
+
+	res, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	t.limiter.observe(res)
+
+	var kind error
+	switch {
+	case res.StatusCode == http.StatusTooManyRequests:
+		kind = ErrRateLimited
+	case res.StatusCode == http.StatusTeapot:
+		kind = ErrIPBanned
+	case res.StatusCode >= http.StatusInternalServerError:
+		kind = ErrUnavailable
+	default:
+		return res, nil
+	}
+	defer res.Body.Close()
+
+	binanceErr := &Error{Kind: kind, Status: res.StatusCode, RetryAfter: retryAfter(res.Header)}
+
+	// bodies of server errors are not always json
+	var apiErr handlers.APIError
+	if body, err := io.ReadAll(io.LimitReader(res.Body, 4096)); err == nil && json.Unmarshal(body, &apiErr) == nil {
+		binanceErr.Code, binanceErr.Message = apiErr.Code, apiErr.Message
+	}
+
+	return nil, binanceErr
+}
+
+// retryAfter parses the Retry-After header in seconds.
+func retryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,145 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	binance_connector "github.com/binance/binance-connector-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *client {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	limiter := newWeightLimiter(DefaultWeightLimit)
+
+	c := binance_connector.NewClient("", "", srv.URL)
+	c.HTTPClient = &http.Client{Transport: &statusTransport{next: http.DefaultTransport, limiter: limiter}}
+
+	return &client{binance: c, limiter: limiter}
+}
+
+func TestErrors(t *testing.T) {
+	tc := []struct {
+		name    string
+		status  int
+		header  map[string]string
+		body    string
+		kind    error
+		code    int64
+		retryIn time.Duration
+	}{
+		{
+			name:   "invalid symbol",
+			status: http.StatusBadRequest,
+			body:   `{"code": -1121, "msg": "Invalid symbol."}`,
+			kind:   ErrInvalidSymbol,
+			code:   -1121,
+		},
+		{
+			name:   "invalid interval",
+			status: http.StatusBadRequest,
+			body:   `{"code": -1120, "msg": "Invalid interval."}`,
+			kind:   ErrInvalidInterval,
+			code:   -1120,
+		},
+		{
+			name:   "bad request",
+			status: http.StatusBadRequest,
+			body:   `{"code": -1100, "msg": "Illegal characters found in parameter 'symbol'."}`,
+			kind:   ErrBadRequest,
+			code:   -1100,
+		},
+		{
+			name:   "server issue",
+			status: http.StatusBadRequest,
+			body:   `{"code": -1001, "msg": "Internal error; unable to process your request."}`,
+			kind:   ErrUnavailable,
+			code:   -1001,
+		},
+		{
+			name:    "rate limited",
+			status:  http.StatusTooManyRequests,
+			header:  map[string]string{"Retry-After": "7"},
+			body:    `{"code": -1003, "msg": "Too much request weight used."}`,
+			kind:    ErrRateLimited,
+			code:    -1003,
+			retryIn: 7 * time.Second,
+		},
+		{
+			name:    "ip banned",
+			status:  http.StatusTeapot,
+			header:  map[string]string{"Retry-After": "120"},
+			body:    `{"code": -1003, "msg": "Way too much request weight used; IP banned."}`,
+			kind:    ErrIPBanned,
+			code:    -1003,
+			retryIn: 2 * time.Minute,
+		},
+		{
+			name:   "server error without json",
+			status: http.StatusBadGateway,
+			body:   `<html>bad gateway</html>`,
+			kind:   ErrUnavailable,
+		},
+	}
+
+	for _, test := range tc {
+		t.Run(test.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range test.header {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(test.status)
+				_, _ = w.Write([]byte(test.body))
+			})
+
+			_, err := c.TickerPriceService(context.Background(), "BTCUSDT")
+			require.ErrorIs(t, err, test.kind)
+
+			var binanceErr *Error
+			require.True(t, errors.As(err, &binanceErr))
+			assert.Equal(t, test.code, binanceErr.Code)
+			assert.Equal(t, test.retryIn, binanceErr.RetryAfter)
+		})
+	}
+
+	t.Run("timeout", func(t *testing.T) {
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			<-r.Context().Done()
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := c.TickerPriceService(ctx, "BTCUSDT")
+		assert.ErrorIs(t, err, ErrTimeout)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := c.TickerPriceService(ctx, "BTCUSDT")
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.NotErrorIs(t, err, ErrUnavailable)
+	})
+
+	t.Run("ok", func(t *testing.T) {
+		c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"symbol": "BTCUSDT", "price": "65000.01"}`))
+		})
+
+		res, err := c.TickerPriceService(context.Background(), "BTCUSDT")
+		require.NoError(t, err)
+		assert.Equal(t, "65000.01", res.Price)
+	})
+}
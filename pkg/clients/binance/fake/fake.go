@@ -0,0 +1,474 @@
+// Package fake serves market data endpoints of Binance from scripted or random walk prices,
+// so the service and its tests run without network access.
+package fake
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultVolatility = 0.001
+	defaultTickSize   = "0.01"
+	defaultStepSize   = "0.00001"
+
+	defaultStreamInterval = time.Second
+
+	klinesDefaultLimit = 500
+	klinesMaxLimit     = 1000
+)
+
+// Error codes of the exchange returned by the fake.
+const (
+	codeBadInterval = -1120
+	codeBadSymbol   = -1121
+	codeBadParam    = -1102
+)
+
+// intervals are candle intervals of the exchange, months are not supported.
+var intervals = map[string]time.Duration{
+	"1s":  time.Second,
+	"1m":  time.Minute,
+	"3m":  3 * time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"2h":  2 * time.Hour,
+	"4h":  4 * time.Hour,
+	"6h":  6 * time.Hour,
+	"8h":  8 * time.Hour,
+	"12h": 12 * time.Hour,
+	"1d":  24 * time.Hour,
+	"3d":  3 * 24 * time.Hour,
+	"1w":  7 * 24 * time.Hour,
+}
+
+// Symbol is a pair listed on the fake exchange.
+type Symbol struct {
+	Symbol     string
+	BaseAsset  string
+	QuoteAsset string
+	// Status is TRADING when empty.
+	Status string
+	// TickSize and StepSize are 0.01 and 0.00001 when empty.
+	TickSize string
+	StepSize string
+	// Price is where the random walk starts.
+	Price decimal.Decimal
+}
+
+// DefaultSymbols are pairs tracked by the service by default, with prices close to real ones.
+func DefaultSymbols() []Symbol {
+	return []Symbol{
+		{Symbol: "BTCUSDT", BaseAsset: "BTC", QuoteAsset: "USDT", Price: decimal.NewFromInt(65000)},
+		{Symbol: "ETHUSDT", BaseAsset: "ETH", QuoteAsset: "USDT", Price: decimal.NewFromInt(3500)},
+		{Symbol: "SOLUSDT", BaseAsset: "SOL", QuoteAsset: "USDT", Price: decimal.NewFromInt(150)},
+		{Symbol: "TRXUSDT", BaseAsset: "TRX", QuoteAsset: "USDT", TickSize: "0.0001", StepSize: "0.1", Price: decimal.RequireFromString("0.12")},
+	}
+}
+
+type Config struct {
+	// Symbols listed on the exchange, DefaultSymbols when empty.
+	Symbols []Symbol
+	// Seed makes random walks repeatable.
+	Seed uint64
+	// Volatility is the largest relative move of a price per step, 0.001 when zero.
+	Volatility float64
+	// StreamInterval is how often market streams push prices, a second when zero.
+	StreamInterval time.Duration
+}
+
+type symbolState struct {
+	info   Symbol
+	tick   decimal.Decimal
+	price  decimal.Decimal
+	open   decimal.Decimal // price of the first step, the 24h change is counted from it
+	high   decimal.Decimal
+	low    decimal.Decimal
+	script []decimal.Decimal
+}
+
+type failure struct {
+	status int
+	body   string
+	times  int
+}
+
+// Exchange is the http handler of the fake exchange.
+// Every call of ticker/price and every push of a market stream moves the price of the symbol one step:
+// to the next scripted price if any, otherwise by a random walk.
+type Exchange struct {
+	volatility     float64
+	seed           uint64
+	streamInterval time.Duration
+	now            func() time.Time
+
+	mu       sync.Mutex
+	rand     *rand.Rand
+	symbols  map[string]*symbolState
+	failures map[string][]failure
+	calls    map[string]int
+	conns    map[*streamConn]struct{}
+}
+
+func New(cfg Config) *Exchange {
+	symbols := cfg.Symbols
+	if len(symbols) == 0 {
+		symbols = DefaultSymbols()
+	}
+	volatility := cfg.Volatility
+	if volatility <= 0 {
+		volatility = defaultVolatility
+	}
+	streamInterval := cfg.StreamInterval
+	if streamInterval <= 0 {
+		streamInterval = defaultStreamInterval
+	}
+
+	e := &Exchange{
+		volatility:     volatility,
+		seed:           cfg.Seed,
+		streamInterval: streamInterval,
+		now:            time.Now,
+		rand:           rand.New(rand.NewPCG(cfg.Seed, cfg.Seed)),
+		symbols:        make(map[string]*symbolState, len(symbols)),
+		failures:       make(map[string][]failure),
+		calls:          make(map[string]int),
+		conns:          make(map[*streamConn]struct{}),
+	}
+	for _, symbol := range symbols {
+		e.AddSymbol(symbol)
+	}
+
+	return e
+}
+
+// AddSymbol lists the pair or replaces the listed one.
+func (e *Exchange) AddSymbol(symbol Symbol) {
+	if symbol.Status == "" {
+		symbol.Status = "TRADING"
+	}
+	if symbol.TickSize == "" {
+		symbol.TickSize = defaultTickSize
+	}
+	if symbol.StepSize == "" {
+		symbol.StepSize = defaultStepSize
+	}
+
+	tick, err := decimal.NewFromString(symbol.TickSize)
+	if err != nil || !tick.IsPositive() {
+		tick = decimal.RequireFromString(defaultTickSize)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	price := symbol.Price.Div(tick).Round(0).Mul(tick)
+	e.symbols[symbol.Symbol] = &symbolState{info: symbol, tick: tick, price: price, open: price, high: price, low: price}
+}
+
+// Script queues prices returned by next calls of ticker/price, the random walk goes on from the last one.
+func (e *Exchange) Script(symbol string, prices ...decimal.Decimal) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if s, ok := e.symbols[symbol]; ok {
+		s.script = append(s.script, prices...)
+	}
+}
+
+// Fail makes the next times calls of the path, e.g. /api/v3/ticker/price, fail with the status and body.
+// Failures of a path are used in the order they were added.
+func (e *Exchange) Fail(path string, status int, body string, times int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.failures[path] = append(e.failures[path], failure{status: status, body: body, times: times})
+}
+
+// Calls returns how many times the path was called, failed calls included.
+func (e *Exchange) Calls(path string) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.calls[path]
+}
+
+// Price returns the last price of the symbol.
+func (e *Exchange) Price(symbol string) (decimal.Decimal, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	s, ok := e.symbols[symbol]
+	if !ok {
+		return decimal.Zero, false
+	}
+
+	return s.price, true
+}
+
+func (e *Exchange) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, codeBadParam, "Only GET is supported.")
+		return
+	}
+	if r.URL.Path == "/stream" { // long lived, served without the lock
+		e.stream(w, r)
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.calls[r.URL.Path]++
+	if f, ok := e.nextFailure(r.URL.Path); ok {
+		w.WriteHeader(f.status)
+		w.Write([]byte(f.body))
+		return
+	}
+
+	switch r.URL.Path {
+	case "/api/v3/ticker/price":
+		e.tickerPrice(w, r)
+	case "/api/v3/ticker/24hr":
+		e.ticker24h(w, r)
+	case "/api/v3/klines":
+		e.klines(w, r)
+	case "/api/v3/exchangeInfo":
+		e.exchangeInfo(w)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// nextFailure takes one failure of the path, the caller must hold mu.
+func (e *Exchange) nextFailure(path string) (failure, bool) {
+	failures := e.failures[path]
+	if len(failures) == 0 {
+		return failure{}, false
+	}
+
+	f := failures[0]
+	if failures[0].times--; failures[0].times <= 0 {
+		e.failures[path] = failures[1:]
+	}
+
+	return f, true
+}
+
+func (e *Exchange) tickerPrice(w http.ResponseWriter, r *http.Request) {
+	s, ok := e.symbol(w, r)
+	if !ok {
+		return
+	}
+
+	e.step(s)
+
+	writeJSON(w, map[string]string{"symbol": s.info.Symbol, "price": s.price.String()})
+}
+
+func (e *Exchange) ticker24h(w http.ResponseWriter, r *http.Request) {
+	s, ok := e.symbol(w, r)
+	if !ok {
+		return
+	}
+
+	now := e.now()
+	change := s.price.Sub(s.open)
+	changePercent := decimal.Zero
+	if s.open.IsPositive() {
+		changePercent = change.Div(s.open).Mul(decimal.NewFromInt(100)).Round(3)
+	}
+
+	writeJSON(w, map[string]any{
+		"symbol":             s.info.Symbol,
+		"priceChange":        change.String(),
+		"priceChangePercent": changePercent.String(),
+		"weightedAvgPrice":   s.high.Add(s.low).Div(decimal.NewFromInt(2)).String(),
+		"prevClosePrice":     s.open.String(),
+		"lastPrice":          s.price.String(),
+		"lastQty":            "1",
+		"bidPrice":           s.price.Sub(s.tick).String(),
+		"askPrice":           s.price.Add(s.tick).String(),
+		"openPrice":          s.open.String(),
+		"highPrice":          s.high.String(),
+		"lowPrice":           s.low.String(),
+		"volume":             "1000",
+		"quoteVolume":        s.price.Mul(decimal.NewFromInt(1000)).String(),
+		"openTime":           now.Add(-24 * time.Hour).UnixMilli(),
+		"closeTime":          now.UnixMilli(),
+		"firstId":            1,
+		"lastId":             1000,
+		"count":              1000,
+	})
+}
+
+// klines generates candles around the current price. A candle depends only on the seed,
+// the symbol and its open time, so repeated calls return the same history.
+func (e *Exchange) klines(w http.ResponseWriter, r *http.Request) {
+	s, ok := e.symbol(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	interval, ok := intervals[query.Get("interval")]
+	if !ok {
+		writeError(w, http.StatusBadRequest, codeBadInterval, "Invalid interval.")
+		return
+	}
+
+	limit := klinesDefaultLimit
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > klinesMaxLimit {
+			writeError(w, http.StatusBadRequest, codeBadParam, "Illegal value for parameter 'limit'.")
+			return
+		}
+		limit = n
+	}
+
+	step := interval.Milliseconds()
+	startTime, _ := strconv.ParseInt(query.Get("startTime"), 10, 64)
+	endTime, _ := strconv.ParseInt(query.Get("endTime"), 10, 64)
+	if endTime <= 0 {
+		endTime = e.now().UnixMilli()
+	}
+	if startTime <= 0 {
+		startTime = endTime - int64(limit-1)*step
+	}
+	// candles are aligned to the interval and open not earlier than startTime, like on the exchange
+	openTime := (startTime + step - 1) / step * step
+
+	res := [][]any{}
+	for ; openTime <= endTime && len(res) < limit; openTime += step {
+		res = append(res, e.kline(s, openTime, step))
+	}
+
+	writeJSON(w, res)
+}
+
+func (e *Exchange) kline(s *symbolState, openTime, step int64) []any {
+	h := fnv.New64a()
+	h.Write([]byte(s.info.Symbol))
+	rnd := rand.New(rand.NewPCG(e.seed^h.Sum64(), uint64(openTime)))
+
+	move := func() decimal.Decimal {
+		return decimal.NewFromFloat(1 + (rnd.Float64()*2-1)*e.volatility*10)
+	}
+	round := func(price decimal.Decimal) decimal.Decimal {
+		return price.Div(s.tick).Round(0).Mul(s.tick)
+	}
+
+	open := round(s.price.Mul(move()))
+	closePrice := round(s.price.Mul(move()))
+	high := round(decimal.Max(open, closePrice).Mul(decimal.NewFromFloat(1 + rnd.Float64()*e.volatility)))
+	low := round(decimal.Min(open, closePrice).Mul(decimal.NewFromFloat(1 - rnd.Float64()*e.volatility)))
+
+	return []any{
+		openTime, open.String(), high.String(), low.String(), closePrice.String(), "100",
+		openTime + step - 1, closePrice.Mul(decimal.NewFromInt(100)).String(), 100, "50", closePrice.Mul(decimal.NewFromInt(50)).String(), "0",
+	}
+}
+
+func (e *Exchange) exchangeInfo(w http.ResponseWriter) {
+	names := make([]string, 0, len(e.symbols))
+	for name := range e.symbols {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	symbols := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		info := e.symbols[name].info
+		symbols = append(symbols, map[string]any{
+			"symbol":     info.Symbol,
+			"status":     info.Status,
+			"baseAsset":  info.BaseAsset,
+			"quoteAsset": info.QuoteAsset,
+			"filters": []map[string]string{
+				{"filterType": "PRICE_FILTER", "tickSize": info.TickSize},
+				{"filterType": "LOT_SIZE", "stepSize": info.StepSize},
+			},
+		})
+	}
+
+	writeJSON(w, map[string]any{
+		"timezone":   "UTC",
+		"serverTime": e.now().UnixMilli(),
+		"symbols":    symbols,
+	})
+}
+
+// symbol finds the symbol of the request or writes the error of the exchange, the caller must hold mu.
+func (e *Exchange) symbol(w http.ResponseWriter, r *http.Request) (*symbolState, bool) {
+	name := r.URL.Query().Get("symbol")
+	if name == "" {
+		writeError(w, http.StatusBadRequest, codeBadParam, "Mandatory parameter 'symbol' was not sent, was empty/null, or malformed.")
+		return nil, false
+	}
+
+	s, ok := e.symbols[name]
+	if !ok {
+		writeError(w, http.StatusBadRequest, codeBadSymbol, "Invalid symbol.")
+		return nil, false
+	}
+
+	return s, true
+}
+
+// step moves the price to the next scripted one or by the random walk, the caller must hold mu.
+func (e *Exchange) step(s *symbolState) {
+	if len(s.script) > 0 {
+		s.price, s.script = s.script[0], s.script[1:]
+	} else {
+		move := decimal.NewFromFloat(1 + (e.rand.Float64()*2-1)*e.volatility)
+		if price := s.price.Mul(move).Div(s.tick).Round(0).Mul(s.tick); price.IsPositive() {
+			s.price = price
+		}
+	}
+
+	s.high = decimal.Max(s.high, s.price)
+	s.low = decimal.Min(s.low, s.price)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"code":%d,"msg":%q}`, code, msg)
+}
+
+// Server is the fake exchange listening on a local port, its URL is the base url of the client.
+type Server struct {
+	*httptest.Server
+	*Exchange
+}
+
+// NewServer starts the fake exchange, the caller must close it.
+func NewServer(cfg Config) *Server {
+	exchange := New(cfg)
+
+	return &Server{Server: httptest.NewServer(exchange), Exchange: exchange}
+}
+
+// Close drops connections of market streams, which the http server does not track, and stops the server.
+func (s *Server) Close() {
+	s.DropStreams()
+	s.Server.Close()
+}
@@ -0,0 +1,173 @@
+package fake_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"gexabyte/pkg/clients/binance"
+	"gexabyte/pkg/clients/binance/fake"
+	"gexabyte/pkg/clients/marketdata"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newClient(t *testing.T, cfg fake.Config) (*fake.Server, binance.Client) {
+	srv := fake.NewServer(cfg)
+	t.Cleanup(srv.Close)
+
+	return srv, binance.New(&binance.Config{BaseURL: srv.URL, Timeout: time.Second})
+}
+
+func TestTickerPrice(t *testing.T) {
+	srv, client := newClient(t, fake.Config{Seed: 1})
+	ctx := context.Background()
+
+	srv.Script("BTCUSDT", decimal.RequireFromString("64000.5"), decimal.RequireFromString("64001"))
+
+	res, err := client.TickerPriceService(ctx, "BTCUSDT")
+	require.NoError(t, err)
+	assert.Equal(t, "64000.5", res.Price)
+
+	res, err = client.TickerPriceService(ctx, "BTCUSDT")
+	require.NoError(t, err)
+	assert.Equal(t, "64001", res.Price)
+
+	// the random walk goes on from the last scripted price
+	res, err = client.TickerPriceService(ctx, "BTCUSDT")
+	require.NoError(t, err)
+	price := decimal.RequireFromString(res.Price)
+	assert.True(t, price.Sub(decimal.NewFromInt(64001)).Abs().LessThanOrEqual(decimal.NewFromInt(65)), price.String())
+	assert.True(t, price.Mod(decimal.RequireFromString("0.01")).IsZero(), price.String())
+
+	_, err = client.TickerPriceService(ctx, "BTCUSDX")
+	assert.ErrorIs(t, err, binance.ErrInvalidSymbol)
+
+	assert.Equal(t, 4, srv.Calls("/api/v3/ticker/price"))
+}
+
+func TestTicker24h(t *testing.T) {
+	srv, client := newClient(t, fake.Config{})
+
+	srv.Script("ETHUSDT", decimal.NewFromInt(3600), decimal.NewFromInt(3400), decimal.NewFromInt(3550))
+	for i := 0; i < 3; i++ {
+		_, err := client.TickerPriceService(context.Background(), "ETHUSDT")
+		require.NoError(t, err)
+	}
+
+	res, err := client.Ticker24hService(context.Background(), "ETHUSDT")
+	require.NoError(t, err)
+	assert.Equal(t, "3500", res.OpenPrice)
+	assert.Equal(t, "3600", res.HighPrice)
+	assert.Equal(t, "3400", res.LowPrice)
+	assert.Equal(t, "3550", res.LastPrice)
+	assert.Equal(t, "50", res.PriceChange)
+}
+
+func TestKlines(t *testing.T) {
+	_, client := newClient(t, fake.Config{Seed: 7})
+	ctx := context.Background()
+
+	start := time.Date(2024, time.March, 15, 10, 0, 30, 0, time.UTC)
+	end := start.Add(10 * time.Minute)
+
+	res, err := client.KlineService(ctx, "SOLUSDT", "1m", start.UnixMilli(), end.UnixMilli(), 5)
+	require.NoError(t, err)
+	require.Len(t, res, 5)
+	assert.Equal(t, uint64(start.Truncate(time.Minute).Add(time.Minute).UnixMilli()), res[0].OpenTime)
+	for i, kline := range res {
+		assert.Equal(t, res[0].OpenTime+uint64(i)*60000, kline.OpenTime)
+		assert.Equal(t, kline.OpenTime+59999, kline.CloseTime)
+
+		high, low := decimal.RequireFromString(kline.High), decimal.RequireFromString(kline.Low)
+		for _, price := range []string{kline.Open, kline.Close} {
+			assert.True(t, decimal.RequireFromString(price).LessThanOrEqual(high))
+			assert.True(t, decimal.RequireFromString(price).GreaterThanOrEqual(low))
+		}
+	}
+
+	// the history is the same on every call
+	again, err := client.KlineService(ctx, "SOLUSDT", "1m", start.UnixMilli(), end.UnixMilli(), 5)
+	require.NoError(t, err)
+	assert.Equal(t, res, again)
+
+	_, err = client.KlineService(ctx, "SOLUSDT", "2m", start.UnixMilli(), end.UnixMilli(), 5)
+	assert.ErrorIs(t, err, binance.ErrInvalidInterval)
+}
+
+func TestExchangeInfo(t *testing.T) {
+	srv, client := newClient(t, fake.Config{})
+	srv.AddSymbol(fake.Symbol{Symbol: "LUNAUSDT", BaseAsset: "LUNA", QuoteAsset: "USDT", Status: "BREAK", Price: decimal.NewFromInt(1)})
+
+	res, err := client.ExchangeInfoService(context.Background())
+	require.NoError(t, err)
+
+	symbols := make(map[string]string, len(res.Symbols))
+	for _, symbol := range res.Symbols {
+		symbols[symbol.Symbol] = symbol.Status
+	}
+	assert.Equal(t, map[string]string{
+		"BTCUSDT":  "TRADING",
+		"ETHUSDT":  "TRADING",
+		"LUNAUSDT": "BREAK",
+		"SOLUSDT":  "TRADING",
+		"TRXUSDT":  "TRADING",
+	}, symbols)
+}
+
+func TestFail(t *testing.T) {
+	srv, client := newClient(t, fake.Config{})
+
+	srv.Fail("/api/v3/ticker/price", http.StatusTooManyRequests, "", 1)
+	srv.Fail("/api/v3/ticker/price", http.StatusBadGateway, "", 1)
+
+	_, err := client.TickerPriceService(context.Background(), "BTCUSDT")
+	assert.ErrorIs(t, err, binance.ErrRateLimited)
+
+	// the client waits out the rate limit, so a fresh one is used
+	client = binance.New(&binance.Config{BaseURL: srv.URL})
+	_, err = client.TickerPriceService(context.Background(), "BTCUSDT")
+	assert.ErrorIs(t, err, binance.ErrUnavailable)
+
+	_, err = client.TickerPriceService(context.Background(), "BTCUSDT")
+	assert.NoError(t, err)
+}
+
+func TestProvider(t *testing.T) {
+	srv, client := newClient(t, fake.Config{})
+	provider := binance.NewProvider(client)
+	ctx := context.Background()
+
+	assert.Equal(t, marketdata.ProviderBinance, provider.Name())
+
+	srv.Script("TRXUSDT", decimal.RequireFromString("0.125"))
+	price, err := provider.Price(ctx, "TRXUSDT")
+	require.NoError(t, err)
+	assert.Equal(t, "0.125", price.Price.String())
+
+	stat, err := provider.Stat24h(ctx, "TRXUSDT")
+	require.NoError(t, err)
+	assert.Equal(t, "0.125", stat.LastPrice.String())
+
+	start := time.Date(2024, time.March, 15, 10, 0, 0, 0, time.UTC)
+	candles, err := provider.Candles(ctx, "TRXUSDT", "1h", start.UnixMilli(), start.Add(3*time.Hour).UnixMilli(), 2)
+	require.NoError(t, err)
+	require.Len(t, candles, 2)
+	assert.Equal(t, start.UnixMilli(), candles[0].OpenTime)
+	assert.Equal(t, start.Add(time.Hour).UnixMilli()-1, candles[0].CloseTime)
+
+	symbols, err := provider.Symbols(ctx)
+	require.NoError(t, err)
+	for _, symbol := range symbols {
+		if symbol.Symbol == "TRXUSDT" {
+			assert.Equal(t, "0.0001", symbol.TickSize.String())
+			assert.Equal(t, "0.1", symbol.StepSize.String())
+		}
+	}
+
+	_, err = provider.Price(ctx, "BTCUSDX")
+	assert.ErrorIs(t, err, marketdata.ErrInvalidSymbol)
+}
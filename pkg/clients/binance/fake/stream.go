@@ -0,0 +1,198 @@
+package fake
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/shopspring/decimal"
+)
+
+const streamWriteTimeout = 10 * time.Second
+
+var upgrader = websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+
+// streamConn is a connection of combined market streams, names of streams are like btcusdt@miniTicker.
+type streamConn struct {
+	conn *websocket.Conn
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	streams map[string]struct{}
+}
+
+// DropStreams closes all connections of market streams, like the exchange does on maintenance or after 24h.
+func (e *Exchange) DropStreams() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for c := range e.conns {
+		c.conn.Close()
+	}
+}
+
+// StreamConns returns how many connections of market streams are open.
+func (e *Exchange) StreamConns() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return len(e.conns)
+}
+
+// stream serves combined market streams, subscriptions are managed by SUBSCRIBE and UNSUBSCRIBE requests.
+func (e *Exchange) stream(w http.ResponseWriter, r *http.Request) {
+	e.mu.Lock()
+	e.calls[r.URL.Path]++
+	f, failed := e.nextFailure(r.URL.Path)
+	e.mu.Unlock()
+	if failed {
+		w.WriteHeader(f.status)
+		w.Write([]byte(f.body))
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	c := &streamConn{conn: conn, streams: make(map[string]struct{})}
+	e.mu.Lock()
+	e.conns[c] = struct{}{}
+	e.mu.Unlock()
+
+	defer func() {
+		e.mu.Lock()
+		delete(e.conns, c)
+		e.mu.Unlock()
+		conn.Close()
+	}()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		c.readRequests()
+	}()
+
+	ticker := time.NewTicker(e.streamInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			for _, event := range e.streamEvents(c.subscribed()) {
+				if err := c.write(event); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// streamEvents moves prices of the streams one step and returns their events.
+func (e *Exchange) streamEvents(streams []string) []any {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.now().UnixMilli()
+	events := make([]any, 0, len(streams))
+	for _, name := range streams {
+		symbol, kind, _ := strings.Cut(name, "@")
+		s, ok := e.symbols[strings.ToUpper(symbol)]
+		if !ok {
+			continue
+		}
+
+		e.step(s)
+
+		var data map[string]any
+		switch kind {
+		case "miniTicker":
+			data = map[string]any{
+				"e": "24hrMiniTicker", "E": now, "s": s.info.Symbol,
+				"c": s.price.String(), "o": s.open.String(), "h": s.high.String(), "l": s.low.String(),
+				"v": "1000", "q": s.price.Mul(decimal.NewFromInt(1000)).String(),
+			}
+		case "kline_1m":
+			openTime := now / time.Minute.Milliseconds() * time.Minute.Milliseconds()
+			data = map[string]any{
+				"e": "kline", "E": now, "s": s.info.Symbol,
+				"k": map[string]any{
+					"t": openTime, "T": openTime + time.Minute.Milliseconds() - 1, "s": s.info.Symbol, "i": "1m",
+					"o": s.price.String(), "c": s.price.String(), "h": s.price.String(), "l": s.price.String(),
+					"v": "1", "x": false,
+				},
+			}
+		default:
+			continue
+		}
+
+		events = append(events, map[string]any{"stream": name, "data": data})
+	}
+
+	return events
+}
+
+// readRequests applies subscription requests until the connection is closed.
+func (c *streamConn) readRequests() {
+	for {
+		var req struct {
+			Method string   `json:"method"`
+			Params []string `json:"params"`
+			ID     int64    `json:"id"`
+		}
+		if err := c.conn.ReadJSON(&req); err != nil {
+			return
+		}
+
+		c.mu.Lock()
+		for _, name := range req.Params {
+			switch req.Method {
+			case "SUBSCRIBE":
+				c.streams[name] = struct{}{}
+			case "UNSUBSCRIBE":
+				delete(c.streams, name)
+			}
+		}
+		c.mu.Unlock()
+
+		reply := map[string]any{"result": nil, "id": req.ID}
+		if req.Method != "SUBSCRIBE" && req.Method != "UNSUBSCRIBE" {
+			reply = map[string]any{"error": map[string]any{"code": 2, "msg": "Invalid request: unknown method"}, "id": req.ID}
+		}
+		if err := c.write(reply); err != nil {
+			return
+		}
+	}
+}
+
+func (c *streamConn) subscribed() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	res := make([]string, 0, len(c.streams))
+	for name := range c.streams {
+		res = append(res, name)
+	}
+
+	return res
+}
+
+func (c *streamConn) write(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
@@ -0,0 +1,156 @@
+package binance
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultWeightLimit leaves headroom under 6000 weight per minute allowed by the exchange for one ip.
+const DefaultWeightLimit = 5000
+
+// usedWeightHeader carries weight used by the ip within the current minute, counted by the exchange.
+const usedWeightHeader = "X-MBX-USED-WEIGHT-1M"
+
+// endpointWeights are request weights of endpoints called with one symbol.
+var endpointWeights = map[string]int{
+	"/api/v3/ticker/price": 2,
+	"/api/v3/ticker/24hr":  2,
+	"/api/v3/klines":       2,
+	"/api/v3/exchangeInfo": 20,
+}
+
+// WeightUsage is the request weight spent within the current minute.
+type WeightUsage struct {
+	Used  int
+	Limit int
+	// ResetAt is when the current minute ends and the weight is reset.
+	ResetAt time.Time
+	// BlockedUntil is set after the exchange asked to retry later, calls are rejected until then.
+	BlockedUntil time.Time
+}
+
+// weightLimiter counts weight of calls within minutes, the same windows as the exchange uses.
+// The local count is replaced by the used weight reported by the exchange whenever the latter is higher.
+type weightLimiter struct {
+	limit int
+	now   func() time.Time
+
+	mu           sync.Mutex
+	window       time.Time
+	used         int
+	blockedUntil time.Time
+	blockedKind  error // ErrRateLimited or ErrIPBanned
+}
+
+func newWeightLimiter(limit int) *weightLimiter {
+	if limit <= 0 {
+		limit = DefaultWeightLimit
+	}
+
+	return &weightLimiter{limit: limit, now: time.Now}
+}
+
+// wait reserves the weight. When the minute is spent the call waits for the next one,
+// unless ctx ends earlier, then it is rejected with ErrRateLimited at once.
+func (l *weightLimiter) wait(ctx context.Context, weight int) error {
+	for {
+		delay, err := l.reserve(weight)
+		if err != nil || delay == 0 {
+			return err
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return &Error{Kind: ErrRateLimited, Message: "request weight limit is reached", RetryAfter: delay}
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes the weight if it fits into the current minute, otherwise it returns how long to wait.
+func (l *weightLimiter) reserve(weight int) (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Before(l.blockedUntil) {
+		return 0, &Error{Kind: l.blockedKind, Message: "exchange asked to retry later", RetryAfter: l.blockedUntil.Sub(now)}
+	}
+
+	l.roll(now)
+	if l.used+weight > l.limit {
+		return l.window.Add(time.Minute).Sub(now), nil
+	}
+
+	l.used += weight
+	return 0, nil
+}
+
+// observe takes the used weight and the retry delay from the response of the exchange.
+func (l *weightLimiter) observe(res *http.Response) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.roll(now)
+
+	if used, err := strconv.Atoi(res.Header.Get(usedWeightHeader)); err == nil && used > l.used {
+		l.used = used
+	}
+
+	if res.StatusCode != http.StatusTooManyRequests && res.StatusCode != http.StatusTeapot {
+		return
+	}
+
+	// without Retry-After the weight is spent until the end of the minute
+	until := l.window.Add(time.Minute)
+	if delay := retryAfter(res.Header); delay > 0 {
+		until = now.Add(delay)
+	}
+	if until.After(l.blockedUntil) {
+		l.blockedUntil, l.blockedKind = until, ErrRateLimited
+		if res.StatusCode == http.StatusTeapot {
+			l.blockedKind = ErrIPBanned
+		}
+	}
+}
+
+func (l *weightLimiter) usage() WeightUsage {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.roll(now)
+
+	res := WeightUsage{Used: l.used, Limit: l.limit, ResetAt: l.window.Add(time.Minute)}
+	if now.Before(l.blockedUntil) {
+		res.BlockedUntil = l.blockedUntil
+	}
+
+	return res
+}
+
+// roll starts a new window when the minute is over, the caller must hold mu.
+func (l *weightLimiter) roll(now time.Time) {
+	if window := now.Truncate(time.Minute); window.After(l.window) {
+		l.window, l.used = window, 0
+	}
+}
+
+// requestWeight returns weight of the endpoint, unknown endpoints are counted as the cheapest ones.
+func requestWeight(req *http.Request) int {
+	if weight, ok := endpointWeights[req.URL.Path]; ok {
+		return weight
+	}
+
+	return 1
+}
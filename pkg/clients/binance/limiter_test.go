@@ -0,0 +1,104 @@
+package binance
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeightLimiter(t *testing.T) {
+	minute := time.Date(2024, time.March, 15, 10, 0, 0, 0, time.UTC)
+	now := minute.Add(30 * time.Second)
+
+	limiter := newWeightLimiter(10)
+	limiter.now = func() time.Time { return now }
+
+	response := func(status int, header map[string]string) *http.Response {
+		res := &http.Response{StatusCode: status, Header: http.Header{}}
+		for k, v := range header {
+			res.Header.Set(k, v)
+		}
+		return res
+	}
+
+	delay, err := limiter.reserve(6)
+	require.NoError(t, err)
+	assert.Zero(t, delay)
+
+	// the rest of the minute is not enough
+	delay, err = limiter.reserve(6)
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, delay)
+
+	// the exchange counts more than the client
+	limiter.observe(response(http.StatusOK, map[string]string{"X-Mbx-Used-Weight-1m": "9"}))
+	assert.Equal(t, WeightUsage{Used: 9, Limit: 10, ResetAt: minute.Add(time.Minute)}, limiter.usage())
+
+	// lower count of the exchange does not forget calls in flight
+	limiter.observe(response(http.StatusOK, map[string]string{"X-Mbx-Used-Weight-1m": "2"}))
+	assert.Equal(t, 9, limiter.usage().Used)
+
+	// the weight is reset with the next minute
+	now = minute.Add(time.Minute)
+	delay, err = limiter.reserve(6)
+	require.NoError(t, err)
+	assert.Zero(t, delay)
+
+	limiter.observe(response(http.StatusTooManyRequests, map[string]string{"Retry-After": "5"}))
+	_, err = limiter.reserve(1)
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Equal(t, now.Add(5*time.Second), limiter.usage().BlockedUntil)
+
+	now = now.Add(5 * time.Second)
+	_, err = limiter.reserve(1)
+	assert.NoError(t, err)
+
+	// ban without Retry-After lasts until the end of the minute
+	limiter.observe(response(http.StatusTeapot, nil))
+	_, err = limiter.reserve(1)
+	assert.ErrorIs(t, err, ErrIPBanned)
+	assert.Equal(t, minute.Add(2*time.Minute), limiter.usage().BlockedUntil)
+}
+
+func TestWeightLimiterWait(t *testing.T) {
+	start := time.Now()
+	// the minute ends in 50ms of the real time
+	clock := time.Date(2024, time.March, 15, 10, 0, 59, 950_000_000, time.UTC)
+
+	limiter := newWeightLimiter(10)
+	limiter.now = func() time.Time { return clock.Add(time.Since(start)) }
+
+	require.NoError(t, limiter.wait(context.Background(), 10))
+
+	// the call does not fit into the minute and ctx ends earlier
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := limiter.wait(ctx, 1)
+	assert.ErrorIs(t, err, ErrRateLimited)
+
+	// the call is queued until the next minute
+	require.NoError(t, limiter.wait(context.Background(), 1))
+	assert.Equal(t, 1, limiter.usage().Used)
+}
+
+func TestWeightUsage(t *testing.T) {
+	used := 0
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		used += 20
+		w.Header().Set("X-MBX-USED-WEIGHT-1M", strconv.Itoa(used))
+		_, _ = w.Write([]byte(`{"symbols": []}`))
+	})
+
+	_, err := c.ExchangeInfoService(context.Background())
+	require.NoError(t, err)
+
+	usage := c.WeightUsage()
+	assert.Equal(t, 20, usage.Used)
+	assert.Equal(t, DefaultWeightLimit, usage.Limit)
+	assert.True(t, usage.BlockedUntil.IsZero())
+}
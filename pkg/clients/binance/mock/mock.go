@@ -6,6 +6,7 @@ package mock_binance
 
 import (
 	context "context"
+	binance "gexabyte/pkg/clients/binance"
 	reflect "reflect"
 
 	binance_connector "github.com/binance/binance-connector-go"
@@ -35,6 +36,21 @@ func (m *MockClient) EXPECT() *MockClientMockRecorder {
 	return m.recorder
 }
 
+// ExchangeInfoService mocks base method.
+func (m *MockClient) ExchangeInfoService(ctx context.Context) (*binance_connector.ExchangeInfoResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExchangeInfoService", ctx)
+	ret0, _ := ret[0].(*binance_connector.ExchangeInfoResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExchangeInfoService indicates an expected call of ExchangeInfoService.
+func (mr *MockClientMockRecorder) ExchangeInfoService(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExchangeInfoService", reflect.TypeOf((*MockClient)(nil).ExchangeInfoService), ctx)
+}
+
 // KlineService mocks base method.
 func (m *MockClient) KlineService(ctx context.Context, symbol, interval string, startTime, endTime int64, limit int) ([]*binance_connector.KlinesResponse, error) {
 	m.ctrl.T.Helper()
@@ -79,3 +95,84 @@ func (mr *MockClientMockRecorder) TickerPriceService(ctx, symbol interface{}) *g
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TickerPriceService", reflect.TypeOf((*MockClient)(nil).TickerPriceService), ctx, symbol)
 }
+
+// WeightUsage mocks base method.
+func (m *MockClient) WeightUsage() binance.WeightUsage {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WeightUsage")
+	ret0, _ := ret[0].(binance.WeightUsage)
+	return ret0
+}
+
+// WeightUsage indicates an expected call of WeightUsage.
+func (mr *MockClientMockRecorder) WeightUsage() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WeightUsage", reflect.TypeOf((*MockClient)(nil).WeightUsage))
+}
+
+// MockMarketStream is a mock of MarketStream interface.
+type MockMarketStream struct {
+	ctrl     *gomock.Controller
+	recorder *MockMarketStreamMockRecorder
+}
+
+// MockMarketStreamMockRecorder is the mock recorder for MockMarketStream.
+type MockMarketStreamMockRecorder struct {
+	mock *MockMarketStream
+}
+
+// NewMockMarketStream creates a new mock instance.
+func NewMockMarketStream(ctrl *gomock.Controller) *MockMarketStream {
+	mock := &MockMarketStream{ctrl: ctrl}
+	mock.recorder = &MockMarketStreamMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockMarketStream) EXPECT() *MockMarketStreamMockRecorder {
+	return m.recorder
+}
+
+// Connected mocks base method.
+func (m *MockMarketStream) Connected() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Connected")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Connected indicates an expected call of Connected.
+func (mr *MockMarketStreamMockRecorder) Connected() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Connected", reflect.TypeOf((*MockMarketStream)(nil).Connected))
+}
+
+// Run mocks base method.
+func (m *MockMarketStream) Run(ctx context.Context, handle func(binance.Tick), onError func(error)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Run", ctx, handle, onError)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Run indicates an expected call of Run.
+func (mr *MockMarketStreamMockRecorder) Run(ctx, handle, onError interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockMarketStream)(nil).Run), ctx, handle, onError)
+}
+
+// SetSymbols mocks base method.
+func (m *MockMarketStream) SetSymbols(symbols ...string) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range symbols {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "SetSymbols", varargs...)
+}
+
+// SetSymbols indicates an expected call of SetSymbols.
+func (mr *MockMarketStreamMockRecorder) SetSymbols(symbols ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSymbols", reflect.TypeOf((*MockMarketStream)(nil).SetSymbols), symbols...)
+}
@@ -0,0 +1,151 @@
+package binance
+
+import (
+	"context"
+	"gexabyte/pkg/clients/marketdata"
+
+	binance_connector "github.com/binance/binance-connector-go"
+	"github.com/shopspring/decimal"
+)
+
+// Types of exchangeInfo filters which carry tick and step sizes.
+const (
+	filterPrice   = "PRICE_FILTER"
+	filterLotSize = "LOT_SIZE"
+)
+
+// provider adapts Client to marketdata.Provider, symbols of the exchange are canonical already.
+type provider struct {
+	client Client
+}
+
+// NewProvider returns the exchange as a market data provider, calls go through the client with its limits and retries.
+func NewProvider(client Client) marketdata.Provider {
+	return &provider{client: client}
+}
+
+func (p *provider) Name() string {
+	return marketdata.ProviderBinance
+}
+
+func (p *provider) Price(ctx context.Context, symbol string) (marketdata.Price, error) {
+	res, err := p.client.TickerPriceService(ctx, symbol)
+	if err != nil {
+		return marketdata.Price{}, err
+	}
+
+	price, err := decimal.NewFromString(res.Price)
+	if err != nil {
+		return marketdata.Price{}, err
+	}
+
+	return marketdata.Price{Symbol: symbol, Price: price}, nil
+}
+
+func (p *provider) Stat24h(ctx context.Context, symbol string) (marketdata.Stat24h, error) {
+	res, err := p.client.Ticker24hService(ctx, symbol)
+	if err != nil {
+		return marketdata.Stat24h{}, err
+	}
+
+	prices, err := parseDecimals(res.OpenPrice, res.LastPrice, res.HighPrice, res.LowPrice)
+	if err != nil {
+		return marketdata.Stat24h{}, err
+	}
+	volume, _ := decimal.NewFromString(res.Volume) // only weighs prices, a malformed one stays zero
+
+	return marketdata.Stat24h{
+		Symbol:    res.Symbol,
+		OpenPrice: prices[0],
+		LastPrice: prices[1],
+		HighPrice: prices[2],
+		LowPrice:  prices[3],
+		Volume:    volume,
+		OpenTime:  int64(res.OpenTime),
+		CloseTime: int64(res.CloseTime),
+		Count:     int(res.Count),
+	}, nil
+}
+
+func (p *provider) Candles(ctx context.Context, symbol, interval string, startTime, endTime int64, limit int) ([]marketdata.Candle, error) {
+	res, err := p.client.KlineService(ctx, symbol, interval, startTime, endTime, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	candles := make([]marketdata.Candle, 0, len(res))
+	for _, k := range res {
+		prices, err := parseDecimals(k.Open, k.Close, k.High, k.Low)
+		if err != nil {
+			return nil, err
+		}
+		volume, _ := decimal.NewFromString(k.Volume)
+
+		candles = append(candles, marketdata.Candle{
+			OpenPrice:  prices[0],
+			ClosePrice: prices[1],
+			HighPrice:  prices[2],
+			LowPrice:   prices[3],
+			Volume:     volume,
+			OpenTime:   int64(k.OpenTime),
+			CloseTime:  int64(k.CloseTime),
+		})
+	}
+
+	return candles, nil
+}
+
+func (p *provider) Symbols(ctx context.Context) ([]marketdata.SymbolInfo, error) {
+	res, err := p.client.ExchangeInfoService(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	symbols := make([]marketdata.SymbolInfo, 0, len(res.Symbols))
+	for _, symbol := range res.Symbols {
+		if symbol == nil {
+			continue
+		}
+		symbols = append(symbols, symbolInfo(symbol))
+	}
+
+	return symbols, nil
+}
+
+// symbolInfo keeps metadata of the symbol, sizes of malformed filters stay zero.
+func symbolInfo(info *binance_connector.SymbolInfo) marketdata.SymbolInfo {
+	res := marketdata.SymbolInfo{
+		Symbol:     info.Symbol,
+		BaseAsset:  info.BaseAsset,
+		QuoteAsset: info.QuoteAsset,
+		Status:     info.Status,
+	}
+
+	for _, filter := range info.Filters {
+		if filter == nil {
+			continue
+		}
+
+		switch filter.FilterType {
+		case filterPrice:
+			res.TickSize, _ = decimal.NewFromString(filter.TickSize)
+		case filterLotSize:
+			res.StepSize, _ = decimal.NewFromString(filter.StepSize)
+		}
+	}
+
+	return res
+}
+
+func parseDecimals(values ...string) ([]decimal.Decimal, error) {
+	res := make([]decimal.Decimal, 0, len(values))
+	for _, v := range values {
+		d, err := decimal.NewFromString(v)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, d)
+	}
+
+	return res, nil
+}
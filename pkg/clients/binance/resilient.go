@@ -0,0 +1,195 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	binance_connector "github.com/binance/binance-connector-go"
+)
+
+// Endpoints with own circuit breakers.
+const (
+	endpointKlines       = "klines"
+	endpointTickerPrice  = "ticker_price"
+	endpointTicker24h    = "ticker_24h"
+	endpointExchangeInfo = "exchange_info"
+)
+
+type ResilienceConfig struct {
+	// Retries of a failed call, zero disables retries.
+	Retries int
+	// BackoffBase is the delay before the first retry, it doubles with every next one up to BackoffMax.
+	// A random part of up to a half of the delay is taken off, so parallel calls do not retry at once.
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// BreakerFailures in a row open the breaker of the endpoint, zero disables breakers.
+	BreakerFailures int
+	// BreakerCooldown is how long an open breaker rejects calls before letting one probe through.
+	BreakerCooldown time.Duration
+}
+
+// resilientClient retries failed calls of the exchange and stops calling endpoints which keep failing.
+// All calls of the client are idempotent GETs, so any of them may be retried.
+type resilientClient struct {
+	next Client
+	cfg  ResilienceConfig
+
+	breakers map[string]*breaker
+}
+
+// NewResilient wraps the client with retries and per endpoint circuit breakers.
+// Calls rejected by an open breaker fail with ErrCircuitOpen.
+func NewResilient(next Client, cfg ResilienceConfig) Client {
+	breakers := make(map[string]*breaker)
+	for _, endpoint := range []string{endpointKlines, endpointTickerPrice, endpointTicker24h, endpointExchangeInfo} {
+		breakers[endpoint] = newBreaker(cfg.BreakerFailures, cfg.BreakerCooldown)
+	}
+
+	return &resilientClient{next: next, cfg: cfg, breakers: breakers}
+}
+
+func (c *resilientClient) KlineService(ctx context.Context, symbol, interval string, startTime, endTime int64, limit int) ([]*binance_connector.KlinesResponse, error) {
+	return call(ctx, c, endpointKlines, func() ([]*binance_connector.KlinesResponse, error) {
+		return c.next.KlineService(ctx, symbol, interval, startTime, endTime, limit)
+	})
+}
+
+func (c *resilientClient) TickerPriceService(ctx context.Context, symbol string) (*binance_connector.TickerPriceResponse, error) {
+	return call(ctx, c, endpointTickerPrice, func() (*binance_connector.TickerPriceResponse, error) {
+		return c.next.TickerPriceService(ctx, symbol)
+	})
+}
+
+func (c *resilientClient) Ticker24hService(ctx context.Context, symbol string) (*binance_connector.Ticker24hrResponse, error) {
+	return call(ctx, c, endpointTicker24h, func() (*binance_connector.Ticker24hrResponse, error) {
+		return c.next.Ticker24hService(ctx, symbol)
+	})
+}
+
+func (c *resilientClient) ExchangeInfoService(ctx context.Context) (*binance_connector.ExchangeInfoResponse, error) {
+	return call(ctx, c, endpointExchangeInfo, func() (*binance_connector.ExchangeInfoResponse, error) {
+		return c.next.ExchangeInfoService(ctx)
+	})
+}
+
+func (c *resilientClient) WeightUsage() WeightUsage {
+	return c.next.WeightUsage()
+}
+
+func call[T any](ctx context.Context, c *resilientClient, endpoint string, fn func() (T, error)) (T, error) {
+	b := c.breakers[endpoint]
+
+	for attempt := 0; ; attempt++ {
+		if wait, ok := b.allow(); !ok {
+			var zero T
+			return zero, &Error{Kind: ErrCircuitOpen, Message: endpoint + " keeps failing", RetryAfter: wait}
+		}
+
+		res, err := fn()
+		if err != nil && ctx.Err() != nil {
+			// the caller gave up, which tells nothing about the endpoint
+			b.done(ctx.Err())
+			var zero T
+			return zero, ctx.Err()
+		}
+		b.done(err)
+		if err == nil || attempt >= c.cfg.Retries || !isUpstreamFailure(err) {
+			return res, err
+		}
+
+		timer := time.NewTimer(c.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			var zero T
+			return zero, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay before the retry following the attempt.
+func (c *resilientClient) backoff(attempt int) time.Duration {
+	delay := c.cfg.BackoffBase << attempt
+	if delay <= 0 || (c.cfg.BackoffMax > 0 && delay > c.cfg.BackoffMax) {
+		delay = c.cfg.BackoffMax
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay - rand.N(delay/2+1)
+}
+
+// isUpstreamFailure tells whether the exchange failed to serve the call, such calls are retried and open breakers.
+func isUpstreamFailure(err error) bool {
+	return errors.Is(err, ErrUnavailable) || errors.Is(err, ErrTimeout)
+}
+
+// breaker opens after failures in a row and rejects calls for the cooldown.
+// Then it lets one probe through, its success closes the breaker and its failure opens it again.
+type breaker struct {
+	failures int
+	cooldown time.Duration
+	now      func() time.Time
+
+	mu       sync.Mutex
+	failed   int
+	openedAt time.Time // zero while closed
+	probing  bool
+}
+
+func newBreaker(failures int, cooldown time.Duration) *breaker {
+	return &breaker{failures: failures, cooldown: cooldown, now: time.Now}
+}
+
+// allow tells whether a call may go, otherwise how long the breaker stays open.
+func (b *breaker) allow() (time.Duration, bool) {
+	if b.failures <= 0 {
+		return 0, true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.openedAt.IsZero() {
+		return 0, true
+	}
+
+	if wait := b.openedAt.Add(b.cooldown).Sub(b.now()); wait > 0 {
+		return wait, false
+	}
+	if b.probing {
+		return 0, false
+	}
+
+	b.probing = true
+	return 0, true
+}
+
+// done takes the outcome of an allowed call.
+// Rate limits and cancellations tell nothing about health of the endpoint and leave the state as is.
+func (b *breaker) done(err error) {
+	if b.failures <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	probe := b.probing
+	b.probing = false
+
+	switch {
+	case isUpstreamFailure(err):
+		b.failed++
+		if probe || b.failed >= b.failures {
+			b.openedAt = b.now()
+		}
+	case err == nil, errors.Is(err, ErrInvalidSymbol), errors.Is(err, ErrInvalidInterval), errors.Is(err, ErrBadRequest):
+		b.failed, b.openedAt = 0, time.Time{}
+	}
+}
@@ -0,0 +1,194 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResilientRetries(t *testing.T) {
+	tc := []struct {
+		name   string
+		fails  int
+		status int
+		body   string
+		calls  int32
+		kind   error
+	}{
+		{
+			name:   "recovers after server errors",
+			fails:  2,
+			status: http.StatusServiceUnavailable,
+			calls:  3,
+		},
+		{
+			name:   "gives up after retries",
+			fails:  5,
+			status: http.StatusServiceUnavailable,
+			calls:  3,
+			kind:   ErrUnavailable,
+		},
+		{
+			name:   "bad request is not retried",
+			fails:  5,
+			status: http.StatusBadRequest,
+			body:   `{"code": -1121, "msg": "Invalid symbol."}`,
+			calls:  1,
+			kind:   ErrInvalidSymbol,
+		},
+		{
+			name:   "rate limit is not retried",
+			fails:  5,
+			status: http.StatusTooManyRequests,
+			calls:  1,
+			kind:   ErrRateLimited,
+		},
+	}
+
+	for _, test := range tc {
+		t.Run(test.name, func(t *testing.T) {
+			var calls atomic.Int32
+			next := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if int(calls.Add(1)) <= test.fails {
+					w.WriteHeader(test.status)
+					w.Write([]byte(test.body))
+					return
+				}
+				w.Write([]byte(`{"symbol": "BTCUSDT", "price": "1.1"}`))
+			})
+
+			c := NewResilient(next, ResilienceConfig{Retries: 2, BackoffBase: time.Millisecond, BackoffMax: time.Millisecond})
+
+			res, err := c.TickerPriceService(context.Background(), "BTCUSDT")
+			assert.Equal(t, test.calls, calls.Load())
+			if test.kind != nil {
+				assert.ErrorIs(t, err, test.kind)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "1.1", res.Price)
+		})
+	}
+}
+
+func TestResilientCircuitOpen(t *testing.T) {
+	var calls, fail atomic.Int32
+	fail.Store(1)
+	next := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if fail.Load() == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte(`{"symbol": "BTCUSDT", "price": "1.1"}`))
+	})
+
+	c := NewResilient(next, ResilienceConfig{BreakerFailures: 2, BreakerCooldown: time.Minute}).(*resilientClient)
+	now := time.Now()
+	c.breakers[endpointTickerPrice].now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		_, err := c.TickerPriceService(context.Background(), "BTCUSDT")
+		assert.ErrorIs(t, err, ErrUnavailable)
+	}
+
+	// the breaker is open and the exchange is not called
+	_, err := c.TickerPriceService(context.Background(), "BTCUSDT")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	var binanceErr *Error
+	require.True(t, errors.As(err, &binanceErr))
+	assert.Equal(t, time.Minute, binanceErr.RetryAfter)
+	assert.Equal(t, int32(2), calls.Load())
+
+	// other endpoints have own breakers
+	_, err = c.Ticker24hService(context.Background(), "BTCUSDT")
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Equal(t, int32(3), calls.Load())
+
+	// a failed probe opens the breaker again
+	now = now.Add(time.Minute)
+	_, err = c.TickerPriceService(context.Background(), "BTCUSDT")
+	assert.ErrorIs(t, err, ErrUnavailable)
+	_, err = c.TickerPriceService(context.Background(), "BTCUSDT")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Equal(t, int32(4), calls.Load())
+
+	// a successful probe closes it
+	now = now.Add(time.Minute)
+	fail.Store(0)
+	for i := 0; i < 2; i++ {
+		_, err = c.TickerPriceService(context.Background(), "BTCUSDT")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, int32(6), calls.Load())
+}
+
+func TestResilientCallerDeadline(t *testing.T) {
+	var calls atomic.Int32
+	next := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte(`{"symbol": "BTCUSDT", "price": "1.1"}`))
+	})
+
+	c := NewResilient(next, ResilienceConfig{Retries: 2, BreakerFailures: 1, BreakerCooldown: time.Minute})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	// the deadline of the caller is neither retried nor taken for a timeout of the exchange
+	_, err := c.TickerPriceService(ctx, "BTCUSDT")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.NotErrorIs(t, err, ErrTimeout)
+	assert.Equal(t, int32(1), calls.Load())
+
+	// and the breaker stays closed
+	res, err := c.TickerPriceService(context.Background(), "BTCUSDT")
+	require.NoError(t, err)
+	assert.Equal(t, "1.1", res.Price)
+}
+
+func TestBreakerHalfOpen(t *testing.T) {
+	now := time.Now()
+	b := newBreaker(1, time.Second)
+	b.now = func() time.Time { return now }
+
+	b.done(ErrUnavailable)
+	_, ok := b.allow()
+	assert.False(t, ok)
+
+	// only one probe goes through at a time
+	now = now.Add(time.Second)
+	_, ok = b.allow()
+	assert.True(t, ok)
+	_, ok = b.allow()
+	assert.False(t, ok)
+
+	// rate limits tell nothing about the endpoint
+	b.done(&Error{Kind: ErrRateLimited})
+	_, ok = b.allow()
+	assert.True(t, ok)
+
+	b.done(nil)
+	_, ok = b.allow()
+	assert.True(t, ok)
+}
+
+func TestBackoff(t *testing.T) {
+	c := &resilientClient{cfg: ResilienceConfig{BackoffBase: 100 * time.Millisecond, BackoffMax: time.Second}}
+
+	for attempt, max := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second} {
+		delay := c.backoff(attempt)
+		assert.LessOrEqual(t, delay, max)
+		assert.GreaterOrEqual(t, delay, max/2)
+	}
+	assert.LessOrEqual(t, c.backoff(100), time.Second)
+}
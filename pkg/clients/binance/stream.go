@@ -0,0 +1,309 @@
+package binance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultStreamURL is the production endpoint of market streams.
+const DefaultStreamURL = "wss://stream.binance.com:9443"
+
+// Kinds of market streams pushing prices of a symbol.
+const (
+	// StreamMiniTicker pushes the last price every second while the symbol trades.
+	StreamMiniTicker = "miniTicker"
+	// StreamKline1m pushes the close price of the current minute candle every two seconds.
+	StreamKline1m = "kline_1m"
+)
+
+const (
+	// streamRotateAfter is less than 24h after which the exchange drops connections,
+	// so the connection is replaced on our own terms.
+	streamRotateAfter = 23 * time.Hour
+	// streamReadTimeout drops a connection which is silent for longer than the exchange pings.
+	streamReadTimeout  = 5 * time.Minute
+	streamWriteTimeout = 10 * time.Second
+
+	streamReconnectMin = time.Second
+	streamReconnectMax = time.Minute
+
+	// the exchange accepts up to 5 messages per second from a client
+	streamMessageInterval  = 250 * time.Millisecond
+	streamParamsPerMessage = 200
+)
+
+// Tick is the price of a symbol pushed by a market stream.
+type Tick struct {
+	Symbol string
+	Price  string
+	// Time is the event time in unix milliseconds.
+	Time int64
+}
+
+type StreamConfig struct {
+	// URL of market streams, DefaultStreamURL when empty.
+	URL string
+	// Kind is StreamMiniTicker or StreamKline1m, StreamMiniTicker when empty.
+	Kind string
+	// RotateAfter is how long one connection lives, 23h when zero.
+	RotateAfter time.Duration
+}
+
+type stream struct {
+	url         string
+	kind        string
+	rotateAfter time.Duration
+	dialer      *websocket.Dialer
+
+	mu      sync.Mutex
+	symbols map[string]struct{}
+	changed chan struct{}
+
+	connected atomic.Bool
+	requestID atomic.Int64
+}
+
+// NewStream returns a combined stream, it does not connect until Run.
+func NewStream(cfg StreamConfig) MarketStream {
+	s := &stream{
+		url:         strings.TrimSuffix(cfg.URL, "/"),
+		kind:        cfg.Kind,
+		rotateAfter: cfg.RotateAfter,
+		dialer:      websocket.DefaultDialer,
+		symbols:     make(map[string]struct{}),
+		changed:     make(chan struct{}, 1),
+	}
+	if s.url == "" {
+		s.url = DefaultStreamURL
+	}
+	if s.kind == "" {
+		s.kind = StreamMiniTicker
+	}
+	if s.rotateAfter <= 0 {
+		s.rotateAfter = streamRotateAfter
+	}
+
+	return s
+}
+
+func (s *stream) SetSymbols(symbols ...string) {
+	s.mu.Lock()
+	s.symbols = make(map[string]struct{}, len(symbols))
+	for _, symbol := range symbols {
+		s.symbols[symbol] = struct{}{}
+	}
+	s.mu.Unlock()
+
+	select {
+	case s.changed <- struct{}{}:
+	default:
+	}
+}
+
+func (s *stream) Connected() bool {
+	return s.connected.Load()
+}
+
+func (s *stream) Run(ctx context.Context, handle func(Tick), onError func(error)) error {
+	delay := streamReconnectMin
+	for {
+		established, err := s.session(ctx, handle, onError)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if established {
+			delay = streamReconnectMin
+		}
+		if err == nil { // rotated
+			continue
+		}
+		onError(err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		delay = min(2*delay, streamReconnectMax)
+	}
+}
+
+// session serves one connection until it fails, is rotated or ctx ends.
+func (s *stream) session(ctx context.Context, handle func(Tick), onError func(error)) (bool, error) {
+	conn, _, err := s.dialer.DialContext(ctx, s.url+"/stream", nil)
+	if err != nil {
+		return false, fmt.Errorf("failed dial market stream: %w", err)
+	}
+	defer conn.Close()
+
+	s.connected.Store(true)
+	defer s.connected.Store(false)
+
+	conn.SetReadDeadline(time.Now().Add(streamReadTimeout))
+	conn.SetPingHandler(func(data string) error {
+		conn.SetReadDeadline(time.Now().Add(streamReadTimeout))
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(streamWriteTimeout))
+	})
+
+	readErr := make(chan error, 1)
+	go func() {
+		readErr <- s.read(conn, handle, onError)
+	}()
+
+	subscribed := make(map[string]struct{})
+	if err := s.resubscribe(ctx, conn, subscribed); err != nil {
+		return true, err
+	}
+
+	rotate := time.NewTimer(s.rotateAfter)
+	defer rotate.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.close(conn)
+			return true, nil
+		case <-rotate.C:
+			s.close(conn)
+			return true, nil
+		case <-s.changed:
+			if err := s.resubscribe(ctx, conn, subscribed); err != nil {
+				return true, err
+			}
+		case err := <-readErr:
+			return true, fmt.Errorf("failed read market stream: %w", err)
+		}
+	}
+}
+
+// resubscribe brings streams of the connection in line with wanted symbols.
+func (s *stream) resubscribe(ctx context.Context, conn *websocket.Conn, subscribed map[string]struct{}) error {
+	s.mu.Lock()
+	var subscribe, unsubscribe []string
+	for symbol := range s.symbols {
+		if _, ok := subscribed[symbol]; !ok {
+			subscribe = append(subscribe, symbol)
+		}
+	}
+	for symbol := range subscribed {
+		if _, ok := s.symbols[symbol]; !ok {
+			unsubscribe = append(unsubscribe, symbol)
+		}
+	}
+	s.mu.Unlock()
+
+	if err := s.send(ctx, conn, "UNSUBSCRIBE", unsubscribe); err != nil {
+		return err
+	}
+	for _, symbol := range unsubscribe {
+		delete(subscribed, symbol)
+	}
+
+	if err := s.send(ctx, conn, "SUBSCRIBE", subscribe); err != nil {
+		return err
+	}
+	for _, symbol := range subscribe {
+		subscribed[symbol] = struct{}{}
+	}
+
+	return nil
+}
+
+// send writes the method for streams of the symbols in batches, keeping within the message rate of the exchange.
+func (s *stream) send(ctx context.Context, conn *websocket.Conn, method string, symbols []string) error {
+	for len(symbols) > 0 {
+		batch := symbols[:min(len(symbols), streamParamsPerMessage)]
+		symbols = symbols[len(batch):]
+
+		params := make([]string, 0, len(batch))
+		for _, symbol := range batch {
+			params = append(params, strings.ToLower(symbol)+"@"+s.kind)
+		}
+
+		conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		err := conn.WriteJSON(map[string]any{"method": method, "params": params, "id": s.requestID.Add(1)})
+		if err != nil {
+			return fmt.Errorf("failed %s: %w", strings.ToLower(method), err)
+		}
+
+		timer := time.NewTimer(streamMessageInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	return nil
+}
+
+// read passes ticks of the connection to handle until reading fails.
+func (s *stream) read(conn *websocket.Conn, handle func(Tick), onError func(error)) error {
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+		conn.SetReadDeadline(time.Now().Add(streamReadTimeout))
+
+		var msg streamMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			onError(fmt.Errorf("failed parse market stream message: %w", err))
+			continue
+		}
+		if msg.Error != nil {
+			onError(fmt.Errorf("market stream request %d failed: code=%d, msg=%s", msg.ID, msg.Error.Code, msg.Error.Msg))
+			continue
+		}
+		if msg.Stream == "" { // replies to requests
+			continue
+		}
+
+		tick := Tick{Symbol: msg.Data.Symbol, Price: msg.Data.Close, Time: msg.Data.EventTime}
+		if msg.Data.Kline != nil {
+			tick.Price = msg.Data.Kline.Close
+		}
+		if tick.Symbol == "" || tick.Price == "" {
+			continue
+		}
+
+		handle(tick)
+	}
+}
+
+// close says goodbye to the exchange, the connection is closed by the caller.
+func (s *stream) close(conn *websocket.Conn) {
+	msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(streamWriteTimeout))
+}
+
+// streamMessage is an event of a combined stream or a reply to a request.
+type streamMessage struct {
+	Stream string `json:"stream"`
+	Data   struct {
+		// the type is declared only to keep "e" off EventTime, json matches names case-insensitively
+		EventType string `json:"e"`
+		EventTime int64  `json:"E"`
+		Symbol    string `json:"s"`
+		Close     string `json:"c"` // miniTicker
+		Kline     *struct {
+			Close string `json:"c"`
+		} `json:"k"`
+	} `json:"data"`
+
+	ID    int64 `json:"id"`
+	Error *struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	} `json:"error"`
+}
@@ -0,0 +1,129 @@
+package binance
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gexabyte/pkg/clients/binance/fake"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tickRecorder keeps ticks received by a stream.
+type tickRecorder struct {
+	mu    sync.Mutex
+	ticks []Tick
+}
+
+func (r *tickRecorder) handle(tick Tick) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ticks = append(r.ticks, tick)
+}
+
+func (r *tickRecorder) reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.ticks = nil
+}
+
+func (r *tickRecorder) symbols() map[string]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	res := make(map[string]int)
+	for _, tick := range r.ticks {
+		res[tick.Symbol]++
+	}
+
+	return res
+}
+
+func runStream(t *testing.T, cfg StreamConfig) (*fake.Server, MarketStream, *tickRecorder) {
+	srv := fake.NewServer(fake.Config{StreamInterval: 10 * time.Millisecond})
+	t.Cleanup(srv.Close)
+
+	cfg.URL = "ws" + strings.TrimPrefix(srv.URL, "http")
+	s := NewStream(cfg)
+	recorder := &tickRecorder{}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- s.Run(ctx, recorder.handle, func(err error) { t.Log(err) })
+	}()
+	t.Cleanup(func() {
+		cancel()
+		assert.ErrorIs(t, <-done, context.Canceled)
+	})
+
+	return srv, s, recorder
+}
+
+func TestStreamSubscriptions(t *testing.T) {
+	_, s, recorder := runStream(t, StreamConfig{})
+
+	s.SetSymbols("BTCUSDT", "ETHUSDT")
+	require.Eventually(t, func() bool {
+		symbols := recorder.symbols()
+		return symbols["BTCUSDT"] > 0 && symbols["ETHUSDT"] > 0
+	}, time.Second, 10*time.Millisecond)
+	assert.True(t, s.Connected())
+
+	recorder.mu.Lock()
+	tick := recorder.ticks[0]
+	recorder.mu.Unlock()
+	assert.NotEmpty(t, tick.Price)
+	assert.NotZero(t, tick.Time)
+
+	// the stream is resubscribed without reconnecting
+	s.SetSymbols("SOLUSDT")
+	time.Sleep(2 * streamMessageInterval)
+	recorder.reset()
+	require.Eventually(t, func() bool {
+		return recorder.symbols()["SOLUSDT"] > 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"SOLUSDT"}, keys(recorder.symbols()))
+}
+
+func TestStreamReconnects(t *testing.T) {
+	srv, s, recorder := runStream(t, StreamConfig{Kind: StreamKline1m})
+
+	s.SetSymbols("BTCUSDT")
+	require.Eventually(t, func() bool { return recorder.symbols()["BTCUSDT"] > 0 }, time.Second, 10*time.Millisecond)
+
+	// the exchange drops the connection and refuses the first reconnect
+	srv.Fail("/stream", 503, "", 1)
+	srv.DropStreams()
+
+	require.Eventually(t, func() bool { return srv.Calls("/stream") == 3 && s.Connected() }, 5*time.Second, 10*time.Millisecond)
+	time.Sleep(streamMessageInterval)
+	recorder.reset()
+	require.Eventually(t, func() bool { return recorder.symbols()["BTCUSDT"] > 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestStreamRotates(t *testing.T) {
+	srv, s, recorder := runStream(t, StreamConfig{RotateAfter: 100 * time.Millisecond})
+
+	s.SetSymbols("BTCUSDT")
+	require.Eventually(t, func() bool { return srv.Calls("/stream") >= 3 }, 2*time.Second, 10*time.Millisecond)
+
+	recorder.reset()
+	require.Eventually(t, func() bool { return recorder.symbols()["BTCUSDT"] > 0 }, time.Second, 10*time.Millisecond)
+	assert.LessOrEqual(t, srv.StreamConns(), 1)
+}
+
+func keys(m map[string]int) []string {
+	res := make([]string, 0, len(m))
+	for k := range m {
+		res = append(res, k)
+	}
+
+	return res
+}
@@ -0,0 +1,324 @@
+// Package bybit is the market data provider of Bybit spot, https://bybit-exchange.github.io/docs/v5/market/tickers.
+package bybit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gexabyte/pkg/clients/marketdata"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultBaseURL is the production public api.
+const DefaultBaseURL = "https://api.bybit.com"
+
+// maxCandles is how many candles Bybit returns in one request.
+const maxCandles = 1000
+
+// Return codes of Bybit, https://bybit-exchange.github.io/docs/v5/error
+const (
+	codeParamsError   = 10001
+	codeRateLimit     = 10006
+	codeIPRateLimit   = 10018
+	codeServerError   = 10016
+	codeInvalidSymbol = 170121
+)
+
+// intervals are names of candles Bybit has.
+var intervals = map[string]string{
+	"1m":  "1",
+	"3m":  "3",
+	"5m":  "5",
+	"15m": "15",
+	"30m": "30",
+	"1h":  "60",
+	"2h":  "120",
+	"4h":  "240",
+	"6h":  "360",
+	"12h": "720",
+	"1d":  "D",
+	"1w":  "W",
+	"1M":  "M",
+}
+
+type Config struct {
+	// BaseURL is DefaultBaseURL when empty.
+	BaseURL string
+	// Timeout limits one http call.
+	Timeout time.Duration
+}
+
+type provider struct {
+	baseURL string
+	client  *http.Client
+}
+
+func New(cfg Config) marketdata.Provider {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &provider{baseURL: baseURL, client: &http.Client{Timeout: cfg.Timeout}}
+}
+
+func (p *provider) Name() string {
+	return marketdata.ProviderBybit
+}
+
+func (p *provider) Price(ctx context.Context, symbol string) (marketdata.Price, error) {
+	t, serverTime, err := p.ticker(ctx, symbol)
+	if err != nil {
+		return marketdata.Price{}, err
+	}
+
+	price, err := decimal.NewFromString(t.LastPrice)
+	if err != nil {
+		return marketdata.Price{}, err
+	}
+
+	return marketdata.Price{Symbol: symbol, Price: price, Time: serverTime}, nil
+}
+
+func (p *provider) Stat24h(ctx context.Context, symbol string) (marketdata.Stat24h, error) {
+	t, serverTime, err := p.ticker(ctx, symbol)
+	if err != nil {
+		return marketdata.Stat24h{}, err
+	}
+
+	var stat marketdata.Stat24h
+	for _, field := range []struct {
+		dst *decimal.Decimal
+		src string
+	}{
+		{&stat.OpenPrice, t.PrevPrice24h},
+		{&stat.LastPrice, t.LastPrice},
+		{&stat.HighPrice, t.HighPrice24h},
+		{&stat.LowPrice, t.LowPrice24h},
+		{&stat.Volume, t.Volume24h},
+	} {
+		if *field.dst, err = decimal.NewFromString(field.src); err != nil {
+			return marketdata.Stat24h{}, err
+		}
+	}
+
+	stat.Symbol = symbol
+	stat.OpenTime = serverTime - (24 * time.Hour).Milliseconds()
+	stat.CloseTime = serverTime
+
+	return stat, nil
+}
+
+func (p *provider) Candles(ctx context.Context, symbol, interval string, startTime, endTime int64, limit int) ([]marketdata.Candle, error) {
+	name, ok := intervals[interval]
+	if !ok {
+		return nil, &marketdata.Error{Provider: p.Name(), Kind: marketdata.ErrInvalidInterval, Message: interval}
+	}
+	pair, err := pairName(symbol)
+	if err != nil {
+		return nil, err
+	}
+	if limit <= 0 || limit > maxCandles {
+		limit = maxCandles
+	}
+
+	query := url.Values{
+		"category": {"spot"},
+		"symbol":   {pair},
+		"interval": {name},
+		"start":    {strconv.FormatInt(startTime, 10)},
+		"end":      {strconv.FormatInt(endTime, 10)},
+		"limit":    {strconv.Itoa(limit)},
+	}
+
+	// [startTime, open, high, low, close, volume, turnover] with strings, newest first
+	var res struct {
+		List [][]string `json:"list"`
+	}
+	if err := p.get(ctx, "/v5/market/kline", query, &res); err != nil {
+		return nil, err
+	}
+
+	candles := make([]marketdata.Candle, 0, len(res.List))
+	for _, row := range res.List {
+		candle, err := parseCandle(row, interval)
+		if err != nil {
+			return nil, err
+		}
+		candles = append(candles, candle)
+	}
+	sort.Slice(candles, func(i, j int) bool { return candles[i].OpenTime < candles[j].OpenTime })
+
+	return candles, nil
+}
+
+func (p *provider) Symbols(ctx context.Context) ([]marketdata.SymbolInfo, error) {
+	var res struct {
+		List []struct {
+			BaseCoin    string `json:"baseCoin"`
+			QuoteCoin   string `json:"quoteCoin"`
+			Status      string `json:"status"`
+			PriceFilter struct {
+				TickSize string `json:"tickSize"`
+			} `json:"priceFilter"`
+			LotSizeFilter struct {
+				BasePrecision string `json:"basePrecision"`
+			} `json:"lotSizeFilter"`
+		} `json:"list"`
+	}
+	if err := p.get(ctx, "/v5/market/instruments-info", url.Values{"category": {"spot"}}, &res); err != nil {
+		return nil, err
+	}
+
+	symbols := make([]marketdata.SymbolInfo, 0, len(res.List))
+	for _, info := range res.List {
+		pair := marketdata.Pair{
+			Base:  marketdata.NormalizeAsset(info.BaseCoin),
+			Quote: marketdata.NormalizeAsset(info.QuoteCoin),
+		}
+
+		status := marketdata.StatusTrading
+		if info.Status != "Trading" {
+			status = strings.ToUpper(info.Status)
+		}
+		tickSize, _ := decimal.NewFromString(info.PriceFilter.TickSize)
+		stepSize, _ := decimal.NewFromString(info.LotSizeFilter.BasePrecision)
+
+		symbols = append(symbols, marketdata.SymbolInfo{
+			Symbol:     pair.Symbol(),
+			BaseAsset:  pair.Base,
+			QuoteAsset: pair.Quote,
+			Status:     status,
+			TickSize:   tickSize,
+			StepSize:   stepSize,
+		})
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i].Symbol < symbols[j].Symbol })
+
+	return symbols, nil
+}
+
+type ticker struct {
+	LastPrice    string `json:"lastPrice"`
+	PrevPrice24h string `json:"prevPrice24h"`
+	HighPrice24h string `json:"highPrice24h"`
+	LowPrice24h  string `json:"lowPrice24h"`
+	Volume24h    string `json:"volume24h"`
+}
+
+// ticker returns the summary of the pair and the server time in unix milliseconds.
+func (p *provider) ticker(ctx context.Context, symbol string) (ticker, int64, error) {
+	pair, err := pairName(symbol)
+	if err != nil {
+		return ticker{}, 0, err
+	}
+
+	var res struct {
+		List []ticker `json:"list"`
+	}
+	serverTime, err := p.call(ctx, "/v5/market/tickers", url.Values{"category": {"spot"}, "symbol": {pair}}, &res)
+	if err != nil {
+		return ticker{}, 0, err
+	}
+	if len(res.List) == 0 {
+		return ticker{}, 0, &marketdata.Error{Provider: p.Name(), Kind: marketdata.ErrInvalidSymbol, Message: symbol}
+	}
+
+	return res.List[0], serverTime, nil
+}
+
+func (p *provider) get(ctx context.Context, path string, query url.Values, out any) error {
+	_, err := p.call(ctx, path, query, out)
+	return err
+}
+
+// call requests a public endpoint and returns the server time, Bybit answers 200 with a return code for most failures.
+func (p *provider) call(ctx context.Context, path string, query url.Values, out any) (int64, error) {
+	var res struct {
+		RetCode int             `json:"retCode"`
+		RetMsg  string          `json:"retMsg"`
+		Result  json.RawMessage `json:"result"`
+		Time    int64           `json:"time"`
+	}
+	err := marketdata.GetJSON(ctx, p.client, p.Name(), p.baseURL+path+"?"+query.Encode(), &res, classify)
+	if err != nil {
+		return 0, err
+	}
+	if res.RetCode != 0 {
+		return 0, &marketdata.Error{Provider: p.Name(), Kind: codeKind(res.RetCode, res.RetMsg), Status: http.StatusOK, Message: res.RetMsg}
+	}
+
+	if err := json.Unmarshal(res.Result, out); err != nil {
+		return 0, &marketdata.Error{Provider: p.Name(), Kind: marketdata.ErrUnavailable, Err: fmt.Errorf("malformed response: %w", err)}
+	}
+
+	return res.Time, nil
+}
+
+// classify maps 4xx of Bybit, it answers 403 when the ip exceeds its rate limit.
+func classify(status int, _ []byte) error {
+	if status == http.StatusForbidden {
+		return marketdata.ErrRateLimited
+	}
+
+	return nil
+}
+
+func codeKind(code int, msg string) error {
+	switch {
+	case code == codeInvalidSymbol:
+		return marketdata.ErrInvalidSymbol
+	case code == codeParamsError && strings.Contains(strings.ToLower(msg), "symbol"):
+		return marketdata.ErrInvalidSymbol
+	case code == codeRateLimit || code == codeIPRateLimit:
+		return marketdata.ErrRateLimited
+	case code == codeServerError:
+		return marketdata.ErrUnavailable
+	}
+
+	return marketdata.ErrBadRequest
+}
+
+// pairName returns the name of the pair on Bybit, which is canonical once aliases are resolved.
+func pairName(symbol string) (string, error) {
+	pair, err := marketdata.ParseSymbol(symbol)
+	if err != nil {
+		return "", &marketdata.Error{Provider: marketdata.ProviderBybit, Kind: marketdata.ErrInvalidSymbol, Err: err}
+	}
+
+	return pair.Symbol(), nil
+}
+
+func parseCandle(row []string, interval string) (marketdata.Candle, error) {
+	if len(row) < 6 {
+		return marketdata.Candle{}, fmt.Errorf("bybit: malformed candle: %v", row)
+	}
+	openTime, err := strconv.ParseInt(row[0], 10, 64)
+	if err != nil {
+		return marketdata.Candle{}, fmt.Errorf("bybit: malformed candle time: %w", err)
+	}
+
+	var values [5]decimal.Decimal
+	for i := range values {
+		if values[i], err = decimal.NewFromString(row[i+1]); err != nil {
+			return marketdata.Candle{}, fmt.Errorf("bybit: malformed candle: %w", err)
+		}
+	}
+
+	return marketdata.Candle{
+		OpenPrice:  values[0],
+		HighPrice:  values[1],
+		LowPrice:   values[2],
+		ClosePrice: values[3],
+		Volume:     values[4],
+		OpenTime:   openTime,
+		CloseTime:  marketdata.CloseTime(openTime, interval),
+	}, nil
+}
@@ -0,0 +1,127 @@
+package bybit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gexabyte/pkg/clients/marketdata"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProvider(t *testing.T, handler http.HandlerFunc) marketdata.Provider {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	return New(Config{BaseURL: srv.URL, Timeout: time.Second})
+}
+
+func TestPrice(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v5/market/tickers", r.URL.Path)
+		assert.Equal(t, "spot", r.URL.Query().Get("category"))
+		switch r.URL.Query().Get("symbol") {
+		case "BTCUSDT":
+			w.Write([]byte(`{"retCode":0,"retMsg":"OK","result":{"category":"spot","list":[{
+				"symbol":"BTCUSDT","lastPrice":"64000.5","prevPrice24h":"63000","highPrice24h":"65000",
+				"lowPrice24h":"62000","volume24h":"1500.25"}]},"time":1714557600000}`))
+		default:
+			w.Write([]byte(`{"retCode":10001,"retMsg":"Not supported symbols","result":{},"time":1714557600000}`))
+		}
+	})
+
+	price, err := p.Price(context.Background(), "XBT/USDT")
+	require.NoError(t, err)
+	assert.Equal(t, "64000.5", price.Price.String())
+	assert.Equal(t, int64(1714557600000), price.Time)
+
+	stat, err := p.Stat24h(context.Background(), "BTC-USDT")
+	require.NoError(t, err)
+	assert.Equal(t, "63000", stat.OpenPrice.String())
+	assert.Equal(t, "1500.25", stat.Volume.String())
+	assert.Equal(t, int64(1714557600000), stat.CloseTime)
+	assert.Equal(t, int64(1714557600000-24*3600_000), stat.OpenTime)
+
+	_, err = p.Price(context.Background(), "ETHUSDT")
+	assert.ErrorIs(t, err, marketdata.ErrInvalidSymbol)
+}
+
+func TestCandles(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v5/market/kline", r.URL.Path)
+		assert.Equal(t, "D", r.URL.Query().Get("interval"))
+		assert.Equal(t, "2", r.URL.Query().Get("limit"))
+		// newest first
+		w.Write([]byte(`{"retCode":0,"retMsg":"OK","result":{"list":[
+			["172800000","2","4","1.5","3","20","60"],
+			["86400000","1","3","0.5","2","10","20"]
+		]},"time":1}`))
+	})
+
+	candles, err := p.Candles(context.Background(), "ETHUSDT", "1d", 86400_000, 172800_000, 2)
+	require.NoError(t, err)
+	require.Len(t, candles, 2)
+	assert.Equal(t, int64(86400_000), candles[0].OpenTime)
+	assert.Equal(t, int64(172800_000-1), candles[0].CloseTime)
+	assert.Equal(t, "1", candles[0].OpenPrice.String())
+	assert.Equal(t, "2", candles[0].ClosePrice.String())
+	assert.Equal(t, "3", candles[0].HighPrice.String())
+	assert.Equal(t, "0.5", candles[0].LowPrice.String())
+	assert.Equal(t, "20", candles[1].Volume.String())
+
+	_, err = p.Candles(context.Background(), "ETHUSDT", "1s", 0, 1, 1)
+	assert.ErrorIs(t, err, marketdata.ErrInvalidInterval)
+}
+
+func TestSymbols(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v5/market/instruments-info", r.URL.Path)
+		w.Write([]byte(`{"retCode":0,"retMsg":"OK","result":{"category":"spot","list":[
+			{"symbol":"BTCUSDT","baseCoin":"BTC","quoteCoin":"USDT","status":"Trading",
+			 "priceFilter":{"tickSize":"0.01"},"lotSizeFilter":{"basePrecision":"0.000001"}},
+			{"symbol":"ABCUSDT","baseCoin":"ABC","quoteCoin":"USDT","status":"PreLaunch",
+			 "priceFilter":{"tickSize":"0.1"},"lotSizeFilter":{"basePrecision":"1"}}
+		]},"time":1}`))
+	})
+
+	symbols, err := p.Symbols(context.Background())
+	require.NoError(t, err)
+	require.Len(t, symbols, 2)
+	assert.Equal(t, "ABCUSDT", symbols[0].Symbol)
+	assert.Equal(t, "PRELAUNCH", symbols[0].Status)
+	assert.Equal(t, "BTCUSDT", symbols[1].Symbol)
+	assert.Equal(t, marketdata.StatusTrading, symbols[1].Status)
+	assert.Equal(t, "0.01", symbols[1].TickSize.String())
+	assert.Equal(t, "0.000001", symbols[1].StepSize.String())
+}
+
+func TestErrors(t *testing.T) {
+	tc := []struct {
+		name   string
+		status int
+		body   string
+		kind   error
+	}{
+		{"rate limit", http.StatusOK, `{"retCode":10006,"retMsg":"Too many visits!"}`, marketdata.ErrRateLimited},
+		{"ip rate limit", http.StatusForbidden, `access too frequent`, marketdata.ErrRateLimited},
+		{"server error", http.StatusOK, `{"retCode":10016,"retMsg":"Server error"}`, marketdata.ErrUnavailable},
+		{"params error", http.StatusOK, `{"retCode":10001,"retMsg":"params error: limit"}`, marketdata.ErrBadRequest},
+		{"gateway", http.StatusBadGateway, ``, marketdata.ErrUnavailable},
+	}
+
+	for _, test := range tc {
+		t.Run(test.name, func(t *testing.T) {
+			p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.body))
+			})
+
+			_, err := p.Price(context.Background(), "BTCUSDT")
+			assert.ErrorIs(t, err, test.kind)
+		})
+	}
+}
@@ -0,0 +1,271 @@
+// Package coinbase is the market data provider of Coinbase Exchange, https://docs.cdp.coinbase.com/exchange/reference.
+package coinbase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gexabyte/pkg/clients/marketdata"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultBaseURL is the production public api.
+const DefaultBaseURL = "https://api.exchange.coinbase.com"
+
+// maxCandles is how many candles Coinbase returns in one request.
+const maxCandles = 300
+
+// granularities are seconds of candles Coinbase has.
+var granularities = map[string]int{
+	"1m":  60,
+	"5m":  300,
+	"15m": 900,
+	"1h":  3600,
+	"6h":  21600,
+	"1d":  86400,
+}
+
+type Config struct {
+	// BaseURL is DefaultBaseURL when empty.
+	BaseURL string
+	// Timeout limits one http call.
+	Timeout time.Duration
+}
+
+type provider struct {
+	baseURL string
+	client  *http.Client
+}
+
+func New(cfg Config) marketdata.Provider {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &provider{baseURL: baseURL, client: &http.Client{Timeout: cfg.Timeout}}
+}
+
+func (p *provider) Name() string {
+	return marketdata.ProviderCoinbase
+}
+
+func (p *provider) Price(ctx context.Context, symbol string) (marketdata.Price, error) {
+	product, err := productID(symbol)
+	if err != nil {
+		return marketdata.Price{}, err
+	}
+
+	var res struct {
+		Price string    `json:"price"`
+		Time  time.Time `json:"time"`
+	}
+	if err := p.get(ctx, "/products/"+product+"/ticker", nil, &res); err != nil {
+		return marketdata.Price{}, err
+	}
+
+	price, err := decimal.NewFromString(res.Price)
+	if err != nil {
+		return marketdata.Price{}, err
+	}
+
+	return marketdata.Price{Symbol: symbol, Price: price, Time: res.Time.UnixMilli()}, nil
+}
+
+func (p *provider) Stat24h(ctx context.Context, symbol string) (marketdata.Stat24h, error) {
+	product, err := productID(symbol)
+	if err != nil {
+		return marketdata.Stat24h{}, err
+	}
+
+	var res struct {
+		Open   string `json:"open"`
+		High   string `json:"high"`
+		Low    string `json:"low"`
+		Last   string `json:"last"`
+		Volume string `json:"volume"`
+	}
+	if err := p.get(ctx, "/products/"+product+"/stats", nil, &res); err != nil {
+		return marketdata.Stat24h{}, err
+	}
+
+	var stat marketdata.Stat24h
+	for _, field := range []struct {
+		dst *decimal.Decimal
+		src string
+	}{
+		{&stat.OpenPrice, res.Open},
+		{&stat.LastPrice, res.Last},
+		{&stat.HighPrice, res.High},
+		{&stat.LowPrice, res.Low},
+		{&stat.Volume, res.Volume},
+	} {
+		if *field.dst, err = decimal.NewFromString(field.src); err != nil {
+			return marketdata.Stat24h{}, err
+		}
+	}
+
+	now := time.Now()
+	stat.Symbol = symbol
+	stat.OpenTime = now.Add(-24 * time.Hour).UnixMilli()
+	stat.CloseTime = now.UnixMilli()
+
+	return stat, nil
+}
+
+// Candles returns up to 300 candles, Coinbase does not return more in one request.
+func (p *provider) Candles(ctx context.Context, symbol, interval string, startTime, endTime int64, limit int) ([]marketdata.Candle, error) {
+	granularity, ok := granularities[interval]
+	if !ok {
+		return nil, &marketdata.Error{Provider: p.Name(), Kind: marketdata.ErrInvalidInterval, Message: interval}
+	}
+	product, err := productID(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 || limit > maxCandles {
+		limit = maxCandles
+	}
+	endTime = min(endTime, startTime+int64(granularity)*1000*int64(limit-1))
+
+	query := url.Values{
+		"granularity": {strconv.Itoa(granularity)},
+		"start":       {time.UnixMilli(startTime).UTC().Format(time.RFC3339)},
+		"end":         {time.UnixMilli(endTime).UTC().Format(time.RFC3339)},
+	}
+
+	// [time, low, high, open, close, volume] with numbers, newest first
+	var rows [][]json.Number
+	if err := p.get(ctx, "/products/"+product+"/candles", query, &rows); err != nil {
+		return nil, err
+	}
+
+	candles := make([]marketdata.Candle, 0, len(rows))
+	for _, row := range rows {
+		candle, err := parseCandle(row, interval)
+		if err != nil {
+			return nil, err
+		}
+		if candle.OpenTime < startTime || candle.OpenTime > endTime {
+			continue
+		}
+		candles = append(candles, candle)
+	}
+	sort.Slice(candles, func(i, j int) bool { return candles[i].OpenTime < candles[j].OpenTime })
+
+	return candles[:min(len(candles), limit)], nil
+}
+
+func (p *provider) Symbols(ctx context.Context) ([]marketdata.SymbolInfo, error) {
+	var res []struct {
+		BaseCurrency    string `json:"base_currency"`
+		QuoteCurrency   string `json:"quote_currency"`
+		QuoteIncrement  string `json:"quote_increment"`
+		BaseIncrement   string `json:"base_increment"`
+		Status          string `json:"status"`
+		TradingDisabled bool   `json:"trading_disabled"`
+	}
+	if err := p.get(ctx, "/products", nil, &res); err != nil {
+		return nil, err
+	}
+
+	symbols := make([]marketdata.SymbolInfo, 0, len(res))
+	for _, product := range res {
+		pair := marketdata.Pair{
+			Base:  marketdata.NormalizeAsset(product.BaseCurrency),
+			Quote: marketdata.NormalizeAsset(product.QuoteCurrency),
+		}
+
+		status := marketdata.StatusTrading
+		switch {
+		case product.Status != "online":
+			status = strings.ToUpper(product.Status)
+		case product.TradingDisabled:
+			status = "HALT"
+		}
+		tickSize, _ := decimal.NewFromString(product.QuoteIncrement)
+		stepSize, _ := decimal.NewFromString(product.BaseIncrement)
+
+		symbols = append(symbols, marketdata.SymbolInfo{
+			Symbol:     pair.Symbol(),
+			BaseAsset:  pair.Base,
+			QuoteAsset: pair.Quote,
+			Status:     status,
+			TickSize:   tickSize,
+			StepSize:   stepSize,
+		})
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i].Symbol < symbols[j].Symbol })
+
+	return symbols, nil
+}
+
+func (p *provider) get(ctx context.Context, path string, query url.Values, out any) error {
+	u := p.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	return marketdata.GetJSON(ctx, p.client, p.Name(), u, out, classify)
+}
+
+// classify maps 4xx of Coinbase, unknown products are answered with 404 NotFound.
+func classify(status int, body []byte) error {
+	var res struct {
+		Message string `json:"message"`
+	}
+	json.Unmarshal(body, &res)
+
+	if status == http.StatusNotFound || strings.EqualFold(res.Message, "NotFound") {
+		return marketdata.ErrInvalidSymbol
+	}
+
+	return nil
+}
+
+// productID returns the id of the product on Coinbase, like BTC-USDT for BTCUSDT.
+func productID(symbol string) (string, error) {
+	pair, err := marketdata.ParseSymbol(symbol)
+	if err != nil {
+		return "", &marketdata.Error{Provider: marketdata.ProviderCoinbase, Kind: marketdata.ErrInvalidSymbol, Err: err}
+	}
+
+	return pair.Base + "-" + pair.Quote, nil
+}
+
+// parseCandle parses [time, low, high, open, close, volume], the time is in seconds.
+func parseCandle(row []json.Number, interval string) (marketdata.Candle, error) {
+	if len(row) < 6 {
+		return marketdata.Candle{}, fmt.Errorf("coinbase: malformed candle: %v", row)
+	}
+	openTime, err := row[0].Int64()
+	if err != nil {
+		return marketdata.Candle{}, fmt.Errorf("coinbase: malformed candle time: %w", err)
+	}
+
+	var values [5]decimal.Decimal
+	for i := range values {
+		if values[i], err = decimal.NewFromString(row[i+1].String()); err != nil {
+			return marketdata.Candle{}, fmt.Errorf("coinbase: malformed candle: %w", err)
+		}
+	}
+
+	open := openTime * 1000
+	return marketdata.Candle{
+		LowPrice:   values[0],
+		HighPrice:  values[1],
+		OpenPrice:  values[2],
+		ClosePrice: values[3],
+		Volume:     values[4],
+		OpenTime:   open,
+		CloseTime:  marketdata.CloseTime(open, interval),
+	}, nil
+}
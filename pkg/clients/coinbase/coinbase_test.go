@@ -0,0 +1,140 @@
+package coinbase
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gexabyte/pkg/clients/marketdata"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProvider(t *testing.T, handler http.HandlerFunc) marketdata.Provider {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	return New(Config{BaseURL: srv.URL, Timeout: time.Second})
+}
+
+func TestPrice(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/products/BTC-USDT/ticker":
+			w.Write([]byte(`{"price":"64000.12","size":"0.1","time":"2024-05-01T10:00:00.123Z"}`))
+		case "/products/BTC-USDT/stats":
+			w.Write([]byte(`{"open":"63000","high":"65000","low":"62000","last":"64000.12","volume":"1234.5"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"message":"NotFound"}`))
+		}
+	})
+
+	price, err := p.Price(context.Background(), "XBTUSDT")
+	require.NoError(t, err)
+	assert.Equal(t, "XBTUSDT", price.Symbol)
+	assert.Equal(t, "64000.12", price.Price.String())
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 123e6, time.UTC).UnixMilli(), price.Time)
+
+	stat, err := p.Stat24h(context.Background(), "BTCUSDT")
+	require.NoError(t, err)
+	assert.Equal(t, "63000", stat.OpenPrice.String())
+	assert.Equal(t, "64000.12", stat.LastPrice.String())
+	assert.Equal(t, "1234.5", stat.Volume.String())
+
+	_, err = p.Price(context.Background(), "ETHUSDT")
+	assert.ErrorIs(t, err, marketdata.ErrInvalidSymbol)
+}
+
+func TestCandles(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/products/ETH-USD/candles", r.URL.Path)
+		assert.Equal(t, "3600", r.URL.Query().Get("granularity"))
+		assert.Equal(t, "1970-01-01T01:00:00Z", r.URL.Query().Get("start"))
+		// newest first
+		w.Write([]byte(`[[7200,1.5,4,2,3,20.5],[3600,0.5,3,1,2,10]]`))
+	})
+
+	candles, err := p.Candles(context.Background(), "ETHUSD", "1h", 3600_000, 7200_000, 10)
+	require.NoError(t, err)
+	require.Len(t, candles, 2)
+	assert.Equal(t, int64(3600_000), candles[0].OpenTime)
+	assert.Equal(t, int64(7200_000-1), candles[0].CloseTime)
+	assert.Equal(t, "1", candles[0].OpenPrice.String())
+	assert.Equal(t, "2", candles[0].ClosePrice.String())
+	assert.Equal(t, "3", candles[0].HighPrice.String())
+	assert.Equal(t, "0.5", candles[0].LowPrice.String())
+	assert.Equal(t, "20.5", candles[1].Volume.String())
+
+	_, err = p.Candles(context.Background(), "ETHUSD", "4h", 0, 1, 1)
+	assert.ErrorIs(t, err, marketdata.ErrInvalidInterval)
+}
+
+func TestSymbols(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/products", r.URL.Path)
+		w.Write([]byte(`[
+			{"id":"BTC-USD","base_currency":"BTC","quote_currency":"USD","quote_increment":"0.01","base_increment":"0.00000001","status":"online","trading_disabled":false},
+			{"id":"ADA-EUR","base_currency":"ADA","quote_currency":"EUR","quote_increment":"0.0001","base_increment":"0.01","status":"online","trading_disabled":true},
+			{"id":"LUNA-USD","base_currency":"LUNA","quote_currency":"USD","status":"delisted"}
+		]`))
+	})
+
+	symbols, err := p.Symbols(context.Background())
+	require.NoError(t, err)
+	require.Len(t, symbols, 3)
+	assert.Equal(t, marketdata.SymbolInfo{
+		Symbol: "ADAEUR", BaseAsset: "ADA", QuoteAsset: "EUR", Status: "HALT",
+		TickSize: symbols[0].TickSize, StepSize: symbols[0].StepSize,
+	}, symbols[0])
+	assert.Equal(t, "0.0001", symbols[0].TickSize.String())
+	assert.Equal(t, "BTCUSD", symbols[1].Symbol)
+	assert.Equal(t, marketdata.StatusTrading, symbols[1].Status)
+	assert.Equal(t, "DELISTED", symbols[2].Status)
+}
+
+func TestErrors(t *testing.T) {
+	tc := []struct {
+		name   string
+		status int
+		header map[string]string
+		body   string
+		kind   error
+		retry  time.Duration
+	}{
+		{"rate limit", http.StatusTooManyRequests, map[string]string{"Retry-After": "3"}, `{"message":"Public rate limit exceeded"}`, marketdata.ErrRateLimited, 3 * time.Second},
+		{"server error", http.StatusServiceUnavailable, nil, ``, marketdata.ErrUnavailable, 0},
+		{"bad request", http.StatusBadRequest, nil, `{"message":"Invalid end"}`, marketdata.ErrBadRequest, 0},
+		{"malformed body", http.StatusOK, nil, `not json`, marketdata.ErrUnavailable, 0},
+	}
+
+	for _, test := range tc {
+		t.Run(test.name, func(t *testing.T) {
+			p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+				for k, v := range test.header {
+					w.Header().Set(k, v)
+				}
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.body))
+			})
+
+			_, err := p.Price(context.Background(), "BTCUSD")
+			assert.ErrorIs(t, err, test.kind)
+			assert.Equal(t, test.retry, marketdata.RetryAfter(err))
+		})
+	}
+
+	t.Run("timeout", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(100 * time.Millisecond)
+		}))
+		t.Cleanup(srv.Close)
+
+		p := New(Config{BaseURL: srv.URL, Timeout: 10 * time.Millisecond})
+		_, err := p.Price(context.Background(), "BTCUSD")
+		assert.ErrorIs(t, err, marketdata.ErrTimeout)
+	})
+}
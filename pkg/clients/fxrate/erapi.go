@@ -0,0 +1,59 @@
+package fxrate
+
+import (
+	"context"
+	"gexabyte/pkg/clients/marketdata"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultERAPIURL is the open access api of ExchangeRate-API, https://www.exchangerate-api.com/docs/free.
+// Rates are updated once a day, so the same rates keep the time of their update.
+const DefaultERAPIURL = "https://open.er-api.com"
+
+type ERAPIConfig struct {
+	// BaseURL is DefaultERAPIURL when empty.
+	BaseURL string
+	// Timeout limits one http call.
+	Timeout time.Duration
+}
+
+type erapi struct {
+	baseURL string
+	client  *http.Client
+}
+
+type erapiLatest struct {
+	Result             string                     `json:"result"`
+	ErrorType          string                     `json:"error-type"`
+	TimeLastUpdateUnix int64                      `json:"time_last_update_unix"`
+	Rates              map[string]decimal.Decimal `json:"rates"`
+}
+
+func NewERAPI(cfg ERAPIConfig) Provider {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultERAPIURL
+	}
+
+	return &erapi{baseURL: baseURL, client: &http.Client{Timeout: cfg.Timeout}}
+}
+
+func (p *erapi) Name() string {
+	return ProviderERAPI
+}
+
+func (p *erapi) Rates(ctx context.Context, currencies ...string) ([]Rate, error) {
+	var latest erapiLatest
+	if err := marketdata.GetJSON(ctx, p.client, ProviderERAPI, p.baseURL+"/v6/latest/USD", &latest, nil); err != nil {
+		return nil, err
+	}
+	if latest.Result != "success" {
+		return nil, &marketdata.Error{Provider: ProviderERAPI, Kind: marketdata.ErrUnavailable, Message: latest.ErrorType}
+	}
+
+	return pick(latest.Rates, latest.TimeLastUpdateUnix*1000, currencies), nil
+}
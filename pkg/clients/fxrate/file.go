@@ -0,0 +1,58 @@
+package fxrate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+type file struct {
+	path string
+}
+
+// fileRates is the content of the file, like {"time": 1710460800000, "rates": {"EUR": "0.92", "KZT": 447.5}}.
+// Time is unix milliseconds, the modification time of the file is taken when it is missing.
+type fileRates struct {
+	Time  int64                      `json:"time"`
+	Rates map[string]decimal.Decimal `json:"rates"`
+}
+
+// NewFile reads rates from the json file on every call, so it can be updated while the service runs.
+func NewFile(path string) Provider {
+	return &file{path: path}
+}
+
+func (p *file) Name() string {
+	return ProviderFile
+}
+
+func (p *file) Rates(ctx context.Context, currencies ...string) ([]Rate, error) {
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := os.ReadFile(p.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var content fileRates
+	if err := json.Unmarshal(body, &content); err != nil {
+		return nil, fmt.Errorf("malformed fx rates file %s: %w", p.path, err)
+	}
+	if content.Time == 0 {
+		content.Time = info.ModTime().UnixMilli()
+	}
+
+	table := make(map[string]decimal.Decimal, len(content.Rates))
+	for currency, rate := range content.Rates {
+		table[strings.ToUpper(currency)] = rate
+	}
+
+	return pick(table, content.Time, currencies), nil
+}
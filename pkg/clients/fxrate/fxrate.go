@@ -0,0 +1,46 @@
+// Package fxrate gives rates of fiat currencies to USD, currencies are ISO 4217 codes like EUR.
+package fxrate
+
+import (
+	"context"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Names of the supported providers, they are stored as the source of rates.
+const (
+	ProviderStatic = "static"
+	ProviderFile   = "file"
+	ProviderERAPI  = "erapi"
+)
+
+var ProviderNames = []string{ProviderStatic, ProviderFile, ProviderERAPI}
+
+// Rate is how many units of Currency one USD buys, Time is unix milliseconds.
+type Rate struct {
+	Currency string
+	Rate     decimal.Decimal
+	Time     int64
+}
+
+// Provider gives the latest rates. Failed calls of remote providers match kinds of errors of the marketdata package.
+type Provider interface {
+	// Name is one of ProviderNames.
+	Name() string
+	// Rates returns rates of the currencies, ones the provider does not know are left out.
+	Rates(ctx context.Context, currencies ...string) ([]Rate, error)
+}
+
+// pick returns rates of the currencies found in the table by upper case codes, in order of currencies.
+func pick(table map[string]decimal.Decimal, time int64, currencies []string) []Rate {
+	rates := make([]Rate, 0, len(currencies))
+	for _, currency := range currencies {
+		currency = strings.ToUpper(strings.TrimSpace(currency))
+		if rate, ok := table[currency]; ok && rate.IsPositive() {
+			rates = append(rates, Rate{Currency: currency, Rate: rate, Time: time})
+		}
+	}
+
+	return rates
+}
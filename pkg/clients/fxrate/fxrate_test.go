@@ -0,0 +1,86 @@
+package fxrate
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gexabyte/pkg/clients/marketdata"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatic(t *testing.T) {
+	p := NewStatic(map[string]decimal.Decimal{"eur": decimal.RequireFromString("0.92"), "KZT": decimal.NewFromInt(470)})
+
+	rates, err := p.Rates(context.Background(), "KZT", "GBP", "EUR")
+	require.NoError(t, err)
+	require.Len(t, rates, 2, "unknown currencies are left out")
+	assert.Equal(t, "KZT", rates[0].Currency)
+	assert.Equal(t, "470", rates[0].Rate.String())
+	assert.Equal(t, "EUR", rates[1].Currency)
+	assert.InDelta(t, time.Now().UnixMilli(), rates[1].Time, float64(time.Minute.Milliseconds()))
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	p := NewFile(path)
+
+	_, err := p.Rates(context.Background(), "EUR")
+	assert.Error(t, err, "no file")
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"time": 1710460800000, "rates": {"eur": "0.92", "KZT": 447.5}}`), 0o600))
+	rates, err := p.Rates(context.Background(), "EUR", "KZT")
+	require.NoError(t, err)
+	assert.Equal(t, []Rate{
+		{Currency: "EUR", Rate: decimal.RequireFromString("0.92"), Time: 1710460800000},
+		{Currency: "KZT", Rate: decimal.RequireFromString("447.5"), Time: 1710460800000},
+	}, rates)
+
+	// the file is read again, rates without time take the time of the file
+	require.NoError(t, os.WriteFile(path, []byte(`{"rates": {"EUR": "0.93"}}`), 0o600))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	rates, err = p.Rates(context.Background(), "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, []Rate{{Currency: "EUR", Rate: decimal.RequireFromString("0.93"), Time: info.ModTime().UnixMilli()}}, rates)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"rates": ["EUR"]}`), 0o600))
+	_, err = p.Rates(context.Background(), "EUR")
+	assert.Error(t, err)
+}
+
+func TestERAPI(t *testing.T) {
+	status, body := http.StatusOK, ""
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v6/latest/USD", r.URL.Path)
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+
+	p := NewERAPI(ERAPIConfig{BaseURL: srv.URL + "/", Timeout: time.Second})
+
+	body = `{"result":"success","base_code":"USD","time_last_update_unix":1710460951,
+		"rates":{"USD":1,"EUR":0.919,"KZT":447.52}}`
+	rates, err := p.Rates(context.Background(), "EUR", "KZT", "XXX")
+	require.NoError(t, err)
+	assert.Equal(t, []Rate{
+		{Currency: "EUR", Rate: decimal.RequireFromString("0.919"), Time: 1710460951000},
+		{Currency: "KZT", Rate: decimal.RequireFromString("447.52"), Time: 1710460951000},
+	}, rates)
+
+	body = `{"result":"error","error-type":"unsupported-code"}`
+	_, err = p.Rates(context.Background(), "EUR")
+	assert.ErrorIs(t, err, marketdata.ErrUnavailable)
+
+	status, body = http.StatusTooManyRequests, ""
+	_, err = p.Rates(context.Background(), "EUR")
+	assert.ErrorIs(t, err, marketdata.ErrRateLimited)
+}
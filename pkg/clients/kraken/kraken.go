@@ -0,0 +1,339 @@
+// Package kraken is the market data provider of Kraken spot, https://docs.kraken.com/api/docs/rest-api/get-ticker-information.
+package kraken
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gexabyte/pkg/clients/marketdata"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultBaseURL is the production public api.
+const DefaultBaseURL = "https://api.kraken.com"
+
+// intervals are minutes of candles Kraken has.
+var intervals = map[string]int{
+	"1m":  1,
+	"5m":  5,
+	"15m": 15,
+	"30m": 30,
+	"1h":  60,
+	"4h":  240,
+	"1d":  1440,
+	"1w":  10080,
+}
+
+// assets are names Kraken gives to canonical assets in pair names.
+var assets = map[string]string{
+	"BTC":  "XBT",
+	"DOGE": "XDG",
+}
+
+type Config struct {
+	// BaseURL is DefaultBaseURL when empty.
+	BaseURL string
+	// Timeout limits one http call.
+	Timeout time.Duration
+}
+
+type provider struct {
+	baseURL string
+	client  *http.Client
+}
+
+func New(cfg Config) marketdata.Provider {
+	baseURL := strings.TrimSuffix(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &provider{baseURL: baseURL, client: &http.Client{Timeout: cfg.Timeout}}
+}
+
+func (p *provider) Name() string {
+	return marketdata.ProviderKraken
+}
+
+func (p *provider) Price(ctx context.Context, symbol string) (marketdata.Price, error) {
+	ticker, err := p.ticker(ctx, symbol)
+	if err != nil {
+		return marketdata.Price{}, err
+	}
+
+	price, err := decimal.NewFromString(first(ticker.Close))
+	if err != nil {
+		return marketdata.Price{}, malformed(err)
+	}
+
+	return marketdata.Price{Symbol: symbol, Price: price}, nil
+}
+
+// Stat24h takes high, low, volume and count of the last 24h, Kraken opens the day at midnight UTC though,
+// so the open price is the one of today.
+func (p *provider) Stat24h(ctx context.Context, symbol string) (marketdata.Stat24h, error) {
+	ticker, err := p.ticker(ctx, symbol)
+	if err != nil {
+		return marketdata.Stat24h{}, err
+	}
+
+	var stat marketdata.Stat24h
+	for _, field := range []struct {
+		dst *decimal.Decimal
+		src string
+	}{
+		{&stat.OpenPrice, ticker.Open},
+		{&stat.LastPrice, first(ticker.Close)},
+		{&stat.HighPrice, last(ticker.High)},
+		{&stat.LowPrice, last(ticker.Low)},
+		{&stat.Volume, last(ticker.Volume)},
+	} {
+		if *field.dst, err = decimal.NewFromString(field.src); err != nil {
+			return marketdata.Stat24h{}, malformed(err)
+		}
+	}
+
+	now := time.Now()
+	stat.Symbol = symbol
+	stat.OpenTime = now.Add(-24 * time.Hour).UnixMilli()
+	stat.CloseTime = now.UnixMilli()
+	if len(ticker.Trades) > 0 {
+		stat.Count = ticker.Trades[len(ticker.Trades)-1]
+	}
+
+	return stat, nil
+}
+
+// Candles returns up to the last 720 candles of the interval, Kraken keeps no older ones.
+func (p *provider) Candles(ctx context.Context, symbol, interval string, startTime, endTime int64, limit int) ([]marketdata.Candle, error) {
+	minutes, ok := intervals[interval]
+	if !ok {
+		return nil, &marketdata.Error{Provider: p.Name(), Kind: marketdata.ErrInvalidInterval, Message: interval}
+	}
+	pair, err := pairName(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{
+		"pair":     {pair},
+		"interval": {strconv.Itoa(minutes)},
+		// since is exclusive
+		"since": {strconv.FormatInt(startTime/1000-1, 10)},
+	}
+
+	var res map[string]json.RawMessage
+	if err := p.get(ctx, "/0/public/OHLC", query, &res); err != nil {
+		return nil, err
+	}
+
+	var rows [][]any
+	for key, raw := range res {
+		if key == "last" {
+			continue
+		}
+		if err := json.Unmarshal(raw, &rows); err != nil {
+			return nil, malformed(err)
+		}
+	}
+
+	candles := make([]marketdata.Candle, 0, len(rows))
+	for _, row := range rows {
+		candle, err := parseCandle(row, interval)
+		if err != nil {
+			return nil, err
+		}
+		if candle.OpenTime < startTime || candle.OpenTime > endTime {
+			continue
+		}
+		candles = append(candles, candle)
+		if len(candles) == limit {
+			break
+		}
+	}
+
+	return candles, nil
+}
+
+func (p *provider) Symbols(ctx context.Context) ([]marketdata.SymbolInfo, error) {
+	var res map[string]struct {
+		Altname     string `json:"altname"`
+		WSName      string `json:"wsname"`
+		TickSize    string `json:"tick_size"`
+		LotDecimals int32  `json:"lot_decimals"`
+		Status      string `json:"status"`
+	}
+	if err := p.get(ctx, "/0/public/AssetPairs", nil, &res); err != nil {
+		return nil, err
+	}
+
+	symbols := make([]marketdata.SymbolInfo, 0, len(res))
+	for _, info := range res {
+		// wsname keeps the separator, pair names like XXBTZUSD do not split reliably
+		pair, err := marketdata.ParseSymbol(info.WSName)
+		if err != nil {
+			continue
+		}
+
+		status := marketdata.StatusTrading
+		if info.Status != "online" {
+			status = strings.ToUpper(info.Status)
+		}
+		tickSize, _ := decimal.NewFromString(info.TickSize)
+
+		symbols = append(symbols, marketdata.SymbolInfo{
+			Symbol:     pair.Symbol(),
+			BaseAsset:  pair.Base,
+			QuoteAsset: pair.Quote,
+			Status:     status,
+			TickSize:   tickSize,
+			StepSize:   decimal.New(1, -info.LotDecimals),
+		})
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i].Symbol < symbols[j].Symbol })
+
+	return symbols, nil
+}
+
+// ticker is the summary of a pair, arrays hold values of today and of the last 24h.
+type ticker struct {
+	Close  []string `json:"c"` // price and lot volume of the last trade
+	Volume []string `json:"v"`
+	Trades []int    `json:"t"`
+	Low    []string `json:"l"`
+	High   []string `json:"h"`
+	Open   string   `json:"o"`
+}
+
+func (p *provider) ticker(ctx context.Context, symbol string) (ticker, error) {
+	pair, err := pairName(symbol)
+	if err != nil {
+		return ticker{}, err
+	}
+
+	// the result is keyed by the legacy name of the pair, like XXBTZUSD, it has the only pair
+	var res map[string]ticker
+	if err := p.get(ctx, "/0/public/Ticker", url.Values{"pair": {pair}}, &res); err != nil {
+		return ticker{}, err
+	}
+	for _, t := range res {
+		return t, nil
+	}
+
+	return ticker{}, &marketdata.Error{Provider: p.Name(), Kind: marketdata.ErrInvalidSymbol, Message: symbol}
+}
+
+// get calls a public endpoint, Kraken answers 200 with a list of errors for most failures.
+func (p *provider) get(ctx context.Context, path string, query url.Values, out any) error {
+	u := p.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var res struct {
+		Error  []string        `json:"error"`
+		Result json.RawMessage `json:"result"`
+	}
+	if err := marketdata.GetJSON(ctx, p.client, p.Name(), u, &res, nil); err != nil {
+		return err
+	}
+	if len(res.Error) > 0 {
+		return &marketdata.Error{Provider: p.Name(), Kind: errorKind(res.Error[0]), Status: http.StatusOK, Message: strings.Join(res.Error, ", ")}
+	}
+
+	if err := json.Unmarshal(res.Result, out); err != nil {
+		return malformed(err)
+	}
+
+	return nil
+}
+
+// malformed reports a response of Kraken which could not be parsed, like an upstream failure.
+func malformed(err error) error {
+	return &marketdata.Error{Provider: marketdata.ProviderKraken, Kind: marketdata.ErrUnavailable, Err: fmt.Errorf("malformed response: %w", err)}
+}
+
+// errorKind maps errors of Kraken, like EQuery:Unknown asset pair, to kinds of marketdata.
+func errorKind(msg string) error {
+	switch {
+	case strings.HasPrefix(msg, "EQuery:Unknown asset pair"):
+		return marketdata.ErrInvalidSymbol
+	case strings.Contains(msg, "Rate limit exceeded") || strings.Contains(msg, "Too many requests"):
+		return marketdata.ErrRateLimited
+	case strings.HasPrefix(msg, "EService:"):
+		return marketdata.ErrUnavailable
+	}
+
+	return marketdata.ErrBadRequest
+}
+
+// pairName returns the name of the pair on Kraken, like XBTUSDT for BTCUSDT.
+func pairName(symbol string) (string, error) {
+	pair, err := marketdata.ParseSymbol(symbol)
+	if err != nil {
+		return "", &marketdata.Error{Provider: marketdata.ProviderKraken, Kind: marketdata.ErrInvalidSymbol, Err: err}
+	}
+
+	return asset(pair.Base) + asset(pair.Quote), nil
+}
+
+func asset(name string) string {
+	if a, ok := assets[name]; ok {
+		return a
+	}
+
+	return name
+}
+
+// parseCandle parses [time, open, high, low, close, vwap, volume, count], the time is in seconds.
+func parseCandle(row []any, interval string) (marketdata.Candle, error) {
+	if len(row) < 7 {
+		return marketdata.Candle{}, malformed(fmt.Errorf("candle %v", row))
+	}
+	openTime, ok := row[0].(float64)
+	if !ok {
+		return marketdata.Candle{}, malformed(fmt.Errorf("candle time %v", row[0]))
+	}
+
+	var values [5]decimal.Decimal
+	for i, idx := range []int{1, 2, 3, 4, 6} {
+		s, _ := row[idx].(string)
+		v, err := decimal.NewFromString(s)
+		if err != nil {
+			return marketdata.Candle{}, malformed(fmt.Errorf("candle: %w", err))
+		}
+		values[i] = v
+	}
+
+	open := int64(openTime) * 1000
+	return marketdata.Candle{
+		OpenPrice:  values[0],
+		HighPrice:  values[1],
+		LowPrice:   values[2],
+		ClosePrice: values[3],
+		Volume:     values[4],
+		OpenTime:   open,
+		CloseTime:  marketdata.CloseTime(open, interval),
+	}, nil
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func last(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
@@ -0,0 +1,138 @@
+package kraken
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gexabyte/pkg/clients/marketdata"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProvider(t *testing.T, handler http.HandlerFunc) marketdata.Provider {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	return New(Config{BaseURL: srv.URL, Timeout: time.Second})
+}
+
+func TestPrice(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/0/public/Ticker", r.URL.Path)
+		switch r.URL.Query().Get("pair") {
+		case "XBTUSDT":
+			w.Write([]byte(`{"error":[],"result":{"XBTUSDT":{
+				"c":["64000.10","0.01"],"v":["100","250.5"],"t":[1000,2500],
+				"l":["63000","62000"],"h":["65000","66000"],"o":"63500"}}}`))
+		default:
+			w.Write([]byte(`{"error":["EQuery:Unknown asset pair"]}`))
+		}
+	})
+
+	price, err := p.Price(context.Background(), "BTCUSDT")
+	require.NoError(t, err)
+	assert.Equal(t, "BTCUSDT", price.Symbol)
+	assert.Equal(t, "64000.1", price.Price.String())
+
+	stat, err := p.Stat24h(context.Background(), "XBT/USDT")
+	require.NoError(t, err)
+	assert.Equal(t, "63500", stat.OpenPrice.String())
+	assert.Equal(t, "66000", stat.HighPrice.String())
+	assert.Equal(t, "62000", stat.LowPrice.String())
+	assert.Equal(t, "250.5", stat.Volume.String())
+	assert.Equal(t, 2500, stat.Count)
+
+	_, err = p.Price(context.Background(), "ETHUSDX")
+	assert.ErrorIs(t, err, marketdata.ErrInvalidSymbol)
+}
+
+func TestCandles(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/0/public/OHLC", r.URL.Path)
+		assert.Equal(t, "60", r.URL.Query().Get("interval"))
+		assert.Equal(t, "3599", r.URL.Query().Get("since"))
+		w.Write([]byte(`{"error":[],"result":{"XETHZUSD":[
+			[3600,"1","3","0.5","2","1.8","10",5],
+			[7200,"2","4","1.5","3","2.5","20",7],
+			[10800,"3","5","2.5","4","3.5","30",9]
+		],"last":10800}}`))
+	})
+
+	candles, err := p.Candles(context.Background(), "ETHUSD", "1h", 3600_000, 7200_000, 10)
+	require.NoError(t, err)
+	require.Len(t, candles, 2)
+	assert.Equal(t, int64(3600_000), candles[0].OpenTime)
+	assert.Equal(t, int64(7200_000-1), candles[0].CloseTime)
+	assert.Equal(t, "1", candles[0].OpenPrice.String())
+	assert.Equal(t, "2", candles[0].ClosePrice.String())
+	assert.Equal(t, "3", candles[0].HighPrice.String())
+	assert.Equal(t, "0.5", candles[0].LowPrice.String())
+	assert.Equal(t, "10", candles[0].Volume.String())
+
+	_, err = p.Candles(context.Background(), "ETHUSD", "3m", 0, 1, 1)
+	assert.ErrorIs(t, err, marketdata.ErrInvalidInterval)
+
+	p = newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":[],"result":{"XETHZUSD":[[3600,"1","3","0.5","n/a","1.8","10",5]],"last":3600}}`))
+	})
+	_, err = p.Candles(context.Background(), "ETHUSD", "1h", 3600_000, 7200_000, 10)
+	assert.ErrorIs(t, err, marketdata.ErrUnavailable)
+}
+
+func TestSymbols(t *testing.T) {
+	p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":[],"result":{
+			"XXBTZUSD":{"altname":"XBTUSD","wsname":"XBT/USD","tick_size":"0.1","lot_decimals":8,"status":"online"},
+			"XDGUSDT":{"altname":"XDGUSDT","wsname":"XDG/USDT","tick_size":"0.0000001","lot_decimals":4,"status":"cancel_only"}
+		}}`))
+	})
+
+	symbols, err := p.Symbols(context.Background())
+	require.NoError(t, err)
+	require.Len(t, symbols, 2)
+	assert.Equal(t, "BTCUSD", symbols[0].Symbol)
+	assert.Equal(t, "BTC", symbols[0].BaseAsset)
+	assert.Equal(t, "USD", symbols[0].QuoteAsset)
+	assert.Equal(t, marketdata.StatusTrading, symbols[0].Status)
+	assert.Equal(t, "0.1", symbols[0].TickSize.String())
+	assert.Equal(t, "0.00000001", symbols[0].StepSize.String())
+	assert.Equal(t, "DOGEUSDT", symbols[1].Symbol)
+	assert.Equal(t, "CANCEL_ONLY", symbols[1].Status)
+}
+
+func TestErrors(t *testing.T) {
+	tc := []struct {
+		name   string
+		status int
+		body   string
+		kind   error
+	}{
+		{"rate limit", http.StatusOK, `{"error":["EAPI:Rate limit exceeded"]}`, marketdata.ErrRateLimited},
+		{"service unavailable", http.StatusOK, `{"error":["EService:Unavailable"]}`, marketdata.ErrUnavailable},
+		{"invalid arguments", http.StatusOK, `{"error":["EGeneral:Invalid arguments"]}`, marketdata.ErrBadRequest},
+		{"gateway", http.StatusBadGateway, `<html></html>`, marketdata.ErrUnavailable},
+		{"too many requests", http.StatusTooManyRequests, ``, marketdata.ErrRateLimited},
+		{"malformed body", http.StatusOK, `{"error":[],"result":[]}`, marketdata.ErrUnavailable},
+		{"malformed price", http.StatusOK, `{"error":[],"result":{"XBTUSD":{"c":["n/a","0.01"]}}}`, marketdata.ErrUnavailable},
+	}
+
+	for _, test := range tc {
+		t.Run(test.name, func(t *testing.T) {
+			p := newTestProvider(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.body))
+			})
+
+			_, err := p.Price(context.Background(), "BTCUSD")
+			assert.ErrorIs(t, err, test.kind)
+
+			var mdErr *marketdata.Error
+			require.ErrorAs(t, err, &mdErr)
+			assert.Equal(t, marketdata.ProviderKraken, mdErr.Provider)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package marketdata
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Kinds of failed calls of venues, errors of adapters match one of them with errors.Is.
+var (
+	ErrInvalidSymbol   = errors.New("invalid symbol")
+	ErrInvalidInterval = errors.New("invalid interval")
+	ErrBadRequest      = errors.New("bad request")
+	ErrRateLimited     = errors.New("rate limited")
+	ErrIPBanned        = errors.New("ip banned")
+	ErrTimeout         = errors.New("upstream timeout")
+	ErrUnavailable     = errors.New("upstream unavailable")
+	// ErrCircuitOpen is returned without calling the venue while it keeps failing.
+	ErrCircuitOpen = errors.New("circuit open")
+)
+
+// Error is a failed call of a venue.
+type Error struct {
+	Provider string
+	Kind     error
+	// Status is the http status of the response, zero when no response was received.
+	Status int
+	// Message is taken from the response body when it has one.
+	Message string
+	// RetryAfter is how long the venue asks to wait, zero when it does not tell.
+	RetryAfter time.Duration
+
+	Err error
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Err != nil:
+		return fmt.Sprintf("%s: %s: %s", e.Provider, e.Kind, e.Err)
+	case e.Message != "":
+		return fmt.Sprintf("%s: %s: status=%d, msg=%s", e.Provider, e.Kind, e.Status, e.Message)
+	}
+
+	return fmt.Sprintf("%s: %s: status=%d", e.Provider, e.Kind, e.Status)
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// RetryAfter returns how long the venue asked to wait, zero when err does not tell it.
+func RetryAfter(err error) time.Duration {
+	var retry interface{ RetryAfterDuration() time.Duration }
+	if errors.As(err, &retry) {
+		return retry.RetryAfterDuration()
+	}
+
+	return 0
+}
+
+func (e *Error) RetryAfterDuration() time.Duration {
+	return e.RetryAfter
+}
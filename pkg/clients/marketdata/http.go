@@ -0,0 +1,85 @@
+package marketdata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxBodySize limits bodies read from venues.
+const maxBodySize = 32 << 20
+
+// ClassifyFunc returns the kind of a 4xx response by its body, nil leaves ErrBadRequest.
+type ClassifyFunc func(status int, body []byte) error
+
+// GetJSON calls url and decodes the response body into out. Failed calls return *Error of the provider:
+// 429 is ErrRateLimited, 5xx is ErrUnavailable and other 4xx are passed to classify.
+// Cancellation by the caller is returned as is.
+func GetJSON(ctx context.Context, client *http.Client, provider, url string, out any, classify ClassifyFunc) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return &Error{Provider: provider, Kind: ErrBadRequest, Err: err}
+	}
+	req.Header.Set("Accept", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return wrapTransportError(provider, err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxBodySize))
+	if err != nil {
+		return wrapTransportError(provider, err)
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		e := &Error{Provider: provider, Kind: ErrBadRequest, Status: res.StatusCode, Message: string(body)}
+		switch {
+		case res.StatusCode == http.StatusTooManyRequests:
+			e.Kind, e.RetryAfter = ErrRateLimited, retryAfter(res.Header)
+		case res.StatusCode >= http.StatusInternalServerError:
+			e.Kind = ErrUnavailable
+		case classify != nil:
+			if kind := classify(res.StatusCode, body); kind != nil {
+				e.Kind = kind
+			}
+		}
+		return e
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return &Error{Provider: provider, Kind: ErrUnavailable, Status: res.StatusCode, Err: fmt.Errorf("malformed response: %w", err)}
+	}
+
+	return nil
+}
+
+func wrapTransportError(provider string, err error) error {
+	if errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return &Error{Provider: provider, Kind: ErrTimeout, Err: err}
+	}
+
+	return &Error{Provider: provider, Kind: ErrUnavailable, Err: err}
+}
+
+// retryAfter parses the Retry-After header in seconds.
+func retryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+
+	return time.Duration(seconds) * time.Second
+}
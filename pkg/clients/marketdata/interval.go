@@ -0,0 +1,36 @@
+package marketdata
+
+import "time"
+
+var intervalDurations = map[string]time.Duration{
+	"1s":  time.Second,
+	"1m":  time.Minute,
+	"3m":  3 * time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"2h":  2 * time.Hour,
+	"4h":  4 * time.Hour,
+	"6h":  6 * time.Hour,
+	"8h":  8 * time.Hour,
+	"12h": 12 * time.Hour,
+	"1d":  24 * time.Hour,
+	"3d":  3 * 24 * time.Hour,
+	"1w":  7 * 24 * time.Hour,
+}
+
+// CloseTime returns the close time of the candle of the interval opened at openTime, times are unix milliseconds.
+// Months are counted in UTC, unknown intervals give openTime.
+func CloseTime(openTime int64, interval string) int64 {
+	if interval == "1M" {
+		return time.UnixMilli(openTime).UTC().AddDate(0, 1, 0).UnixMilli() - 1
+	}
+
+	d, ok := intervalDurations[interval]
+	if !ok {
+		return openTime
+	}
+
+	return openTime + d.Milliseconds() - 1
+}
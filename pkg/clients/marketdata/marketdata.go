@@ -0,0 +1,79 @@
+// Package marketdata is a venue neutral view of exchanges: current prices, 24h summaries, candles and listed pairs.
+// Symbols are canonical, like BTCUSDT, every adapter translates them to the names of its venue.
+package marketdata
+
+import (
+	"context"
+
+	"github.com/shopspring/decimal"
+)
+
+// Names of the supported venues, they are stored as the source of prices.
+const (
+	ProviderBinance  = "binance"
+	ProviderKraken   = "kraken"
+	ProviderCoinbase = "coinbase"
+	ProviderBybit    = "bybit"
+)
+
+var ProviderNames = []string{ProviderBinance, ProviderKraken, ProviderCoinbase, ProviderBybit}
+
+// StatusTrading is the status of pairs open for trading, other statuses are passed as the venue names them.
+const StatusTrading = "TRADING"
+
+// Provider calls one venue. Failed calls match one of the kinds of errors.go with errors.Is.
+type Provider interface {
+	// Name is one of ProviderNames.
+	Name() string
+	Price(ctx context.Context, symbol string) (Price, error)
+	Stat24h(ctx context.Context, symbol string) (Stat24h, error)
+	// Candles returns candles opened between startTime and endTime, oldest first.
+	// Intervals are named like 1m, 4h, 1d, 1w, a venue which does not have one returns ErrInvalidInterval.
+	Candles(ctx context.Context, symbol, interval string, startTime, endTime int64, limit int) ([]Candle, error)
+	// Symbols returns all pairs listed on the venue.
+	Symbols(ctx context.Context) ([]SymbolInfo, error)
+}
+
+type Price struct {
+	Symbol string
+	Price  decimal.Decimal
+	// Time is the time of the price in unix milliseconds, zero when the venue does not tell it.
+	Time int64
+}
+
+// Stat24h is the rolling 24h summary, times are unix milliseconds.
+type Stat24h struct {
+	Symbol    string
+	OpenPrice decimal.Decimal
+	LastPrice decimal.Decimal
+	HighPrice decimal.Decimal
+	LowPrice  decimal.Decimal
+	// Volume is traded within 24h in the base asset.
+	Volume decimal.Decimal
+
+	OpenTime  int64
+	CloseTime int64
+	// Count of trades, zero when the venue does not tell it.
+	Count int
+}
+
+type Candle struct {
+	OpenPrice  decimal.Decimal
+	ClosePrice decimal.Decimal
+	HighPrice  decimal.Decimal
+	LowPrice   decimal.Decimal
+	Volume     decimal.Decimal
+
+	OpenTime  int64
+	CloseTime int64
+}
+
+// SymbolInfo is a pair listed on a venue, sizes are zero when the venue does not tell them.
+type SymbolInfo struct {
+	Symbol     string
+	BaseAsset  string
+	QuoteAsset string
+	Status     string
+	TickSize   decimal.Decimal
+	StepSize   decimal.Decimal
+}
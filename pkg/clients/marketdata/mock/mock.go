@@ -0,0 +1,110 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: ./pkg/clients/marketdata/marketdata.go
+
+// Package mock_marketdata is a generated GoMock package.
+package mock_marketdata
+
+import (
+	context "context"
+	marketdata "gexabyte/pkg/clients/marketdata"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockProvider is a mock of Provider interface.
+type MockProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockProviderMockRecorder
+}
+
+// MockProviderMockRecorder is the mock recorder for MockProvider.
+type MockProviderMockRecorder struct {
+	mock *MockProvider
+}
+
+// NewMockProvider creates a new mock instance.
+func NewMockProvider(ctrl *gomock.Controller) *MockProvider {
+	mock := &MockProvider{ctrl: ctrl}
+	mock.recorder = &MockProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockProvider) EXPECT() *MockProviderMockRecorder {
+	return m.recorder
+}
+
+// Candles mocks base method.
+func (m *MockProvider) Candles(ctx context.Context, symbol, interval string, startTime, endTime int64, limit int) ([]marketdata.Candle, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Candles", ctx, symbol, interval, startTime, endTime, limit)
+	ret0, _ := ret[0].([]marketdata.Candle)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Candles indicates an expected call of Candles.
+func (mr *MockProviderMockRecorder) Candles(ctx, symbol, interval, startTime, endTime, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Candles", reflect.TypeOf((*MockProvider)(nil).Candles), ctx, symbol, interval, startTime, endTime, limit)
+}
+
+// Name mocks base method.
+func (m *MockProvider) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name.
+func (mr *MockProviderMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockProvider)(nil).Name))
+}
+
+// Price mocks base method.
+func (m *MockProvider) Price(ctx context.Context, symbol string) (marketdata.Price, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Price", ctx, symbol)
+	ret0, _ := ret[0].(marketdata.Price)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Price indicates an expected call of Price.
+func (mr *MockProviderMockRecorder) Price(ctx, symbol interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Price", reflect.TypeOf((*MockProvider)(nil).Price), ctx, symbol)
+}
+
+// Stat24h mocks base method.
+func (m *MockProvider) Stat24h(ctx context.Context, symbol string) (marketdata.Stat24h, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stat24h", ctx, symbol)
+	ret0, _ := ret[0].(marketdata.Stat24h)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stat24h indicates an expected call of Stat24h.
+func (mr *MockProviderMockRecorder) Stat24h(ctx, symbol interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stat24h", reflect.TypeOf((*MockProvider)(nil).Stat24h), ctx, symbol)
+}
+
+// Symbols mocks base method.
+func (m *MockProvider) Symbols(ctx context.Context) ([]marketdata.SymbolInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Symbols", ctx)
+	ret0, _ := ret[0].([]marketdata.SymbolInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Symbols indicates an expected call of Symbols.
+func (mr *MockProviderMockRecorder) Symbols(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Symbols", reflect.TypeOf((*MockProvider)(nil).Symbols), ctx)
+}
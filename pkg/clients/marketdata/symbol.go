@@ -0,0 +1,111 @@
+package marketdata
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pair is a base and a quote asset in canonical names.
+type Pair struct {
+	Base  string
+	Quote string
+}
+
+// Symbol returns the canonical symbol of the pair, like BTCUSDT.
+func (p Pair) Symbol() string {
+	return p.Base + p.Quote
+}
+
+func (p Pair) String() string {
+	return p.Base + "/" + p.Quote
+}
+
+// assetAliases are names some venues use instead of canonical ones.
+var assetAliases = map[string]string{
+	"XBT": "BTC",
+	"XDG": "DOGE",
+}
+
+// knownQuotes split symbols without a separator, longer ones are tried first so USDT is not taken for USD.
+var knownQuotes = []string{
+	"FDUSD",
+	"USDT", "USDC", "BUSD", "TUSD", "USDE",
+	"DAI", "USD", "EUR", "GBP", "JPY", "TRY", "BRL", "AUD", "CAD", "CHF",
+	"BTC", "XBT", "ETH", "BNB",
+}
+
+// legacyAssets are names of Kraken with the X or Z prefix, which its pairs still carry.
+var legacyAssets = map[string]string{
+	"XXBT": "BTC",
+	"XXDG": "DOGE",
+	"XETH": "ETH",
+	"XETC": "ETC",
+	"XLTC": "LTC",
+	"XXRP": "XRP",
+	"XXLM": "XLM",
+	"XXMR": "XMR",
+	"XZEC": "ZEC",
+	"ZUSD": "USD",
+	"ZEUR": "EUR",
+	"ZGBP": "GBP",
+	"ZJPY": "JPY",
+	"ZCAD": "CAD",
+	"ZAUD": "AUD",
+	"ZCHF": "CHF",
+}
+
+// NormalizeAsset returns the canonical name of an asset.
+func NormalizeAsset(asset string) string {
+	asset = strings.ToUpper(strings.TrimSpace(asset))
+	if canonical, ok := legacyAssets[asset]; ok {
+		return canonical
+	}
+	if canonical, ok := assetAliases[asset]; ok {
+		return canonical
+	}
+
+	return asset
+}
+
+// ParseSymbol splits a symbol of any venue into a canonical pair.
+// BTC/USDT, BTC-USDT, BTC_USDT and XBTUSDT all give BTC and USDT,
+// symbols without a separator are split by the longest known quote asset.
+func ParseSymbol(symbol string) (Pair, error) {
+	s := strings.ToUpper(strings.TrimSpace(symbol))
+
+	if base, quote, ok := cutSeparator(s); ok {
+		if base == "" || quote == "" {
+			return Pair{}, fmt.Errorf("%w: %s", ErrInvalidSymbol, symbol)
+		}
+		return Pair{Base: NormalizeAsset(base), Quote: NormalizeAsset(quote)}, nil
+	}
+
+	for _, quote := range knownQuotes {
+		base, ok := strings.CutSuffix(s, quote)
+		if ok && base != "" {
+			return Pair{Base: NormalizeAsset(base), Quote: NormalizeAsset(quote)}, nil
+		}
+	}
+
+	return Pair{}, fmt.Errorf("%w: %s has no known quote asset", ErrInvalidSymbol, symbol)
+}
+
+// NormalizeSymbol returns the canonical symbol, symbols which do not parse are only upper cased.
+func NormalizeSymbol(symbol string) string {
+	pair, err := ParseSymbol(symbol)
+	if err != nil {
+		return strings.ToUpper(strings.TrimSpace(symbol))
+	}
+
+	return pair.Symbol()
+}
+
+func cutSeparator(s string) (string, string, bool) {
+	for _, sep := range []string{"/", "-", "_"} {
+		if base, quote, ok := strings.Cut(s, sep); ok {
+			return base, quote, true
+		}
+	}
+
+	return "", "", false
+}
@@ -0,0 +1,49 @@
+package marketdata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSymbol(t *testing.T) {
+	tc := []struct {
+		symbol string
+		pair   Pair
+	}{
+		{"BTCUSDT", Pair{"BTC", "USDT"}},
+		{"btcusdt", Pair{"BTC", "USDT"}},
+		{"BTC/USDT", Pair{"BTC", "USDT"}},
+		{"BTC-USDT", Pair{"BTC", "USDT"}},
+		{"btc_usdt", Pair{"BTC", "USDT"}},
+		{"XBT/USDT", Pair{"BTC", "USDT"}},
+		{"XBTUSDT", Pair{"BTC", "USDT"}},
+		{"XDGUSD", Pair{"DOGE", "USD"}},
+		{"XXBT/ZUSD", Pair{"BTC", "USD"}},
+		{"BTCTUSD", Pair{"BTC", "TUSD"}},
+		{"ETHBTC", Pair{"ETH", "BTC"}},
+		{"1000SATSFDUSD", Pair{"1000SATS", "FDUSD"}},
+	}
+
+	for _, test := range tc {
+		t.Run(test.symbol, func(t *testing.T) {
+			pair, err := ParseSymbol(test.symbol)
+			require.NoError(t, err)
+			assert.Equal(t, test.pair, pair)
+		})
+	}
+
+	for _, symbol := range []string{"", "USDT", "ABCDEF", "BTC/", "/USDT"} {
+		_, err := ParseSymbol(symbol)
+		assert.ErrorIs(t, err, ErrInvalidSymbol, symbol)
+	}
+}
+
+func TestNormalizeSymbol(t *testing.T) {
+	assert.Equal(t, "BTCUSDT", NormalizeSymbol("XBT/USDT"))
+	assert.Equal(t, "BTCUSDT", NormalizeSymbol("BTC-USDT"))
+	assert.Equal(t, "ETHUSDC", NormalizeSymbol(" eth-usdc "))
+	// unknown quotes are only upper cased
+	assert.Equal(t, "ABCDEF", NormalizeSymbol("abcdef"))
+}
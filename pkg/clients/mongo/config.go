@@ -0,0 +1,12 @@
+package mongo
+
+import "time"
+
+const (
+	pingTimeout = 5 * time.Second
+)
+
+type Config struct {
+	URI      string
+	Database string
+}
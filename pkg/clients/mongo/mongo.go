@@ -0,0 +1,29 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type Client struct {
+	*mongo.Database
+}
+
+func NewClient(cfg Config) (*Client, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+
+	conn, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.URI))
+	if err != nil {
+		return nil, fmt.Errorf("failed connect database: %w", err)
+	}
+
+	if err := conn.Ping(ctx, nil); err != nil {
+		return nil, fmt.Errorf("failed ping database: %w", err)
+	}
+
+	return &Client{Database: conn.Database(cfg.Database)}, nil
+}
@@ -9,15 +9,86 @@ import (
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
-func RunDBMigration(migrationURL string, dsn string) error {
-	migration, err := migrate.New(migrationURL, dsn)
+// Migration moves the database schema between versions of migration files.
+type Migration struct {
+	migrate *migrate.Migrate
+}
+
+func NewMigration(migrationURL string, dsn string) (*Migration, error) {
+	m, err := migrate.New(migrationURL, dsn)
 	if err != nil {
-		return fmt.Errorf("failed create migrate: %w", err)
+		return nil, fmt.Errorf("failed create migrate: %w", err)
 	}
 
-	if err = migration.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+	return &Migration{migrate: m}, nil
+}
+
+// Up applies all migrations which are not applied yet.
+func (m *Migration) Up() error {
+	if err := m.migrate.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
 		return fmt.Errorf("failed up migrate: %w", err)
 	}
 
 	return nil
 }
+
+// Down rolls back the last steps migrations.
+func (m *Migration) Down(steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("steps must be positive, got %d", steps)
+	}
+
+	if err := m.migrate.Steps(-steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed down migrate: %w", err)
+	}
+
+	return nil
+}
+
+// Goto migrates up or down to the version.
+func (m *Migration) Goto(version uint) error {
+	if err := m.migrate.Migrate(version); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return fmt.Errorf("failed goto migrate: %w", err)
+	}
+
+	return nil
+}
+
+// Version returns the applied version, zero when nothing is applied.
+// Dirty means the last migration failed and the schema must be fixed by hand and forced.
+func (m *Migration) Version() (version uint, dirty bool, err error) {
+	version, dirty, err = m.migrate.Version()
+	if errors.Is(err, migrate.ErrNilVersion) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed get migrate version: %w", err)
+	}
+
+	return version, dirty, nil
+}
+
+// Force sets the version without running migrations and clears the dirty flag.
+// Version -1 means no migrations are applied.
+func (m *Migration) Force(version int) error {
+	if err := m.migrate.Force(version); err != nil {
+		return fmt.Errorf("failed force migrate: %w", err)
+	}
+
+	return nil
+}
+
+func (m *Migration) Close() error {
+	sourceErr, dbErr := m.migrate.Close()
+	return errors.Join(sourceErr, dbErr)
+}
+
+func RunDBMigration(migrationURL string, dsn string) error {
+	migration, err := NewMigration(migrationURL, dsn)
+	if err != nil {
+		return err
+	}
+	defer migration.Close()
+
+	return migration.Up()
+}
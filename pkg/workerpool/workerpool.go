@@ -0,0 +1,125 @@
+// Package workerpool runs tasks with bounded parallelism and collects their results in order of tasks or as they complete.
+package workerpool
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Task computes one result. Its ctx is done once the task times out or the run is cancelled,
+// calls made by the task should take it to be cancelled with the task.
+type Task[T any] func(ctx context.Context) (T, error)
+
+type Config struct {
+	// Workers is how many tasks run at the same time, all tasks run at once when it is zero.
+	Workers int
+	// TaskTimeout limits each task on its own, tasks are limited only by the ctx of the run when it is zero.
+	TaskTimeout time.Duration
+}
+
+// Result of the task at Index of the run.
+type Result[T any] struct {
+	Index int
+	Value T
+	Err   error
+}
+
+// Stream runs tasks and sends their results as they complete, the channel is closed after the last one.
+// Tasks not started before ctx is done are not run, their results carry the error of ctx.
+// Results are buffered, so workers never wait for a reader which stopped reading.
+func Stream[T any](ctx context.Context, cfg Config, tasks ...Task[T]) <-chan Result[T] {
+	out := make(chan Result[T], len(tasks))
+
+	indexes := make(chan int, len(tasks))
+	for i := range tasks {
+		indexes <- i
+	}
+	close(indexes)
+
+	workers := cfg.Workers
+	if workers <= 0 || workers > len(tasks) {
+		workers = len(tasks)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				out <- run(ctx, cfg.TaskTimeout, i, tasks[i])
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// All runs tasks and returns all results in order of tasks, failed tasks do not stop others.
+func All[T any](ctx context.Context, cfg Config, tasks ...Task[T]) []Result[T] {
+	results := make([]Result[T], len(tasks))
+	for res := range Stream(ctx, cfg, tasks...) {
+		results[res.Index] = res
+	}
+
+	return results
+}
+
+// Collect runs tasks and returns their values in order of tasks.
+// The first failed task cancels tasks which are still running or waiting, its error is returned.
+func Collect[T any](ctx context.Context, cfg Config, tasks ...Task[T]) ([]T, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// the failure cancels the run before its worker takes the next task
+	var (
+		once  sync.Once
+		first error
+	)
+	failFast := make([]Task[T], 0, len(tasks))
+	for _, task := range tasks {
+		failFast = append(failFast, func(ctx context.Context) (T, error) {
+			value, err := task(ctx)
+			if err != nil {
+				once.Do(func() {
+					first = err
+					cancel()
+				})
+			}
+			return value, err
+		})
+	}
+
+	values := make([]T, len(tasks))
+	for res := range Stream(ctx, cfg, failFast...) {
+		if res.Err != nil {
+			// results of cancelled tasks may come first, the error which cancelled them is returned
+			once.Do(func() { first = res.Err })
+			return nil, first
+		}
+		values[res.Index] = res.Value
+	}
+
+	return values, nil
+}
+
+func run[T any](ctx context.Context, timeout time.Duration, i int, task Task[T]) Result[T] {
+	if err := ctx.Err(); err != nil {
+		return Result[T]{Index: i, Err: err}
+	}
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	value, err := task(ctx)
+	return Result[T]{Index: i, Value: value, Err: err}
+}
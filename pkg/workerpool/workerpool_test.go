@@ -0,0 +1,121 @@
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStream(t *testing.T) {
+	var running, peak atomic.Int32
+	tasks := make([]Task[int], 0, 10)
+	for i := range 10 {
+		tasks = append(tasks, func(ctx context.Context) (int, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+
+			time.Sleep(5 * time.Millisecond)
+			return i * 10, nil
+		})
+	}
+
+	seen := make(map[int]int)
+	for res := range Stream(context.Background(), Config{Workers: 3}, tasks...) {
+		require.NoError(t, res.Err)
+		seen[res.Index] = res.Value
+	}
+
+	assert.Len(t, seen, 10)
+	assert.Equal(t, 90, seen[9])
+	assert.LessOrEqual(t, peak.Load(), int32(3), "no more than workers run at once")
+
+	_, open := <-Stream[int](context.Background(), Config{})
+	assert.False(t, open, "no tasks close the stream")
+}
+
+func TestAll(t *testing.T) {
+	failed := errors.New("failed")
+	results := All(context.Background(), Config{Workers: 2},
+		func(ctx context.Context) (string, error) {
+			time.Sleep(10 * time.Millisecond)
+			return "slow", nil
+		},
+		func(ctx context.Context) (string, error) { return "", failed },
+		func(ctx context.Context) (string, error) { return "fast", nil },
+	)
+
+	require.Len(t, results, 3)
+	assert.Equal(t, Result[string]{Index: 0, Value: "slow"}, results[0])
+	assert.ErrorIs(t, results[1].Err, failed)
+	assert.Equal(t, "fast", results[2].Value)
+}
+
+func TestCollect(t *testing.T) {
+	values, err := Collect(context.Background(), Config{},
+		func(ctx context.Context) (int, error) {
+			time.Sleep(10 * time.Millisecond)
+			return 1, nil
+		},
+		func(ctx context.Context) (int, error) { return 2, nil },
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, values, "values are in order of tasks")
+
+	// the failure cancels the running task and the waiting one does not start
+	failed := errors.New("failed")
+	var cancelled, started atomic.Bool
+	_, err = Collect(context.Background(), Config{Workers: 2},
+		func(ctx context.Context) (int, error) {
+			select {
+			case <-ctx.Done():
+				cancelled.Store(true)
+				return 0, ctx.Err()
+			case <-time.After(time.Second):
+				return 1, nil
+			}
+		},
+		func(ctx context.Context) (int, error) { return 0, failed },
+		func(ctx context.Context) (int, error) {
+			started.Store(true)
+			return 3, nil
+		},
+	)
+	assert.ErrorIs(t, err, failed)
+	assert.Eventually(t, cancelled.Load, time.Second, time.Millisecond)
+	assert.False(t, started.Load())
+}
+
+func TestTaskTimeout(t *testing.T) {
+	start := time.Now()
+	results := All(context.Background(), Config{TaskTimeout: 20 * time.Millisecond},
+		func(ctx context.Context) (int, error) {
+			<-ctx.Done()
+			return 0, ctx.Err()
+		},
+		func(ctx context.Context) (int, error) { return 1, nil },
+	)
+
+	assert.ErrorIs(t, results[0].Err, context.DeadlineExceeded)
+	assert.NoError(t, results[1].Err)
+	assert.Less(t, time.Since(start), time.Second)
+
+	// tasks of a done run are not started
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results = All(ctx, Config{}, func(ctx context.Context) (int, error) {
+		t.Error("task of a cancelled run started")
+		return 0, nil
+	})
+	assert.ErrorIs(t, results[0].Err, context.Canceled)
+}